@@ -1,14 +1,23 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"calendar/internal/auth"
 	"calendar/internal/calendar"
 	"calendar/internal/config"
 	"calendar/internal/handlers"
 	"calendar/internal/middleware"
+	"calendar/internal/notifier"
 )
 
 func main() {
@@ -17,11 +26,31 @@ func main() {
 		log.Fatalf("Ошибка загрузки конфигурации: %v", err)
 	}
 
-	calendarService := calendar.NewService()
+	if len(os.Args) > 1 && os.Args[1] == "token" {
+		issueToken(cfg.Auth, os.Args[2:])
+		return
+	}
+
+	store, err := calendar.NewStore(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Ошибка открытия хранилища: %v", err)
+	}
+
+	calendarService := calendar.NewService(store)
+
+	reminderNotifier, err := notifier.New(cfg.Reminders.Notifier)
+	if err != nil {
+		log.Fatalf("Ошибка настройки напоминаний: %v", err)
+	}
+
+	// Первый SIGINT или SIGTERM запускает плавную остановку, второй завершает процесс сразу
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	remindersDone := calendarService.StartReminders(ctx, reminderNotifier, cfg.Reminders)
 
 	handler := handlers.NewHandler(calendarService)
 
-	logger := middleware.NewLogger()
+	logger := middleware.NewLogger(cfg.Log)
 
 	mux := http.NewServeMux()
 
@@ -32,13 +61,61 @@ func main() {
 	mux.HandleFunc("/events_for_day", handler.GetEventsForDay)
 	mux.HandleFunc("/events_for_week", handler.GetEventsForWeek)
 	mux.HandleFunc("/events_for_month", handler.GetEventsForMonth)
+	mux.HandleFunc("/events_in_range", handler.GetEventsInRange)
+	mux.HandleFunc("/free_busy", handler.GetFreeBusy)
+	mux.HandleFunc("/search_events", handler.SearchEvents)
+
+	mux.HandleFunc("/user_settings", handler.GetUserSettings)
+	mux.HandleFunc("/update_user_settings", handler.UpdateUserSettings)
+
+	mux.HandleFunc("/events.ics", handler.ExportICS)
+	mux.HandleFunc("/import_ics", handler.ImportICS)
+
+	mux.HandleFunc("/share_calendar", handler.ShareCalendar)
+	mux.HandleFunc("/unshare_calendar", handler.UnshareCalendar)
+	mux.HandleFunc("/calendar_shares", handler.GetCalendarShares)
+
+	mux.HandleFunc("/respond_invitation", handler.RespondInvitation)
+	mux.HandleFunc("/invitations", handler.GetInvitations)
+
+	handler.RegisterV2(mux)
+
+	var root http.Handler = mux
+	if cfg.Auth.Secret != "" {
+		root = middleware.NewAuth(auth.NewTokens(cfg.Auth.Secret)).AuthMiddleware(mux)
+	} else {
+		log.Printf("CALENDAR_AUTH_SECRET не задан: аутентификация отключена, пользователь берется из user_id запроса")
+	}
+
+	// Id запроса присваивается первым, чтобы попасть в журнал, а журнал пишется снаружи восстановления
+	// после паники, чтобы видеть итоговый код ответа. Предварительные запросы CORS не требуют токена
+	middlewares := []func(http.Handler) http.Handler{
+		middleware.RequestIDMiddleware,
+		logger.LoggingMiddleware,
+		logger.RecoveryMiddleware,
+	}
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		middlewares = append(middlewares, middleware.NewCORS(cfg.CORS).CORSMiddleware)
+	}
+	if cfg.RateLimit.RPS > 0 {
+		middlewares = append(middlewares, middleware.NewRateLimiter(cfg.RateLimit).RateLimitMiddleware)
+	}
 
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.Port),
-		Handler: logger.LoggingMiddleware(mux),
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           middleware.Chain(root, middlewares...),
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
 	}
 
-	log.Printf("Сервер календаря запущен на порту %d", cfg.Port)
+	scheme := "http"
+	if cfg.Server.TLS.Enabled() {
+		scheme = "https"
+	}
+	log.Printf("Сервер календаря запущен на порту %d (%s), хранилище: %s", cfg.Port, scheme, cfg.Storage.Backend)
+	log.Printf("Напоминания: %s, проверка каждые %s", cfg.Reminders.Notifier.Kind, cfg.Reminders.Interval)
 	log.Printf("Доступные эндпоинты:")
 	log.Printf("  POST /create_event - создание события")
 	log.Printf("  POST /update_event - обновление события")
@@ -46,8 +123,86 @@ func main() {
 	log.Printf("  GET  /events_for_day - события на день")
 	log.Printf("  GET  /events_for_week - события на неделю")
 	log.Printf("  GET  /events_for_month - события на месяц")
+	log.Printf("  GET  /events_in_range - события за интервал, постранично")
+	log.Printf("  GET  /free_busy - занятость и общее свободное время пользователей")
+	log.Printf("  GET  /search_events - полнотекстовый поиск событий")
+	log.Printf("  GET  /user_settings - настройки пользователя")
+	log.Printf("  POST /update_user_settings - изменение часового пояса пользователя")
+	log.Printf("  GET  /events.ics - экспорт событий в iCalendar")
+	log.Printf("  POST /import_ics - импорт событий из iCalendar")
+	log.Printf("  POST /share_calendar - выдача доступа к календарю")
+	log.Printf("  POST /unshare_calendar - отзыв доступа к календарю")
+	log.Printf("  GET  /calendar_shares - общие календари пользователя")
+	log.Printf("  POST /respond_invitation - ответ на приглашение")
+	log.Printf("  GET  /invitations - приглашения пользователя")
+	log.Printf("  API v2: /api/v2/users/{user}/events[/{id}] - POST, GET, PUT, PATCH, DELETE")
+	log.Printf("  API v2: /api/v2/users/{user}/events/{id}/rsvp - PUT")
+	log.Printf("  API v2: /api/v2/users/{user}/invitations - GET")
+	log.Printf("  API v2: /api/v2/users/{user}/shares[/{grantee}] - GET, PUT, DELETE")
+	log.Printf("  API v2: /api/v2/freebusy - GET")
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- listen(server, cfg.Server.TLS)
+	}()
+
+	var exitErr error
+	select {
+	case err := <-serverErr:
+		exitErr = fmt.Errorf("ошибка запуска сервера: %w", err)
+		stop()
+	case <-ctx.Done():
+		stop()
+		log.Printf("Получен сигнал остановки, завершаем начатые запросы (до %s)", cfg.Server.ShutdownTimeout)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Не все запросы завершились до остановки: %v", err)
+		}
+		cancel()
+	}
+
+	// Хранилище закрывается, только когда его больше никто не использует: ни обработчики запросов,
+	// ни планировщик напоминаний. Файловое хранилище при закрытии сворачивает журнал в снимок
+	<-remindersDone
+	if err := store.Close(); err != nil {
+		log.Printf("Ошибка закрытия хранилища: %v", err)
+	}
+
+	if exitErr != nil {
+		log.Fatal(exitErr)
+	}
+	log.Printf("Сервер остановлен")
+}
 
-	if err := server.ListenAndServe(); err != nil {
-		log.Fatalf("Ошибка запуска сервера: %v", err)
+// listen принимает запросы по HTTP или, если задан сертификат, по HTTPS до остановки сервера
+func listen(server *http.Server, tls config.TLSConfig) error {
+	var err error
+	if tls.Enabled() {
+		err = server.ListenAndServeTLS(tls.CertFile, tls.KeyFile)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
 	}
+	return err
+}
+
+// issueToken выпускает токен доступа пользователя: calendar token -user <user_id> [-ttl 720h]
+func issueToken(cfg config.AuthConfig, args []string) {
+	flags := flag.NewFlagSet("token", flag.ExitOnError)
+	userID := flags.String("user", "", "пользователь, которому выпускается токен")
+	ttl := flags.Duration("ttl", 30*24*time.Hour, "срок действия токена")
+	flags.Parse(args)
+
+	if cfg.Secret == "" {
+		log.Fatalf("Для выпуска токенов нужен CALENDAR_AUTH_SECRET")
+	}
+	if *userID == "" || *ttl <= 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	fmt.Println(auth.NewTokens(cfg.Secret).Issue(*userID, *ttl))
 }
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken возвращается для токена с неверным форматом или подписью
+var ErrInvalidToken = errors.New("недействительный токен")
+
+// ErrTokenExpired возвращается для токена с истекшим сроком действия
+var ErrTokenExpired = errors.New("срок действия токена истек")
+
+// Tokens выпускает и проверяет токены доступа пользователей.
+// Токен - это user_id и срок действия, подписанные HMAC-SHA256 секретом сервера:
+// base64url(user_id).срок в секундах Unix.base64url(подпись)
+type Tokens struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewTokens создает выпуск и проверку токенов с секретом secret
+func NewTokens(secret string) *Tokens {
+	return &Tokens{
+		secret: []byte(secret),
+		now:    time.Now,
+	}
+}
+
+// Issue выпускает токен пользователя userID, действующий ttl
+func (t *Tokens) Issue(userID string, ttl time.Duration) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(userID)) + "." +
+		strconv.FormatInt(t.now().Add(ttl).Unix(), 10)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(t.sign(payload))
+}
+
+// Verify проверяет подпись и срок действия токена и возвращает user_id его владельца
+func (t *Tokens) Verify(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, t.sign(parts[0]+"."+parts[1])) {
+		return "", ErrInvalidToken
+	}
+
+	userID, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(userID) == 0 {
+		return "", ErrInvalidToken
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	if !t.now().Before(time.Unix(expires, 0)) {
+		return "", ErrTokenExpired
+	}
+
+	return string(userID), nil
+}
+
+func (t *Tokens) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokens(t *testing.T) {
+	tokens := NewTokens("secret")
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	tokens.now = func() time.Time { return now }
+
+	token := tokens.Issue("user1", time.Hour)
+	userID, err := tokens.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user1", userID)
+
+	// Токен, подписанный другим секретом, недействителен
+	_, err = NewTokens("other").Verify(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Подмена пользователя ломает подпись
+	parts := strings.Split(token, ".")
+	forged := strings.Join([]string{strings.TrimRight("dXNlcjI", "="), parts[1], parts[2]}, ".")
+	_, err = tokens.Verify(forged)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	for _, malformed := range []string{"", "abc", "a.b", "a.b.c.d", parts[0] + ".x." + parts[2]} {
+		_, err = tokens.Verify(malformed)
+		assert.ErrorIs(t, err, ErrInvalidToken, malformed)
+	}
+
+	now = now.Add(time.Hour)
+	_, err = tokens.Verify(token)
+	assert.ErrorIs(t, err, ErrTokenExpired)
+}
@@ -1,8 +1,12 @@
 package calendar
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,48 +15,112 @@ import (
 
 // Service представляет сервис календаря
 type Service struct {
-	events map[string]*types.Event
-	mutex  sync.RWMutex
+	store EventStore
+	// mutex сериализует изменения, чтобы проверка владельца и запись выполнялись атомарно.
+	// Общий для сервиса и его представлений
+	mutex *sync.Mutex
+	// search - поисковый индекс событий, общий для сервиса и его представлений
+	search *searchIndex
+	// rejectConflicts запрещает сохранять события, пересекающиеся с другими событиями календаря
+	rejectConflicts bool
 }
 
-// NewService создает новый экземпляр сервиса календаря
-func NewService() *Service {
+// NewService создает новый экземпляр сервиса календаря поверх хранилища событий
+func NewService(store EventStore) *Service {
 	return &Service{
-		events: make(map[string]*types.Event),
+		store:  store,
+		mutex:  &sync.Mutex{},
+		search: newSearchIndex(),
 	}
 }
 
+// RejectingConflicts возвращает представление сервиса над тем же хранилищем, которое не создает и не изменяет
+// события, пересекающиеся с другими событиями того же календаря, и возвращает для них *ConflictError
+func (s *Service) RejectingConflicts() *Service {
+	view := *s
+	view.rejectConflicts = true
+	return &view
+}
+
 // CreateEvent создает новое событие
 func (s *Service) CreateEvent(userID, dateStr, text string) (*types.Event, error) {
-	if userID == "" {
-		return nil, errors.New("user_id не может быть пустым")
-	}
-	if text == "" {
-		return nil, errors.New("текст события не может быть пустым")
+	return s.CreateRecurringEvent(userID, dateStr, text, "", nil)
+}
+
+// CreateRecurringEvent создает событие на весь день с правилом повторения RFC 5545 и исключенными датами.
+// Пустое правило создает одиночное событие
+func (s *Service) CreateRecurringEvent(userID, dateStr, text, rule string, exdates []string) (*types.Event, error) {
+	return s.ScheduleEvent(userID, types.EventInput{Text: text, EventTime: types.EventTime{Date: dateStr}, RRule: rule, ExDates: exdates})
+}
+
+// ScheduleEvent создает событие со временем или на весь день, в том числе многодневное,
+// с необязательными правилом повторения, напоминаниями и сведениями о событии. Время без часового пояса
+// задается в поясе пользователя. Участники из input.Attendees получают приглашения в свои календари
+func (s *Service) ScheduleEvent(userID string, input types.EventInput) (*types.Event, error) {
+	timeZone, err := s.userTimeZone(userID)
+	if err != nil {
+		return nil, err
 	}
 
-	date, err := time.Parse("2006-01-02", dateStr)
+	event, err := newEvent(userID, input, timeZone)
 	if err != nil {
-		return nil, fmt.Errorf("некорректный формат даты: %v", err)
+		return nil, err
+	}
+	if event.Reminders, err = parseReminders(input.Reminders); err != nil {
+		return nil, err
+	}
+	if err := applyDetails(event, input.EventDetails); err != nil {
+		return nil, err
 	}
 
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	eventID := fmt.Sprintf("%s_%s_%d", userID, dateStr, time.Now().UnixNano())
+	if err := s.checkConflicts(event); err != nil {
+		return nil, err
+	}
+	if err := s.saveEvent(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// newEvent проверяет текст, время и повторение и собирает новое событие с уникальным id
+func newEvent(userID string, input types.EventInput, timeZone string) (*types.Event, error) {
+	if userID == "" {
+		return nil, errors.New("user_id не может быть пустым")
+	}
+	if input.Text == "" {
+		return nil, errors.New("текст события не может быть пустым")
+	}
+
 	event := &types.Event{
-		ID:     eventID,
 		UserID: userID,
-		Date:   date,
-		Text:   text,
+		Text:   input.Text,
+	}
+	if err := applyTime(event, input.EventTime, timeZone); err != nil {
+		return nil, err
+	}
+
+	rule, excluded, err := parseRecurrence(input.RRule, input.ExDates)
+	if err != nil {
+		return nil, err
 	}
+	event.RRule = rule
+	event.ExDates = excluded
+	event.ID = newEventID(userID, event.Date.Format("2006-01-02"))
 
-	s.events[eventID] = event
 	return event, nil
 }
 
-// UpdateEvent обновляет существующее событие
+// UpdateEvent переносит событие на другую дату и меняет его текст.
+// Событие со временем сохраняет время начала и длительность
 func (s *Service) UpdateEvent(id, userID, dateStr, text string) (*types.Event, error) {
+	return s.RescheduleEvent(id, userID, text, types.EventTime{Date: dateStr})
+}
+
+// RescheduleEvent меняет время и текст существующего события или всей серии
+func (s *Service) RescheduleEvent(id, userID, text string, when types.EventTime) (*types.Event, error) {
 	if id == "" {
 		return nil, errors.New("id события не может быть пустым")
 	}
@@ -63,31 +131,227 @@ func (s *Service) UpdateEvent(id, userID, dateStr, text string) (*types.Event, e
 		return nil, errors.New("текст события не может быть пустым")
 	}
 
-	date, err := time.Parse("2006-01-02", dateStr)
+	timeZone, err := s.userTimeZone(userID)
 	if err != nil {
-		return nil, fmt.Errorf("некорректный формат даты: %v", err)
+		return nil, err
+	}
+
+	return s.modifyEvent(id, userID, "", func(event *types.Event) error {
+		if err := applyTime(event, when, timeZone); err != nil {
+			return err
+		}
+		event.Text = text
+		return nil
+	})
+}
+
+// ReplaceEvent заменяет все поля события или серии кроме id. Участники, оставшиеся в списке, сохраняют ответы.
+// Непустой ifMatch проверяется как заголовок If-Match (см. etagMatches), иначе возвращается ErrPreconditionFailed
+func (s *Service) ReplaceEvent(id, userID, ifMatch string, input types.EventInput) (*types.Event, error) {
+	timeZone, err := s.userTimeZone(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reminders, err := parseReminders(input.Reminders)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.modifyEvent(id, userID, ifMatch, func(event *types.Event) error {
+		replacement, err := newEvent(event.UserID, input, timeZone)
+		if err != nil {
+			return err
+		}
+		if event.SeriesID != "" && replacement.RRule != "" {
+			return errors.New("правило повторения задается для всей серии, а не для отдельного повторения")
+		}
+
+		replacement.ID = event.ID
+		replacement.UID = event.UID
+		replacement.SeriesID = event.SeriesID
+		replacement.RecurrenceID = event.RecurrenceID
+		replacement.Reminders = reminders
+		replacement.Attendees = event.Attendees
+		if err := applyDetails(replacement, input.EventDetails); err != nil {
+			return err
+		}
+		*event = *replacement
+		return nil
+	})
+}
+
+// PatchEvent изменяет заданные поля события или серии, остальные остаются прежними.
+// Непустой ifMatch проверяется как заголовок If-Match (см. etagMatches), иначе возвращается ErrPreconditionFailed
+func (s *Service) PatchEvent(id, userID, ifMatch string, patch types.EventPatch) (*types.Event, error) {
+	if patch.Text != nil && *patch.Text == "" {
+		return nil, errors.New("текст события не может быть пустым")
+	}
+
+	timeZone, err := s.userTimeZone(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var reminders []int
+	if patch.Reminders != nil {
+		if reminders, err = parseReminders(patch.Reminders); err != nil {
+			return nil, err
+		}
+	}
+
+	var categories []string
+	if patch.Categories != nil {
+		if categories, err = normalizeCategories(patch.Categories); err != nil {
+			return nil, err
+		}
+	}
+	var color string
+	if patch.Color != nil {
+		if color, err = normalizeColor(*patch.Color); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.modifyEvent(id, userID, ifMatch, func(event *types.Event) error {
+		if patch.Text != nil {
+			event.Text = *patch.Text
+		}
+		if patch.EventTime != (types.EventTime{}) {
+			if err := applyTime(event, patch.EventTime, timeZone); err != nil {
+				return err
+			}
+		}
+
+		if patch.RRule != nil || patch.ExDates != nil {
+			rule := event.RRule
+			if patch.RRule != nil {
+				if event.SeriesID != "" && *patch.RRule != "" {
+					return errors.New("правило повторения задается для всей серии, а не для отдельного повторения")
+				}
+				rule = *patch.RRule
+			}
+			rule, excluded, err := parseRecurrence(rule, patch.ExDates)
+			if err != nil {
+				return err
+			}
+			event.RRule = rule
+			event.ExDates = excluded
+		}
+
+		if patch.Reminders != nil {
+			event.Reminders = reminders
+		}
+
+		if patch.Title != nil {
+			event.Title = strings.TrimSpace(*patch.Title)
+		}
+		if patch.Description != nil {
+			event.Description = strings.TrimSpace(*patch.Description)
+		}
+		if patch.Location != nil {
+			event.Location = strings.TrimSpace(*patch.Location)
+		}
+		if patch.Categories != nil {
+			event.Categories = categories
+		}
+		if patch.Color != nil {
+			event.Color = color
+		}
+		if patch.Attendees != nil {
+			return setAttendees(event, patch.Attendees)
+		}
+		return nil
+	})
+}
+
+// GetEvent возвращает событие или серию пользователя по id
+func (s *Service) GetEvent(id, userID string) (*types.Event, error) {
+	if id == "" {
+		return nil, errors.New("id события не может быть пустым")
+	}
+	if userID == "" {
+		return nil, errors.New("user_id не может быть пустым")
+	}
+
+	event, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorize(userID, event.UserID, types.AccessRead, "нет прав на просмотр этого события"); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// EventETag возвращает ETag версии события: хеш его содержимого в кавычках, как в заголовке HTTP
+func EventETag(event *types.Event) string {
+	data, _ := json.Marshal(event)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// etagMatches проверяет значение заголовка If-Match по RFC 9110: пустое значение и * подходят любой версии
+// события, иначе одна из перечисленных через запятую меток должна совпадать с EventETag
+func etagMatches(ifMatch string, event *types.Event) bool {
+	if ifMatch == "" {
+		return true
+	}
+	etag := EventETag(event)
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		if candidate = strings.TrimSpace(candidate); candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// modifyEvent загружает событие, проверяет владельца и ETag, применяет modify и сохраняет результат.
+// Все шаги выполняются под блокировкой сервиса, поэтому проверка ETag и запись атомарны
+func (s *Service) modifyEvent(id, userID, ifMatch string, modify func(event *types.Event) error) (*types.Event, error) {
+	if id == "" {
+		return nil, errors.New("id события не может быть пустым")
+	}
+	if userID == "" {
+		return nil, errors.New("user_id не может быть пустым")
 	}
 
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	event, exists := s.events[id]
-	if !exists {
-		return nil, errors.New("событие не найдено")
+	event, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
 	}
 
-	if event.UserID != userID {
-		return nil, errors.New("нет прав на обновление этого события")
+	if err := s.authorizeChange(userID, event, "нет прав на обновление этого события"); err != nil {
+		return nil, err
+	}
+	if !etagMatches(ifMatch, event) {
+		return nil, ErrPreconditionFailed
 	}
 
-	event.Date = date
-	event.Text = text
+	if err := modify(event); err != nil {
+		return nil, err
+	}
 
+	if err := s.checkConflicts(event); err != nil {
+		return nil, err
+	}
+	if err := s.saveEvent(event); err != nil {
+		return nil, err
+	}
 	return event, nil
 }
 
 // DeleteEvent удаляет событие
 func (s *Service) DeleteEvent(id, userID string) error {
+	return s.DeleteEventIfMatch(id, userID, "")
+}
+
+// DeleteEventIfMatch удаляет событие, если непустой ifMatch совпадает с EventETag его текущей версии (см. etagMatches)
+func (s *Service) DeleteEventIfMatch(id, userID, ifMatch string) error {
 	if id == "" {
 		return errors.New("id события не может быть пустым")
 	}
@@ -98,97 +362,205 @@ func (s *Service) DeleteEvent(id, userID string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	event, exists := s.events[id]
-	if !exists {
-		return errors.New("событие не найдено")
+	event, err := s.store.Get(id)
+	if err != nil {
+		return err
 	}
 
-	if event.UserID != userID {
-		return errors.New("нет прав на удаление этого события")
+	if err := s.authorizeChange(userID, event, "нет прав на удаление этого события"); err != nil {
+		return err
+	}
+	if !etagMatches(ifMatch, event) {
+		return ErrPreconditionFailed
 	}
 
-	delete(s.events, id)
-	return nil
+	if event.RRule != "" {
+		if err := s.deleteOverrides(event); err != nil {
+			return err
+		}
+	}
+	return s.deleteEvent(event)
 }
 
-// GetEventsForDay возвращает события на конкретный день
-func (s *Service) GetEventsForDay(userID, dateStr string) ([]*types.Event, error) {
+// GetEventsForDay возвращает события, которые идут в конкретный день в часовом поясе пользователя.
+// Многодневные события попадают в каждый свой день. calendars - владельцы календарей, доступных
+// пользователю, события которых нужно вернуть; по умолчанию - только его собственный календарь
+func (s *Service) GetEventsForDay(userID, dateStr string, calendars ...string) ([]*types.Event, error) {
 	if userID == "" {
 		return nil, errors.New("user_id не может быть пустым")
 	}
 
-	date, err := time.Parse("2006-01-02", dateStr)
+	location, err := s.userLocation(userID)
 	if err != nil {
-		return nil, fmt.Errorf("некорректный формат даты: %v", err)
+		return nil, err
 	}
 
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-
-	var events []*types.Event
-	for _, event := range s.events {
-		if event.UserID == userID && isSameDay(event.Date, date) {
-			events = append(events, event)
-		}
+	date, err := time.ParseInLocation("2006-01-02", dateStr, location)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный формат даты: %v", err)
 	}
 
-	return events, nil
+	return s.eventsBetween(userID, date, date.AddDate(0, 0, 1), calendars)
 }
 
-// GetEventsForWeek возвращает события на неделю
-func (s *Service) GetEventsForWeek(userID, dateStr string) ([]*types.Event, error) {
+// GetEventsForWeek возвращает события, которые идут на неделе с понедельника в часовом поясе пользователя
+func (s *Service) GetEventsForWeek(userID, dateStr string, calendars ...string) ([]*types.Event, error) {
 	if userID == "" {
 		return nil, errors.New("user_id не может быть пустым")
 	}
 
-	date, err := time.Parse("2006-01-02", dateStr)
+	location, err := s.userLocation(userID)
 	if err != nil {
-		return nil, fmt.Errorf("некорректный формат даты: %v", err)
+		return nil, err
 	}
 
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
+	date, err := time.ParseInLocation("2006-01-02", dateStr, location)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный формат даты: %v", err)
+	}
 
 	weekStart := getWeekStart(date)
 	weekEnd := weekStart.AddDate(0, 0, 7)
 
-	var events []*types.Event
-	for _, event := range s.events {
-		if event.UserID == userID && (event.Date.Equal(weekStart) || event.Date.After(weekStart)) && event.Date.Before(weekEnd) {
-			events = append(events, event)
-		}
-	}
-
-	return events, nil
+	return s.eventsBetween(userID, weekStart, weekEnd, calendars)
 }
 
-// GetEventsForMonth возвращает события на месяц
-func (s *Service) GetEventsForMonth(userID, dateStr string) ([]*types.Event, error) {
+// GetEventsForMonth возвращает события, которые идут в месяце в часовом поясе пользователя
+func (s *Service) GetEventsForMonth(userID, dateStr string, calendars ...string) ([]*types.Event, error) {
 	if userID == "" {
 		return nil, errors.New("user_id не может быть пустым")
 	}
 
-	date, err := time.Parse("2006-01-02", dateStr)
+	location, err := s.userLocation(userID)
 	if err != nil {
-		return nil, fmt.Errorf("некорректный формат даты: %v", err)
+		return nil, err
 	}
 
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
+	date, err := time.ParseInLocation("2006-01-02", dateStr, location)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный формат даты: %v", err)
+	}
 
 	monthStart := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
 	monthEnd := monthStart.AddDate(0, 1, 0)
 
+	return s.eventsBetween(userID, monthStart, monthEnd, calendars)
+}
+
+// maxRangeDays ограничивает интервал GetEventsInRange, чтобы разворачивание серий оставалось ограниченным
+const maxRangeDays = 366
+
+// GetEventsInRange возвращает события, которые идут в интервале [from, to). Границы задаются датой
+// (полночь в часовом поясе пользователя), местным временем 2006-01-02T15:04 в этом поясе или временем RFC 3339
+func (s *Service) GetEventsInRange(userID, fromStr, toStr string, calendars ...string) ([]*types.Event, error) {
+	if userID == "" {
+		return nil, errors.New("user_id не может быть пустым")
+	}
+
+	location, err := s.userLocation(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, err := parseRange(fromStr, toStr, location)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.eventsBetween(userID, from, to, calendars)
+}
+
+// parseRange разбирает границы интервала [from, to) и проверяет его длину
+func parseRange(fromStr, toStr string, location *time.Location) (time.Time, time.Time, error) {
+	from, err := parseRangeBound(fromStr, location)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("некорректное начало интервала: %v", err)
+	}
+	to, err := parseRangeBound(toStr, location)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("некорректный конец интервала: %v", err)
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("конец интервала должен быть позже начала")
+	}
+	if to.After(from.AddDate(0, 0, maxRangeDays)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("интервал не может быть длиннее %d дней", maxRangeDays)
+	}
+	return from, to, nil
+}
+
+// parseRangeBound разбирает границу интервала: дату или время в формате parseDateTime
+func parseRangeBound(value string, location *time.Location) (time.Time, error) {
+	if date, err := time.ParseInLocation("2006-01-02", value, location); err == nil {
+		return date, nil
+	}
+	return parseDateTime(value, location)
+}
+
+// eventsBetween возвращает события и повторения серий из календарей calendars (по умолчанию - календаря
+// пользователя), пересекающиеся с интервалом [from, to), упорядоченные по началу
+func (s *Service) eventsBetween(userID string, from, to time.Time, calendars []string) ([]*types.Event, error) {
+	if len(calendars) == 0 {
+		calendars = []string{userID}
+	}
+
 	var events []*types.Event
-	for _, event := range s.events {
-		if event.UserID == userID && (event.Date.Equal(monthStart) || event.Date.After(monthStart)) && event.Date.Before(monthEnd) {
-			events = append(events, event)
+	seen := make(map[string]bool, len(calendars))
+	for _, ownerID := range calendars {
+		if seen[ownerID] {
+			continue
 		}
+		seen[ownerID] = true
+
+		if err := s.CheckAccess(userID, ownerID, types.AccessRead); err != nil {
+			return nil, err
+		}
+
+		calendarEvents, err := s.calendarEventsBetween(ownerID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, calendarEvents...)
 	}
 
+	sortEvents(events)
 	return events, nil
 }
 
+// calendarEventsBetween возвращает события календаря ownerID, пересекающиеся с интервалом [from, to)
+func (s *Service) calendarEventsBetween(ownerID string, from, to time.Time) ([]*types.Event, error) {
+	// События на весь день сравниваются с датами границ в поясе пользователя, которые отличаются
+	// от границ не больше чем на сутки, поэтому хранилище просматривает интервал с запасом
+	userEvents, err := s.store.ListByUserInRange(ownerID, from.Add(-24*time.Hour), to.Add(24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*types.Event
+	for _, event := range userEvents {
+		event.Owner = ownerID
+		normalizeEvent(event)
+		if event.RRule != "" {
+			occurrences, err := expandSeries(event, from, to)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, occurrences...)
+			continue
+		}
+		eventFrom, eventTo := eventWindow(event, from, to)
+		if overlaps(event.Date, event.End, eventFrom, eventTo) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func newEventID(userID, dateStr string) string {
+	return fmt.Sprintf("%s_%s_%d", userID, dateStr, time.Now().UnixNano())
+}
+
 func isSameDay(date1, date2 time.Time) bool {
 	return date1.Year() == date2.Year() && date1.YearDay() == date2.YearDay()
 }
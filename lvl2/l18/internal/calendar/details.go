@@ -0,0 +1,142 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"calendar/internal/types"
+)
+
+const (
+	// maxCategories ограничивает число категорий одного события
+	maxCategories = 20
+	// maxAttendees ограничивает число участников одного события
+	maxAttendees = 100
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// applyDetails проверяет необязательные сведения о событии и записывает их в event.
+// Участники, которые уже были приглашены, сохраняют свои ответы
+func applyDetails(event *types.Event, details types.EventDetails) error {
+	categories, err := normalizeCategories(details.Categories)
+	if err != nil {
+		return err
+	}
+	color, err := normalizeColor(details.Color)
+	if err != nil {
+		return err
+	}
+	if err := setAttendees(event, details.Attendees); err != nil {
+		return err
+	}
+
+	event.Title = strings.TrimSpace(details.Title)
+	event.Description = strings.TrimSpace(details.Description)
+	event.Location = strings.TrimSpace(details.Location)
+	event.Categories = categories
+	event.Color = color
+	return nil
+}
+
+// normalizeCategories приводит категории к виду, в котором они хранятся и ищутся: без # в начале,
+// в нижнем регистре и без повторов
+func normalizeCategories(categories []string) ([]string, error) {
+	if len(categories) > maxCategories {
+		return nil, fmt.Errorf("категорий не может быть больше %d", maxCategories)
+	}
+
+	var normalized []string
+	seen := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		category = normalizeCategory(category)
+		if category == "" {
+			return nil, errors.New("категория не может быть пустой")
+		}
+		if strings.Contains(category, ",") {
+			return nil, fmt.Errorf("категория не может содержать запятую: %q", category)
+		}
+		if seen[category] {
+			continue
+		}
+		seen[category] = true
+		normalized = append(normalized, category)
+	}
+	return normalized, nil
+}
+
+func normalizeCategory(category string) string {
+	return normalizeTerm(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(category), "#")))
+}
+
+// normalizeColor проверяет цвет в формате #rrggbb и приводит его к нижнему регистру
+func normalizeColor(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if color != "" && !colorPattern.MatchString(color) {
+		return "", fmt.Errorf("цвет должен быть в формате #rrggbb: %q", color)
+	}
+	return color, nil
+}
+
+// setAttendees заменяет участников события. Новые участники получают ответ needs_action,
+// оставшиеся в списке сохраняют прежний
+func setAttendees(event *types.Event, userIDs []string) error {
+	previous := make(map[string]string, len(event.Attendees))
+	for _, attendee := range event.Attendees {
+		previous[attendee.UserID] = attendee.Status
+	}
+
+	var attendees []types.Attendee
+	seen := make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		userID = strings.TrimSpace(userID)
+		if userID == "" {
+			return errors.New("id участника не может быть пустым")
+		}
+		if userID == event.UserID {
+			return errors.New("организатор не может пригласить сам себя")
+		}
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		status := previous[userID]
+		if status == "" {
+			status = types.RSVPNeedsAction
+		}
+		attendees = append(attendees, types.Attendee{UserID: userID, Status: status})
+	}
+	if len(attendees) > maxAttendees {
+		return fmt.Errorf("участников не может быть больше %d", maxAttendees)
+	}
+
+	event.Attendees = attendees
+	return nil
+}
+
+// FilterByCategories оставляет события, у которых есть хотя бы одна из категорий categories.
+// Пустой список категорий не фильтрует события
+func FilterByCategories(events []*types.Event, categories []string) []*types.Event {
+	if len(categories) == 0 {
+		return events
+	}
+
+	wanted := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		wanted[normalizeCategory(category)] = true
+	}
+
+	filtered := make([]*types.Event, 0, len(events))
+	for _, event := range events {
+		for _, category := range event.Categories {
+			if wanted[category] {
+				filtered = append(filtered, event)
+				break
+			}
+		}
+	}
+	return filtered
+}
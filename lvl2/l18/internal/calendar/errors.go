@@ -0,0 +1,63 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"calendar/internal/types"
+)
+
+// ErrPermissionDenied возвращается при попытке изменить или удалить чужое событие
+var ErrPermissionDenied = errors.New("нет прав на это событие")
+
+// ErrPreconditionFailed возвращается, если событие изменилось после того, как клиент получил его ETag
+var ErrPreconditionFailed = errors.New("событие изменено другим запросом, загрузите его заново")
+
+// ErrConflict возвращается, если событие пересекается с другими событиями календаря, а пересечения запрещены
+var ErrConflict = errors.New("событие пересекается с другими событиями")
+
+// ErrStorage отмечает сбои хранилища, в отличие от ошибок во входных данных
+var ErrStorage = errors.New("ошибка хранилища")
+
+// kindError сохраняет текст ошибки и сопоставляется со своим видом через errors.Is
+type kindError struct {
+	kind    error
+	message string
+}
+
+func (e *kindError) Error() string {
+	return e.message
+}
+
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+// ConflictError перечисляет события, с которыми пересекается отклоненное событие, и сопоставляется с ErrConflict
+type ConflictError struct {
+	Conflicts []*types.Event
+}
+
+func (e *ConflictError) Error() string {
+	descriptions := make([]string, 0, len(e.Conflicts))
+	for _, event := range e.Conflicts {
+		descriptions = append(descriptions, fmt.Sprintf("%q (%s)", event.Text, event.Date.Format(time.RFC3339)))
+	}
+	return fmt.Sprintf("%v: %s", ErrConflict, strings.Join(descriptions, ", "))
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// permissionDenied возвращает ErrPermissionDenied с описанием запрещенного действия
+func permissionDenied(message string) error {
+	return &kindError{kind: ErrPermissionDenied, message: message}
+}
+
+// storageErrorf возвращает ErrStorage с описанием сбоя
+func storageErrorf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrStorage, message: fmt.Sprintf(format, args...)}
+}
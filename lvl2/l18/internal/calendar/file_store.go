@@ -0,0 +1,434 @@
+package calendar
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"calendar/internal/types"
+)
+
+const (
+	snapshotFileName = "snapshot.json"
+	walFileName      = "wal.jsonl"
+
+	walOpSave     = "save"
+	walOpDelete   = "delete"
+	walOpSettings = "settings"
+	walOpReminder = "reminder_sent"
+	walOpGrant    = "grant"
+	walOpRevoke   = "revoke"
+)
+
+// walRecord представляет одну запись журнала изменений
+type walRecord struct {
+	Op       string              `json:"op"`
+	Event    *types.Event        `json:"event,omitempty"`
+	ID       string              `json:"id,omitempty"`
+	Settings *types.UserSettings `json:"settings,omitempty"`
+	RemindAt *time.Time          `json:"remind_at,omitempty"`
+	Grant    *types.Grant        `json:"grant,omitempty"`
+}
+
+// snapshotData представляет содержимое файла снимка
+type snapshotData struct {
+	Events   []*types.Event       `json:"events"`
+	Settings []types.UserSettings `json:"settings,omitempty"`
+	// SentReminders - время срабатывания отправленных напоминаний по их ключам
+	SentReminders map[string]time.Time `json:"sent_reminders,omitempty"`
+	Grants        []*types.Grant       `json:"grants,omitempty"`
+}
+
+// FileStore хранит события в памяти и записывает каждое изменение в журнал (WAL) на диске.
+// После snapshotEvery записей журнал сворачивается в снимок, при открытии снимок
+// загружается и журнал проигрывается поверх него
+type FileStore struct {
+	events        *MemoryStore
+	dir           string
+	wal           *os.File
+	walRecords    int
+	snapshotEvery int
+	mutex         sync.Mutex
+}
+
+// NewFileStore открывает файловое хранилище в каталоге dir, создавая его при необходимости.
+// snapshotEvery = 0 отключает снимки по количеству записей, снимок делается только при Close
+func NewFileStore(dir string, snapshotEvery int) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, storageErrorf("не удалось создать каталог хранилища: %v", err)
+	}
+
+	s := &FileStore{
+		events:        NewMemoryStore(),
+		dir:           dir,
+		snapshotEvery: snapshotEvery,
+	}
+
+	if err := s.loadSnapshot(); err != nil {
+		return nil, err
+	}
+
+	wal, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, storageErrorf("не удалось открыть журнал: %v", err)
+	}
+	s.wal = wal
+
+	if err := s.replayWAL(); err != nil {
+		wal.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Save записывает событие в журнал и применяет его
+func (s *FileStore) Save(event *types.Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.append(walRecord{Op: walOpSave, Event: event}); err != nil {
+		return err
+	}
+	if err := s.events.Save(event); err != nil {
+		return err
+	}
+	s.maybeSnapshot()
+	return nil
+}
+
+// Get возвращает событие по id
+func (s *FileStore) Get(id string) (*types.Event, error) {
+	return s.events.Get(id)
+}
+
+// Delete записывает удаление в журнал и применяет его
+func (s *FileStore) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.events.Get(id); err != nil {
+		return err
+	}
+	if err := s.append(walRecord{Op: walOpDelete, ID: id}); err != nil {
+		return err
+	}
+	if err := s.events.Delete(id); err != nil {
+		return err
+	}
+	s.maybeSnapshot()
+	return nil
+}
+
+// ListByUser возвращает события пользователя, упорядоченные по дате
+func (s *FileStore) ListByUser(userID string) ([]*types.Event, error) {
+	return s.events.ListByUser(userID)
+}
+
+// ListByUserInRange возвращает события пользователя, которые могут попасть в интервал, упорядоченные по дате
+func (s *FileStore) ListByUserInRange(userID string, from, to time.Time) ([]*types.Event, error) {
+	return s.events.ListByUserInRange(userID, from, to)
+}
+
+// SaveSettings записывает настройки пользователя в журнал и применяет их
+func (s *FileStore) SaveSettings(settings *types.UserSettings) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.append(walRecord{Op: walOpSettings, Settings: settings}); err != nil {
+		return err
+	}
+	if err := s.events.SaveSettings(settings); err != nil {
+		return err
+	}
+	s.maybeSnapshot()
+	return nil
+}
+
+// GetSettings возвращает настройки пользователя
+func (s *FileStore) GetSettings(userID string) (*types.UserSettings, error) {
+	return s.events.GetSettings(userID)
+}
+
+// SaveGrant записывает доступ к календарю в журнал и применяет его
+func (s *FileStore) SaveGrant(grant *types.Grant) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.append(walRecord{Op: walOpGrant, Grant: grant}); err != nil {
+		return err
+	}
+	if err := s.events.SaveGrant(grant); err != nil {
+		return err
+	}
+	s.maybeSnapshot()
+	return nil
+}
+
+// GetGrant возвращает доступ к календарю
+func (s *FileStore) GetGrant(ownerID, granteeID string) (*types.Grant, error) {
+	return s.events.GetGrant(ownerID, granteeID)
+}
+
+// DeleteGrant записывает отзыв доступа в журнал и применяет его
+func (s *FileStore) DeleteGrant(ownerID, granteeID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.events.GetGrant(ownerID, granteeID); err != nil {
+		return err
+	}
+	if err := s.append(walRecord{Op: walOpRevoke, Grant: &types.Grant{OwnerID: ownerID, GranteeID: granteeID}}); err != nil {
+		return err
+	}
+	if err := s.events.DeleteGrant(ownerID, granteeID); err != nil {
+		return err
+	}
+	s.maybeSnapshot()
+	return nil
+}
+
+// ListGrants возвращает доступы, выданные пользователем и выданные ему
+func (s *FileStore) ListGrants(userID string) ([]*types.Grant, error) {
+	return s.events.ListGrants(userID)
+}
+
+// ListWithReminders возвращает события с напоминаниями
+func (s *FileStore) ListWithReminders() ([]*types.Event, error) {
+	return s.events.ListWithReminders()
+}
+
+// MarkReminderSent записывает отметку об отправке напоминания в журнал и применяет ее
+func (s *FileStore) MarkReminderSent(key string, remindAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.append(walRecord{Op: walOpReminder, ID: key, RemindAt: &remindAt}); err != nil {
+		return err
+	}
+	if err := s.events.MarkReminderSent(key, remindAt); err != nil {
+		return err
+	}
+	s.maybeSnapshot()
+	return nil
+}
+
+// ReminderSent сообщает, что напоминание уже отправлено
+func (s *FileStore) ReminderSent(key string) (bool, error) {
+	return s.events.ReminderSent(key)
+}
+
+// PruneSentReminders удаляет старые отметки только в памяти: они пропадут из файлов со следующим снимком,
+// а восстановленные из журнала после перезапуска удалятся при следующей очистке
+func (s *FileStore) PruneSentReminders(before time.Time) error {
+	return s.events.PruneSentReminders(before)
+}
+
+// Close сворачивает журнал в снимок и закрывает файл журнала
+func (s *FileStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.wal == nil {
+		return nil
+	}
+
+	err := s.snapshot()
+	if closeErr := s.wal.Close(); err == nil {
+		err = closeErr
+	}
+	s.wal = nil
+	return err
+}
+
+// append дописывает запись в журнал. Запись считается сохраненной только после fsync
+func (s *FileStore) append(record walRecord) error {
+	if s.wal == nil {
+		return storageErrorf("хранилище закрыто")
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return storageErrorf("не удалось сериализовать запись журнала: %v", err)
+	}
+	line = append(line, '\n')
+
+	if _, err := s.wal.Write(line); err != nil {
+		return storageErrorf("не удалось записать журнал: %v", err)
+	}
+	if err := s.wal.Sync(); err != nil {
+		return storageErrorf("не удалось сбросить журнал на диск: %v", err)
+	}
+
+	s.walRecords++
+	return nil
+}
+
+// maybeSnapshot делает снимок после snapshotEvery записей журнала.
+// Изменение уже сохранено в журнале, поэтому ошибка снимка не возвращается: журнал свернется позже
+func (s *FileStore) maybeSnapshot() {
+	if s.snapshotEvery > 0 && s.walRecords >= s.snapshotEvery {
+		_ = s.snapshot()
+	}
+}
+
+// snapshot атомарно записывает все события и настройки в файл снимка и очищает журнал
+func (s *FileStore) snapshot() error {
+	if s.walRecords == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(snapshotData{
+		Events:        s.events.all(),
+		Settings:      s.events.allSettings(),
+		SentReminders: s.events.allSent(),
+		Grants:        s.events.allGrants(),
+	})
+	if err != nil {
+		return storageErrorf("не удалось сериализовать снимок: %v", err)
+	}
+
+	path := filepath.Join(s.dir, snapshotFileName)
+	tmp := path + ".tmp"
+	if err := writeFileSync(tmp, data); err != nil {
+		return storageErrorf("не удалось записать снимок: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return storageErrorf("не удалось заменить снимок: %v", err)
+	}
+
+	if err := s.wal.Truncate(0); err != nil {
+		return storageErrorf("не удалось очистить журнал: %v", err)
+	}
+	s.walRecords = 0
+	return nil
+}
+
+func (s *FileStore) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(s.dir, snapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return storageErrorf("не удалось прочитать снимок: %v", err)
+	}
+
+	var snapshot snapshotData
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		// Снимки старого формата содержат только массив событий
+		err = json.Unmarshal(data, &snapshot.Events)
+	} else {
+		err = json.Unmarshal(data, &snapshot)
+	}
+	if err != nil {
+		return storageErrorf("снимок поврежден: %v", err)
+	}
+
+	for _, event := range snapshot.Events {
+		s.events.Save(event)
+	}
+	for i := range snapshot.Settings {
+		s.events.SaveSettings(&snapshot.Settings[i])
+	}
+	for key, remindAt := range snapshot.SentReminders {
+		s.events.MarkReminderSent(key, remindAt)
+	}
+	for _, grant := range snapshot.Grants {
+		s.events.SaveGrant(grant)
+	}
+	return nil
+}
+
+// replayWAL применяет записи журнала поверх снимка.
+// Недописанная последняя строка (сбой во время записи) отбрасывается
+func (s *FileStore) replayWAL() error {
+	reader := bufio.NewReader(s.wal)
+	var offset int64
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(line) > 0 {
+				if err := s.wal.Truncate(offset); err != nil {
+					return storageErrorf("не удалось отбросить недописанную запись журнала: %v", err)
+				}
+			}
+			return nil
+		}
+		if err != nil {
+			return storageErrorf("не удалось прочитать журнал: %v", err)
+		}
+
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			var record walRecord
+			if err := json.Unmarshal(trimmed, &record); err != nil {
+				return storageErrorf("журнал поврежден на смещении %d: %v", offset, err)
+			}
+			if err := s.apply(record); err != nil {
+				return err
+			}
+			s.walRecords++
+		}
+		offset += int64(len(line))
+	}
+}
+
+func (s *FileStore) apply(record walRecord) error {
+	switch record.Op {
+	case walOpSave:
+		if record.Event == nil {
+			return storageErrorf("запись журнала без события")
+		}
+		return s.events.Save(record.Event)
+	case walOpDelete:
+		if err := s.events.Delete(record.ID); err != nil && !errors.Is(err, ErrEventNotFound) {
+			return err
+		}
+		return nil
+	case walOpSettings:
+		if record.Settings == nil {
+			return storageErrorf("запись журнала без настроек")
+		}
+		return s.events.SaveSettings(record.Settings)
+	case walOpReminder:
+		if record.RemindAt == nil {
+			return storageErrorf("запись журнала без времени напоминания")
+		}
+		return s.events.MarkReminderSent(record.ID, *record.RemindAt)
+	case walOpGrant, walOpRevoke:
+		if record.Grant == nil {
+			return storageErrorf("запись журнала без доступа к календарю")
+		}
+		if record.Op == walOpGrant {
+			return s.events.SaveGrant(record.Grant)
+		}
+		if err := s.events.DeleteGrant(record.Grant.OwnerID, record.Grant.GranteeID); err != nil && !errors.Is(err, ErrGrantNotFound) {
+			return err
+		}
+		return nil
+	default:
+		return storageErrorf("неизвестная операция журнала: %s", record.Op)
+	}
+}
+
+func writeFileSync(path string, data []byte) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,243 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"calendar/internal/types"
+)
+
+// maxFreeBusyUsers ограничивает число пользователей в одном запросе занятости
+const maxFreeBusyUsers = 50
+
+// conflictHorizonDays - сколько дней от начала серии проверяются пересечения ее повторений
+const conflictHorizonDays = maxRangeDays
+
+// FreeBusy возвращает занятое время пользователей users в интервале [from, to) и промежутки не короче minFree,
+// когда свободны все они. Границы задаются как в GetEventsInRange в часовом поясе пользователя userID,
+// в нем же возвращаются промежутки. Занятость не раскрывает содержание событий, поэтому доступ к календарям не нужен
+func (s *Service) FreeBusy(userID string, users []string, fromStr, toStr string, minFree time.Duration) (*types.FreeBusy, error) {
+	if userID == "" {
+		return nil, errors.New("user_id не может быть пустым")
+	}
+	if len(users) == 0 {
+		return nil, errors.New("нужен хотя бы один пользователь")
+	}
+	if len(users) > maxFreeBusyUsers {
+		return nil, fmt.Errorf("пользователей не может быть больше %d", maxFreeBusyUsers)
+	}
+	if minFree < 0 {
+		return nil, errors.New("минимальная длительность свободного времени не может быть отрицательной")
+	}
+
+	location, err := s.userLocation(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, err := parseRange(fromStr, toStr, location)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.FreeBusy{
+		From: from,
+		To:   to,
+		Busy: make(map[string][]types.Interval, len(users)),
+	}
+	var all []types.Interval
+	for _, user := range users {
+		if user == "" {
+			return nil, errors.New("user_id не может быть пустым")
+		}
+		if _, ok := result.Busy[user]; ok {
+			continue
+		}
+
+		busy, err := s.busyBetween(user, from, to)
+		if err != nil {
+			return nil, err
+		}
+		result.Busy[user] = busy
+		all = append(all, busy...)
+	}
+
+	result.Free = freeIntervals(mergeIntervals(all), from, to, minFree)
+	return result, nil
+}
+
+// busyBetween возвращает объединенные занятые промежутки пользователя, обрезанные по интервалу [from, to)
+// и переведенные в часовой пояс from
+func (s *Service) busyBetween(userID string, from, to time.Time) ([]types.Interval, error) {
+	ownerLocation, err := s.userLocation(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// События на весь день занимают свои дни в часовом поясе владельца календаря
+	events, err := s.calendarEventsBetween(userID, from.In(ownerLocation), to.In(ownerLocation))
+	if err != nil {
+		return nil, err
+	}
+
+	intervals := make([]types.Interval, 0, len(events))
+	for _, event := range events {
+		if isDeclined(event) {
+			continue
+		}
+		interval := busyInterval(event, ownerLocation)
+		if interval.Start.Before(from) {
+			interval.Start = from
+		}
+		if interval.End.After(to) {
+			interval.End = to
+		}
+		if !interval.Start.Before(interval.End) {
+			continue
+		}
+		interval.Start = interval.Start.In(from.Location())
+		interval.End = interval.End.In(from.Location())
+		intervals = append(intervals, interval)
+	}
+	return mergeIntervals(intervals), nil
+}
+
+// Conflicts возвращает события календаря владельца event, которые пересекаются с ним по времени.
+// Для серии проверяются повторения в течение conflictHorizonDays от ее начала.
+// Само событие, повторения его серии, заменяемое им повторение и отклоненные приглашения не считаются пересечениями
+func (s *Service) Conflicts(event *types.Event) ([]*types.Event, error) {
+	location, err := s.userLocation(event.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	own, err := eventIntervals(event, location)
+	if err != nil || len(own) == 0 {
+		return nil, err
+	}
+
+	others, err := s.calendarEventsBetween(event.UserID, own[0].Start.In(location), own[len(own)-1].End.In(location))
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []*types.Event
+	for _, other := range others {
+		if sameEvent(event, other) || isDeclined(other) {
+			continue
+		}
+		busy := busyInterval(other, location)
+		// Промежутки события упорядочены и по началу, и по концу: первый, который заканчивается после
+		// начала other, - единственный кандидат на пересечение с ним среди более ранних
+		i := sort.Search(len(own), func(i int) bool { return own[i].End.After(busy.Start) })
+		if i < len(own) && own[i].Start.Before(busy.End) {
+			conflicts = append(conflicts, other)
+		}
+	}
+	return conflicts, nil
+}
+
+// checkConflicts возвращает *ConflictError, если представление сервиса запрещает пересечения,
+// а event пересекается с другими событиями своего календаря
+func (s *Service) checkConflicts(event *types.Event) error {
+	if !s.rejectConflicts {
+		return nil
+	}
+
+	conflicts, err := s.Conflicts(event)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &ConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// eventIntervals возвращает упорядоченные промежутки, которые занимает событие или повторения серии
+func eventIntervals(event *types.Event, location *time.Location) ([]types.Interval, error) {
+	event = cloneEvent(event)
+	normalizeEvent(event)
+	if event.RRule == "" {
+		return []types.Interval{busyInterval(event, location)}, nil
+	}
+
+	occurrences, err := expandSeries(event, event.Date, event.Date.AddDate(0, 0, conflictHorizonDays))
+	if err != nil {
+		return nil, err
+	}
+
+	intervals := make([]types.Interval, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		intervals = append(intervals, busyInterval(occurrence, location))
+	}
+	return intervals, nil
+}
+
+// sameEvent сообщает, что other - это event, повторение или измененное повторение его серии
+// либо повторение, которое event заменяет
+func sameEvent(event, other *types.Event) bool {
+	if other.ID == event.ID || other.SeriesID == event.ID {
+		return true
+	}
+	return event.SeriesID != "" && other.ID == event.SeriesID &&
+		event.RecurrenceID != nil && other.RecurrenceID != nil && other.RecurrenceID.Equal(*event.RecurrenceID)
+}
+
+// busyInterval возвращает время, которое событие занимает в календаре с часовым поясом location.
+// Событие на весь день занимает свои дни целиком в этом поясе
+func busyInterval(event *types.Event, location *time.Location) types.Interval {
+	if !event.AllDay {
+		return types.Interval{Start: event.Date, End: event.End}
+	}
+
+	startYear, startMonth, startDay := event.Date.Date()
+	endYear, endMonth, endDay := event.End.Date()
+	return types.Interval{
+		Start: time.Date(startYear, startMonth, startDay, 0, 0, 0, 0, location),
+		End:   time.Date(endYear, endMonth, endDay, 0, 0, 0, 0, location),
+	}
+}
+
+// mergeIntervals упорядочивает промежутки и объединяет пересекающиеся и смежные
+func mergeIntervals(intervals []types.Interval) []types.Interval {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].Start.Before(intervals[j].Start)
+	})
+
+	merged := make([]types.Interval, 0, len(intervals))
+	for _, interval := range intervals {
+		last := len(merged) - 1
+		if last >= 0 && !interval.Start.After(merged[last].End) {
+			if interval.End.After(merged[last].End) {
+				merged[last].End = interval.End
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+// freeIntervals возвращает промежутки интервала [from, to) между объединенными занятыми промежутками busy
+// длительностью не меньше minFree
+func freeIntervals(busy []types.Interval, from, to time.Time, minFree time.Duration) []types.Interval {
+	free := []types.Interval{}
+	add := func(start, end time.Time) {
+		if length := end.Sub(start); length > 0 && length >= minFree {
+			free = append(free, types.Interval{Start: start, End: end})
+		}
+	}
+
+	cursor := from
+	for _, interval := range busy {
+		add(cursor, interval.Start)
+		if interval.End.After(cursor) {
+			cursor = interval.End
+		}
+	}
+	add(cursor, to)
+	return free
+}
@@ -0,0 +1,159 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"calendar/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func at(hour, minute int) time.Time {
+	return time.Date(2024, 1, 10, hour, minute, 0, 0, time.UTC)
+}
+
+func schedule(t *testing.T, service *Service, userID, start, duration string) *types.Event {
+	t.Helper()
+	event, err := service.ScheduleEvent(userID, types.EventInput{
+		Text:      "Встреча",
+		EventTime: types.EventTime{Start: start, Duration: duration},
+	})
+	require.NoError(t, err)
+	return event
+}
+
+func TestService_FreeBusy(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		schedule(t, service, "user1", "2024-01-10T10:00:00Z", "1h")
+		schedule(t, service, "user2", "2024-01-10T10:30:00Z", "90m")
+		schedule(t, service, "user2", "2024-01-10T13:00:00Z", "15m")
+		schedule(t, service, "user2", "2024-01-10T13:10:00Z", "10m")
+
+		freeBusy, err := service.FreeBusy("user1", []string{"user1", "user2", "user1"}, "2024-01-10T09:00", "2024-01-10T14:00", 30*time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, []types.Interval{{Start: at(10, 0), End: at(11, 0)}}, freeBusy.Busy["user1"])
+		assert.Equal(t, []types.Interval{
+			{Start: at(10, 30), End: at(12, 0)},
+			{Start: at(13, 0), End: at(13, 20)},
+		}, freeBusy.Busy["user2"])
+		// Занятость пользователей объединяется, свободное время - общее для всех
+		assert.Equal(t, []types.Interval{
+			{Start: at(9, 0), End: at(10, 0)},
+			{Start: at(12, 0), End: at(13, 0)},
+			{Start: at(13, 20), End: at(14, 0)},
+		}, freeBusy.Free)
+
+		// Промежуток 13:20-14:00 короче часа
+		freeBusy, err = service.FreeBusy("user1", []string{"user2"}, "2024-01-10T09:00", "2024-01-10T14:00", time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, []types.Interval{{Start: at(9, 0), End: at(10, 30)}, {Start: at(12, 0), End: at(13, 0)}}, freeBusy.Free)
+
+		// У пользователя без событий нет занятых промежутков
+		freeBusy, err = service.FreeBusy("user1", []string{"user3"}, "2024-01-10", "2024-01-11", 0)
+		require.NoError(t, err)
+		assert.Empty(t, freeBusy.Busy["user3"])
+		assert.Equal(t, []types.Interval{{Start: at(0, 0), End: at(24, 0)}}, freeBusy.Free)
+
+		_, err = service.FreeBusy("user1", nil, "2024-01-10", "2024-01-11", 0)
+		assert.Error(t, err)
+		_, err = service.FreeBusy("user1", []string{"user2"}, "2024-01-10", "2024-01-11", -time.Minute)
+		assert.Error(t, err)
+		_, err = service.FreeBusy("user1", []string{"user2"}, "2024-01-11", "2024-01-10", 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestService_FreeBusyAllDay(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		_, err := service.SetUserTimeZone("user2", "Europe/Moscow")
+		require.NoError(t, err)
+		_, err = service.CreateEvent("user2", "2024-01-11", "Отпуск")
+		require.NoError(t, err)
+
+		// Событие на весь день занимает сутки в часовом поясе владельца календаря
+		freeBusy, err := service.FreeBusy("user1", []string{"user2"}, "2024-01-10", "2024-01-13", 0)
+		require.NoError(t, err)
+		assert.Equal(t, []types.Interval{{
+			Start: time.Date(2024, 1, 10, 21, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 1, 11, 21, 0, 0, 0, time.UTC),
+		}}, freeBusy.Busy["user2"])
+		assert.Len(t, freeBusy.Free, 2)
+	})
+}
+
+func TestService_RejectingConflicts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		strict := service.RejectingConflicts()
+		meeting := schedule(t, service, "user1", "2024-01-10T10:00:00Z", "1h")
+
+		_, err := strict.ScheduleEvent("user1", types.EventInput{
+			Text:      "Пересечение",
+			EventTime: types.EventTime{Start: "2024-01-10T10:30:00Z", Duration: "1h"},
+		})
+		require.ErrorIs(t, err, ErrConflict)
+		var conflictErr *ConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		require.Len(t, conflictErr.Conflicts, 1)
+		assert.Equal(t, meeting.ID, conflictErr.Conflicts[0].ID)
+
+		// Смежные события и события других пользователей не пересекаются
+		next, err := strict.ScheduleEvent("user1", types.EventInput{
+			Text:      "Следом",
+			EventTime: types.EventTime{Start: "2024-01-10T11:00:00Z", Duration: "1h"},
+		})
+		require.NoError(t, err)
+		_, err = strict.ScheduleEvent("user2", types.EventInput{
+			Text:      "Чужое",
+			EventTime: types.EventTime{Start: "2024-01-10T10:00:00Z", Duration: "1h"},
+		})
+		require.NoError(t, err)
+
+		// Событие не пересекается само с собой, но не может наехать на соседнее
+		_, err = strict.RescheduleEvent(meeting.ID, "user1", "Встреча", types.EventTime{Start: "2024-01-10T09:30:00Z", Duration: "90m"})
+		require.NoError(t, err)
+		_, err = strict.RescheduleEvent(next.ID, "user1", "Следом", types.EventTime{Start: "2024-01-10T10:45:00Z", Duration: "1h"})
+		assert.ErrorIs(t, err, ErrConflict)
+
+		// Без запрета пересечение сохраняется, а Conflicts его находит
+		overlapping := schedule(t, service, "user1", "2024-01-10T10:30:00Z", "1h")
+		conflicts, err := service.Conflicts(overlapping)
+		require.NoError(t, err)
+		assert.Len(t, conflicts, 2)
+
+		// Повторения серии проверяются на год вперед, событие на весь день занимает весь день
+		_, err = strict.ScheduleEvent("user1", types.EventInput{
+			Text:      "Планерка",
+			EventTime: types.EventTime{Start: "2024-01-01T10:00:00Z", Duration: "15m"},
+			RRule:     "FREQ=DAILY",
+		})
+		assert.ErrorIs(t, err, ErrConflict)
+		_, err = strict.CreateEvent("user1", "2024-01-10", "Весь день")
+		assert.ErrorIs(t, err, ErrConflict)
+		_, err = strict.CreateEvent("user1", "2024-01-11", "Весь день")
+		require.NoError(t, err)
+	})
+}
+
+func TestService_RejectingConflictsSeries(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		strict := service.RejectingConflicts()
+		series, err := strict.ScheduleEvent("user1", types.EventInput{
+			Text:      "Планерка",
+			EventTime: types.EventTime{Start: "2024-01-08T10:00:00Z", Duration: "30m"},
+			RRule:     "FREQ=DAILY;COUNT=5",
+		})
+		require.NoError(t, err)
+
+		// Перенос повторения не пересекается с ним самим, но пересекается с соседним
+		_, err = strict.RescheduleOccurrence(series.ID, "user1", "2024-01-09", "Планерка", types.EventTime{Start: "2024-01-09T10:15:00Z", Duration: "30m"})
+		require.NoError(t, err)
+		_, err = strict.RescheduleOccurrence(series.ID, "user1", "2024-01-10", "Планерка", types.EventTime{Start: "2024-01-11T10:15:00Z", Duration: "30m"})
+		assert.ErrorIs(t, err, ErrConflict)
+
+		// Изменение серии не пересекается с ее перенесенными повторениями
+		_, err = strict.UpdateRecurrence(series.ID, "user1", "FREQ=DAILY;COUNT=3", nil)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,497 @@
+package calendar
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"calendar/internal/ical"
+	"calendar/internal/types"
+)
+
+const (
+	icsProdID    = "-//calendar//Calendar Service//RU"
+	icsUIDDomain = "calendar"
+)
+
+// ExportICS записывает все события пользователя в формате iCalendar (RFC 5545).
+// Серии выгружаются с RRULE и EXDATE, измененные повторения - отдельными VEVENT с RECURRENCE-ID.
+// Время событий задается с TZID по именам IANA без компонентов VTIMEZONE, как это делают
+// популярные календари: клиенты берут правила поясов из своей базы
+func (s *Service) ExportICS(w io.Writer, userID string) error {
+	if userID == "" {
+		return errors.New("user_id не может быть пустым")
+	}
+
+	timeZone, err := s.userTimeZone(userID)
+	if err != nil {
+		return err
+	}
+
+	events, err := s.store.ListByUser(userID)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]*types.Event, len(events))
+	uids := make(map[string]string, len(events))
+	overridden := make(map[string]bool)
+	for _, event := range events {
+		if err := localize(event); err != nil {
+			return err
+		}
+		byID[event.ID] = event
+		uids[event.ID] = eventUID(event)
+		if event.SeriesID != "" && event.RecurrenceID != nil {
+			overridden[overrideKey(event.SeriesID, *event.RecurrenceID)] = true
+		}
+	}
+
+	cal := ical.NewComponent("VCALENDAR")
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", icsProdID)
+	cal.Add("CALSCALE", "GREGORIAN")
+	cal.Add("METHOD", "PUBLISH")
+	cal.Add("X-WR-CALNAME", ical.EscapeText(userID))
+	cal.Add("X-WR-TIMEZONE", timeZone)
+
+	stamp := ical.FormatUTC(time.Now())
+	for _, event := range events {
+		uid := uids[event.ID]
+		if seriesUID, ok := uids[event.SeriesID]; ok {
+			// Измененное повторение в iCalendar имеет UID своей серии
+			uid = seriesUID
+		}
+
+		vevent := ical.NewComponent("VEVENT")
+		vevent.Add("UID", uid)
+		vevent.Add("DTSTAMP", stamp)
+		addICSTime(vevent, "DTSTART", event, event.Date)
+		// Для DATE-TIME конец должен быть позже начала, поэтому у мгновенных событий DTEND нет
+		if event.End.After(event.Date) {
+			addICSTime(vevent, "DTEND", event, event.End)
+		}
+		if event.RecurrenceID != nil {
+			// RECURRENCE-ID имеет тот же тип значения, что и DTSTART серии
+			series, ok := byID[event.SeriesID]
+			if !ok {
+				series = event
+			}
+			addICSTime(vevent, "RECURRENCE-ID", series, *event.RecurrenceID)
+		}
+		vevent.Add("SUMMARY", ical.EscapeText(event.Text))
+		if event.Description != "" {
+			vevent.Add("DESCRIPTION", ical.EscapeText(event.Description))
+		}
+		if event.Location != "" {
+			vevent.Add("LOCATION", ical.EscapeText(event.Location))
+		}
+		if len(event.Categories) > 0 {
+			categories := make([]string, 0, len(event.Categories))
+			for _, category := range event.Categories {
+				categories = append(categories, ical.EscapeText(category))
+			}
+			vevent.Add("CATEGORIES", strings.Join(categories, ","))
+		}
+		for _, minutes := range event.Reminders {
+			alarm := ical.NewComponent("VALARM")
+			alarm.Add("ACTION", "DISPLAY")
+			alarm.Add("DESCRIPTION", ical.EscapeText(event.Text))
+			alarm.Add("TRIGGER", ical.FormatDuration(-time.Duration(minutes)*time.Minute))
+			vevent.Components = append(vevent.Components, alarm)
+		}
+		if event.RRule != "" {
+			vevent.Add("RRULE", event.RRule)
+		}
+		// Измененные повторения заменяют экземпляр серии через RECURRENCE-ID, поэтому в EXDATE их нет
+		var exdates []string
+		var exdateParams []string
+		for _, exdate := range event.ExDates {
+			if overridden[overrideKey(event.ID, exdate)] {
+				continue
+			}
+			// Исключенная дата хранится без времени, а EXDATE серии со временем указывает время повторения
+			hh, mm, ss := event.Date.Clock()
+			occurrence := time.Date(exdate.Year(), exdate.Month(), exdate.Day(), hh, mm, ss, 0, event.Date.Location())
+			var value string
+			value, exdateParams = icsTime(event, occurrence)
+			exdates = append(exdates, value)
+		}
+		if len(exdates) > 0 {
+			vevent.Add("EXDATE", strings.Join(exdates, ","), exdateParams...)
+		}
+		cal.Components = append(cal.Components, vevent)
+	}
+
+	return ical.Write(w, cal)
+}
+
+// ImportICS создает события пользователя из VEVENT потока iCalendar.
+// Уже импортированные (по UID) и отмененные события пропускаются, ошибки отдельных VEVENT попадают в отчет
+func (s *Service) ImportICS(r io.Reader, userID string) (*types.ImportResult, error) {
+	if userID == "" {
+		return nil, errors.New("user_id не может быть пустым")
+	}
+
+	cal, err := ical.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	timeZone, err := s.userTimeZone(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.store.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &icsImport{
+		service:   s,
+		userID:    userID,
+		timeZone:  timeZone,
+		series:    make(map[string]*types.Event),
+		overrides: make(map[string]bool),
+		result: &types.ImportResult{
+			Created: []types.ImportEntry{},
+			Skipped: []types.ImportEntry{},
+			Failed:  []types.ImportEntry{},
+		},
+	}
+
+	byID := make(map[string]*types.Event, len(existing))
+	for _, event := range existing {
+		byID[event.ID] = event
+		if event.SeriesID == "" {
+			imp.series[eventUID(event)] = event
+		}
+	}
+	for _, event := range existing {
+		if series, ok := byID[event.SeriesID]; ok && event.RecurrenceID != nil {
+			imp.overrides[overrideKey(eventUID(series), *event.RecurrenceID)] = true
+		}
+	}
+
+	// Измененные повторения импортируются после серий, на которые они ссылаются
+	var overrides []*ical.Component
+	for _, component := range cal.Components {
+		switch {
+		case component.Name == "VTIMEZONE":
+			continue
+		case component.Name != "VEVENT":
+			imp.skip(component, "поддерживаются только VEVENT")
+		case component.Get("RECURRENCE-ID") != nil:
+			overrides = append(overrides, component)
+		default:
+			imp.importEvent(component)
+		}
+	}
+	for _, component := range overrides {
+		imp.importOverride(component)
+	}
+
+	return imp.result, nil
+}
+
+// icsImport хранит состояние одного импорта
+type icsImport struct {
+	service   *Service
+	userID    string
+	timeZone  string
+	series    map[string]*types.Event
+	overrides map[string]bool
+	result    *types.ImportResult
+}
+
+func (imp *icsImport) importEvent(vevent *ical.Component) {
+	uid := vevent.Value("UID")
+	if isCancelled(vevent) {
+		imp.skip(vevent, "событие отменено")
+		return
+	}
+	if _, exists := imp.series[uid]; exists && uid != "" {
+		imp.skip(vevent, "событие уже существует")
+		return
+	}
+
+	when, err := icsEventTime(vevent, imp.timeZone)
+	if err != nil {
+		imp.fail(vevent, err)
+		return
+	}
+
+	var exdates []string
+	for _, property := range vevent.GetAll("EXDATE") {
+		for _, value := range strings.Split(property.Value, ",") {
+			exdate, err := icsDate(&ical.Property{Name: property.Name, Params: property.Params, Value: value})
+			if err != nil {
+				imp.fail(vevent, err)
+				return
+			}
+			exdates = append(exdates, exdate)
+		}
+	}
+
+	event, err := newEvent(imp.userID, types.EventInput{
+		Text:      summary(vevent),
+		EventTime: when,
+		RRule:     vevent.Value("RRULE"),
+		ExDates:   exdates,
+	}, imp.timeZone)
+	if err != nil {
+		imp.fail(vevent, err)
+		return
+	}
+	event.UID = uid
+	event.Reminders = icsReminders(vevent)
+	if err := applyDetails(event, icsDetails(vevent)); err != nil {
+		imp.fail(vevent, err)
+		return
+	}
+
+	imp.service.mutex.Lock()
+	err = imp.service.saveEvent(event)
+	imp.service.mutex.Unlock()
+	if err != nil {
+		imp.fail(vevent, err)
+		return
+	}
+
+	if uid != "" {
+		imp.series[uid] = event
+	}
+	imp.create(vevent, event.ID, "")
+}
+
+func (imp *icsImport) importOverride(vevent *ical.Component) {
+	uid := vevent.Value("UID")
+	series, ok := imp.series[uid]
+	if !ok {
+		imp.fail(vevent, errors.New("серия повторения не найдена"))
+		return
+	}
+
+	recurrenceDate, err := icsDate(vevent.Get("RECURRENCE-ID"))
+	if err != nil {
+		imp.fail(vevent, err)
+		return
+	}
+	recurrenceDay, _ := time.Parse("2006-01-02", recurrenceDate)
+	key := overrideKey(uid, recurrenceDay)
+	if imp.overrides[key] {
+		imp.skip(vevent, "повторение уже существует")
+		return
+	}
+
+	if isCancelled(vevent) {
+		if err := imp.service.DeleteOccurrence(series.ID, imp.userID, recurrenceDate); err != nil {
+			imp.fail(vevent, err)
+			return
+		}
+		imp.create(vevent, series.ID, "повторение удалено из серии")
+		return
+	}
+
+	// Без DTSTART повторение сохраняет время серии
+	when := types.EventTime{Date: recurrenceDate}
+	if vevent.Get("DTSTART") != nil {
+		if when, err = icsEventTime(vevent, imp.timeZone); err != nil {
+			imp.fail(vevent, err)
+			return
+		}
+	}
+	text := summary(vevent)
+	if text == "" {
+		text = series.Text
+	}
+
+	override, err := imp.service.RescheduleOccurrence(series.ID, imp.userID, recurrenceDate, text, when)
+	if err != nil {
+		imp.fail(vevent, err)
+		return
+	}
+
+	imp.overrides[key] = true
+	imp.create(vevent, override.ID, "")
+}
+
+func (imp *icsImport) create(component *ical.Component, eventID, reason string) {
+	entry := importEntry(component, reason)
+	entry.EventID = eventID
+	imp.result.Created = append(imp.result.Created, entry)
+}
+
+func (imp *icsImport) skip(component *ical.Component, reason string) {
+	imp.result.Skipped = append(imp.result.Skipped, importEntry(component, reason))
+}
+
+func (imp *icsImport) fail(component *ical.Component, err error) {
+	imp.result.Failed = append(imp.result.Failed, importEntry(component, err.Error()))
+}
+
+func importEntry(component *ical.Component, reason string) types.ImportEntry {
+	entry := types.ImportEntry{
+		UID:     component.Value("UID"),
+		Summary: summary(component),
+		Reason:  reason,
+	}
+	if component.Name != "VEVENT" {
+		entry.Summary = component.Name
+	}
+	return entry
+}
+
+// icsEventTime переводит DTSTART, DTEND и DURATION во время события.
+// Плавающее время без часового пояса относится к поясу пользователя timeZone
+func icsEventTime(vevent *ical.Component, timeZone string) (types.EventTime, error) {
+	dtstart := vevent.Get("DTSTART")
+	if dtstart == nil {
+		return types.EventTime{}, errors.New("не задана дата начала DTSTART")
+	}
+	start, allDay, err := ical.ParseDateTime(dtstart)
+	if err != nil {
+		return types.EventTime{}, err
+	}
+	end, err := icsEnd(vevent, start, allDay)
+	if err != nil {
+		return types.EventTime{}, err
+	}
+
+	if allDay {
+		when := types.EventTime{Start: start.Format("2006-01-02"), AllDay: true}
+		// DTEND события на весь день - день после последнего
+		if lastDay := end.AddDate(0, 0, -1); lastDay.After(start) {
+			when.End = lastDay.Format("2006-01-02")
+		}
+		return when, nil
+	}
+
+	zone := dtstart.Param("TZID")
+	switch {
+	case strings.HasSuffix(dtstart.Value, "Z"):
+		zone = defaultTimeZone
+	case zone == "":
+		zone = timeZone
+	}
+
+	when := types.EventTime{Start: start.Format("2006-01-02T15:04:05"), TimeZone: zone}
+	if end.After(start) {
+		when.Duration = end.Sub(start).String()
+	}
+	return when, nil
+}
+
+// icsEnd возвращает конец события из DTEND или DURATION. Без них событие на весь день длится один день,
+// а событие со временем не имеет длительности
+func icsEnd(vevent *ical.Component, start time.Time, allDay bool) (time.Time, error) {
+	if dtend := vevent.Get("DTEND"); dtend != nil {
+		end, _, err := ical.ParseDateTime(dtend)
+		return end, err
+	}
+	if value := vevent.Value("DURATION"); value != "" {
+		duration, err := ical.ParseDuration(value)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return start.Add(duration), nil
+	}
+	if allDay {
+		return start.AddDate(0, 0, 1), nil
+	}
+	return start, nil
+}
+
+// icsReminders возвращает напоминания из VALARM с TRIGGER относительно начала события.
+// Напоминания в абсолютное время, после начала или раньше допустимого пропускаются
+func icsReminders(vevent *ical.Component) []int {
+	var minutes []int
+	for _, alarm := range vevent.Components {
+		trigger := alarm.Get("TRIGGER")
+		if alarm.Name != "VALARM" || trigger == nil || trigger.Param("VALUE") == "DATE-TIME" || trigger.Param("RELATED") == "END" {
+			continue
+		}
+		before, err := ical.ParseDuration(trigger.Value)
+		if err != nil || before > 0 {
+			continue
+		}
+		minutes = append(minutes, int(-before/time.Minute))
+	}
+
+	reminders, err := parseReminders(minutes)
+	if err != nil {
+		return nil
+	}
+	return reminders
+}
+
+// addICSTime добавляет свойство со временем t в формате, соответствующем событию
+func addICSTime(component *ical.Component, name string, event *types.Event, t time.Time) {
+	value, params := icsTime(event, t)
+	component.Add(name, value, params...)
+}
+
+// icsTime возвращает значение DATE для события на весь день или DATE-TIME в часовом поясе события
+// вместе с параметрами свойства
+func icsTime(event *types.Event, t time.Time) (string, []string) {
+	if event.AllDay {
+		return ical.FormatDate(t), []string{"VALUE", "DATE"}
+	}
+	if event.TimeZone != "" && event.TimeZone != defaultTimeZone {
+		if location, err := loadLocation(event.TimeZone); err == nil {
+			return ical.FormatLocal(t.In(location)), []string{"TZID", event.TimeZone}
+		}
+	}
+	return ical.FormatUTC(t), nil
+}
+
+// icsDate возвращает дату значения DATE или DATE-TIME в формате 2006-01-02 в его часовом поясе
+func icsDate(property *ical.Property) (string, error) {
+	if property == nil {
+		return "", errors.New("не задана дата начала DTSTART")
+	}
+	t, _, err := ical.ParseDateTime(property)
+	if err != nil {
+		return "", err
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+// icsDetails читает описание, место и категории VEVENT. Участники не импортируются:
+// адреса iCalendar не совпадают с пользователями календаря
+func icsDetails(vevent *ical.Component) types.EventDetails {
+	details := types.EventDetails{
+		Description: ical.UnescapeText(vevent.Value("DESCRIPTION")),
+		Location:    ical.UnescapeText(vevent.Value("LOCATION")),
+	}
+	for _, property := range vevent.GetAll("CATEGORIES") {
+		for _, category := range strings.Split(property.Value, ",") {
+			if category = ical.UnescapeText(category); strings.TrimSpace(category) != "" {
+				details.Categories = append(details.Categories, category)
+			}
+		}
+	}
+	return details
+}
+
+func summary(component *ical.Component) string {
+	return ical.UnescapeText(component.Value("SUMMARY"))
+}
+
+func isCancelled(component *ical.Component) bool {
+	return strings.EqualFold(component.Value("STATUS"), "CANCELLED")
+}
+
+// eventUID возвращает UID события для iCalendar: исходный UID импортированного события или id в домене сервиса
+func eventUID(event *types.Event) string {
+	if event.UID != "" {
+		return event.UID
+	}
+	return event.ID + "@" + icsUIDDomain
+}
+
+func overrideKey(uid string, recurrenceID time.Time) string {
+	return uid + "|" + recurrenceID.Format("2006-01-02")
+}
@@ -0,0 +1,189 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"calendar/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testICS = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example//Example//EN
+BEGIN:VTIMEZONE
+TZID:Europe/Moscow
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:standup@example.com
+DTSTART;TZID=Europe/Moscow:20231204T100000
+SUMMARY:Планерка
+RRULE:FREQ=WEEKLY;BYDAY=MO;COUNT=4
+EXDATE;TZID=Europe/Moscow:20231218T100000
+END:VEVENT
+BEGIN:VEVENT
+UID:standup@example.com
+RECURRENCE-ID;TZID=Europe/Moscow:20231211T100000
+DTSTART;TZID=Europe/Moscow:20231212T100000
+SUMMARY:Планерка во вторник
+END:VEVENT
+BEGIN:VEVENT
+UID:party@example.com
+DTSTART;VALUE=DATE:20231229
+SUMMARY:Корпоратив\, с семьями
+END:VEVENT
+BEGIN:VEVENT
+UID:cancelled@example.com
+DTSTART;VALUE=DATE:20231230
+SUMMARY:Отменено
+STATUS:CANCELLED
+END:VEVENT
+BEGIN:VEVENT
+UID:broken@example.com
+SUMMARY:Без даты
+END:VEVENT
+BEGIN:VEVENT
+UID:orphan@example.com
+RECURRENCE-ID;VALUE=DATE:20231211
+DTSTART;VALUE=DATE:20231212
+SUMMARY:Без серии
+END:VEVENT
+BEGIN:VTODO
+UID:todo@example.com
+SUMMARY:Задача
+END:VTODO
+END:VCALENDAR
+`
+
+func TestService_ImportICS(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		result, err := service.ImportICS(strings.NewReader(testICS), "user1")
+		require.NoError(t, err)
+
+		assert.Len(t, result.Created, 3)
+		assert.Len(t, result.Skipped, 2, "отмененное событие и VTODO")
+		require.Len(t, result.Failed, 2)
+		assert.Equal(t, "broken@example.com", result.Failed[0].UID)
+		assert.Equal(t, "orphan@example.com", result.Failed[1].UID)
+
+		events, err := service.GetEventsForMonth("user1", "2023-12-01")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2023-12-04", "2023-12-12", "2023-12-25", "2023-12-29"}, eventDates(events))
+		assert.Equal(t, "Планерка во вторник", events[1].Text)
+		assert.Equal(t, "Корпоратив, с семьями", events[3].Text)
+
+		// Повторный импорт того же файла не создает дубликатов
+		result, err = service.ImportICS(strings.NewReader(testICS), "user1")
+		require.NoError(t, err)
+		assert.Empty(t, result.Created)
+		assert.Len(t, result.Skipped, 5)
+
+		_, err = service.ImportICS(strings.NewReader("not a calendar"), "user1")
+		assert.Error(t, err)
+	})
+}
+
+func TestService_ExportICS(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		series, err := service.CreateRecurringEvent("user1", "2023-12-04", "Планерка; еженедельная", "FREQ=WEEKLY;COUNT=4", []string{"2023-12-18"})
+		require.NoError(t, err)
+		_, err = service.UpdateOccurrence(series.ID, "user1", "2023-12-11", "2023-12-12", "Планерка во вторник")
+		require.NoError(t, err)
+		_, err = service.CreateEvent("user1", "2023-12-29", "Корпоратив")
+		require.NoError(t, err)
+		_, err = service.CreateEvent("user2", "2023-12-29", "Чужое событие")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, service.ExportICS(&buf, "user1"))
+		ics := buf.String()
+
+		assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+		assert.Contains(t, ics, "UID:"+series.ID+"@calendar\r\n")
+		assert.Contains(t, ics, "RRULE:FREQ=WEEKLY;COUNT=4\r\n")
+		assert.Contains(t, ics, "EXDATE;VALUE=DATE:20231218\r\n")
+		assert.Contains(t, ics, "RECURRENCE-ID;VALUE=DATE:20231211\r\n")
+		assert.Contains(t, ics, `SUMMARY:Планерка\; еженедельная`)
+		assert.NotContains(t, ics, "Чужое событие")
+		assert.Equal(t, 3, strings.Count(ics, "BEGIN:VEVENT"))
+
+		// Выгрузка импортируется в другой календарь без потерь
+		other := NewService(NewMemoryStore())
+		result, err := other.ImportICS(&buf, "user3")
+		require.NoError(t, err)
+		assert.Len(t, result.Created, 3)
+		assert.Empty(t, result.Failed)
+
+		want, err := service.GetEventsForMonth("user1", "2023-12-01")
+		require.NoError(t, err)
+		got, err := other.GetEventsForMonth("user3", "2023-12-01")
+		require.NoError(t, err)
+		assert.Equal(t, eventDates(want), eventDates(got))
+	})
+}
+
+func TestService_ICSTimedEvents(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		_, err := service.ScheduleEvent("user1", types.EventInput{
+			Text: "Планерка",
+			EventTime: types.EventTime{
+				Start:    "2023-12-04T10:00",
+				Duration: "30m",
+				TimeZone: "Europe/Moscow",
+			},
+			RRule:   "FREQ=WEEKLY;COUNT=3",
+			ExDates: []string{"2023-12-11"},
+		})
+		require.NoError(t, err)
+		_, err = service.ScheduleEvent("user1", types.EventInput{
+			Text:      "Отпуск",
+			EventTime: types.EventTime{Date: "2023-12-29", End: "2024-01-02", AllDay: true},
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, service.ExportICS(&buf, "user1"))
+		ics := buf.String()
+
+		assert.Contains(t, ics, "DTSTART;TZID=Europe/Moscow:20231204T100000\r\n")
+		assert.Contains(t, ics, "DTEND;TZID=Europe/Moscow:20231204T103000\r\n")
+		assert.Contains(t, ics, "EXDATE;TZID=Europe/Moscow:20231211T100000\r\n")
+		assert.Contains(t, ics, "DTSTART;VALUE=DATE:20231229\r\n")
+		assert.Contains(t, ics, "DTEND;VALUE=DATE:20240103\r\n")
+
+		other := NewService(NewMemoryStore())
+		result, err := other.ImportICS(&buf, "user2")
+		require.NoError(t, err)
+		assert.Len(t, result.Created, 2)
+
+		want, err := service.GetEventsForMonth("user1", "2023-12-01")
+		require.NoError(t, err)
+		got, err := other.GetEventsForMonth("user2", "2023-12-01")
+		require.NoError(t, err)
+		require.Len(t, got, len(want))
+		for i := range want {
+			assert.True(t, want[i].Date.Equal(got[i].Date))
+			assert.True(t, want[i].End.Equal(got[i].End))
+			assert.Equal(t, want[i].TimeZone, got[i].TimeZone)
+		}
+
+		// DURATION и плавающее время в поясе пользователя
+		_, err = other.SetUserTimeZone("user3", "Asia/Tokyo")
+		require.NoError(t, err)
+		floating := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:call\r\nDTSTART:20231205T090000\r\nDURATION:PT45M\r\nSUMMARY:Звонок\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+		result, err = other.ImportICS(strings.NewReader(floating), "user3")
+		require.NoError(t, err)
+		require.Len(t, result.Created, 1)
+
+		events, err := other.GetEventsForDay("user3", "2023-12-05")
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, "Asia/Tokyo", events[0].TimeZone)
+		assert.Equal(t, 9, events[0].Date.Hour())
+		assert.Equal(t, 45*time.Minute, events[0].End.Sub(events[0].Date))
+	})
+}
@@ -0,0 +1,230 @@
+package calendar
+
+import (
+	"sort"
+	"time"
+
+	"calendar/internal/rrule"
+	"calendar/internal/types"
+)
+
+// shortSpan - наибольшая длительность события, которое хранится в списке коротких событий.
+// Сутки с запасом на переход на летнее время: так в него попадают события на один день
+const shortSpan = 25 * time.Hour
+
+// unbounded - конец бесконечной серии
+var unbounded = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// userIndex упорядочивает события одного пользователя по началу, чтобы выборка по интервалу
+// занимала O(log n + k) вместо просмотра всех событий хранилища
+type userIndex struct {
+	// short - одиночные события не длиннее shortSpan, упорядоченные по дате и id. Событие, начавшееся
+	// раньше from-shortSpan, уже закончилось к from, поэтому просмотр начинается с этой даты
+	short []*types.Event
+	// long - многодневные события и серии: их длительность не ограничена, поэтому они ищутся по интервалам
+	long intervalList
+}
+
+// interval - промежуток времени, который занимает событие или все повторения серии
+type interval struct {
+	start, end time.Time
+	event      *types.Event
+}
+
+// intervalList - интервалы, упорядоченные по началу и id, с неявным деревом поиска поверх них:
+// узел поддерева [lo, hi) - элемент (lo+hi)/2, а maxEnd[узел] - наибольший конец интервала в поддереве
+type intervalList struct {
+	items  []interval
+	maxEnd []time.Time
+}
+
+func newUserIndex() *userIndex {
+	return &userIndex{}
+}
+
+// insert добавляет событие в индекс. Вставка в конец, частая при создании событий по порядку, не сдвигает срез
+func (idx *userIndex) insert(event *types.Event) {
+	end := indexedEnd(event)
+	if event.RRule == "" && end.Sub(event.Date) <= shortSpan {
+		i := searchEvents(idx.short, event.Date, event.ID)
+		idx.short = append(idx.short, nil)
+		copy(idx.short[i+1:], idx.short[i:])
+		idx.short[i] = event
+		return
+	}
+	idx.long.insert(interval{start: event.Date, end: end, event: event})
+}
+
+// remove удаляет событие, ранее добавленное insert
+func (idx *userIndex) remove(event *types.Event) {
+	i := searchEvents(idx.short, event.Date, event.ID)
+	if i < len(idx.short) && idx.short[i].ID == event.ID {
+		idx.short = append(idx.short[:i], idx.short[i+1:]...)
+		return
+	}
+	idx.long.remove(event)
+}
+
+// empty сообщает, что в индексе нет событий
+func (idx *userIndex) empty() bool {
+	return len(idx.short) == 0 && len(idx.long.items) == 0
+}
+
+// searchEvents возвращает позицию первого события, которое не раньше (date, id)
+func searchEvents(events []*types.Event, date time.Time, id string) int {
+	return sort.Search(len(events), func(i int) bool {
+		event := events[i]
+		if !event.Date.Equal(date) {
+			return event.Date.After(date)
+		}
+		return event.ID >= id
+	})
+}
+
+// between возвращает события, пересекающиеся с интервалом [from, to), и серии, повторения которых
+// могут в него попасть, упорядоченные по дате
+func (idx *userIndex) between(from, to time.Time) []*types.Event {
+	var events []*types.Event
+	for i := searchEvents(idx.short, from.Add(-shortSpan), ""); i < len(idx.short); i++ {
+		event := idx.short[i]
+		if !event.Date.Before(to) {
+			break
+		}
+		if overlaps(event.Date, eventEnd(event), from, to) {
+			events = append(events, event)
+		}
+	}
+
+	long := idx.long.between(from, to)
+	if len(long) == 0 {
+		return events
+	}
+	events = append(events, long...)
+	sortEvents(events)
+	return events
+}
+
+// all возвращает все события пользователя, упорядоченные по дате
+func (idx *userIndex) all() []*types.Event {
+	events := make([]*types.Event, 0, len(idx.short)+len(idx.long.items))
+	events = append(events, idx.short...)
+	for _, item := range idx.long.items {
+		events = append(events, item.event)
+	}
+	sortEvents(events)
+	return events
+}
+
+// search возвращает позицию первого интервала, который не раньше (start, id)
+func (l *intervalList) search(start time.Time, id string) int {
+	return sort.Search(len(l.items), func(i int) bool {
+		item := l.items[i]
+		if !item.start.Equal(start) {
+			return item.start.After(start)
+		}
+		return item.event.ID >= id
+	})
+}
+
+func (l *intervalList) insert(item interval) {
+	i := l.search(item.start, item.event.ID)
+	l.items = append(l.items, interval{})
+	copy(l.items[i+1:], l.items[i:])
+	l.items[i] = item
+	l.rebuild()
+}
+
+func (l *intervalList) remove(event *types.Event) {
+	i := l.search(event.Date, event.ID)
+	if i < len(l.items) && l.items[i].event.ID == event.ID {
+		l.items = append(l.items[:i], l.items[i+1:]...)
+		l.rebuild()
+	}
+}
+
+// rebuild пересчитывает maxEnd после изменения списка. Вставка в срез и так занимает O(n)
+func (l *intervalList) rebuild() {
+	if cap(l.maxEnd) < len(l.items) {
+		l.maxEnd = make([]time.Time, len(l.items), cap(l.items))
+	}
+	l.maxEnd = l.maxEnd[:len(l.items)]
+	l.build(0, len(l.items))
+}
+
+func (l *intervalList) build(lo, hi int) time.Time {
+	if lo >= hi {
+		return time.Time{}
+	}
+	mid := (lo + hi) / 2
+	end := l.items[mid].end
+	if left := l.build(lo, mid); left.After(end) {
+		end = left
+	}
+	if right := l.build(mid+1, hi); right.After(end) {
+		end = right
+	}
+	l.maxEnd[mid] = end
+	return end
+}
+
+// between возвращает события интервалов, пересекающихся с [from, to), по возрастанию начала.
+// Поддеревья, все интервалы которых закончились к from или начинаются не раньше to, пропускаются
+func (l *intervalList) between(from, to time.Time) []*types.Event {
+	var events []*types.Event
+	var walk func(lo, hi int)
+	walk = func(lo, hi int) {
+		if lo >= hi {
+			return
+		}
+		mid := (lo + hi) / 2
+		if l.maxEnd[mid].Before(from) {
+			return
+		}
+		walk(lo, mid)
+		item := l.items[mid]
+		if !item.start.Before(to) {
+			return
+		}
+		if overlaps(item.start, item.end, from, to) {
+			events = append(events, item.event)
+		}
+		walk(mid+1, hi)
+	}
+	walk(0, len(l.items))
+	return events
+}
+
+// indexedEnd возвращает конец промежутка, который занимает событие, а для серии - конец ее последнего
+// повторения с запасом на часовые пояса: хранилище может вернуть дату серии не в ее поясе, а повторения
+// считаются по местным датам. Конец серии без COUNT и UNTIL не ограничен
+func indexedEnd(event *types.Event) time.Time {
+	if event.RRule == "" {
+		return eventEnd(event)
+	}
+
+	rule, err := rrule.Parse(event.RRule)
+	if err != nil {
+		return unbounded
+	}
+	duration := eventEnd(event).Sub(event.Date)
+	switch {
+	case rule.Count > 0:
+		occurrences := rule.Between(event.Date, event.Date, unbounded)
+		if len(occurrences) == 0 {
+			return eventEnd(event)
+		}
+		return occurrences[len(occurrences)-1].AddDate(0, 0, 2).Add(duration)
+	case !rule.Until.IsZero():
+		return rule.Until.AddDate(0, 0, 2).Add(duration)
+	default:
+		return unbounded
+	}
+}
+
+// eventEnd возвращает конец события. События без конца, сохраненные до его появления, длятся сутки
+func eventEnd(event *types.Event) time.Time {
+	if event.End.IsZero() {
+		return event.Date.AddDate(0, 0, 1)
+	}
+	return event.End
+}
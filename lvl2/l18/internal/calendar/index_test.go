@@ -0,0 +1,312 @@
+package calendar
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"calendar/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_ListByUserInRange(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	for _, ts := range testStores {
+		t.Run(ts.name, func(t *testing.T) {
+			store := ts.open(t)
+			defer store.Close()
+
+			for _, event := range []*types.Event{
+				{ID: "short", UserID: "user1", Date: day(10).Add(9 * time.Hour), End: day(10).Add(10 * time.Hour)},
+				{ID: "long", UserID: "user1", Date: day(1), End: day(20), AllDay: true},
+				{ID: "legacy", UserID: "user1", Date: day(12)},
+				{ID: "instant", UserID: "user1", Date: day(15), End: day(15)},
+				{ID: "series", UserID: "user1", Date: day(2), End: day(2).Add(time.Hour), RRule: "FREQ=DAILY"},
+				{ID: "later series", UserID: "user1", Date: day(25), End: day(25).Add(time.Hour), RRule: "FREQ=DAILY"},
+				{ID: "other", UserID: "user2", Date: day(10), End: day(11)},
+			} {
+				require.NoError(t, store.Save(event))
+			}
+
+			ids := func(from, to time.Time) []string {
+				events, err := store.ListByUserInRange("user1", from, to)
+				require.NoError(t, err)
+				var ids []string
+				for _, event := range events {
+					ids = append(ids, event.ID)
+				}
+				return ids
+			}
+
+			assert.Equal(t, []string{"long", "series", "short"}, ids(day(10), day(11)))
+			assert.Equal(t, []string{"long", "series", "legacy"}, ids(day(12), day(13)))
+			assert.Equal(t, []string{"long", "series", "instant"}, ids(day(15), day(16)))
+			assert.Equal(t, []string{"series", "later series"}, ids(day(22), day(26)))
+			assert.Empty(t, ids(day(1).AddDate(0, -1, 0), day(1)))
+
+			// Перенос события перемещает его в индексе
+			require.NoError(t, store.Save(&types.Event{ID: "short", UserID: "user1", Date: day(21), End: day(21).Add(time.Hour)}))
+			assert.Equal(t, []string{"long", "series"}, ids(day(10), day(11)))
+			assert.Equal(t, []string{"series", "short"}, ids(day(21), day(22)))
+
+			require.NoError(t, store.Delete("series"))
+			require.NoError(t, store.Delete("short"))
+			assert.Empty(t, ids(day(21), day(22)))
+		})
+	}
+}
+
+// TestStore_ListByUserInRangeMatchesScan сверяет выборку по индексу с полным просмотром событий пользователя
+func TestStore_ListByUserInRangeMatchesScan(t *testing.T) {
+	for _, ts := range testStores {
+		t.Run(ts.name, func(t *testing.T) {
+			store := ts.open(t)
+			defer store.Close()
+
+			random := rand.New(rand.NewSource(1))
+			base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			for i := 0; i < 300; i++ {
+				start := base.Add(time.Duration(random.Intn(60*24)) * time.Hour)
+				event := &types.Event{
+					ID:     fmt.Sprintf("event%03d", i),
+					UserID: fmt.Sprintf("user%d", i%3),
+					Date:   start,
+					End:    start.Add(time.Duration(random.Intn(72)) * time.Hour),
+				}
+				require.NoError(t, store.Save(event))
+				if i%7 == 0 {
+					require.NoError(t, store.Delete(event.ID))
+				}
+			}
+
+			all, err := store.ListByUser("user1")
+			require.NoError(t, err)
+
+			for i := 0; i < 50; i++ {
+				from := base.Add(time.Duration(random.Intn(70*24)-5*24) * time.Hour)
+				to := from.Add(time.Duration(random.Intn(10*24)+1) * time.Hour)
+
+				var want []string
+				for _, event := range all {
+					if overlaps(event.Date, event.End, from, to) {
+						want = append(want, event.ID)
+					}
+				}
+
+				events, err := store.ListByUserInRange("user1", from, to)
+				require.NoError(t, err)
+				var got []string
+				for _, event := range events {
+					got = append(got, event.ID)
+				}
+				assert.Equal(t, want, got, "интервал %s - %s", from, to)
+			}
+		})
+	}
+}
+
+func TestUserIndex_LongEventsAndSeries(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	ids := func(events []*types.Event) []string {
+		var ids []string
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+		return ids
+	}
+
+	idx := newUserIndex()
+	vacation := &types.Event{ID: "vacation", Date: day(1), End: day(1).AddDate(0, 6, 0), AllDay: true}
+	for _, event := range []*types.Event{
+		vacation,
+		{ID: "short", Date: day(10).Add(9 * time.Hour), End: day(10).Add(10 * time.Hour)},
+		{ID: "ended", Date: day(2), End: day(2).Add(time.Hour), RRule: "FREQ=DAILY;COUNT=3"},
+		{ID: "until", Date: day(3), End: day(3).Add(time.Hour), RRule: "FREQ=WEEKLY;UNTIL=20240110"},
+		{ID: "forever", Date: day(5), End: day(5).Add(time.Hour), RRule: "FREQ=DAILY"},
+		{ID: "future", Date: day(25), End: day(25).Add(time.Hour), RRule: "FREQ=DAILY"},
+	} {
+		idx.insert(event)
+	}
+
+	// Серии, закончившиеся до from или начинающиеся не раньше to, не попадают в выборку
+	assert.Equal(t, []string{"vacation", "ended", "until"}, ids(idx.between(day(3), day(4))))
+	assert.Equal(t, []string{"vacation", "until", "forever", "short"}, ids(idx.between(day(10), day(11))))
+	assert.Equal(t, []string{"vacation", "forever"}, ids(idx.between(day(20), day(21))))
+
+	idx.remove(vacation)
+	assert.Equal(t, []string{"forever"}, ids(idx.between(day(20), day(21))))
+	assert.Empty(t, idx.between(day(1).AddDate(0, -1, 0), day(1)))
+
+	// Все события упорядочены по дате, серии вперемешку с одиночными
+	assert.Equal(t, []string{"ended", "until", "forever", "short", "future"}, ids(idx.all()))
+}
+
+func TestSQLiteStore_MigratesRangeColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar.db")
+
+	// База в схеме до появления колонок выборки по интервалу
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		CREATE TABLE events (id TEXT PRIMARY KEY, user_id TEXT NOT NULL, date TEXT NOT NULL, data TEXT NOT NULL);
+		INSERT INTO events VALUES ('legacy', 'user1', '2024-01-10T00:00:00.000000000Z',
+			'{"id":"legacy","user_id":"user1","date":"2024-01-10T00:00:00Z","text":"Старое"}');`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	store, err := NewSQLiteStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	events, err := store.ListByUserInRange("user1", time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC), time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "legacy", events[0].ID)
+}
+
+func TestService_GetEventsInRange(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		_, err := service.SetUserTimeZone("user1", "Europe/Moscow")
+		require.NoError(t, err)
+
+		_, err = service.ScheduleEvent("user1", types.EventInput{
+			Text:      "Конференция",
+			EventTime: types.EventTime{Date: "2024-01-09", End: "2024-01-11", AllDay: true},
+		})
+		require.NoError(t, err)
+		_, err = service.ScheduleEvent("user1", types.EventInput{
+			Text:      "Встреча",
+			EventTime: types.EventTime{Start: "2024-01-10T10:00", Duration: "1h"},
+		})
+		require.NoError(t, err)
+		_, err = service.ScheduleEvent("user1", types.EventInput{
+			Text:      "Планерка",
+			EventTime: types.EventTime{Start: "2024-01-08T09:00", Duration: "30m"},
+			RRule:     "FREQ=DAILY;COUNT=5",
+		})
+		require.NoError(t, err)
+
+		events, err := service.GetEventsInRange("user1", "2024-01-10", "2024-01-11")
+		require.NoError(t, err)
+		require.Len(t, events, 3)
+		assert.Equal(t, "Конференция", events[0].Text)
+		assert.Equal(t, "Планерка", events[1].Text)
+		assert.Equal(t, "Встреча", events[2].Text)
+
+		events, err = service.GetEventsInRange("user1", "2024-01-10T09:45", "2024-01-10T12:00")
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, "Конференция", events[0].Text)
+		assert.Equal(t, "Встреча", events[1].Text)
+
+		events, err = service.GetEventsInRange("user1", "2024-01-10T06:00:00Z", "2024-01-10T06:30:00Z")
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, "Планерка", events[1].Text)
+
+		_, err = service.GetEventsInRange("user1", "2024-01-11", "2024-01-10")
+		assert.Error(t, err)
+		_, err = service.GetEventsInRange("user1", "2024-01-01", "2025-06-01")
+		assert.Error(t, err)
+		_, err = service.GetEventsInRange("user1", "завтра", "2024-01-10")
+		assert.Error(t, err)
+		_, err = service.GetEventsInRange("", "2024-01-10", "2024-01-11")
+		assert.Error(t, err)
+	})
+}
+
+const (
+	benchEvents = 1_000_000
+	benchUsers  = 100
+)
+
+var (
+	benchStoreOnce sync.Once
+	benchStore     *MemoryStore
+)
+
+// loadBenchStore заполняет хранилище в памяти миллионом часовых событий: по 10 000 на пользователя,
+// одно событие каждые 2 часа примерно на два года
+func loadBenchStore(b *testing.B) *MemoryStore {
+	benchStoreOnce.Do(func() {
+		benchStore = NewMemoryStore()
+		base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < benchEvents/benchUsers; i++ {
+			start := base.Add(time.Duration(i) * 2 * time.Hour)
+			for u := 0; u < benchUsers; u++ {
+				benchStore.Save(&types.Event{
+					ID:     fmt.Sprintf("user%d_%d", u, i),
+					UserID: fmt.Sprintf("user%d", u),
+					Date:   start,
+					End:    start.Add(time.Hour),
+					Text:   "Событие",
+				})
+			}
+		}
+	})
+	b.ResetTimer()
+	return benchStore
+}
+
+func BenchmarkMemoryStore_ListByUserInRange(b *testing.B) {
+	store := loadBenchStore(b)
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < b.N; i++ {
+		events, err := store.ListByUserInRange("user42", from, from.AddDate(0, 0, 1))
+		if err != nil || len(events) != 12 {
+			b.Fatalf("ожидалось 12 событий, получено %d: %v", len(events), err)
+		}
+	}
+}
+
+func BenchmarkService_GetEventsForDay(b *testing.B) {
+	service := NewService(loadBenchStore(b))
+
+	for i := 0; i < b.N; i++ {
+		if _, err := service.GetEventsForDay("user42", "2025-03-01"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkService_GetEventsForMonth(b *testing.B) {
+	service := NewService(loadBenchStore(b))
+
+	for i := 0; i < b.N; i++ {
+		if _, err := service.GetEventsForMonth("user42", "2025-03-01"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkMemoryStore_ListByUser показывает стоимость полного просмотра событий пользователя для сравнения
+func BenchmarkMemoryStore_ListByUser(b *testing.B) {
+	store := loadBenchStore(b)
+
+	for i := 0; i < b.N; i++ {
+		if _, err := store.ListByUser("user42"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMemoryStore_Save(b *testing.B) {
+	store := loadBenchStore(b)
+	date := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < b.N; i++ {
+		// Перенос существующего события внутри индекса пользователя
+		start := date.Add(time.Duration(i%1000) * time.Hour)
+		if err := store.Save(&types.Event{ID: "user42_0", UserID: "user42", Date: start, End: start.Add(time.Hour)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package calendar
+
+import (
+	"errors"
+
+	"calendar/internal/types"
+)
+
+// RespondToInvitation записывает ответ участника userID на приглашение: accepted, declined или tentative.
+// id - приглашение в календаре участника или исходное событие. Ответ на серию относится и к ее измененным
+// повторениям. Возвращает приглашение с новым ответом
+func (s *Service) RespondToInvitation(id, userID, status string) (*types.Event, error) {
+	if id == "" {
+		return nil, errors.New("id события не может быть пустым")
+	}
+	if userID == "" {
+		return nil, errors.New("user_id не может быть пустым")
+	}
+	switch status {
+	case types.RSVPAccepted, types.RSVPDeclined, types.RSVPTentative:
+	default:
+		return nil, errors.New("ответ должен быть accepted, declined или tentative")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	event, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if event.Organizer != "" {
+		if event, err = s.store.Get(event.InvitationOf); err != nil {
+			return nil, err
+		}
+	}
+
+	if !setAttendeeStatus(event, userID, status) {
+		return nil, permissionDenied("пользователь не приглашен на это событие")
+	}
+	if err := s.saveEvent(event); err != nil {
+		return nil, err
+	}
+
+	if event.RRule != "" {
+		events, err := s.store.ListByUser(event.UserID)
+		if err != nil {
+			return nil, err
+		}
+		for _, override := range events {
+			if override.SeriesID != event.ID || !setAttendeeStatus(override, userID, status) {
+				continue
+			}
+			if err := s.saveEvent(override); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return s.store.Get(invitationID(event.ID, userID))
+}
+
+// Invitations возвращает приглашения пользователя, упорядоченные по началу. Непустой status оставляет
+// приглашения с этим ответом, например needs_action - еще не отвеченные
+func (s *Service) Invitations(userID, status string) ([]*types.Event, error) {
+	if userID == "" {
+		return nil, errors.New("user_id не может быть пустым")
+	}
+	switch status {
+	case "", types.RSVPNeedsAction, types.RSVPAccepted, types.RSVPDeclined, types.RSVPTentative:
+	default:
+		return nil, errors.New("ответ должен быть needs_action, accepted, declined или tentative")
+	}
+
+	events, err := s.store.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := []*types.Event{}
+	for _, event := range events {
+		if event.Organizer == "" {
+			continue
+		}
+		if status != "" && attendeeStatus(event, userID) != status {
+			continue
+		}
+		invitations = append(invitations, event)
+	}
+	return invitations, nil
+}
+
+// syncInvitations приводит приглашения участников в соответствие с сохраненным событием event:
+// обновляет копии у приглашенных и удаляет у тех, кто был в previous, но исключен из списка
+func (s *Service) syncInvitations(previous, event *types.Event) error {
+	if event.Organizer != "" {
+		return nil
+	}
+
+	invited := make(map[string]bool, len(event.Attendees))
+	for _, attendee := range event.Attendees {
+		invited[attendee.UserID] = true
+		invitation := invitationFor(event, attendee.UserID)
+		if err := s.store.Save(invitation); err != nil {
+			return err
+		}
+		s.search.add(invitation)
+	}
+
+	if previous == nil {
+		return nil
+	}
+	for _, attendee := range previous.Attendees {
+		if invited[attendee.UserID] {
+			continue
+		}
+		if err := s.deleteInvitation(event.ID, attendee.UserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteInvitations удаляет приглашения всех участников события
+func (s *Service) deleteInvitations(event *types.Event) error {
+	for _, attendee := range event.Attendees {
+		if err := s.deleteInvitation(event.ID, attendee.UserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) deleteInvitation(eventID, userID string) error {
+	id := invitationID(eventID, userID)
+	if err := s.store.Delete(id); err != nil && !errors.Is(err, ErrEventNotFound) {
+		return err
+	}
+	s.search.remove(id)
+	return nil
+}
+
+// authorizeChange проверяет, что пользователь может изменить или удалить событие: у него есть доступ
+// на запись к календарю, а событие - не приглашение, которое меняет только организатор
+func (s *Service) authorizeChange(userID string, event *types.Event, message string) error {
+	if err := s.authorize(userID, event.UserID, types.AccessWrite, message); err != nil {
+		return err
+	}
+	if event.Organizer != "" {
+		return permissionDenied("приглашение изменяет только организатор, участник может только ответить на него")
+	}
+	return nil
+}
+
+// invitationFor возвращает приглашение участника userID: копию события в его календаре.
+// Приглашение на измененное повторение ссылается на приглашение на серию
+func invitationFor(event *types.Event, userID string) *types.Event {
+	invitation := cloneEvent(event)
+	invitation.ID = invitationID(event.ID, userID)
+	invitation.UserID = userID
+	invitation.Owner = ""
+	invitation.Organizer = event.UserID
+	invitation.InvitationOf = event.ID
+	invitation.UID = eventUID(event)
+	// Напоминания организатора участнику не отправляются
+	invitation.Reminders = nil
+	if event.SeriesID != "" {
+		invitation.SeriesID = invitationID(event.SeriesID, userID)
+	}
+	return invitation
+}
+
+func invitationID(eventID, userID string) string {
+	return eventID + "@" + userID
+}
+
+// setAttendeeStatus записывает ответ участника и сообщает, что он приглашен на событие
+func setAttendeeStatus(event *types.Event, userID, status string) bool {
+	for i := range event.Attendees {
+		if event.Attendees[i].UserID == userID {
+			event.Attendees[i].Status = status
+			return true
+		}
+	}
+	return false
+}
+
+func attendeeStatus(event *types.Event, userID string) string {
+	for _, attendee := range event.Attendees {
+		if attendee.UserID == userID {
+			return attendee.Status
+		}
+	}
+	return ""
+}
+
+// isDeclined сообщает, что событие - приглашение, от которого владелец календаря отказался.
+// Такое событие не занимает его время
+func isDeclined(event *types.Event) bool {
+	return event.Organizer != "" && attendeeStatus(event, event.UserID) == types.RSVPDeclined
+}
@@ -0,0 +1,196 @@
+package calendar
+
+import (
+	"bytes"
+	"testing"
+
+	"calendar/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_EventDetails(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		event, err := service.ScheduleEvent("user1", types.EventInput{
+			Text:      "Встреча",
+			EventTime: types.EventTime{Date: "2024-01-10"},
+			EventDetails: types.EventDetails{
+				Title:       " Планирование ",
+				Description: "Обсудить квартал",
+				Location:    "Переговорная",
+				Categories:  []string{"#Работа", "работа", "Важное"},
+				Color:       "#FF8800",
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Планирование", event.Title)
+		assert.Equal(t, []string{"работа", "важное"}, event.Categories)
+		assert.Equal(t, "#ff8800", event.Color)
+
+		stored, err := service.GetEvent(event.ID, "user1")
+		require.NoError(t, err)
+		assert.Equal(t, "Переговорная", stored.Location)
+		assert.Equal(t, []string{"работа", "важное"}, stored.Categories)
+
+		_, err = service.ScheduleEvent("user1", types.EventInput{
+			Text:         "Встреча",
+			EventTime:    types.EventTime{Date: "2024-01-10"},
+			EventDetails: types.EventDetails{Color: "red"},
+		})
+		assert.Error(t, err)
+		_, err = service.ScheduleEvent("user1", types.EventInput{
+			Text:         "Встреча",
+			EventTime:    types.EventTime{Date: "2024-01-10"},
+			EventDetails: types.EventDetails{Categories: []string{" # "}},
+		})
+		assert.Error(t, err)
+
+		// Категории участвуют в поиске как теги, описание и место - как слова
+		results, err := service.SearchEvents("user1", "переговорная", "", "", []string{"важное"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Встреча"}, resultTexts(results))
+
+		_, err = service.CreateEvent("user1", "2024-01-10", "Обед")
+		require.NoError(t, err)
+		events, err := service.GetEventsForDay("user1", "2024-01-10")
+		require.NoError(t, err)
+		assert.Len(t, FilterByCategories(events, nil), 2)
+		filtered := FilterByCategories(events, []string{"#РАБОТА", "дом"})
+		require.Len(t, filtered, 1)
+		assert.Equal(t, event.ID, filtered[0].ID)
+
+		// Пустой список категорий удаляет их, незаданные поля остаются прежними
+		patched, err := service.PatchEvent(event.ID, "user1", "", types.EventPatch{Categories: []string{}})
+		require.NoError(t, err)
+		assert.Empty(t, patched.Categories)
+		assert.Equal(t, "#ff8800", patched.Color)
+		assert.Equal(t, "Планирование", patched.Title)
+
+		var buf bytes.Buffer
+		require.NoError(t, service.ExportICS(&buf, "user1"))
+		assert.Contains(t, buf.String(), "LOCATION:Переговорная")
+		assert.Contains(t, buf.String(), "DESCRIPTION:Обсудить квартал")
+	})
+}
+
+func TestService_Invitations(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		event, err := service.ScheduleEvent("alice", types.EventInput{
+			Text:         "Ретро",
+			EventTime:    types.EventTime{Start: "2024-01-10T10:00:00Z", Duration: "1h"},
+			Reminders:    []int{15},
+			EventDetails: types.EventDetails{Attendees: []string{"bob", "carol", "bob"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []types.Attendee{
+			{UserID: "bob", Status: types.RSVPNeedsAction},
+			{UserID: "carol", Status: types.RSVPNeedsAction},
+		}, event.Attendees)
+
+		_, err = service.ScheduleEvent("alice", types.EventInput{
+			Text:         "Ретро",
+			EventTime:    types.EventTime{Date: "2024-01-10"},
+			EventDetails: types.EventDetails{Attendees: []string{"alice"}},
+		})
+		assert.Error(t, err)
+
+		// Приглашение появляется в календаре участника
+		invitations, err := service.Invitations("bob", types.RSVPNeedsAction)
+		require.NoError(t, err)
+		require.Len(t, invitations, 1)
+		invitation := invitations[0]
+		assert.Equal(t, "alice", invitation.Organizer)
+		assert.Equal(t, event.ID, invitation.InvitationOf)
+		assert.Empty(t, invitation.Reminders)
+		events, err := service.GetEventsForDay("bob", "2024-01-10")
+		require.NoError(t, err)
+		assert.Len(t, events, 1)
+
+		// Участник не может изменить приглашение, только ответить на него
+		_, err = service.UpdateEvent(invitation.ID, "bob", "2024-01-11", "Другое")
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+		assert.ErrorIs(t, service.DeleteEvent(invitation.ID, "bob"), ErrPermissionDenied)
+		_, err = service.RespondToInvitation(event.ID, "dave", types.RSVPAccepted)
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+		_, err = service.RespondToInvitation(invitation.ID, "bob", types.RSVPNeedsAction)
+		assert.Error(t, err)
+
+		responded, err := service.RespondToInvitation(invitation.ID, "bob", types.RSVPDeclined)
+		require.NoError(t, err)
+		assert.Equal(t, invitation.ID, responded.ID)
+		_, err = service.RespondToInvitation(event.ID, "carol", types.RSVPTentative)
+		require.NoError(t, err)
+
+		// Организатор видит ответы участников
+		organized, err := service.GetEvent(event.ID, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, []types.Attendee{
+			{UserID: "bob", Status: types.RSVPDeclined},
+			{UserID: "carol", Status: types.RSVPTentative},
+		}, organized.Attendees)
+
+		// Отклоненное приглашение не занимает время участника
+		freeBusy, err := service.FreeBusy("bob", []string{"bob", "carol"}, "2024-01-10", "2024-01-11", 0)
+		require.NoError(t, err)
+		assert.Empty(t, freeBusy.Busy["bob"])
+		assert.Len(t, freeBusy.Busy["carol"], 1)
+
+		// Изменения организатора попадают в приглашения, ответы сохраняются, исключенный участник теряет приглашение
+		location := "Кухня"
+		_, err = service.PatchEvent(event.ID, "alice", "", types.EventPatch{Location: &location, Attendees: []string{"carol", "dave"}})
+		require.NoError(t, err)
+		carol, err := service.Invitations("carol", "")
+		require.NoError(t, err)
+		require.Len(t, carol, 1)
+		assert.Equal(t, "Кухня", carol[0].Location)
+		assert.Equal(t, types.RSVPTentative, attendeeStatus(carol[0], "carol"))
+		invitations, err = service.Invitations("bob", "")
+		require.NoError(t, err)
+		assert.Empty(t, invitations)
+		_, err = service.GetEvent(invitation.ID, "bob")
+		assert.ErrorIs(t, err, ErrEventNotFound)
+
+		// Удаление события удаляет приглашения
+		require.NoError(t, service.DeleteEvent(event.ID, "alice"))
+		invitations, err = service.Invitations("dave", "")
+		require.NoError(t, err)
+		assert.Empty(t, invitations)
+		results, err := service.SearchEvents("carol", "ретро", "", "", nil)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestService_InvitationsToSeries(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		series, err := service.ScheduleEvent("alice", types.EventInput{
+			Text:         "Планерка",
+			EventTime:    types.EventTime{Date: "2024-01-01"},
+			RRule:        "FREQ=WEEKLY;COUNT=3",
+			EventDetails: types.EventDetails{Attendees: []string{"bob"}},
+		})
+		require.NoError(t, err)
+
+		override, err := service.RescheduleOccurrence(series.ID, "alice", "2024-01-08", "Планерка перенесена", types.EventTime{Date: "2024-01-09"})
+		require.NoError(t, err)
+		assert.Equal(t, series.Attendees, override.Attendees)
+
+		events, err := service.GetEventsInRange("bob", "2024-01-01", "2024-01-31")
+		require.NoError(t, err)
+		require.Len(t, events, 3)
+		assert.Equal(t, "Планерка перенесена", events[1].Text)
+
+		// Ответ на серию относится и к измененному повторению
+		_, err = service.RespondToInvitation(invitationID(series.ID, "bob"), "bob", types.RSVPAccepted)
+		require.NoError(t, err)
+		override, err = service.GetEvent(override.ID, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, types.RSVPAccepted, attendeeStatus(override, "bob"))
+
+		require.NoError(t, service.DeleteEvent(series.ID, "alice"))
+		events, err = service.GetEventsInRange("bob", "2024-01-01", "2024-01-31")
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+}
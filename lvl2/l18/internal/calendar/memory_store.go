@@ -0,0 +1,314 @@
+package calendar
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"calendar/internal/types"
+)
+
+// MemoryStore хранит события в памяти процесса, данные теряются при перезапуске
+type MemoryStore struct {
+	events map[string]*types.Event
+	// users - упорядоченные по времени индексы событий пользователей
+	users    map[string]*userIndex
+	settings map[string]types.UserSettings
+	// grants хранит доступы к календарям по владельцу и получателю
+	grants map[grantKey]types.Grant
+	// sent хранит время срабатывания отправленных напоминаний по их ключам
+	sent  map[string]time.Time
+	mutex sync.RWMutex
+}
+
+// NewMemoryStore создает пустое хранилище в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		events:   make(map[string]*types.Event),
+		users:    make(map[string]*userIndex),
+		settings: make(map[string]types.UserSettings),
+		grants:   make(map[grantKey]types.Grant),
+		sent:     make(map[string]time.Time),
+	}
+}
+
+// Save создает или заменяет событие
+func (s *MemoryStore) Save(event *types.Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if existing, exists := s.events[event.ID]; exists {
+		s.unindex(existing)
+	}
+
+	stored := cloneEvent(event)
+	s.events[event.ID] = stored
+
+	index := s.users[stored.UserID]
+	if index == nil {
+		index = newUserIndex()
+		s.users[stored.UserID] = index
+	}
+	index.insert(stored)
+	return nil
+}
+
+// Get возвращает копию события по id
+func (s *MemoryStore) Get(id string) (*types.Event, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	event, exists := s.events[id]
+	if !exists {
+		return nil, ErrEventNotFound
+	}
+	return cloneEvent(event), nil
+}
+
+// Delete удаляет событие по id
+func (s *MemoryStore) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	event, exists := s.events[id]
+	if !exists {
+		return ErrEventNotFound
+	}
+	s.unindex(event)
+	delete(s.events, id)
+	return nil
+}
+
+// unindex удаляет событие из индекса его пользователя
+func (s *MemoryStore) unindex(event *types.Event) {
+	index := s.users[event.UserID]
+	if index == nil {
+		return
+	}
+	index.remove(event)
+	if index.empty() {
+		delete(s.users, event.UserID)
+	}
+}
+
+// ListByUser возвращает копии событий пользователя, упорядоченные по дате
+func (s *MemoryStore) ListByUser(userID string) ([]*types.Event, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	index := s.users[userID]
+	if index == nil {
+		return nil, nil
+	}
+	return cloneEvents(index.all()), nil
+}
+
+// ListByUserInRange возвращает копии событий пользователя, которые могут попасть в интервал, упорядоченные по дате
+func (s *MemoryStore) ListByUserInRange(userID string, from, to time.Time) ([]*types.Event, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	index := s.users[userID]
+	if index == nil {
+		return nil, nil
+	}
+	return cloneEvents(index.between(from, to)), nil
+}
+
+// SaveSettings создает или заменяет настройки пользователя
+func (s *MemoryStore) SaveSettings(settings *types.UserSettings) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.settings[settings.UserID] = *settings
+	return nil
+}
+
+// GetSettings возвращает копию настроек пользователя
+func (s *MemoryStore) GetSettings(userID string) (*types.UserSettings, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	settings, exists := s.settings[userID]
+	if !exists {
+		return nil, ErrSettingsNotFound
+	}
+	return &settings, nil
+}
+
+// grantKey определяет доступ к календарю
+type grantKey struct {
+	ownerID   string
+	granteeID string
+}
+
+// SaveGrant создает или заменяет доступ к календарю
+func (s *MemoryStore) SaveGrant(grant *types.Grant) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.grants[grantKey{grant.OwnerID, grant.GranteeID}] = *grant
+	return nil
+}
+
+// GetGrant возвращает копию доступа к календарю
+func (s *MemoryStore) GetGrant(ownerID, granteeID string) (*types.Grant, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	grant, exists := s.grants[grantKey{ownerID, granteeID}]
+	if !exists {
+		return nil, ErrGrantNotFound
+	}
+	return &grant, nil
+}
+
+// DeleteGrant отзывает доступ к календарю
+func (s *MemoryStore) DeleteGrant(ownerID, granteeID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := grantKey{ownerID, granteeID}
+	if _, exists := s.grants[key]; !exists {
+		return ErrGrantNotFound
+	}
+	delete(s.grants, key)
+	return nil
+}
+
+// ListGrants возвращает копии доступов, выданных пользователем и выданных ему
+func (s *MemoryStore) ListGrants(userID string) ([]*types.Grant, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var grants []*types.Grant
+	for _, grant := range s.grants {
+		if grant.OwnerID == userID || grant.GranteeID == userID {
+			grant := grant
+			grants = append(grants, &grant)
+		}
+	}
+
+	sortGrants(grants)
+	return grants, nil
+}
+
+// ListWithReminders возвращает копии событий с напоминаниями, упорядоченные по дате
+func (s *MemoryStore) ListWithReminders() ([]*types.Event, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var events []*types.Event
+	for _, event := range s.events {
+		if len(event.Reminders) > 0 {
+			events = append(events, cloneEvent(event))
+		}
+	}
+
+	sortEvents(events)
+	return events, nil
+}
+
+// MarkReminderSent отмечает напоминание отправленным
+func (s *MemoryStore) MarkReminderSent(key string, remindAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sent[key] = remindAt
+	return nil
+}
+
+// ReminderSent сообщает, что напоминание уже отправлено
+func (s *MemoryStore) ReminderSent(key string) (bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	_, sent := s.sent[key]
+	return sent, nil
+}
+
+// PruneSentReminders удаляет отметки о напоминаниях, сработавших раньше before
+func (s *MemoryStore) PruneSentReminders(before time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for key, remindAt := range s.sent {
+		if remindAt.Before(before) {
+			delete(s.sent, key)
+		}
+	}
+	return nil
+}
+
+// Close ничего не делает: хранилищу в памяти нечего сбрасывать
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// cloneEvents копирует события и упорядочивает копии по дате
+func cloneEvents(events []*types.Event) []*types.Event {
+	clones := make([]*types.Event, 0, len(events))
+	for _, event := range events {
+		clones = append(clones, cloneEvent(event))
+	}
+
+	sortEvents(clones)
+	return clones
+}
+
+// all возвращает копии всех событий, используется для снимка файлового хранилища
+func (s *MemoryStore) all() []*types.Event {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	events := make([]*types.Event, 0, len(s.events))
+	for _, event := range s.events {
+		events = append(events, cloneEvent(event))
+	}
+
+	sortEvents(events)
+	return events
+}
+
+// allSettings возвращает настройки всех пользователей, используется для снимка файлового хранилища
+func (s *MemoryStore) allSettings() []types.UserSettings {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	settings := make([]types.UserSettings, 0, len(s.settings))
+	for _, userSettings := range s.settings {
+		settings = append(settings, userSettings)
+	}
+
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].UserID < settings[j].UserID
+	})
+	return settings
+}
+
+// allGrants возвращает все доступы к календарям, используется для снимка файлового хранилища
+func (s *MemoryStore) allGrants() []*types.Grant {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	grants := make([]*types.Grant, 0, len(s.grants))
+	for _, grant := range s.grants {
+		grant := grant
+		grants = append(grants, &grant)
+	}
+
+	sortGrants(grants)
+	return grants
+}
+
+// allSent возвращает отметки об отправленных напоминаниях, используется для снимка файлового хранилища
+func (s *MemoryStore) allSent() map[string]time.Time {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	sent := make(map[string]time.Time, len(s.sent))
+	for key, remindAt := range s.sent {
+		sent[key] = remindAt
+	}
+	return sent
+}
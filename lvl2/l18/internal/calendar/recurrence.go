@@ -0,0 +1,250 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"calendar/internal/rrule"
+	"calendar/internal/types"
+)
+
+// UpdateRecurrence заменяет правило повторения и исключенные даты серии.
+// Пустое правило превращает серию в одиночное событие на дату начала серии
+func (s *Service) UpdateRecurrence(id, userID, rule string, exdates []string) (*types.Event, error) {
+	if id == "" {
+		return nil, errors.New("id события не может быть пустым")
+	}
+	if userID == "" {
+		return nil, errors.New("user_id не может быть пустым")
+	}
+
+	rule, excluded, err := parseRecurrence(rule, exdates)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.modifyEvent(id, userID, "", func(event *types.Event) error {
+		if event.SeriesID != "" {
+			return errors.New("правило повторения задается для всей серии, а не для отдельного повторения")
+		}
+		event.RRule = rule
+		event.ExDates = excluded
+		return nil
+	})
+}
+
+// UpdateOccurrence переносит одно повторение серии на другую дату, сохраняя время начала и длительность
+func (s *Service) UpdateOccurrence(id, userID, occurrenceDate, dateStr, text string) (*types.Event, error) {
+	return s.RescheduleOccurrence(id, userID, occurrenceDate, text, types.EventTime{Date: dateStr})
+}
+
+// RescheduleOccurrence изменяет одно повторение серии: дата исключается из серии,
+// а измененное повторение сохраняется отдельным событием со ссылкой на серию
+func (s *Service) RescheduleOccurrence(id, userID, occurrenceDate, text string, when types.EventTime) (*types.Event, error) {
+	if id == "" {
+		return nil, errors.New("id события не может быть пустым")
+	}
+	if userID == "" {
+		return nil, errors.New("user_id не может быть пустым")
+	}
+	if text == "" {
+		return nil, errors.New("текст события не может быть пустым")
+	}
+
+	timeZone, err := s.userTimeZone(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	series, occurrence, err := s.findOccurrence(id, userID, occurrenceDate, "нет прав на обновление этого события")
+	if err != nil {
+		return nil, err
+	}
+
+	// Измененное повторение начинается как копия исходного и получает новое время из запроса
+	override := &types.Event{
+		UserID:       series.UserID,
+		Date:         occurrence,
+		End:          occurrence.Add(series.End.Sub(series.Date)),
+		TimeZone:     series.TimeZone,
+		AllDay:       series.AllDay,
+		Text:         text,
+		Reminders:    series.Reminders,
+		SeriesID:     series.ID,
+		RecurrenceID: &occurrence,
+		Title:        series.Title,
+		Description:  series.Description,
+		Location:     series.Location,
+		Categories:   append([]string(nil), series.Categories...),
+		Color:        series.Color,
+		Attendees:    append([]types.Attendee(nil), series.Attendees...),
+	}
+	if err := applyTime(override, when, timeZone); err != nil {
+		return nil, err
+	}
+	override.ID = newEventID(series.UserID, override.Date.Format("2006-01-02"))
+
+	if err := s.checkConflicts(override); err != nil {
+		return nil, err
+	}
+	if err := s.saveEvent(override); err != nil {
+		return nil, err
+	}
+
+	series.ExDates = append(series.ExDates, occurrence)
+	if err := s.saveEvent(series); err != nil {
+		// Без исключения в серии повторение задвоится, поэтому откатываем созданное событие
+		s.deleteEvent(override)
+		return nil, err
+	}
+
+	return override, nil
+}
+
+// DeleteOccurrence удаляет одно повторение серии, добавляя его дату в исключения
+func (s *Service) DeleteOccurrence(id, userID, occurrenceDate string) error {
+	if id == "" {
+		return errors.New("id события не может быть пустым")
+	}
+	if userID == "" {
+		return errors.New("user_id не может быть пустым")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	series, occurrence, err := s.findOccurrence(id, userID, occurrenceDate, "нет прав на удаление этого события")
+	if err != nil {
+		return err
+	}
+
+	series.ExDates = append(series.ExDates, occurrence)
+	return s.saveEvent(series)
+}
+
+// findOccurrence загружает серию и проверяет, что на occurrenceDate приходится ее неисключенное повторение
+func (s *Service) findOccurrence(id, userID, occurrenceDate, forbidden string) (*types.Event, time.Time, error) {
+	day, err := time.Parse("2006-01-02", occurrenceDate)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("некорректный формат даты повторения: %v", err)
+	}
+
+	series, err := s.store.Get(id)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if err := s.authorizeChange(userID, series, forbidden); err != nil {
+		return nil, time.Time{}, err
+	}
+	if series.RRule == "" {
+		return nil, time.Time{}, errors.New("событие не является повторяющимся")
+	}
+	if err := localize(series); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	rule, err := rrule.Parse(series.RRule)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	// Повторение наследует время начала серии
+	hh, mm, ss := series.Date.Clock()
+	occurrence := time.Date(day.Year(), day.Month(), day.Day(), hh, mm, ss, series.Date.Nanosecond(), series.Date.Location())
+	if !rule.Includes(series.Date, occurrence) || isExcluded(series, occurrence) {
+		return nil, time.Time{}, errors.New("повторение не найдено")
+	}
+
+	return series, occurrence, nil
+}
+
+// deleteOverrides удаляет измененные повторения серии
+func (s *Service) deleteOverrides(series *types.Event) error {
+	events, err := s.store.ListByUser(series.UserID)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		if event.SeriesID != series.ID {
+			continue
+		}
+		if err := s.deleteEvent(event); err != nil && !errors.Is(err, ErrEventNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandSeries возвращает повторения серии, пересекающиеся с интервалом [from, to), без исключенных дат.
+// Повторения считаются в часовом поясе серии, поэтому сохраняют местное время при переходе на летнее время
+func expandSeries(series *types.Event, from, to time.Time) ([]*types.Event, error) {
+	rule, err := rrule.Parse(series.RRule)
+	if err != nil {
+		return nil, fmt.Errorf("некорректное правило повторения события %s: %v", series.ID, err)
+	}
+	if err := localize(series); err != nil {
+		return nil, err
+	}
+
+	from, to = eventWindow(series, from, to)
+	duration := series.End.Sub(series.Date)
+
+	var occurrences []*types.Event
+	// Повторение, начавшееся до from, еще идет в интервале, если длится дольше разницы
+	for _, date := range rule.Between(series.Date, from.Add(-duration), to) {
+		end := date.Add(duration)
+		if isExcluded(series, date) || !overlaps(date, end, from, to) {
+			continue
+		}
+
+		occurrence := cloneEvent(series)
+		occurrence.Date = date
+		occurrence.End = end
+		occurrence.ExDates = nil
+		occurrence.SeriesID = series.ID
+		occurrence.RecurrenceID = &date
+		occurrences = append(occurrences, occurrence)
+	}
+	return occurrences, nil
+}
+
+func isExcluded(series *types.Event, occurrence time.Time) bool {
+	for _, excluded := range series.ExDates {
+		if isSameDay(excluded, occurrence) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRecurrence проверяет правило повторения и исключенные даты и приводит правило к каноническому виду
+func parseRecurrence(rule string, exdates []string) (string, []time.Time, error) {
+	if rule == "" {
+		if len(exdates) > 0 {
+			return "", nil, errors.New("исключенные даты допустимы только для повторяющихся событий")
+		}
+		return "", nil, nil
+	}
+
+	parsed, err := rrule.Parse(rule)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var excluded []time.Time
+	for _, exdate := range exdates {
+		date, err := time.Parse("2006-01-02", exdate)
+		if err != nil {
+			return "", nil, fmt.Errorf("некорректный формат исключенной даты: %v", err)
+		}
+		excluded = append(excluded, date)
+	}
+
+	return parsed.String(), excluded, nil
+}
@@ -0,0 +1,143 @@
+package calendar
+
+import (
+	"testing"
+
+	"calendar/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func eventDates(events []*types.Event) []string {
+	dates := make([]string, 0, len(events))
+	for _, event := range events {
+		dates = append(dates, event.Date.Format("2006-01-02"))
+	}
+	return dates
+}
+
+func TestService_CreateRecurringEvent(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		series, err := service.CreateRecurringEvent("user1", "2023-12-04", "Планерка", "RRULE:FREQ=WEEKLY;BYDAY=MO,TH", []string{"2023-12-14"})
+		require.NoError(t, err)
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,TH", series.RRule)
+
+		events, err := service.GetEventsForMonth("user1", "2023-12-01")
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"2023-12-04", "2023-12-07", "2023-12-11", "2023-12-18",
+			"2023-12-21", "2023-12-25", "2023-12-28",
+		}, eventDates(events))
+		for _, event := range events {
+			assert.Equal(t, series.ID, event.SeriesID)
+			require.NotNil(t, event.RecurrenceID)
+			assert.Equal(t, event.Date, *event.RecurrenceID)
+		}
+
+		events, err = service.GetEventsForWeek("user1", "2024-01-03")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2024-01-01", "2024-01-04"}, eventDates(events))
+
+		events, err = service.GetEventsForDay("user1", "2023-12-05")
+		require.NoError(t, err)
+		assert.Empty(t, events)
+
+		_, err = service.CreateRecurringEvent("user1", "2023-12-04", "Планерка", "FREQ=HOURLY", nil)
+		assert.Error(t, err)
+
+		_, err = service.CreateRecurringEvent("user1", "2023-12-04", "Планерка", "", []string{"2023-12-14"})
+		assert.Error(t, err)
+	})
+}
+
+func TestService_UpdateOccurrence(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		series, err := service.CreateRecurringEvent("user1", "2023-12-04", "Планерка", "FREQ=DAILY;COUNT=5", nil)
+		require.NoError(t, err)
+
+		override, err := service.UpdateOccurrence(series.ID, "user1", "2023-12-06", "2023-12-09", "Перенесенная планерка")
+		require.NoError(t, err)
+		assert.Equal(t, series.ID, override.SeriesID)
+		assert.NotEqual(t, series.ID, override.ID)
+
+		events, err := service.GetEventsForMonth("user1", "2023-12-01")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2023-12-04", "2023-12-05", "2023-12-07", "2023-12-08", "2023-12-09"}, eventDates(events))
+		assert.Equal(t, "Перенесенная планерка", events[4].Text)
+
+		_, err = service.UpdateOccurrence(series.ID, "user1", "2023-12-06", "2023-12-10", "Еще раз")
+		assert.ErrorContains(t, err, "повторение не найдено")
+
+		_, err = service.UpdateOccurrence(series.ID, "user1", "2023-12-20", "2023-12-10", "После COUNT")
+		assert.ErrorContains(t, err, "повторение не найдено")
+
+		_, err = service.UpdateOccurrence(series.ID, "user2", "2023-12-07", "2023-12-10", "Чужая")
+		assert.ErrorContains(t, err, "нет прав на обновление этого события")
+
+		_, err = service.UpdateRecurrence(override.ID, "user1", "FREQ=DAILY", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestService_DeleteOccurrence(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		series, err := service.CreateRecurringEvent("user1", "2023-12-04", "Планерка", "FREQ=DAILY;COUNT=3", nil)
+		require.NoError(t, err)
+
+		require.NoError(t, service.DeleteOccurrence(series.ID, "user1", "2023-12-05"))
+		assert.ErrorContains(t, service.DeleteOccurrence(series.ID, "user1", "2023-12-05"), "повторение не найдено")
+
+		events, err := service.GetEventsForWeek("user1", "2023-12-04")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2023-12-04", "2023-12-06"}, eventDates(events))
+
+		single, err := service.CreateEvent("user1", "2023-12-04", "Разовая встреча")
+		require.NoError(t, err)
+		assert.ErrorContains(t, service.DeleteOccurrence(single.ID, "user1", "2023-12-04"), "событие не является повторяющимся")
+	})
+}
+
+func TestService_DeleteSeries(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		series, err := service.CreateRecurringEvent("user1", "2023-12-04", "Планерка", "FREQ=DAILY;COUNT=3", nil)
+		require.NoError(t, err)
+		_, err = service.UpdateOccurrence(series.ID, "user1", "2023-12-05", "2023-12-08", "Перенесенная планерка")
+		require.NoError(t, err)
+
+		require.NoError(t, service.DeleteEvent(series.ID, "user1"))
+
+		events, err := service.GetEventsForMonth("user1", "2023-12-01")
+		require.NoError(t, err)
+		assert.Empty(t, events, "вместе с серией удаляются ее измененные повторения")
+	})
+}
+
+func TestService_UpdateRecurrence(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		series, err := service.CreateRecurringEvent("user1", "2023-12-04", "Планерка", "FREQ=DAILY", nil)
+		require.NoError(t, err)
+
+		_, err = service.UpdateRecurrence(series.ID, "user1", "FREQ=WEEKLY;INTERVAL=2", []string{"2023-12-18"})
+		require.NoError(t, err)
+
+		events, err := service.GetEventsForMonth("user1", "2023-12-01")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2023-12-04"}, eventDates(events))
+
+		// Изменение даты серии переносит все повторения
+		_, err = service.UpdateEvent(series.ID, "user1", "2023-12-05", "Планерка")
+		require.NoError(t, err)
+		events, err = service.GetEventsForMonth("user1", "2023-12-01")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2023-12-05", "2023-12-19"}, eventDates(events))
+
+		updated, err := service.UpdateRecurrence(series.ID, "user1", "", nil)
+		require.NoError(t, err)
+		assert.Empty(t, updated.RRule)
+		events, err = service.GetEventsForMonth("user1", "2023-12-01")
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Empty(t, events[0].SeriesID)
+	})
+}
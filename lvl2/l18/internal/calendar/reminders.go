@@ -0,0 +1,205 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"calendar/internal/config"
+	"calendar/internal/notifier"
+	"calendar/internal/types"
+)
+
+// maxReminderMinutes ограничивает напоминание четырьмя неделями до начала события
+const maxReminderMinutes = 4 * 7 * 24 * 60
+
+// SetReminders заменяет напоминания события или всей серии. Пустой список удаляет напоминания
+func (s *Service) SetReminders(id, userID string, reminders []int) (*types.Event, error) {
+	if id == "" {
+		return nil, errors.New("id события не может быть пустым")
+	}
+	if userID == "" {
+		return nil, errors.New("user_id не может быть пустым")
+	}
+
+	parsed, err := parseReminders(reminders)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.modifyEvent(id, userID, "", func(event *types.Event) error {
+		event.Reminders = parsed
+		return nil
+	})
+}
+
+// StartReminders запускает фоновую отправку напоминаний через n. Раз в cfg.Interval планировщик
+// отправляет наступившие напоминания, опоздавшие не больше чем на cfg.Lookback. Отправленные напоминания
+// отмечаются в хранилище, поэтому после перезапуска не повторяются, а неотправленные из-за ошибки
+// повторяются на следующих проверках. Возвращаемый канал закрывается после остановки по отмене ctx
+func (s *Service) StartReminders(ctx context.Context, n notifier.Notifier, cfg config.RemindersConfig) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.sendDueReminders(ctx, n, time.Now(), cfg.Lookback); err != nil {
+				log.Printf("Ошибка отправки напоминаний: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return done
+}
+
+// sendDueReminders отправляет напоминания, сработавшие в интервале (now-lookback, now], и возвращает число отправленных.
+// Ошибка доставки отдельного напоминания только пишется в лог, чтобы не задерживать остальные
+func (s *Service) sendDueReminders(ctx context.Context, n notifier.Notifier, now time.Time, lookback time.Duration) (int, error) {
+	from := now.Add(-lookback)
+
+	reminders, err := s.dueReminders(from, now)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, reminder := range reminders {
+		if ctx.Err() != nil {
+			return sent, nil
+		}
+
+		key := reminderKey(reminder)
+		alreadySent, err := s.store.ReminderSent(key)
+		if err != nil {
+			return sent, err
+		}
+		if alreadySent {
+			continue
+		}
+
+		if err := n.Notify(ctx, reminder); err != nil {
+			log.Printf("Не удалось отправить напоминание о событии %s: %v", reminder.EventID, err)
+			continue
+		}
+		if err := s.store.MarkReminderSent(key, reminder.RemindAt); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+
+	// Отметки старше интервала проверки больше не понадобятся
+	return sent, s.store.PruneSentReminders(from)
+}
+
+// dueReminders возвращает напоминания со временем срабатывания в (from, to], упорядоченные по нему
+func (s *Service) dueReminders(from, to time.Time) ([]*types.Reminder, error) {
+	events, err := s.store.ListWithReminders()
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make(map[string]*time.Location)
+	var reminders []*types.Reminder
+	for _, event := range events {
+		normalizeEvent(event)
+
+		// Полночь события на весь день считается в часовом поясе его владельца
+		location := time.UTC
+		if event.AllDay {
+			if location = locations[event.UserID]; location == nil {
+				if location, err = s.userLocation(event.UserID); err != nil {
+					return nil, err
+				}
+				locations[event.UserID] = location
+			}
+		}
+
+		for _, minutes := range event.Reminders {
+			before := time.Duration(minutes) * time.Minute
+			// Запас в сутки покрывает сдвиг полуночи событий на весь день в любом часовом поясе
+			starts, err := occurrenceStarts(event, from.Add(before-24*time.Hour), to.Add(before+24*time.Hour))
+			if err != nil {
+				return nil, err
+			}
+
+			for _, start := range starts {
+				if event.AllDay {
+					start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, location)
+				}
+				remindAt := start.Add(-before)
+				if !remindAt.After(from) || remindAt.After(to) {
+					continue
+				}
+
+				reminders = append(reminders, &types.Reminder{
+					EventID:  event.ID,
+					UserID:   event.UserID,
+					Text:     event.Text,
+					Start:    start,
+					AllDay:   event.AllDay,
+					Minutes:  minutes,
+					RemindAt: remindAt,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(reminders, func(i, j int) bool {
+		return reminders[i].RemindAt.Before(reminders[j].RemindAt)
+	})
+	return reminders, nil
+}
+
+// occurrenceStarts возвращает начала события или повторений серии, пересекающихся с интервалом [from, to)
+func occurrenceStarts(event *types.Event, from, to time.Time) ([]time.Time, error) {
+	if event.RRule == "" {
+		return []time.Time{event.Date}, nil
+	}
+
+	occurrences, err := expandSeries(cloneEvent(event), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	starts := make([]time.Time, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		starts = append(starts, occurrence.Date)
+	}
+	return starts, nil
+}
+
+// reminderKey однозначно определяет напоминание: событие, начало повторения и смещение
+func reminderKey(reminder *types.Reminder) string {
+	return fmt.Sprintf("%s|%s|%d", reminder.EventID, reminder.Start.UTC().Format(time.RFC3339), reminder.Minutes)
+}
+
+// parseReminders проверяет смещения напоминаний и возвращает их без повторов по возрастанию
+func parseReminders(reminders []int) ([]int, error) {
+	seen := make(map[int]bool, len(reminders))
+	var parsed []int
+	for _, minutes := range reminders {
+		if minutes < 0 || minutes > maxReminderMinutes {
+			return nil, fmt.Errorf("напоминание должно быть от 0 до %d минут до начала события", maxReminderMinutes)
+		}
+		if !seen[minutes] {
+			seen[minutes] = true
+			parsed = append(parsed, minutes)
+		}
+	}
+
+	sort.Ints(parsed)
+	return parsed, nil
+}
@@ -0,0 +1,213 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"calendar/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNotifier запоминает доставленные напоминания и может возвращать ошибку
+type fakeNotifier struct {
+	reminders []*types.Reminder
+	err       error
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, reminder *types.Reminder) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.reminders = append(n.reminders, reminder)
+	return nil
+}
+
+func TestService_SendDueReminders(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		event, err := service.ScheduleEvent("user1", types.EventInput{
+			Text: "Встреча",
+			EventTime: types.EventTime{
+				Start:    "2024-01-10T10:00",
+				Duration: "1h",
+				TimeZone: "Europe/Moscow",
+			},
+			Reminders: []int{60, 15, 60},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int{15, 60}, event.Reminders)
+
+		n := &fakeNotifier{}
+		ctx := context.Background()
+		now := time.Date(2024, 1, 10, 6, 50, 0, 0, time.UTC)
+
+		// Напоминания за час (06:00 UTC) и за 15 минут (06:45 UTC) попадают в интервал проверки
+		sent, err := service.sendDueReminders(ctx, n, now, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 2, sent)
+		require.Len(t, n.reminders, 2)
+		assert.Equal(t, 60, n.reminders[0].Minutes)
+		assert.Equal(t, 15, n.reminders[1].Minutes)
+		assert.Equal(t, event.ID, n.reminders[0].EventID)
+		assert.Equal(t, "user1", n.reminders[0].UserID)
+		assert.True(t, n.reminders[0].Start.Equal(time.Date(2024, 1, 10, 7, 0, 0, 0, time.UTC)))
+		assert.True(t, n.reminders[1].RemindAt.Equal(time.Date(2024, 1, 10, 6, 45, 0, 0, time.UTC)))
+
+		// Повторная проверка не отправляет уже доставленные напоминания
+		sent, err = service.sendDueReminders(ctx, n, now.Add(time.Minute), time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+
+		// Напоминание, опоздавшее больше чем на lookback, пропускается
+		late := &fakeNotifier{}
+		other := NewService(NewMemoryStore())
+		_, err = other.ScheduleEvent("user1", types.EventInput{
+			Text: "Встреча",
+			EventTime: types.EventTime{
+				Start:    "2024-01-10T10:00",
+				TimeZone: "Europe/Moscow",
+			},
+			Reminders: []int{15},
+		})
+		require.NoError(t, err)
+		sent, err = other.sendDueReminders(ctx, late, time.Date(2024, 1, 10, 7, 30, 0, 0, time.UTC), 30*time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		assert.Empty(t, late.reminders)
+	})
+}
+
+func TestService_SendDueRemindersRetry(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		_, err := service.ScheduleEvent("user1", types.EventInput{
+			Text:      "Созвон",
+			EventTime: types.EventTime{Start: "2024-01-10T12:00:00Z"},
+			Reminders: []int{10},
+		})
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		now := time.Date(2024, 1, 10, 11, 50, 0, 0, time.UTC)
+
+		n := &fakeNotifier{err: errors.New("сервис недоступен")}
+		sent, err := service.sendDueReminders(ctx, n, now, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+
+		// Недоставленное напоминание отправляется на следующей проверке
+		n.err = nil
+		sent, err = service.sendDueReminders(ctx, n, now.Add(30*time.Second), time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		assert.Len(t, n.reminders, 1)
+	})
+}
+
+func TestService_SeriesAndAllDayReminders(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		_, err := service.ScheduleEvent("user1", types.EventInput{
+			Text: "Планерка",
+			EventTime: types.EventTime{
+				Start:    "2024-01-08T10:00",
+				Duration: "30m",
+				TimeZone: "Europe/Moscow",
+			},
+			RRule:     "FREQ=DAILY;COUNT=3",
+			Reminders: []int{30},
+		})
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		n := &fakeNotifier{}
+
+		// Напоминание о втором повторении: 09:30 по Москве 9 января
+		sent, err := service.sendDueReminders(ctx, n, time.Date(2024, 1, 9, 6, 35, 0, 0, time.UTC), 10*time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		require.Len(t, n.reminders, 1)
+		assert.True(t, n.reminders[0].Start.Equal(time.Date(2024, 1, 9, 7, 0, 0, 0, time.UTC)))
+
+		// Событие на весь день напоминает относительно полуночи в часовом поясе владельца
+		_, err = service.SetUserTimeZone("user2", "Asia/Tokyo")
+		require.NoError(t, err)
+		_, err = service.ScheduleEvent("user2", types.EventInput{
+			Text:      "Отпуск",
+			EventTime: types.EventTime{Date: "2024-01-10", AllDay: true},
+			Reminders: []int{60},
+		})
+		require.NoError(t, err)
+
+		n = &fakeNotifier{}
+		sent, err = service.sendDueReminders(ctx, n, time.Date(2024, 1, 9, 14, 0, 0, 0, time.UTC), time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		require.Len(t, n.reminders, 1)
+		assert.True(t, n.reminders[0].AllDay)
+		assert.True(t, n.reminders[0].RemindAt.Equal(time.Date(2024, 1, 9, 14, 0, 0, 0, time.UTC)))
+	})
+}
+
+func TestService_SetReminders(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		event, err := service.ScheduleEvent("user1", types.EventInput{
+			Text:      "Встреча",
+			EventTime: types.EventTime{Date: "2024-01-10"},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, event.Reminders)
+
+		_, err = service.ScheduleEvent("user1", types.EventInput{
+			Text:      "Встреча",
+			EventTime: types.EventTime{Date: "2024-01-10"},
+			Reminders: []int{-5},
+		})
+		assert.Error(t, err)
+
+		updated, err := service.SetReminders(event.ID, "user1", []int{30, 5})
+		require.NoError(t, err)
+		assert.Equal(t, []int{5, 30}, updated.Reminders)
+
+		_, err = service.SetReminders(event.ID, "user2", []int{10})
+		assert.Error(t, err)
+		_, err = service.SetReminders(event.ID, "user1", []int{maxReminderMinutes + 1})
+		assert.Error(t, err)
+
+		updated, err = service.SetReminders(event.ID, "user1", []int{})
+		require.NoError(t, err)
+		assert.Empty(t, updated.Reminders)
+
+		events, err := service.store.ListWithReminders()
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+}
+
+func TestService_ICSReminders(t *testing.T) {
+	service := NewService(NewMemoryStore())
+	_, err := service.ScheduleEvent("user1", types.EventInput{
+		Text:      "Встреча",
+		EventTime: types.EventTime{Start: "2024-01-10T12:00:00Z"},
+		Reminders: []int{15, 1440},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, service.ExportICS(&buf, "user1"))
+	assert.Contains(t, buf.String(), "BEGIN:VALARM\r\n")
+	assert.Contains(t, buf.String(), "TRIGGER:-PT15M\r\n")
+	assert.Contains(t, buf.String(), "TRIGGER:-P1D\r\n")
+
+	other := NewService(NewMemoryStore())
+	result, err := other.ImportICS(&buf, "user2")
+	require.NoError(t, err)
+	require.Len(t, result.Created, 1)
+
+	events, err := other.store.ListWithReminders()
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, []int{15, 1440}, events[0].Reminders)
+}
@@ -0,0 +1,385 @@
+package calendar
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"calendar/internal/types"
+)
+
+// prefixWeight - вес совпадения слова запроса с началом слова события относительно точного совпадения
+const prefixWeight = 0.5
+
+// SearchEvents ищет события календарей calendars (по умолчанию - календаря пользователя) по словам запроса query
+// без учета регистра. Событие подходит, если каждое слово запроса совпадает со словом его заголовка, текста,
+// описания или места либо с началом слова. Непустые fromStr и toStr оставляют события и серии, которые идут
+// в интервале [from, to), а tags - события со всеми указанными тегами: хештегами текста или категориями.
+// Результаты упорядочены по релевантности, затем по началу
+func (s *Service) SearchEvents(userID, query, fromStr, toStr string, tags []string, calendars ...string) ([]*types.SearchResult, error) {
+	if userID == "" {
+		return nil, errors.New("user_id не может быть пустым")
+	}
+
+	terms, _ := tokenize(query)
+	normalizedTags := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		normalizedTags = append(normalizedTags, normalizeCategory(tag))
+	}
+	if len(terms) == 0 && len(normalizedTags) == 0 {
+		return nil, errors.New("нужен поисковый запрос или теги")
+	}
+
+	var from, to time.Time
+	if fromStr != "" || toStr != "" {
+		location, err := s.userLocation(userID)
+		if err != nil {
+			return nil, err
+		}
+		if from, to, err = parseRange(fromStr, toStr, location); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(calendars) == 0 {
+		calendars = []string{userID}
+	}
+
+	var results []*types.SearchResult
+	seen := make(map[string]bool, len(calendars))
+	for _, ownerID := range calendars {
+		if seen[ownerID] {
+			continue
+		}
+		seen[ownerID] = true
+
+		if err := s.CheckAccess(userID, ownerID, types.AccessRead); err != nil {
+			return nil, err
+		}
+		if err := s.loadSearchIndex(ownerID); err != nil {
+			return nil, err
+		}
+
+		for _, hit := range s.search.search(ownerID, terms, normalizedTags) {
+			event, err := s.store.Get(hit.id)
+			if errors.Is(err, ErrEventNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			event.Owner = ownerID
+			if !from.IsZero() {
+				inRange, err := occursBetween(event, from, to)
+				if err != nil {
+					return nil, err
+				}
+				if !inRange {
+					continue
+				}
+			}
+			results = append(results, &types.SearchResult{Event: event, Score: hit.score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if !results[i].Date.Equal(results[j].Date) {
+			return results[i].Date.Before(results[j].Date)
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results, nil
+}
+
+// loadSearchIndex индексирует события календаря при первом поиске в нем. Загрузка идет под блокировкой
+// сервиса, поэтому изменения, сделанные во время нее, не теряются
+func (s *Service) loadSearchIndex(ownerID string) error {
+	if s.search.isLoaded(ownerID) {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.search.isLoaded(ownerID) {
+		return nil
+	}
+	events, err := s.store.ListByUser(ownerID)
+	if err != nil {
+		return err
+	}
+	s.search.load(ownerID, events)
+	return nil
+}
+
+// saveEvent сохраняет событие, обновляет поисковый индекс и приглашения участников
+func (s *Service) saveEvent(event *types.Event) error {
+	previous, err := s.store.Get(event.ID)
+	if err != nil && !errors.Is(err, ErrEventNotFound) {
+		return err
+	}
+
+	if err := s.store.Save(event); err != nil {
+		return err
+	}
+	s.search.add(event)
+	return s.syncInvitations(previous, event)
+}
+
+// deleteEvent удаляет событие из хранилища и поискового индекса вместе с приглашениями участников
+func (s *Service) deleteEvent(event *types.Event) error {
+	if err := s.store.Delete(event.ID); err != nil {
+		return err
+	}
+	s.search.remove(event.ID)
+	return s.deleteInvitations(event)
+}
+
+// occursBetween сообщает, что событие или хотя бы одно повторение серии пересекается с интервалом [from, to)
+func occursBetween(event *types.Event, from, to time.Time) (bool, error) {
+	event = cloneEvent(event)
+	normalizeEvent(event)
+	if event.RRule != "" {
+		occurrences, err := expandSeries(event, from, to)
+		return len(occurrences) > 0, err
+	}
+	eventFrom, eventTo := eventWindow(event, from, to)
+	return overlaps(event.Date, event.End, eventFrom, eventTo), nil
+}
+
+// searchIndex - обратный индекс слов заголовка, текста, описания и места событий. Календарь индексируется при первом поиске в нем,
+// после этого индекс обновляется при каждом изменении событий через сервис
+type searchIndex struct {
+	mutex sync.RWMutex
+	// calendars - индексы загруженных календарей по владельцу
+	calendars map[string]*calendarIndex
+	// owners - владелец календаря каждого проиндексированного события
+	owners map[string]string
+}
+
+// calendarIndex - обратный индекс одного календаря
+type calendarIndex struct {
+	// postings - сколько раз слово встречается в каждом событии
+	postings map[string]map[string]int
+	docs     map[string]*searchDoc
+}
+
+// searchDoc - проиндексированное событие
+type searchDoc struct {
+	terms map[string]int
+	tags  map[string]bool
+}
+
+// searchHit - событие, подходящее под запрос, и его релевантность
+type searchHit struct {
+	id    string
+	score float64
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		calendars: make(map[string]*calendarIndex),
+		owners:    make(map[string]string),
+	}
+}
+
+func (idx *searchIndex) isLoaded(ownerID string) bool {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	_, ok := idx.calendars[ownerID]
+	return ok
+}
+
+// load индексирует все события календаря
+func (idx *searchIndex) load(ownerID string, events []*types.Event) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.calendars[ownerID] = &calendarIndex{
+		postings: make(map[string]map[string]int),
+		docs:     make(map[string]*searchDoc),
+	}
+	for _, event := range events {
+		idx.addLocked(event)
+	}
+}
+
+// add индексирует новую версию события. События незагруженных календарей пропускаются:
+// они попадут в индекс из хранилища при первом поиске
+func (idx *searchIndex) add(event *types.Event) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	idx.addLocked(event)
+}
+
+func (idx *searchIndex) addLocked(event *types.Event) {
+	idx.removeLocked(event.ID)
+
+	calendar, ok := idx.calendars[event.UserID]
+	if !ok {
+		return
+	}
+
+	words, tags := tokenize(strings.Join([]string{event.Title, event.Text, event.Description, event.Location}, "\n"))
+	doc := &searchDoc{
+		terms: make(map[string]int, len(words)),
+		tags:  make(map[string]bool, len(tags)+len(event.Categories)),
+	}
+	for _, word := range words {
+		doc.terms[word]++
+	}
+	for _, tag := range tags {
+		doc.tags[tag] = true
+	}
+	for _, category := range event.Categories {
+		doc.tags[category] = true
+	}
+
+	for term, count := range doc.terms {
+		if calendar.postings[term] == nil {
+			calendar.postings[term] = make(map[string]int)
+		}
+		calendar.postings[term][event.ID] = count
+	}
+	calendar.docs[event.ID] = doc
+	idx.owners[event.ID] = event.UserID
+}
+
+// remove удаляет событие из индекса
+func (idx *searchIndex) remove(id string) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	idx.removeLocked(id)
+}
+
+func (idx *searchIndex) removeLocked(id string) {
+	ownerID, ok := idx.owners[id]
+	if !ok {
+		return
+	}
+	delete(idx.owners, id)
+
+	calendar := idx.calendars[ownerID]
+	doc := calendar.docs[id]
+	for term := range doc.terms {
+		delete(calendar.postings[term], id)
+		if len(calendar.postings[term]) == 0 {
+			delete(calendar.postings, term)
+		}
+	}
+	delete(calendar.docs, id)
+}
+
+// search возвращает события календаря, в которых есть все слова terms (целиком или как начало слова)
+// и все теги tags, с релевантностью TF-IDF
+func (idx *searchIndex) search(ownerID string, terms, tags []string) []searchHit {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	calendar, ok := idx.calendars[ownerID]
+	if !ok || len(calendar.docs) == 0 {
+		return nil
+	}
+
+	var scores map[string]float64
+	if len(terms) == 0 {
+		scores = make(map[string]float64, len(calendar.docs))
+		for id := range calendar.docs {
+			scores[id] = 0
+		}
+	}
+
+	total := float64(len(calendar.docs))
+	for _, term := range terms {
+		termScores := make(map[string]float64)
+		for word, postings := range calendar.postings {
+			weight := 1.0
+			if word != term {
+				if !strings.HasPrefix(word, term) {
+					continue
+				}
+				weight = prefixWeight
+			}
+
+			idf := math.Log(1 + total/float64(len(postings)))
+			for id, count := range postings {
+				score := weight * (1 + math.Log(float64(count))) * idf
+				// Слово запроса засчитывается по лучшему совпадению в событии
+				termScores[id] = math.Max(termScores[id], score)
+			}
+		}
+
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id, score := range scores {
+			if termScore, ok := termScores[id]; ok {
+				scores[id] = score + termScore
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	hits := make([]searchHit, 0, len(scores))
+	for id, score := range scores {
+		if hasTags(calendar.docs[id], tags) {
+			hits = append(hits, searchHit{id: id, score: score})
+		}
+	}
+	return hits
+}
+
+func hasTags(doc *searchDoc, tags []string) bool {
+	for _, tag := range tags {
+		if !doc.tags[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenize разбивает текст на слова - последовательности букв и цифр любого алфавита - в нижнем регистре.
+// Слова, перед которыми стоит #, дополнительно возвращаются как теги
+func tokenize(text string) (words, tags []string) {
+	var word strings.Builder
+	hashtag := false
+	flush := func() {
+		if word.Len() > 0 {
+			term := normalizeTerm(word.String())
+			words = append(words, term)
+			if hashtag {
+				tags = append(tags, term)
+			}
+			word.Reset()
+		}
+		hashtag = false
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		case r == '#' && word.Len() == 0:
+			hashtag = true
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words, tags
+}
+
+// normalizeTerm приводит слово к нижнему регистру и заменяет ё на е, чтобы «ёлка» находилась по «елка»
+func normalizeTerm(term string) string {
+	return strings.ReplaceAll(strings.ToLower(term), "ё", "е")
+}
@@ -0,0 +1,115 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"calendar/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resultTexts(results []*types.SearchResult) []string {
+	texts := make([]string, 0, len(results))
+	for _, result := range results {
+		texts = append(texts, result.Text)
+	}
+	return texts
+}
+
+func TestTokenize(t *testing.T) {
+	words, tags := tokenize("Ёлка у Пети: встреча в 10:30, #Работа #офис-2 и e-mail!")
+	assert.Equal(t, []string{"елка", "у", "пети", "встреча", "в", "10", "30", "работа", "офис", "2", "и", "e", "mail"}, words)
+	assert.Equal(t, []string{"работа", "офис"}, tags)
+
+	words, tags = tokenize("  ")
+	assert.Empty(t, words)
+	assert.Empty(t, tags)
+}
+
+func TestService_SearchEvents(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		// Событие, сохраненное до первого поиска, попадает в индекс из хранилища
+		require.NoError(t, service.store.Save(&types.Event{ID: "old", UserID: "user1", Date: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), Text: "Старая встреча"}))
+
+		_, err := service.CreateEvent("user1", "2024-01-10", "Встреча с командой #работа")
+		require.NoError(t, err)
+		planning, err := service.CreateEvent("user1", "2024-01-11", "Планирование: встреча, встреча и еще встреча #работа")
+		require.NoError(t, err)
+		_, err = service.CreateEvent("user1", "2024-01-12", "Встречать гостей #дом")
+		require.NoError(t, err)
+		_, err = service.CreateEvent("user2", "2024-01-10", "Встреча")
+		require.NoError(t, err)
+
+		// Без учета регистра; чаще встречающееся слово выше, совпадение по началу слова ниже точного
+		results, err := service.SearchEvents("user1", "ВСТРЕЧА", "", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"Планирование: встреча, встреча и еще встреча #работа",
+			"Старая встреча",
+			"Встреча с командой #работа",
+			"Встречать гостей #дом",
+		}, resultTexts(results))
+		assert.Greater(t, results[0].Score, results[1].Score)
+		assert.Greater(t, results[2].Score, results[3].Score)
+		assert.Equal(t, "user1", results[0].Owner)
+
+		// Все слова запроса должны быть в событии
+		results, err = service.SearchEvents("user1", "встреч команд", "", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Встреча с командой #работа"}, resultTexts(results))
+
+		results, err = service.SearchEvents("user1", "встреча", "2024-01-10", "2024-01-12", []string{"#Работа"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Планирование: встреча, встреча и еще встреча #работа", "Встреча с командой #работа"}, resultTexts(results))
+
+		results, err = service.SearchEvents("user1", "", "", "", []string{"дом"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Встречать гостей #дом"}, resultTexts(results))
+
+		// Индекс следует за изменениями и удалениями
+		_, err = service.UpdateEvent(planning.ID, "user1", "2024-01-11", "Ретроспектива")
+		require.NoError(t, err)
+		require.NoError(t, service.DeleteEvent("old", "user1"))
+		results, err = service.SearchEvents("user1", "встреча", "", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Встреча с командой #работа", "Встречать гостей #дом"}, resultTexts(results))
+		results, err = service.SearchEvents("user1", "ретро", "", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Ретроспектива"}, resultTexts(results))
+
+		_, err = service.SearchEvents("user1", " ,", "", "", nil)
+		assert.Error(t, err)
+		_, err = service.SearchEvents("user1", "встреча", "2024-01-10", "", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestService_SearchSharedAndSeries(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		_, err := service.CreateRecurringEvent("user1", "2024-01-01", "Ёжедневная планерка", "FREQ=WEEKLY;COUNT=3", nil)
+		require.NoError(t, err)
+
+		// Серия подходит, если ее повторение идет в интервале
+		results, err := service.SearchEvents("user1", "планерка", "2024-01-15", "2024-01-16", nil)
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+		results, err = service.SearchEvents("user1", "планерка", "2024-01-16", "2024-01-30", nil)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+		results, err = service.SearchEvents("user1", "ежедневная", "", "", nil)
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+
+		_, err = service.SearchEvents("user2", "планерка", "", "", nil, "user1")
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+
+		_, err = service.ShareCalendar("user1", "user2", types.AccessRead)
+		require.NoError(t, err)
+		results, err = service.SearchEvents("user2", "планерка", "", "", nil, "user2", "user1")
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "user1", results[0].Owner)
+	})
+}
@@ -0,0 +1,119 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+
+	"calendar/internal/types"
+)
+
+// ShareCalendar выдает пользователю granteeID доступ к календарю ownerID или меняет уровень выданного доступа
+func (s *Service) ShareCalendar(ownerID, granteeID, access string) (*types.Grant, error) {
+	if ownerID == "" {
+		return nil, errors.New("user_id не может быть пустым")
+	}
+	if granteeID == "" {
+		return nil, errors.New("grantee_id не может быть пустым")
+	}
+	if granteeID == ownerID {
+		return nil, errors.New("нельзя выдать доступ к календарю самому себе")
+	}
+	if access != types.AccessRead && access != types.AccessWrite {
+		return nil, fmt.Errorf("уровень доступа должен быть %s или %s", types.AccessRead, types.AccessWrite)
+	}
+
+	grant := &types.Grant{
+		OwnerID:   ownerID,
+		GranteeID: granteeID,
+		Access:    access,
+	}
+	if err := s.store.SaveGrant(grant); err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+// UnshareCalendar отзывает доступ пользователя granteeID к календарю ownerID
+func (s *Service) UnshareCalendar(ownerID, granteeID string) error {
+	if ownerID == "" {
+		return errors.New("user_id не может быть пустым")
+	}
+	if granteeID == "" {
+		return errors.New("grantee_id не может быть пустым")
+	}
+	return s.store.DeleteGrant(ownerID, granteeID)
+}
+
+// ListShares возвращает доступы, выданные пользователем к его календарю и выданные ему к чужим
+func (s *Service) ListShares(userID string) (*types.CalendarShares, error) {
+	if userID == "" {
+		return nil, errors.New("user_id не может быть пустым")
+	}
+
+	grants, err := s.store.ListGrants(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := &types.CalendarShares{
+		SharedByMe:   []*types.Grant{},
+		SharedWithMe: []*types.Grant{},
+	}
+	for _, grant := range grants {
+		if grant.OwnerID == userID {
+			shares.SharedByMe = append(shares.SharedByMe, grant)
+		} else {
+			shares.SharedWithMe = append(shares.SharedWithMe, grant)
+		}
+	}
+	return shares, nil
+}
+
+// SharedCalendars возвращает владельцев календарей, доступных пользователю для просмотра, кроме его собственного
+func (s *Service) SharedCalendars(userID string) ([]string, error) {
+	shares, err := s.ListShares(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make([]string, 0, len(shares.SharedWithMe))
+	for _, grant := range shares.SharedWithMe {
+		owners = append(owners, grant.OwnerID)
+	}
+	return owners, nil
+}
+
+// CheckAccess проверяет, что пользователь actor может читать (AccessRead) или изменять (AccessWrite)
+// календарь ownerID, и возвращает ErrPermissionDenied, если нет
+func (s *Service) CheckAccess(actor, ownerID, access string) error {
+	return s.authorize(actor, ownerID, access, fmt.Sprintf("нет доступа к календарю пользователя %s", ownerID))
+}
+
+// authorize возвращает ErrPermissionDenied с текстом message, если у actor нет доступа к календарю ownerID
+func (s *Service) authorize(actor, ownerID, access, message string) error {
+	allowed, err := s.hasAccess(actor, ownerID, access)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return permissionDenied(message)
+	}
+	return nil
+}
+
+// hasAccess сообщает, что actor - владелец календаря или получил к нему доступ нужного уровня.
+// Доступ на запись включает просмотр
+func (s *Service) hasAccess(actor, ownerID, access string) (bool, error) {
+	if actor == ownerID {
+		return true, nil
+	}
+
+	grant, err := s.store.GetGrant(ownerID, actor)
+	if errors.Is(err, ErrGrantNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return grant.Access == types.AccessWrite || access == types.AccessRead, nil
+}
@@ -0,0 +1,111 @@
+package calendar
+
+import (
+	"testing"
+
+	"calendar/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ShareCalendar(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		_, err := service.ShareCalendar("user1", "user1", types.AccessRead)
+		assert.Error(t, err)
+		_, err = service.ShareCalendar("user1", "user2", "admin")
+		assert.Error(t, err)
+		_, err = service.ShareCalendar("user1", "", types.AccessRead)
+		assert.Error(t, err)
+
+		_, err = service.ShareCalendar("user1", "user2", types.AccessRead)
+		require.NoError(t, err)
+		_, err = service.ShareCalendar("user3", "user2", types.AccessWrite)
+		require.NoError(t, err)
+
+		shares, err := service.ListShares("user2")
+		require.NoError(t, err)
+		assert.Empty(t, shares.SharedByMe)
+		require.Len(t, shares.SharedWithMe, 2)
+
+		owners, err := service.SharedCalendars("user2")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user1", "user3"}, owners)
+
+		assert.NoError(t, service.CheckAccess("user1", "user1", types.AccessWrite))
+		assert.NoError(t, service.CheckAccess("user2", "user1", types.AccessRead))
+		assert.ErrorIs(t, service.CheckAccess("user2", "user1", types.AccessWrite), ErrPermissionDenied)
+		assert.NoError(t, service.CheckAccess("user2", "user3", types.AccessRead))
+		assert.NoError(t, service.CheckAccess("user2", "user3", types.AccessWrite))
+		assert.ErrorIs(t, service.CheckAccess("user4", "user1", types.AccessRead), ErrPermissionDenied)
+
+		require.NoError(t, service.UnshareCalendar("user1", "user2"))
+		assert.ErrorIs(t, service.CheckAccess("user2", "user1", types.AccessRead), ErrPermissionDenied)
+		assert.ErrorIs(t, service.UnshareCalendar("user1", "user2"), ErrGrantNotFound)
+	})
+}
+
+func TestService_SharedCalendarEvents(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		own, err := service.CreateEvent("user2", "2024-01-10", "Свое")
+		require.NoError(t, err)
+		shared, err := service.CreateEvent("user1", "2024-01-10", "Общее")
+		require.NoError(t, err)
+
+		// Без доступа чужой календарь не виден
+		_, err = service.GetEventsForDay("user2", "2024-01-10", "user1")
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+		_, err = service.GetEvent(shared.ID, "user2")
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+
+		_, err = service.ShareCalendar("user1", "user2", types.AccessRead)
+		require.NoError(t, err)
+
+		events, err := service.GetEventsForDay("user2", "2024-01-10", "user2", "user1")
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		owners := map[string]string{}
+		for _, event := range events {
+			owners[event.ID] = event.Owner
+		}
+		assert.Equal(t, map[string]string{own.ID: "user2", shared.ID: "user1"}, owners)
+
+		event, err := service.GetEvent(shared.ID, "user2")
+		require.NoError(t, err)
+		assert.Equal(t, "Общее", event.Text)
+
+		// Доступ на просмотр не дает менять события
+		_, err = service.UpdateEvent(shared.ID, "user2", "2024-01-11", "Изменено")
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+		assert.ErrorIs(t, service.DeleteEvent(shared.ID, "user2"), ErrPermissionDenied)
+
+		_, err = service.ShareCalendar("user1", "user2", types.AccessWrite)
+		require.NoError(t, err)
+
+		updated, err := service.UpdateEvent(shared.ID, "user2", "2024-01-11", "Изменено")
+		require.NoError(t, err)
+		assert.Equal(t, "user1", updated.UserID)
+		require.NoError(t, service.DeleteEvent(shared.ID, "user2"))
+
+		events, err = service.GetEventsForWeek("user1", "2024-01-10")
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+}
+
+func TestService_SharedSeriesOverride(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		series, err := service.CreateRecurringEvent("user1", "2023-12-04", "Планерка", "FREQ=DAILY;COUNT=5", nil)
+		require.NoError(t, err)
+		_, err = service.ShareCalendar("user1", "user2", types.AccessWrite)
+		require.NoError(t, err)
+
+		override, err := service.UpdateOccurrence(series.ID, "user2", "2023-12-06", "2023-12-09", "Перенесенная планерка")
+		require.NoError(t, err)
+		assert.Equal(t, "user1", override.UserID)
+
+		events, err := service.GetEventsForMonth("user1", "2023-12-01")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2023-12-04", "2023-12-05", "2023-12-07", "2023-12-08", "2023-12-09"}, eventDates(events))
+	})
+}
@@ -0,0 +1,440 @@
+package calendar
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"calendar/internal/types"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteDateLayout сохраняет лексикографический порядок дат в текстовой колонке
+const sqliteDateLayout = "2006-01-02T15:04:05.000000000Z"
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS events (
+	id       TEXT PRIMARY KEY,
+	user_id  TEXT NOT NULL,
+	date     TEXT NOT NULL,
+	data     TEXT NOT NULL,
+	end_date TEXT NOT NULL DEFAULT '',
+	series   INTEGER NOT NULL DEFAULT 0,
+	span     INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_events_user_date ON events (user_id, date);
+CREATE TABLE IF NOT EXISTS user_settings (
+	user_id TEXT PRIMARY KEY,
+	data    TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS sent_reminders (
+	key       TEXT PRIMARY KEY,
+	remind_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sent_reminders_remind_at ON sent_reminders (remind_at);
+CREATE TABLE IF NOT EXISTS calendar_grants (
+	owner_id   TEXT NOT NULL,
+	grantee_id TEXT NOT NULL,
+	access     TEXT NOT NULL,
+	PRIMARY KEY (owner_id, grantee_id)
+);
+CREATE INDEX IF NOT EXISTS idx_calendar_grants_grantee ON calendar_grants (grantee_id);
+`
+
+// sqliteRangeSchema индексирует колонки выборки по интервалу, созданные схемой или migrateSQLiteRange
+const sqliteRangeSchema = `
+CREATE INDEX IF NOT EXISTS idx_events_user_series_date ON events (user_id, series, date);
+CREATE INDEX IF NOT EXISTS idx_events_user_series_span ON events (user_id, series, span);
+`
+
+// SQLiteStore хранит события в базе SQLite.
+// Ключевые для выборок поля вынесены в колонки, событие целиком хранится в data как JSON
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore открывает базу SQLite по пути path и создает схему при необходимости
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, storageErrorf("не удалось открыть базу SQLite: %v", err)
+	}
+	// SQLite допускает одного писателя, а база ":memory:" существует только в своем соединении
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, storageErrorf("не удалось создать схему SQLite: %v", err)
+	}
+	if err := migrateSQLiteRange(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteRangeSchema); err != nil {
+		db.Close()
+		return nil, storageErrorf("не удалось создать схему SQLite: %v", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// Save создает или заменяет событие
+func (s *SQLiteStore) Save(event *types.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return storageErrorf("не удалось сериализовать событие: %v", err)
+	}
+
+	end, series, span := sqliteRangeColumns(event)
+	_, err = s.db.Exec(`
+		INSERT INTO events (id, user_id, date, data, end_date, series, span) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			user_id = excluded.user_id,
+			date = excluded.date,
+			data = excluded.data,
+			end_date = excluded.end_date,
+			series = excluded.series,
+			span = excluded.span`,
+		event.ID, event.UserID, formatSQLiteDate(event.Date), string(data), end, series, span,
+	)
+	if err != nil {
+		return storageErrorf("не удалось сохранить событие: %v", err)
+	}
+	return nil
+}
+
+// Get возвращает событие по id
+func (s *SQLiteStore) Get(id string) (*types.Event, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM events WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrEventNotFound
+	}
+	if err != nil {
+		return nil, storageErrorf("не удалось загрузить событие: %v", err)
+	}
+	return decodeSQLiteEvent(data)
+}
+
+// Delete удаляет событие по id
+func (s *SQLiteStore) Delete(id string) error {
+	result, err := s.db.Exec(`DELETE FROM events WHERE id = ?`, id)
+	if err != nil {
+		return storageErrorf("не удалось удалить событие: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return storageErrorf("не удалось удалить событие: %v", err)
+	}
+	if rows == 0 {
+		return ErrEventNotFound
+	}
+	return nil
+}
+
+// ListByUser возвращает события пользователя, упорядоченные по дате
+func (s *SQLiteStore) ListByUser(userID string) ([]*types.Event, error) {
+	return s.queryEvents(`SELECT data FROM events WHERE user_id = ? ORDER BY date, id`, userID)
+}
+
+// ListByUserInRange возвращает события пользователя, которые могут попасть в интервал, упорядоченные по дате.
+// Одиночные события ищутся по индексу даты начиная с from минус наибольшая длительность события пользователя
+func (s *SQLiteStore) ListByUserInRange(userID string, from, to time.Time) ([]*types.Event, error) {
+	var maxSpan int64
+	err := s.db.QueryRow(
+		`SELECT COALESCE(MAX(span), 0) FROM events WHERE user_id = ? AND series = 0`, userID,
+	).Scan(&maxSpan)
+	if err != nil {
+		return nil, storageErrorf("не удалось загрузить события: %v", err)
+	}
+
+	fromDate := formatSQLiteDate(from)
+	events, err := s.queryEvents(`
+		SELECT data FROM events
+		WHERE user_id = ? AND series = 0 AND date >= ? AND date < ? AND (end_date > ? OR date >= ?)`,
+		userID, formatSQLiteDate(from.Add(-time.Duration(maxSpan))), formatSQLiteDate(to), fromDate, fromDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := s.queryEvents(
+		`SELECT data FROM events WHERE user_id = ? AND series = 1 AND date < ?`, userID, formatSQLiteDate(to),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	events = append(events, series...)
+	sortEvents(events)
+	return events, nil
+}
+
+// ListWithReminders возвращает события с напоминаниями, упорядоченные по дате
+func (s *SQLiteStore) ListWithReminders() ([]*types.Event, error) {
+	return s.queryEvents(`SELECT data FROM events WHERE json_array_length(data, '$.reminders') > 0 ORDER BY date, id`)
+}
+
+// MarkReminderSent отмечает напоминание отправленным
+func (s *SQLiteStore) MarkReminderSent(key string, remindAt time.Time) error {
+	_, err := s.db.Exec(
+		`INSERT INTO sent_reminders (key, remind_at) VALUES (?, ?) ON CONFLICT (key) DO NOTHING`,
+		key, formatSQLiteDate(remindAt),
+	)
+	if err != nil {
+		return storageErrorf("не удалось сохранить отметку о напоминании: %v", err)
+	}
+	return nil
+}
+
+// ReminderSent сообщает, что напоминание уже отправлено
+func (s *SQLiteStore) ReminderSent(key string) (bool, error) {
+	var exists int
+	err := s.db.QueryRow(`SELECT 1 FROM sent_reminders WHERE key = ?`, key).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, storageErrorf("не удалось проверить отметку о напоминании: %v", err)
+	}
+	return true, nil
+}
+
+// PruneSentReminders удаляет отметки о напоминаниях, сработавших раньше before
+func (s *SQLiteStore) PruneSentReminders(before time.Time) error {
+	if _, err := s.db.Exec(`DELETE FROM sent_reminders WHERE remind_at < ?`, formatSQLiteDate(before)); err != nil {
+		return storageErrorf("не удалось удалить отметки о напоминаниях: %v", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) queryEvents(query string, args ...interface{}) ([]*types.Event, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, storageErrorf("не удалось загрузить события: %v", err)
+	}
+	defer rows.Close()
+
+	var events []*types.Event
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, storageErrorf("не удалось загрузить события: %v", err)
+		}
+		event, err := decodeSQLiteEvent(data)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, storageErrorf("не удалось загрузить события: %v", err)
+	}
+	return events, nil
+}
+
+// SaveSettings создает или заменяет настройки пользователя
+func (s *SQLiteStore) SaveSettings(settings *types.UserSettings) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return storageErrorf("не удалось сериализовать настройки: %v", err)
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO user_settings (user_id, data) VALUES (?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET data = excluded.data`,
+		settings.UserID, string(data),
+	)
+	if err != nil {
+		return storageErrorf("не удалось сохранить настройки: %v", err)
+	}
+	return nil
+}
+
+// GetSettings возвращает настройки пользователя
+func (s *SQLiteStore) GetSettings(userID string) (*types.UserSettings, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM user_settings WHERE user_id = ?`, userID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSettingsNotFound
+	}
+	if err != nil {
+		return nil, storageErrorf("не удалось загрузить настройки: %v", err)
+	}
+
+	var settings types.UserSettings
+	if err := json.Unmarshal([]byte(data), &settings); err != nil {
+		return nil, storageErrorf("настройки в базе повреждены: %v", err)
+	}
+	return &settings, nil
+}
+
+// SaveGrant создает или заменяет доступ к календарю
+func (s *SQLiteStore) SaveGrant(grant *types.Grant) error {
+	_, err := s.db.Exec(`
+		INSERT INTO calendar_grants (owner_id, grantee_id, access) VALUES (?, ?, ?)
+		ON CONFLICT (owner_id, grantee_id) DO UPDATE SET access = excluded.access`,
+		grant.OwnerID, grant.GranteeID, grant.Access,
+	)
+	if err != nil {
+		return storageErrorf("не удалось сохранить доступ к календарю: %v", err)
+	}
+	return nil
+}
+
+// GetGrant возвращает доступ к календарю
+func (s *SQLiteStore) GetGrant(ownerID, granteeID string) (*types.Grant, error) {
+	grant := types.Grant{OwnerID: ownerID, GranteeID: granteeID}
+	err := s.db.QueryRow(
+		`SELECT access FROM calendar_grants WHERE owner_id = ? AND grantee_id = ?`, ownerID, granteeID,
+	).Scan(&grant.Access)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrGrantNotFound
+	}
+	if err != nil {
+		return nil, storageErrorf("не удалось загрузить доступ к календарю: %v", err)
+	}
+	return &grant, nil
+}
+
+// DeleteGrant отзывает доступ к календарю
+func (s *SQLiteStore) DeleteGrant(ownerID, granteeID string) error {
+	result, err := s.db.Exec(`DELETE FROM calendar_grants WHERE owner_id = ? AND grantee_id = ?`, ownerID, granteeID)
+	if err != nil {
+		return storageErrorf("не удалось отозвать доступ к календарю: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return storageErrorf("не удалось отозвать доступ к календарю: %v", err)
+	}
+	if rows == 0 {
+		return ErrGrantNotFound
+	}
+	return nil
+}
+
+// ListGrants возвращает доступы, выданные пользователем и выданные ему
+func (s *SQLiteStore) ListGrants(userID string) ([]*types.Grant, error) {
+	rows, err := s.db.Query(`
+		SELECT owner_id, grantee_id, access FROM calendar_grants
+		WHERE owner_id = ? OR grantee_id = ?
+		ORDER BY owner_id, grantee_id`,
+		userID, userID,
+	)
+	if err != nil {
+		return nil, storageErrorf("не удалось загрузить доступы к календарю: %v", err)
+	}
+	defer rows.Close()
+
+	var grants []*types.Grant
+	for rows.Next() {
+		var grant types.Grant
+		if err := rows.Scan(&grant.OwnerID, &grant.GranteeID, &grant.Access); err != nil {
+			return nil, storageErrorf("не удалось загрузить доступы к календарю: %v", err)
+		}
+		grants = append(grants, &grant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, storageErrorf("не удалось загрузить доступы к календарю: %v", err)
+	}
+	return grants, nil
+}
+
+// Close закрывает соединение с базой
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// migrateSQLiteRange добавляет колонки выборки по интервалу в базу, созданную до их появления, и заполняет их
+func migrateSQLiteRange(db *sql.DB) error {
+	var exists int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('events') WHERE name = 'end_date'`).Scan(&exists)
+	if err != nil {
+		return storageErrorf("не удалось проверить схему SQLite: %v", err)
+	}
+	if exists > 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return storageErrorf("не удалось обновить схему SQLite: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, statement := range []string{
+		`ALTER TABLE events ADD COLUMN end_date TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE events ADD COLUMN series INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE events ADD COLUMN span INTEGER NOT NULL DEFAULT 0`,
+	} {
+		if _, err := tx.Exec(statement); err != nil {
+			return storageErrorf("не удалось обновить схему SQLite: %v", err)
+		}
+	}
+
+	rows, err := tx.Query(`SELECT data FROM events`)
+	if err != nil {
+		return storageErrorf("не удалось обновить схему SQLite: %v", err)
+	}
+	var events []*types.Event
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			rows.Close()
+			return storageErrorf("не удалось обновить схему SQLite: %v", err)
+		}
+		event, err := decodeSQLiteEvent(data)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		events = append(events, event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return storageErrorf("не удалось обновить схему SQLite: %v", err)
+	}
+
+	for _, event := range events {
+		end, series, span := sqliteRangeColumns(event)
+		if _, err := tx.Exec(
+			`UPDATE events SET end_date = ?, series = ?, span = ? WHERE id = ?`, end, series, span, event.ID,
+		); err != nil {
+			return storageErrorf("не удалось обновить схему SQLite: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return storageErrorf("не удалось обновить схему SQLite: %v", err)
+	}
+	return nil
+}
+
+// sqliteRangeColumns возвращает значения колонок выборки по интервалу: конец события, признак серии
+// и длительность в наносекундах
+func sqliteRangeColumns(event *types.Event) (string, int, int64) {
+	end := eventEnd(event)
+	series := 0
+	if event.RRule != "" {
+		series = 1
+	}
+	return formatSQLiteDate(end), series, int64(end.Sub(event.Date))
+}
+
+func formatSQLiteDate(date time.Time) string {
+	return date.UTC().Format(sqliteDateLayout)
+}
+
+func decodeSQLiteEvent(data string) (*types.Event, error) {
+	var event types.Event
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		return nil, storageErrorf("событие в базе повреждено: %v", err)
+	}
+	return &event, nil
+}
@@ -0,0 +1,111 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"calendar/internal/config"
+	"calendar/internal/types"
+)
+
+// ErrEventNotFound возвращается, если события с указанным id нет в хранилище
+var ErrEventNotFound = errors.New("событие не найдено")
+
+// ErrGrantNotFound возвращается, если пользователь не выдавал доступ к своему календарю
+var ErrGrantNotFound = errors.New("доступ к календарю не найден")
+
+// ErrSettingsNotFound возвращается, если пользователь еще не сохранял настройки
+var ErrSettingsNotFound = errors.New("настройки пользователя не найдены")
+
+// EventStore представляет хранилище событий календаря.
+// Реализации безопасны для конкурентного использования и возвращают копии событий
+type EventStore interface {
+	// Save создает событие или заменяет существующее с тем же id
+	Save(event *types.Event) error
+	// Get возвращает событие по id или ErrEventNotFound
+	Get(id string) (*types.Event, error)
+	// Delete удаляет событие по id или возвращает ErrEventNotFound
+	Delete(id string) error
+	// ListByUser возвращает события пользователя, упорядоченные по дате
+	ListByUser(userID string) ([]*types.Event, error)
+	// ListByUserInRange возвращает одиночные события пользователя, пересекающиеся с интервалом [from, to),
+	// и его серии, начинающиеся раньше to, упорядоченные по дате. Повторения серий разворачивает сервис
+	ListByUserInRange(userID string, from, to time.Time) ([]*types.Event, error)
+	// SaveSettings создает или заменяет настройки пользователя
+	SaveSettings(settings *types.UserSettings) error
+	// GetSettings возвращает настройки пользователя или ErrSettingsNotFound
+	GetSettings(userID string) (*types.UserSettings, error)
+	// SaveGrant создает или заменяет доступ к календарю
+	SaveGrant(grant *types.Grant) error
+	// GetGrant возвращает доступ пользователя granteeID к календарю ownerID или ErrGrantNotFound
+	GetGrant(ownerID, granteeID string) (*types.Grant, error)
+	// DeleteGrant отзывает доступ или возвращает ErrGrantNotFound
+	DeleteGrant(ownerID, granteeID string) error
+	// ListGrants возвращает доступы, выданные пользователем и выданные ему, упорядоченные по владельцу и получателю
+	ListGrants(userID string) ([]*types.Grant, error)
+	// ListWithReminders возвращает события всех пользователей, у которых есть напоминания
+	ListWithReminders() ([]*types.Event, error)
+	// MarkReminderSent отмечает напоминание с ключом key отправленным, remindAt - время его срабатывания
+	MarkReminderSent(key string, remindAt time.Time) error
+	// ReminderSent сообщает, что напоминание с ключом key уже отправлено
+	ReminderSent(key string) (bool, error)
+	// PruneSentReminders удаляет отметки о напоминаниях, сработавших раньше before
+	PruneSentReminders(before time.Time) error
+	// Close сбрасывает данные на диск и освобождает ресурсы хранилища
+	Close() error
+}
+
+// NewStore создает хранилище событий по конфигурации
+func NewStore(cfg config.StorageConfig) (EventStore, error) {
+	switch cfg.Backend {
+	case config.StorageMemory:
+		return NewMemoryStore(), nil
+	case config.StorageFile:
+		return NewFileStore(cfg.Path, cfg.SnapshotEvery)
+	case config.StorageSQLite:
+		return NewSQLiteStore(cfg.Path)
+	default:
+		return nil, fmt.Errorf("неизвестное хранилище: %s", cfg.Backend)
+	}
+}
+
+func cloneEvent(event *types.Event) *types.Event {
+	clone := *event
+	if event.ExDates != nil {
+		clone.ExDates = append([]time.Time(nil), event.ExDates...)
+	}
+	if event.Reminders != nil {
+		clone.Reminders = append([]int(nil), event.Reminders...)
+	}
+	if event.RecurrenceID != nil {
+		recurrenceID := *event.RecurrenceID
+		clone.RecurrenceID = &recurrenceID
+	}
+	if event.Categories != nil {
+		clone.Categories = append([]string(nil), event.Categories...)
+	}
+	if event.Attendees != nil {
+		clone.Attendees = append([]types.Attendee(nil), event.Attendees...)
+	}
+	return &clone
+}
+
+func sortGrants(grants []*types.Grant) {
+	sort.Slice(grants, func(i, j int) bool {
+		if grants[i].OwnerID != grants[j].OwnerID {
+			return grants[i].OwnerID < grants[j].OwnerID
+		}
+		return grants[i].GranteeID < grants[j].GranteeID
+	})
+}
+
+func sortEvents(events []*types.Event) {
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].Date.Equal(events[j].Date) {
+			return events[i].Date.Before(events[j].Date)
+		}
+		return events[i].ID < events[j].ID
+	})
+}
@@ -0,0 +1,286 @@
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"calendar/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStores перечисляет хранилища, на которых прогоняются тесты сервиса
+var testStores = []struct {
+	name string
+	open func(t *testing.T) EventStore
+}{
+	{
+		name: "memory",
+		open: func(t *testing.T) EventStore {
+			return NewMemoryStore()
+		},
+	},
+	{
+		name: "file",
+		open: func(t *testing.T) EventStore {
+			store, err := NewFileStore(t.TempDir(), 3)
+			require.NoError(t, err)
+			return store
+		},
+	},
+	{
+		name: "sqlite",
+		open: func(t *testing.T) EventStore {
+			store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "calendar.db"))
+			require.NoError(t, err)
+			return store
+		},
+	},
+}
+
+// forEachStore запускает тест сервиса на каждом хранилище
+func forEachStore(t *testing.T, test func(t *testing.T, service *Service)) {
+	for _, ts := range testStores {
+		t.Run(ts.name, func(t *testing.T) {
+			store := ts.open(t)
+			t.Cleanup(func() { store.Close() })
+			test(t, NewService(store))
+		})
+	}
+}
+
+func TestStore_Contract(t *testing.T) {
+	for _, ts := range testStores {
+		t.Run(ts.name, func(t *testing.T) {
+			store := ts.open(t)
+			defer store.Close()
+
+			_, err := store.Get("missing")
+			assert.ErrorIs(t, err, ErrEventNotFound)
+			assert.ErrorIs(t, store.Delete("missing"), ErrEventNotFound)
+
+			later := &types.Event{ID: "b", UserID: "user1", Date: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Text: "Позже"}
+			earlier := &types.Event{ID: "a", UserID: "user1", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Text: "Раньше"}
+			require.NoError(t, store.Save(later))
+			require.NoError(t, store.Save(earlier))
+			require.NoError(t, store.Save(&types.Event{ID: "c", UserID: "user2", Date: earlier.Date, Text: "Чужое"}))
+
+			// Изменение возвращенного события не должно менять хранилище
+			loaded, err := store.Get("a")
+			require.NoError(t, err)
+			loaded.Text = "Изменено"
+			loaded, err = store.Get("a")
+			require.NoError(t, err)
+			assert.Equal(t, "Раньше", loaded.Text)
+
+			events, err := store.ListByUser("user1")
+			require.NoError(t, err)
+			require.Len(t, events, 2)
+			assert.Equal(t, "a", events[0].ID)
+			assert.Equal(t, "b", events[1].ID)
+
+			later.Text = "Обновлено"
+			require.NoError(t, store.Save(later))
+			loaded, err = store.Get("b")
+			require.NoError(t, err)
+			assert.Equal(t, "Обновлено", loaded.Text)
+
+			require.NoError(t, store.Delete("b"))
+			events, err = store.ListByUser("user1")
+			require.NoError(t, err)
+			assert.Len(t, events, 1)
+
+			_, err = store.GetSettings("user1")
+			assert.ErrorIs(t, err, ErrSettingsNotFound)
+			require.NoError(t, store.SaveSettings(&types.UserSettings{UserID: "user1", TimeZone: "Europe/Moscow"}))
+			require.NoError(t, store.SaveSettings(&types.UserSettings{UserID: "user1", TimeZone: "Asia/Tokyo"}))
+			settings, err := store.GetSettings("user1")
+			require.NoError(t, err)
+			assert.Equal(t, "Asia/Tokyo", settings.TimeZone)
+
+			require.NoError(t, store.Save(&types.Event{ID: "r", UserID: "user1", Date: earlier.Date, Text: "С напоминанием", Reminders: []int{15}}))
+			withReminders, err := store.ListWithReminders()
+			require.NoError(t, err)
+			require.Len(t, withReminders, 1)
+			assert.Equal(t, "r", withReminders[0].ID)
+			assert.Equal(t, []int{15}, withReminders[0].Reminders)
+
+			sent, err := store.ReminderSent("r|old")
+			require.NoError(t, err)
+			assert.False(t, sent)
+			require.NoError(t, store.MarkReminderSent("r|old", earlier.Date))
+			require.NoError(t, store.MarkReminderSent("r|new", later.Date))
+			sent, err = store.ReminderSent("r|old")
+			require.NoError(t, err)
+			assert.True(t, sent)
+
+			require.NoError(t, store.PruneSentReminders(later.Date))
+			sent, err = store.ReminderSent("r|old")
+			require.NoError(t, err)
+			assert.False(t, sent)
+			sent, err = store.ReminderSent("r|new")
+			require.NoError(t, err)
+			assert.True(t, sent)
+
+			_, err = store.GetGrant("user1", "user2")
+			assert.ErrorIs(t, err, ErrGrantNotFound)
+			assert.ErrorIs(t, store.DeleteGrant("user1", "user2"), ErrGrantNotFound)
+			require.NoError(t, store.SaveGrant(&types.Grant{OwnerID: "user1", GranteeID: "user2", Access: types.AccessRead}))
+			require.NoError(t, store.SaveGrant(&types.Grant{OwnerID: "user1", GranteeID: "user2", Access: types.AccessWrite}))
+			require.NoError(t, store.SaveGrant(&types.Grant{OwnerID: "user3", GranteeID: "user1", Access: types.AccessRead}))
+			require.NoError(t, store.SaveGrant(&types.Grant{OwnerID: "user3", GranteeID: "user4", Access: types.AccessRead}))
+			grant, err := store.GetGrant("user1", "user2")
+			require.NoError(t, err)
+			assert.Equal(t, types.AccessWrite, grant.Access)
+
+			grants, err := store.ListGrants("user1")
+			require.NoError(t, err)
+			assert.Equal(t, []*types.Grant{
+				{OwnerID: "user1", GranteeID: "user2", Access: types.AccessWrite},
+				{OwnerID: "user3", GranteeID: "user1", Access: types.AccessRead},
+			}, grants)
+
+			require.NoError(t, store.DeleteGrant("user1", "user2"))
+			_, err = store.GetGrant("user1", "user2")
+			assert.ErrorIs(t, err, ErrGrantNotFound)
+		})
+	}
+}
+
+func TestStore_Persistence(t *testing.T) {
+	tests := []struct {
+		name string
+		open func(t *testing.T, path string) EventStore
+	}{
+		{
+			name: "file",
+			open: func(t *testing.T, path string) EventStore {
+				store, err := NewFileStore(path, 2)
+				require.NoError(t, err)
+				return store
+			},
+		},
+		{
+			name: "sqlite",
+			open: func(t *testing.T, path string) EventStore {
+				store, err := NewSQLiteStore(filepath.Join(path, "calendar.db"))
+				require.NoError(t, err)
+				return store
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := t.TempDir()
+
+			service := NewService(tt.open(t, path))
+			first, err := service.CreateEvent("user1", "2023-12-31", "Новый год")
+			require.NoError(t, err)
+			second, err := service.CreateEvent("user1", "2024-01-01", "Первое января")
+			require.NoError(t, err)
+			_, err = service.UpdateEvent(first.ID, "user1", "2023-12-30", "Перенесено")
+			require.NoError(t, err)
+			require.NoError(t, service.DeleteEvent(second.ID, "user1"))
+			_, err = service.SetUserTimeZone("user1", "Europe/Moscow")
+			require.NoError(t, err)
+			require.NoError(t, service.store.MarkReminderSent("reminder", time.Date(2023, 12, 29, 23, 0, 0, 0, time.UTC)))
+			_, err = service.ShareCalendar("user1", "user2", types.AccessRead)
+			require.NoError(t, err)
+			_, err = service.ShareCalendar("user1", "user3", types.AccessWrite)
+			require.NoError(t, err)
+			require.NoError(t, service.UnshareCalendar("user1", "user3"))
+			require.NoError(t, service.store.Close())
+
+			reopened := tt.open(t, path)
+			defer reopened.Close()
+
+			events, err := reopened.ListByUser("user1")
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+			assert.Equal(t, first.ID, events[0].ID)
+			assert.Equal(t, "Перенесено", events[0].Text)
+			assert.True(t, events[0].Date.Equal(time.Date(2023, 12, 30, 0, 0, 0, 0, time.UTC)))
+
+			settings, err := reopened.GetSettings("user1")
+			require.NoError(t, err)
+			assert.Equal(t, "Europe/Moscow", settings.TimeZone)
+
+			sent, err := reopened.ReminderSent("reminder")
+			require.NoError(t, err)
+			assert.True(t, sent)
+
+			grants, err := reopened.ListGrants("user1")
+			require.NoError(t, err)
+			assert.Equal(t, []*types.Grant{{OwnerID: "user1", GranteeID: "user2", Access: types.AccessRead}}, grants)
+		})
+	}
+}
+
+func TestFileStore_ReplaysWALWithoutClose(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewFileStore(dir, 0)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(&types.Event{ID: "a", UserID: "user1", Text: "Первое"}))
+	require.NoError(t, store.Save(&types.Event{ID: "b", UserID: "user1", Text: "Второе"}))
+	require.NoError(t, store.Delete("a"))
+
+	// Имитируем падение процесса во время записи: журнал без Close и с недописанной строкой
+	require.NoError(t, store.wal.Close())
+	wal, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = wal.WriteString(`{"op":"save","event":{"id":"c"`)
+	require.NoError(t, err)
+	require.NoError(t, wal.Close())
+
+	reopened, err := NewFileStore(dir, 0)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	events, err := reopened.ListByUser("user1")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "b", events[0].ID)
+
+	require.NoError(t, reopened.Save(&types.Event{ID: "c", UserID: "user1", Text: "Третье"}))
+	events, err = reopened.ListByUser("user1")
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+}
+
+func TestFileStore_Snapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewFileStore(dir, 2)
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.Save(&types.Event{ID: "a", UserID: "user1", Text: "Первое"}))
+	require.NoError(t, store.Save(&types.Event{ID: "b", UserID: "user1", Text: "Второе"}))
+
+	_, err = os.Stat(filepath.Join(dir, snapshotFileName))
+	require.NoError(t, err, "после snapshotEvery записей журнал сворачивается в снимок")
+
+	info, err := os.Stat(filepath.Join(dir, walFileName))
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+}
+
+func TestFileStore_LoadsLegacySnapshot(t *testing.T) {
+	dir := t.TempDir()
+	legacy := `[{"id":"a","user_id":"user1","date":"2023-12-31T00:00:00Z","text":"Новый год"}]`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, snapshotFileName), []byte(legacy), 0o644))
+
+	store, err := NewFileStore(dir, 0)
+	require.NoError(t, err)
+	defer store.Close()
+
+	events, err := store.ListByUser("user1")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "Новый год", events[0].Text)
+}
@@ -0,0 +1,263 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"calendar/internal/types"
+)
+
+// defaultTimeZone - часовой пояс пользователя, который его еще не выбрал
+const defaultTimeZone = "UTC"
+
+// locations кэширует часовые пояса: time.LoadLocation при каждом вызове читает базу tzdata
+var locations sync.Map
+
+// GetUserSettings возвращает настройки пользователя, для нового пользователя - настройки по умолчанию
+func (s *Service) GetUserSettings(userID string) (*types.UserSettings, error) {
+	if userID == "" {
+		return nil, errors.New("user_id не может быть пустым")
+	}
+
+	settings, err := s.store.GetSettings(userID)
+	if errors.Is(err, ErrSettingsNotFound) {
+		return &types.UserSettings{UserID: userID, TimeZone: defaultTimeZone}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// SetUserTimeZone задает часовой пояс IANA, в котором считаются границы дня, недели и месяца пользователя
+// и время новых событий без явного пояса. Пустая строка возвращает пояс по умолчанию (UTC)
+func (s *Service) SetUserTimeZone(userID, timeZone string) (*types.UserSettings, error) {
+	if timeZone == "" {
+		timeZone = defaultTimeZone
+	}
+	if _, err := loadLocation(timeZone); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	settings, err := s.GetUserSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings.TimeZone = timeZone
+	if err := s.store.SaveSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// userTimeZone возвращает часовой пояс пользователя
+func (s *Service) userTimeZone(userID string) (string, error) {
+	settings, err := s.GetUserSettings(userID)
+	if err != nil {
+		return "", err
+	}
+	return settings.TimeZone, nil
+}
+
+// userLocation возвращает часовой пояс пользователя для вычисления границ дня, недели и месяца
+func (s *Service) userLocation(userID string) (*time.Location, error) {
+	timeZone, err := s.userTimeZone(userID)
+	if err != nil {
+		return nil, err
+	}
+	return loadLocation(timeZone)
+}
+
+// applyTime задает событию время из запроса. defaultZone - пояс для событий со временем без TimeZone.
+// Дата без времени создает событие на весь день, а существующее событие со временем
+// переносит на эту дату с сохранением времени начала и длительности
+func applyTime(event *types.Event, when types.EventTime, defaultZone string) error {
+	normalizeEvent(event)
+
+	existingTimed := !event.Date.IsZero() && !event.AllDay
+	if !when.AllDay && (when.Start != "" || existingTimed) {
+		return applyTimed(event, when, defaultZone)
+	}
+	return applyAllDay(event, when)
+}
+
+func applyAllDay(event *types.Event, when types.EventTime) error {
+	value := when.Start
+	if value == "" {
+		value = when.Date
+	}
+	start, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return fmt.Errorf("некорректный формат даты: %v", err)
+	}
+	if when.Duration != "" {
+		return errors.New("длительность задается только для событий со временем, для события на весь день укажите end")
+	}
+
+	lastDay := start
+	switch {
+	case when.End != "":
+		lastDay, err = time.Parse("2006-01-02", when.End)
+		if err != nil {
+			return fmt.Errorf("некорректный формат даты окончания: %v", err)
+		}
+		if lastDay.Before(start) {
+			return errors.New("событие не может закончиться раньше, чем началось")
+		}
+	case event.AllDay:
+		// При переносе многодневное событие сохраняет количество дней
+		days := int(event.End.Sub(event.Date).Hours() / 24)
+		lastDay = start.AddDate(0, 0, days-1)
+	}
+
+	event.Date = start
+	event.End = lastDay.AddDate(0, 0, 1)
+	event.TimeZone = ""
+	event.AllDay = true
+	return nil
+}
+
+func applyTimed(event *types.Event, when types.EventTime, defaultZone string) error {
+	existingTimed := !event.Date.IsZero() && !event.AllDay
+
+	zone := when.TimeZone
+	if zone == "" && existingTimed {
+		zone = event.TimeZone
+	}
+	if zone == "" {
+		zone = defaultZone
+	}
+	location, err := loadLocation(zone)
+	if err != nil {
+		return err
+	}
+
+	var start time.Time
+	if when.Start != "" {
+		start, err = parseDateTime(when.Start, location)
+		if err != nil {
+			return fmt.Errorf("некорректное время начала: %v", err)
+		}
+	} else {
+		day, err := time.Parse("2006-01-02", when.Date)
+		if err != nil {
+			return fmt.Errorf("некорректный формат даты: %v", err)
+		}
+		// Событие переносится на другой день в то же время по местным часам
+		hh, mm, ss := event.Date.Clock()
+		start = time.Date(day.Year(), day.Month(), day.Day(), hh, mm, ss, 0, location)
+	}
+
+	end := start
+	switch {
+	case when.End != "" && when.Duration != "":
+		return errors.New("укажите либо end, либо duration")
+	case when.End != "":
+		end, err = parseDateTime(when.End, location)
+		if err != nil {
+			return fmt.Errorf("некорректное время окончания: %v", err)
+		}
+	case when.Duration != "":
+		duration, err := time.ParseDuration(when.Duration)
+		if err != nil {
+			return fmt.Errorf("некорректная длительность: %v", err)
+		}
+		end = start.Add(duration)
+	case existingTimed:
+		end = start.Add(event.End.Sub(event.Date))
+	}
+	if end.Before(start) {
+		return errors.New("событие не может закончиться раньше, чем началось")
+	}
+
+	event.Date = start
+	event.End = end
+	event.TimeZone = zone
+	event.AllDay = false
+	return nil
+}
+
+// parseDateTime разбирает время по местным часам в location или время RFC 3339 с явным смещением
+func parseDateTime(value string, location *time.Location) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ожидается 2006-01-02T15:04 или RFC 3339, получено %q", value)
+	}
+	return t.In(location), nil
+}
+
+// normalizeEvent дополняет события, сохраненные до появления времени окончания:
+// они задавались только датой и считаются событиями на весь день
+func normalizeEvent(event *types.Event) {
+	if event.End.IsZero() && !event.Date.IsZero() {
+		event.AllDay = true
+		event.End = event.Date.AddDate(0, 0, 1)
+	}
+}
+
+// localize переводит начало и конец события в его часовой пояс.
+// Хранилища восстанавливают время с фиксированным смещением, а повторениям серии нужны правила перехода на летнее время
+func localize(event *types.Event) error {
+	normalizeEvent(event)
+	if event.AllDay || event.TimeZone == "" {
+		return nil
+	}
+
+	location, err := loadLocation(event.TimeZone)
+	if err != nil {
+		return err
+	}
+	event.Date = event.Date.In(location)
+	event.End = event.End.In(location)
+	return nil
+}
+
+// overlaps сообщает, что событие [start, end) пересекается с интервалом [from, to).
+// Событие нулевой длительности пересекается, если начинается внутри интервала
+func overlaps(start, end, from, to time.Time) bool {
+	return start.Before(to) && (end.After(from) || !start.Before(from))
+}
+
+// eventWindow возвращает интервал выборки для события. События на весь день не привязаны к часовому поясу,
+// поэтому сравниваются с датами границ интервала в поясе пользователя
+func eventWindow(event *types.Event, from, to time.Time) (time.Time, time.Time) {
+	if !event.AllDay {
+		return from, to
+	}
+	return floatingDate(from), floatingDate(to)
+}
+
+func floatingDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func loadLocation(name string) (*time.Location, error) {
+	if cached, ok := locations.Load(name); ok {
+		return cached.(*time.Location), nil
+	}
+
+	// Local зависит от настроек сервера, а не пользователя
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("неизвестный часовой пояс %q", name)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("неизвестный часовой пояс %q", name)
+	}
+
+	locations.Store(name, location)
+	return location, nil
+}
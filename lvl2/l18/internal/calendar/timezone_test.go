@@ -0,0 +1,313 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"calendar/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return location
+}
+
+func TestService_ScheduleEvent(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		moscow := mustLocation(t, "Europe/Moscow")
+
+		event, err := service.ScheduleEvent("user1", types.EventInput{
+			Text: "Встреча",
+			EventTime: types.EventTime{
+				Start:    "2023-12-04T10:00",
+				Duration: "1h30m",
+				TimeZone: "Europe/Moscow",
+			},
+		})
+		require.NoError(t, err)
+		assert.False(t, event.AllDay)
+		assert.Equal(t, "Europe/Moscow", event.TimeZone)
+		assert.True(t, event.Date.Equal(time.Date(2023, 12, 4, 10, 0, 0, 0, moscow)))
+		assert.True(t, event.End.Equal(time.Date(2023, 12, 4, 11, 30, 0, 0, moscow)))
+
+		event, err = service.ScheduleEvent("user1", types.EventInput{
+			Text: "Созвон",
+			EventTime: types.EventTime{
+				Start: "2023-12-04T09:00:00Z",
+				End:   "2023-12-04T13:00:00+03:00",
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, defaultTimeZone, event.TimeZone, "без пояса используется пояс пользователя")
+		assert.True(t, event.End.Equal(time.Date(2023, 12, 4, 10, 0, 0, 0, time.UTC)))
+
+		event, err = service.ScheduleEvent("user1", types.EventInput{
+			Text: "Отпуск",
+			EventTime: types.EventTime{
+				Date:   "2023-12-30",
+				End:    "2024-01-02",
+				AllDay: true,
+			},
+		})
+		require.NoError(t, err)
+		assert.True(t, event.AllDay)
+		assert.Empty(t, event.TimeZone)
+		assert.True(t, event.End.Equal(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)), "конец не включает день после последнего")
+
+		tests := []struct {
+			name   string
+			when   types.EventTime
+			errMsg string
+		}{
+			{
+				name:   "конец раньше начала",
+				when:   types.EventTime{Start: "2023-12-04T10:00", End: "2023-12-04T09:00"},
+				errMsg: "событие не может закончиться раньше, чем началось",
+			},
+			{
+				name:   "конец и длительность",
+				when:   types.EventTime{Start: "2023-12-04T10:00", End: "2023-12-04T11:00", Duration: "1h"},
+				errMsg: "укажите либо end, либо duration",
+			},
+			{
+				name:   "неизвестный пояс",
+				when:   types.EventTime{Start: "2023-12-04T10:00", TimeZone: "Mars/Olympus"},
+				errMsg: "неизвестный часовой пояс",
+			},
+			{
+				name:   "некорректное время",
+				when:   types.EventTime{Start: "завтра утром"},
+				errMsg: "некорректное время начала",
+			},
+			{
+				name:   "длительность события на весь день",
+				when:   types.EventTime{Date: "2023-12-04", Duration: "48h", AllDay: true},
+				errMsg: "длительность задается только для событий со временем",
+			},
+			{
+				name:   "нет даты",
+				when:   types.EventTime{},
+				errMsg: "некорректный формат даты",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := service.ScheduleEvent("user1", types.EventInput{Text: "Ошибка", EventTime: tt.when})
+				assert.ErrorContains(t, err, tt.errMsg)
+			})
+		}
+	})
+}
+
+func TestService_MultiDayEvents(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		_, err := service.ScheduleEvent("user1", types.EventInput{
+			Text:      "Отпуск",
+			EventTime: types.EventTime{Date: "2023-12-30", End: "2024-01-02", AllDay: true},
+		})
+		require.NoError(t, err)
+		_, err = service.ScheduleEvent("user1", types.EventInput{
+			Text:      "Ночная смена",
+			EventTime: types.EventTime{Start: "2024-01-05T22:00", Duration: "8h"},
+		})
+		require.NoError(t, err)
+
+		for _, day := range []string{"2023-12-30", "2023-12-31", "2024-01-01", "2024-01-02"} {
+			events, err := service.GetEventsForDay("user1", day)
+			require.NoError(t, err)
+			assert.Len(t, events, 1, day)
+		}
+		events, err := service.GetEventsForDay("user1", "2024-01-03")
+		require.NoError(t, err)
+		assert.Empty(t, events)
+
+		events, err = service.GetEventsForDay("user1", "2024-01-06")
+		require.NoError(t, err)
+		require.Len(t, events, 1, "событие через полночь идет и на следующий день")
+		assert.Equal(t, "Ночная смена", events[0].Text)
+
+		events, err = service.GetEventsForWeek("user1", "2023-12-27")
+		require.NoError(t, err)
+		assert.Len(t, events, 1)
+		events, err = service.GetEventsForWeek("user1", "2024-01-03")
+		require.NoError(t, err)
+		assert.Len(t, events, 2)
+
+		events, err = service.GetEventsForMonth("user1", "2023-12-01")
+		require.NoError(t, err)
+		assert.Len(t, events, 1)
+		events, err = service.GetEventsForMonth("user1", "2024-01-01")
+		require.NoError(t, err)
+		assert.Len(t, events, 2)
+	})
+}
+
+func TestService_UserTimeZone(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		settings, err := service.GetUserSettings("user1")
+		require.NoError(t, err)
+		assert.Equal(t, defaultTimeZone, settings.TimeZone)
+
+		_, err = service.SetUserTimeZone("user1", "Mars/Olympus")
+		assert.ErrorContains(t, err, "неизвестный часовой пояс")
+		_, err = service.SetUserTimeZone("", "Europe/Moscow")
+		assert.ErrorContains(t, err, "user_id не может быть пустым")
+
+		// 23:30 в Москве 31 декабря - это 20:30 в UTC
+		_, err = service.ScheduleEvent("user1", types.EventInput{
+			Text: "Новый год",
+			EventTime: types.EventTime{
+				Start:    "2023-12-31T23:30",
+				Duration: "2h",
+				TimeZone: "Europe/Moscow",
+			},
+		})
+		require.NoError(t, err)
+		_, err = service.CreateEvent("user1", "2024-01-01", "Первое января")
+		require.NoError(t, err)
+
+		events, err := service.GetEventsForDay("user1", "2024-01-01")
+		require.NoError(t, err)
+		assert.Len(t, events, 1, "в UTC событие заканчивается 31 декабря")
+
+		settings, err = service.SetUserTimeZone("user1", "Europe/Moscow")
+		require.NoError(t, err)
+		assert.Equal(t, "Europe/Moscow", settings.TimeZone)
+
+		events, err = service.GetEventsForDay("user1", "2024-01-01")
+		require.NoError(t, err)
+		assert.Len(t, events, 2, "по Москве событие продолжается 1 января")
+
+		// Событие на весь день попадает в свою дату в любом поясе
+		_, err = service.SetUserTimeZone("user1", "America/Los_Angeles")
+		require.NoError(t, err)
+		events, err = service.GetEventsForDay("user1", "2024-01-01")
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, "Первое января", events[0].Text)
+
+		// Новые события без пояса создаются в поясе пользователя
+		event, err := service.ScheduleEvent("user1", types.EventInput{
+			Text:      "Завтрак",
+			EventTime: types.EventTime{Start: "2024-01-02T08:00"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "America/Los_Angeles", event.TimeZone)
+
+		settings, err = service.SetUserTimeZone("user1", "")
+		require.NoError(t, err)
+		assert.Equal(t, defaultTimeZone, settings.TimeZone)
+	})
+}
+
+func TestService_RescheduleEvent(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		berlin := mustLocation(t, "Europe/Berlin")
+
+		event, err := service.ScheduleEvent("user1", types.EventInput{
+			Text: "Встреча",
+			EventTime: types.EventTime{
+				Start:    "2024-03-29T10:00",
+				Duration: "1h",
+				TimeZone: "Europe/Berlin",
+			},
+		})
+		require.NoError(t, err)
+
+		// Перенос через переход на летнее время сохраняет время по местным часам
+		moved, err := service.UpdateEvent(event.ID, "user1", "2024-04-02", "Встреча")
+		require.NoError(t, err)
+		assert.True(t, moved.Date.Equal(time.Date(2024, 4, 2, 10, 0, 0, 0, berlin)))
+		assert.Equal(t, time.Hour, moved.End.Sub(moved.Date))
+
+		allDay, err := service.RescheduleEvent(event.ID, "user1", "Выходной", types.EventTime{Date: "2024-04-03", AllDay: true})
+		require.NoError(t, err)
+		assert.True(t, allDay.AllDay)
+		assert.Empty(t, allDay.TimeZone)
+
+		// Многодневное событие на весь день при переносе сохраняет количество дней
+		trip, err := service.ScheduleEvent("user1", types.EventInput{
+			Text:      "Поездка",
+			EventTime: types.EventTime{Date: "2024-04-10", End: "2024-04-12", AllDay: true},
+		})
+		require.NoError(t, err)
+		trip, err = service.UpdateEvent(trip.ID, "user1", "2024-04-20", "Поездка")
+		require.NoError(t, err)
+		assert.True(t, trip.End.Equal(time.Date(2024, 4, 23, 0, 0, 0, 0, time.UTC)))
+	})
+}
+
+func TestService_TimedRecurringEvent(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		berlin := mustLocation(t, "Europe/Berlin")
+
+		series, err := service.ScheduleEvent("user1", types.EventInput{
+			Text: "Планерка",
+			EventTime: types.EventTime{
+				Start:    "2024-03-18T10:00",
+				Duration: "30m",
+				TimeZone: "Europe/Berlin",
+			},
+			RRule: "FREQ=WEEKLY;COUNT=3",
+		})
+		require.NoError(t, err)
+
+		events, err := service.GetEventsForMonth("user1", "2024-03-01")
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		for _, event := range events {
+			local := event.Date.In(berlin)
+			assert.Equal(t, 10, local.Hour(), "повторение после перехода на летнее время остается в 10:00")
+			assert.Equal(t, 30*time.Minute, event.End.Sub(event.Date))
+		}
+
+		override, err := service.UpdateOccurrence(series.ID, "user1", "2024-03-25", "2024-03-26", "Планерка во вторник")
+		require.NoError(t, err)
+		assert.True(t, override.Date.Equal(time.Date(2024, 3, 26, 10, 0, 0, 0, berlin)))
+		assert.Equal(t, 30*time.Minute, override.End.Sub(override.Date))
+
+		// Ночное повторение попадает и в следующий день
+		night, err := service.ScheduleEvent("user2", types.EventInput{
+			Text: "Бэкап",
+			EventTime: types.EventTime{
+				Start:    "2024-01-01T23:00",
+				Duration: "2h",
+			},
+			RRule: "FREQ=DAILY;COUNT=2",
+		})
+		require.NoError(t, err)
+		events, err = service.GetEventsForDay("user2", "2024-01-03")
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, night.ID, events[0].SeriesID)
+	})
+}
+
+func TestService_LegacyEvents(t *testing.T) {
+	forEachStore(t, func(t *testing.T, service *Service) {
+		// События, сохраненные до появления времени окончания, считаются событиями на весь день
+		require.NoError(t, service.store.Save(&types.Event{
+			ID:     "legacy",
+			UserID: "user1",
+			Date:   time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+			Text:   "Новый год",
+		}))
+
+		_, err := service.SetUserTimeZone("user1", "America/Los_Angeles")
+		require.NoError(t, err)
+
+		events, err := service.GetEventsForDay("user1", "2023-12-31")
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.True(t, events[0].AllDay)
+
+		events, err = service.GetEventsForDay("user1", "2023-12-30")
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+}
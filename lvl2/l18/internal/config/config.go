@@ -1,33 +1,439 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Хранилища событий
+const (
+	StorageMemory = "memory"
+	StorageFile   = "file"
+	StorageSQLite = "sqlite"
+)
+
+// Способы доставки напоминаний
+const (
+	NotifierLog             = "log"
+	NotifierWebhook         = "webhook"
+	NotifierDelayedNotifier = "delayed-notifier"
+)
+
+// Форматы журнала запросов
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
 )
 
-// Config представляет конфигурацию приложения
+// Config представляет конфигурацию приложения. Ее можно задать YAML-файлом из CALENDAR_CONFIG,
+// переменные окружения имеют приоритет над файлом
 type Config struct {
-	Port int
+	Port      int             `yaml:"port"`
+	Server    ServerConfig    `yaml:"server"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Reminders RemindersConfig `yaml:"reminders"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Log       LogConfig       `yaml:"log"`
+	CORS      CORSConfig      `yaml:"cors"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+}
+
+// ServerConfig представляет настройки HTTP-сервера. Нулевой таймаут отключает ограничение
+type ServerConfig struct {
+	// ReadTimeout - время на чтение всего запроса вместе с телом
+	ReadTimeout time.Duration `yaml:"read_timeout"`
+	// ReadHeaderTimeout - время на чтение заголовков запроса
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	// WriteTimeout - время от конца чтения заголовков до конца записи ответа
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	// IdleTimeout - сколько keep-alive соединение ждет следующего запроса
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+	// ShutdownTimeout - сколько при остановке ждать завершения начатых запросов
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	TLS             TLSConfig     `yaml:"tls"`
+}
+
+// TLSConfig представляет сертификат сервера. Без сертификата сервер работает по HTTP
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+}
+
+// Enabled сообщает, что сервер работает по HTTPS
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// LogConfig представляет настройки журнала запросов
+type LogConfig struct {
+	// Format - text или json: одна JSON-запись на запрос
+	Format string `yaml:"format"`
+}
+
+// CORSConfig представляет настройки запросов из браузера с других доменов
+type CORSConfig struct {
+	// AllowedOrigins - разрешенные источники, * разрешает любой. Пустой список отключает CORS
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	// MaxAge - сколько браузер может кешировать ответ на предварительный запрос
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+// RateLimitConfig представляет ограничение частоты запросов одного клиента
+type RateLimitConfig struct {
+	// RPS - сколько запросов в секунду в среднем разрешено клиенту, 0 отключает ограничение
+	RPS float64 `yaml:"rps"`
+	// Burst - сколько запросов клиент может сделать подряд сверх среднего темпа
+	Burst int `yaml:"burst"`
+	// TrustProxy - определять клиента по первому адресу X-Forwarded-For, если сервер стоит за прокси
+	TrustProxy bool `yaml:"trust_proxy"`
+}
+
+// AuthConfig представляет настройки аутентификации
+type AuthConfig struct {
+	// Secret - секрет подписи токенов доступа. Пустой секрет отключает аутентификацию:
+	// пользователь берется из user_id запроса
+	Secret string `yaml:"secret"`
+}
+
+// StorageConfig представляет настройки хранилища событий
+type StorageConfig struct {
+	// Backend - memory, file или sqlite
+	Backend string `yaml:"backend"`
+	// Path - каталог файлового хранилища или файл базы SQLite
+	Path string `yaml:"path"`
+	// SnapshotEvery - количество записей журнала файлового хранилища, после которого делается снимок
+	SnapshotEvery int `yaml:"snapshot_every"`
+}
+
+// RemindersConfig представляет настройки планировщика напоминаний
+type RemindersConfig struct {
+	// Interval - период проверки наступивших напоминаний
+	Interval time.Duration `yaml:"interval"`
+	// Lookback - на сколько напоминание может опоздать, например пока сервер был остановлен.
+	// Более старые напоминания не отправляются
+	Lookback time.Duration  `yaml:"lookback"`
+	Notifier NotifierConfig `yaml:"notifier"`
+}
+
+// NotifierConfig представляет настройки доставки напоминаний
+type NotifierConfig struct {
+	// Kind - log, webhook или delayed-notifier
+	Kind string `yaml:"kind"`
+	// URL - адрес вебхука или базовый адрес API delayed-notifier
+	URL string `yaml:"url"`
+	// Token - токен доступа, передается в заголовке Authorization: Bearer
+	Token string `yaml:"token"`
+	// Channel - канал доставки delayed-notifier: email или telegram
+	Channel string `yaml:"channel"`
+	// Timeout - таймаут HTTP-запроса
+	Timeout time.Duration `yaml:"timeout"`
 }
 
-// Load загружает конфигурацию из переменных окружения
+// Load загружает конфигурацию: значения по умолчанию, затем YAML-файл из CALENDAR_CONFIG, если он задан,
+// затем переменные окружения
 func Load() (*Config, error) {
-	portStr := os.Getenv("CALENDAR_PORT")
-	if portStr == "" {
-		portStr = "8080"
+	cfg := defaults()
+
+	if path := os.Getenv("CALENDAR_CONFIG"); path != "" {
+		if err := loadFile(path, cfg); err != nil {
+			return nil, err
+		}
 	}
 
-	port, err := strconv.Atoi(portStr)
-	if err != nil {
-		return nil, fmt.Errorf("некорректный порт: %v", err)
+	if portStr := os.Getenv("CALENDAR_PORT"); portStr != "" {
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный порт: %v", err)
+		}
+		cfg.Port = port
 	}
 
-	if port < 1 || port > 65535 {
+	if cfg.Port < 1 || cfg.Port > 65535 {
 		return nil, fmt.Errorf("порт должен быть в диапазоне 1-65535")
 	}
 
+	if secret := os.Getenv("CALENDAR_AUTH_SECRET"); secret != "" {
+		cfg.Auth.Secret = secret
+	}
+
+	if err := loadServer(&cfg.Server); err != nil {
+		return nil, err
+	}
+	if err := loadStorage(&cfg.Storage); err != nil {
+		return nil, err
+	}
+	if err := loadReminders(&cfg.Reminders); err != nil {
+		return nil, err
+	}
+	if err := loadLog(&cfg.Log); err != nil {
+		return nil, err
+	}
+	if err := loadCORS(&cfg.CORS); err != nil {
+		return nil, err
+	}
+	if err := loadRateLimit(&cfg.RateLimit); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// defaults возвращает конфигурацию по умолчанию
+func defaults() *Config {
 	return &Config{
-		Port: port,
-	}, nil
+		Port: 8080,
+		Server: ServerConfig{
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   15 * time.Second,
+		},
+		Storage: StorageConfig{
+			SnapshotEvery: 1000,
+		},
+		Reminders: RemindersConfig{
+			Interval: 30 * time.Second,
+			Lookback: time.Hour,
+			Notifier: NotifierConfig{
+				Timeout: 10 * time.Second,
+			},
+		},
+		CORS: CORSConfig{
+			MaxAge: 10 * time.Minute,
+		},
+	}
+}
+
+// loadFile читает YAML-файл конфигурации поверх cfg. Неизвестные ключи считаются ошибкой, чтобы опечатка
+// в имени настройки не оставляла молча значение по умолчанию
+func loadFile(path string, cfg *Config) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть файл конфигурации: %v", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("некорректный файл конфигурации %s: %v", path, err)
+	}
+	return nil
+}
+
+// namedDuration - длительность, которую переопределяет переменная окружения name
+type namedDuration struct {
+	name   string
+	target *time.Duration
+}
+
+// loadDurations переопределяет длительности значениями переменных окружения. Отрицательные и,
+// если allowZero не задан, нулевые значения считаются ошибкой
+func loadDurations(allowZero bool, durations []namedDuration) error {
+	for _, d := range durations {
+		value := os.Getenv(d.name)
+		if value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 || (duration == 0 && !allowZero) {
+			return fmt.Errorf("некорректный %s: %q", d.name, value)
+		}
+		*d.target = duration
+	}
+	return nil
+}
+
+func loadServer(server *ServerConfig) error {
+	err := loadDurations(true, []namedDuration{
+		{name: "CALENDAR_READ_TIMEOUT", target: &server.ReadTimeout},
+		{name: "CALENDAR_READ_HEADER_TIMEOUT", target: &server.ReadHeaderTimeout},
+		{name: "CALENDAR_WRITE_TIMEOUT", target: &server.WriteTimeout},
+		{name: "CALENDAR_IDLE_TIMEOUT", target: &server.IdleTimeout},
+		{name: "CALENDAR_SHUTDOWN_TIMEOUT", target: &server.ShutdownTimeout},
+	})
+	if err != nil {
+		return err
+	}
+
+	timeouts := []time.Duration{server.ReadTimeout, server.ReadHeaderTimeout, server.WriteTimeout, server.IdleTimeout, server.ShutdownTimeout}
+	for _, timeout := range timeouts {
+		if timeout < 0 {
+			return errors.New("таймауты сервера не могут быть отрицательными")
+		}
+	}
+
+	if certFile := os.Getenv("CALENDAR_TLS_CERT"); certFile != "" {
+		server.TLS.CertFile = certFile
+	}
+	if keyFile := os.Getenv("CALENDAR_TLS_KEY"); keyFile != "" {
+		server.TLS.KeyFile = keyFile
+	}
+	if (server.TLS.CertFile == "") != (server.TLS.KeyFile == "") {
+		return errors.New("для TLS нужны и сертификат CALENDAR_TLS_CERT, и ключ CALENDAR_TLS_KEY")
+	}
+	return nil
+}
+
+func loadLog(logConfig *LogConfig) error {
+	if format := os.Getenv("CALENDAR_LOG_FORMAT"); format != "" {
+		logConfig.Format = format
+	}
+
+	switch logConfig.Format {
+	case "":
+		logConfig.Format = LogFormatText
+	case LogFormatText, LogFormatJSON:
+	default:
+		return fmt.Errorf("неизвестный формат журнала %q, допустимы text, json", logConfig.Format)
+	}
+	return nil
+}
+
+func loadCORS(cors *CORSConfig) error {
+	if originsStr := os.Getenv("CALENDAR_CORS_ORIGINS"); originsStr != "" {
+		cors.AllowedOrigins = nil
+		for _, origin := range strings.Split(originsStr, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				cors.AllowedOrigins = append(cors.AllowedOrigins, origin)
+			}
+		}
+	}
+
+	if maxAgeStr := os.Getenv("CALENDAR_CORS_MAX_AGE"); maxAgeStr != "" {
+		maxAge, err := time.ParseDuration(maxAgeStr)
+		if err != nil || maxAge < 0 {
+			return fmt.Errorf("некорректный CALENDAR_CORS_MAX_AGE: %q", maxAgeStr)
+		}
+		cors.MaxAge = maxAge
+	}
+	return nil
+}
+
+func loadRateLimit(rateLimit *RateLimitConfig) error {
+	if rpsStr := os.Getenv("CALENDAR_RATE_LIMIT"); rpsStr != "" {
+		rps, err := strconv.ParseFloat(rpsStr, 64)
+		if err != nil {
+			return fmt.Errorf("некорректный CALENDAR_RATE_LIMIT: %q", rpsStr)
+		}
+		rateLimit.RPS = rps
+	}
+	if rateLimit.RPS < 0 {
+		return fmt.Errorf("частота запросов не может быть отрицательной: %v", rateLimit.RPS)
+	}
+
+	if burstStr := os.Getenv("CALENDAR_RATE_BURST"); burstStr != "" {
+		burst, err := strconv.Atoi(burstStr)
+		if err != nil || burst < 1 {
+			return fmt.Errorf("некорректный CALENDAR_RATE_BURST: %q", burstStr)
+		}
+		rateLimit.Burst = burst
+	}
+	if rateLimit.Burst < 0 {
+		return fmt.Errorf("число запросов подряд не может быть отрицательным: %d", rateLimit.Burst)
+	}
+	if rateLimit.Burst == 0 {
+		// По умолчанию клиент может сделать подряд столько запросов, сколько ему разрешено за 2 секунды
+		rateLimit.Burst = max(int(2*rateLimit.RPS), 1)
+	}
+
+	if trustStr := os.Getenv("CALENDAR_TRUST_PROXY"); trustStr != "" {
+		trust, err := strconv.ParseBool(trustStr)
+		if err != nil {
+			return fmt.Errorf("некорректный CALENDAR_TRUST_PROXY: %q", trustStr)
+		}
+		rateLimit.TrustProxy = trust
+	}
+	return nil
+}
+
+func loadStorage(storage *StorageConfig) error {
+	if backend := os.Getenv("CALENDAR_STORAGE"); backend != "" {
+		storage.Backend = backend
+	}
+	if path := os.Getenv("CALENDAR_STORAGE_PATH"); path != "" {
+		storage.Path = path
+	}
+
+	switch storage.Backend {
+	case "", StorageMemory:
+		storage.Backend = StorageMemory
+	case StorageFile:
+		if storage.Path == "" {
+			storage.Path = "data"
+		}
+	case StorageSQLite:
+		if storage.Path == "" {
+			storage.Path = "calendar.db"
+		}
+	default:
+		return fmt.Errorf("неизвестное хранилище %q, допустимы memory, file, sqlite", storage.Backend)
+	}
+
+	if snapshotStr := os.Getenv("CALENDAR_SNAPSHOT_EVERY"); snapshotStr != "" {
+		snapshotEvery, err := strconv.Atoi(snapshotStr)
+		if err != nil {
+			return fmt.Errorf("некорректный CALENDAR_SNAPSHOT_EVERY: %q", snapshotStr)
+		}
+		storage.SnapshotEvery = snapshotEvery
+	}
+	if storage.SnapshotEvery < 0 {
+		return fmt.Errorf("число записей журнала до снимка не может быть отрицательным: %d", storage.SnapshotEvery)
+	}
+
+	return nil
+}
+
+func loadReminders(reminders *RemindersConfig) error {
+	notifier := &reminders.Notifier
+	strs := []struct {
+		name   string
+		target *string
+	}{
+		{name: "CALENDAR_NOTIFIER", target: &notifier.Kind},
+		{name: "CALENDAR_NOTIFIER_URL", target: &notifier.URL},
+		{name: "CALENDAR_NOTIFIER_TOKEN", target: &notifier.Token},
+		{name: "CALENDAR_NOTIFIER_CHANNEL", target: &notifier.Channel},
+	}
+	for _, str := range strs {
+		if value := os.Getenv(str.name); value != "" {
+			*str.target = value
+		}
+	}
+
+	err := loadDurations(false, []namedDuration{
+		{name: "CALENDAR_REMINDER_INTERVAL", target: &reminders.Interval},
+		{name: "CALENDAR_REMINDER_LOOKBACK", target: &reminders.Lookback},
+		{name: "CALENDAR_NOTIFIER_TIMEOUT", target: &notifier.Timeout},
+	})
+	if err != nil {
+		return err
+	}
+	if reminders.Interval <= 0 || reminders.Lookback <= 0 || notifier.Timeout <= 0 {
+		return errors.New("период, опоздание и таймаут напоминаний должны быть положительными")
+	}
+
+	switch notifier.Kind {
+	case "", NotifierLog:
+		notifier.Kind = NotifierLog
+	case NotifierWebhook, NotifierDelayedNotifier:
+		if notifier.URL == "" {
+			return fmt.Errorf("для CALENDAR_NOTIFIER=%s нужен CALENDAR_NOTIFIER_URL", notifier.Kind)
+		}
+	default:
+		return fmt.Errorf("неизвестный способ доставки напоминаний %q, допустимы log, webhook, delayed-notifier", notifier.Kind)
+	}
+	if notifier.Channel == "" {
+		notifier.Channel = "email"
+	}
+
+	return nil
 }
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "calendar.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, 15*time.Second, cfg.Server.ReadTimeout)
+	assert.Equal(t, 15*time.Second, cfg.Server.ShutdownTimeout)
+	assert.False(t, cfg.Server.TLS.Enabled())
+	assert.Equal(t, StorageMemory, cfg.Storage.Backend)
+	assert.Equal(t, LogFormatText, cfg.Log.Format)
+	assert.Equal(t, NotifierLog, cfg.Reminders.Notifier.Kind)
+	assert.Equal(t, 1, cfg.RateLimit.Burst)
+}
+
+func TestLoad_File(t *testing.T) {
+	t.Setenv("CALENDAR_CONFIG", writeConfig(t, `
+port: 9090
+server:
+  read_timeout: 5s
+  write_timeout: 0s
+  shutdown_timeout: 1m
+  tls:
+    cert_file: server.crt
+    key_file: server.key
+storage:
+  backend: sqlite
+log:
+  format: json
+rate_limit:
+  rps: 5
+`))
+	// Переменные окружения важнее файла
+	t.Setenv("CALENDAR_PORT", "3000")
+	t.Setenv("CALENDAR_SHUTDOWN_TIMEOUT", "30s")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 3000, cfg.Port)
+	assert.Equal(t, 5*time.Second, cfg.Server.ReadTimeout)
+	assert.Equal(t, 5*time.Second, cfg.Server.ReadHeaderTimeout)
+	assert.Zero(t, cfg.Server.WriteTimeout)
+	assert.Equal(t, 30*time.Second, cfg.Server.ShutdownTimeout)
+	assert.True(t, cfg.Server.TLS.Enabled())
+	assert.Equal(t, "server.key", cfg.Server.TLS.KeyFile)
+	assert.Equal(t, StorageConfig{Backend: StorageSQLite, Path: "calendar.db", SnapshotEvery: 1000}, cfg.Storage)
+	assert.Equal(t, LogFormatJSON, cfg.Log.Format)
+	assert.Equal(t, 10, cfg.RateLimit.Burst)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		file string
+	}{
+		{name: "неизвестный ключ", file: "server:\n  read_timout: 5s\n"},
+		{name: "некорректная длительность", file: "server:\n  idle_timeout: час\n"},
+		{name: "отрицательный таймаут", file: "server:\n  idle_timeout: -1s\n"},
+		{name: "сертификат без ключа", env: map[string]string{"CALENDAR_TLS_CERT": "server.crt"}},
+		{name: "таймаут из окружения", env: map[string]string{"CALENDAR_READ_TIMEOUT": "быстро"}},
+		{name: "хранилище из файла", file: "storage:\n  backend: redis\n"},
+		{name: "порт из файла", file: "port: 70000\n"},
+		{name: "нет файла", env: map[string]string{"CALENDAR_CONFIG": "/nonexistent/calendar.yaml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.file != "" {
+				t.Setenv("CALENDAR_CONFIG", writeConfig(t, tt.file))
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			_, err := Load()
+			assert.Error(t, err)
+		})
+	}
+}
@@ -1,14 +1,24 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"calendar/internal/calendar"
+	"calendar/internal/middleware"
 	"calendar/internal/types"
 )
 
+// maxImportSize ограничивает размер импортируемого файла iCalendar
+const maxImportSize = 10 << 20
+
 // Handler представляет HTTP-обработчики
 type Handler struct {
 	calendarService *calendar.Service
@@ -34,13 +44,38 @@ func (h *Handler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	event, err := h.calendarService.CreateEvent(req.UserID, req.Date, req.Text)
+	owner, err := h.calendarOwner(r, req.UserID, types.AccessWrite)
 	if err != nil {
-		h.sendErrorResponse(w, err.Error(), http.StatusServiceUnavailable)
+		h.sendServiceError(w, err)
+		return
+	}
+
+	service, err := h.conflictService(req.Conflicts)
+	if err != nil {
+		h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := service.ScheduleEvent(owner, types.EventInput{
+		Text:         req.Text,
+		EventTime:    req.EventTime,
+		RRule:        req.RRule,
+		ExDates:      req.ExDates,
+		Reminders:    req.Reminders,
+		EventDetails: req.EventDetails,
+	})
+	if err != nil {
+		h.sendServiceError(w, err)
 		return
 	}
 
-	h.sendSuccessResponse(w, event)
+	result, err := h.withConflicts(event, req.Conflicts)
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	h.sendSuccessResponse(w, result)
 }
 
 // UpdateEvent обрабатывает POST /update_event
@@ -56,13 +91,37 @@ func (h *Handler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	event, err := h.calendarService.UpdateEvent(req.ID, req.UserID, req.Date, req.Text)
+	userID := actor(r, req.UserID)
+	if req.EventTime == (types.EventTime{}) {
+		h.sendErrorResponse(w, "date или start обязательны", http.StatusBadRequest)
+		return
+	}
+
+	service, err := h.conflictService(req.Conflicts)
 	if err != nil {
-		h.sendErrorResponse(w, err.Error(), http.StatusServiceUnavailable)
+		h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	h.sendSuccessResponse(w, event)
+	var event *types.Event
+	if req.OccurrenceDate != "" {
+		event, err = service.RescheduleOccurrence(req.ID, userID, req.OccurrenceDate, req.Text, req.EventTime)
+	} else {
+		// Все поля применяются одним изменением: если одно из них некорректно, событие не меняется
+		event, err = service.PatchEvent(req.ID, userID, "", updatePatch(&req))
+	}
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	result, err := h.withConflicts(event, req.Conflicts)
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	h.sendSuccessResponse(w, result)
 }
 
 // DeleteEvent обрабатывает POST /delete_event
@@ -78,9 +137,16 @@ func (h *Handler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.calendarService.DeleteEvent(req.ID, req.UserID)
+	userID := actor(r, req.UserID)
+
+	var err error
+	if req.OccurrenceDate != "" {
+		err = h.calendarService.DeleteOccurrence(req.ID, userID, req.OccurrenceDate)
+	} else {
+		err = h.calendarService.DeleteEvent(req.ID, userID)
+	}
 	if err != nil {
-		h.sendErrorResponse(w, err.Error(), http.StatusServiceUnavailable)
+		h.sendServiceError(w, err)
 		return
 	}
 
@@ -94,21 +160,113 @@ func (h *Handler) GetEventsForDay(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID := r.URL.Query().Get("user_id")
+	userID, calendars, err := h.calendars(r)
 	date := r.URL.Query().Get("date")
 
 	if userID == "" || date == "" {
 		h.sendErrorResponse(w, "user_id и date обязательны", http.StatusBadRequest)
 		return
 	}
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
 
-	events, err := h.calendarService.GetEventsForDay(userID, date)
+	events, err := h.calendarService.GetEventsForDay(userID, date, calendars...)
 	if err != nil {
-		h.sendErrorResponse(w, err.Error(), http.StatusServiceUnavailable)
+		h.sendServiceError(w, err)
 		return
 	}
 
-	h.sendSuccessResponse(w, events)
+	h.sendSuccessResponse(w, calendar.FilterByCategories(events, queryList(r, "categories")))
+}
+
+const (
+	// defaultPageLimit - размер страницы events_in_range по умолчанию
+	defaultPageLimit = 100
+	// maxPageLimit - наибольший размер страницы events_in_range
+	maxPageLimit = 1000
+)
+
+// GetEventsInRange обрабатывает GET /events_in_range с постраничной выдачей через limit и offset
+func (h *Handler) GetEventsInRange(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	userID, calendars, err := h.calendars(r)
+	from := query.Get("from")
+	to := query.Get("to")
+
+	if userID == "" || from == "" || to == "" {
+		h.sendErrorResponse(w, "user_id, from и to обязательны", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	limit, err := queryInt(query.Get("limit"), defaultPageLimit)
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		h.sendErrorResponse(w, fmt.Sprintf("limit должен быть от 1 до %d", maxPageLimit), http.StatusBadRequest)
+		return
+	}
+	offset, err := queryInt(query.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		h.sendErrorResponse(w, "offset должен быть неотрицательным числом", http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.calendarService.GetEventsInRange(userID, from, to, calendars...)
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	events = calendar.FilterByCategories(events, queryList(r, "categories"))
+	h.sendSuccessResponse(w, paginate(events, offset, limit))
+}
+
+// paginate возвращает страницу из limit событий начиная с offset
+func paginate(events []*types.Event, offset, limit int) *types.EventsPage {
+	start, end, next := pageBounds(len(events), offset, limit)
+	return &types.EventsPage{
+		Events:     append([]*types.Event{}, events[start:end]...),
+		Total:      len(events),
+		NextOffset: next,
+	}
+}
+
+// paginateResults возвращает страницу из limit результатов поиска начиная с offset
+func paginateResults(results []*types.SearchResult, offset, limit int) *types.SearchPage {
+	start, end, next := pageBounds(len(results), offset, limit)
+	return &types.SearchPage{
+		Results:    append([]*types.SearchResult{}, results[start:end]...),
+		Total:      len(results),
+		NextOffset: next,
+	}
+}
+
+// pageBounds возвращает границы страницы из limit элементов начиная с offset в списке из total элементов
+// и offset следующей страницы, если она есть
+func pageBounds(total, offset, limit int) (start, end int, next *int) {
+	start = min(offset, total)
+	end = min(start+limit, total)
+	if end < total {
+		next = &end
+	}
+	return start, end, next
+}
+
+// queryInt разбирает числовой параметр запроса, пустое значение заменяется на fallback
+func queryInt(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
 }
 
 // GetEventsForWeek обрабатывает GET /events_for_week
@@ -118,21 +276,25 @@ func (h *Handler) GetEventsForWeek(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID := r.URL.Query().Get("user_id")
+	userID, calendars, err := h.calendars(r)
 	date := r.URL.Query().Get("date")
 
 	if userID == "" || date == "" {
 		h.sendErrorResponse(w, "user_id и date обязательны", http.StatusBadRequest)
 		return
 	}
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
 
-	events, err := h.calendarService.GetEventsForWeek(userID, date)
+	events, err := h.calendarService.GetEventsForWeek(userID, date, calendars...)
 	if err != nil {
-		h.sendErrorResponse(w, err.Error(), http.StatusServiceUnavailable)
+		h.sendServiceError(w, err)
 		return
 	}
 
-	h.sendSuccessResponse(w, events)
+	h.sendSuccessResponse(w, calendar.FilterByCategories(events, queryList(r, "categories")))
 }
 
 // GetEventsForMonth обрабатывает GET /events_for_month
@@ -142,21 +304,469 @@ func (h *Handler) GetEventsForMonth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID := r.URL.Query().Get("user_id")
+	userID, calendars, err := h.calendars(r)
 	date := r.URL.Query().Get("date")
 
 	if userID == "" || date == "" {
 		h.sendErrorResponse(w, "user_id и date обязательны", http.StatusBadRequest)
 		return
 	}
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
 
-	events, err := h.calendarService.GetEventsForMonth(userID, date)
+	events, err := h.calendarService.GetEventsForMonth(userID, date, calendars...)
 	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	h.sendSuccessResponse(w, calendar.FilterByCategories(events, queryList(r, "categories")))
+}
+
+// GetUserSettings обрабатывает GET /user_settings
+func (h *Handler) GetUserSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := self(r, r.URL.Query().Get("user_id"))
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+	if userID == "" {
+		h.sendErrorResponse(w, "user_id обязателен", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.calendarService.GetUserSettings(userID)
+	if err != nil {
+		h.sendErrorResponse(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	h.sendSuccessResponse(w, settings)
+}
+
+// UpdateUserSettings обрабатывает POST /update_user_settings
+func (h *Handler) UpdateUserSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req types.UpdateSettingsRequest
+	if err := h.parseRequest(r, &req); err != nil {
+		h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := self(r, req.UserID)
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	settings, err := h.calendarService.SetUserTimeZone(userID, req.TimeZone)
+	if err != nil {
+		h.sendErrorResponse(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	h.sendSuccessResponse(w, settings)
+}
+
+// ExportICS обрабатывает GET /events.ics
+func (h *Handler) ExportICS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := h.calendarOwner(r, r.URL.Query().Get("user_id"), types.AccessRead)
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+	if userID == "" {
+		h.sendErrorResponse(w, "user_id обязателен", http.StatusBadRequest)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.calendarService.ExportICS(&buf, userID); err != nil {
 		h.sendErrorResponse(w, err.Error(), http.StatusServiceUnavailable)
 		return
 	}
 
-	h.sendSuccessResponse(w, events)
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="calendar.ics"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// ImportICS обрабатывает POST /import_ics.
+// Календарь передается телом запроса или файлом "file" в multipart/form-data
+func (h *Handler) ImportICS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	// Тело без multipart читается как есть, даже если клиент прислал его как форму
+	var body io.Reader = r.Body
+	userID := r.URL.Query().Get("user_id")
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			h.sendErrorResponse(w, "файл file обязателен", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+		userID = r.FormValue("user_id")
+	}
+
+	userID, err := h.calendarOwner(r, userID, types.AccessWrite)
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+	if userID == "" {
+		h.sendErrorResponse(w, "user_id обязателен", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.calendarService.ImportICS(body, userID)
+	if err != nil {
+		h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.sendSuccessResponse(w, result)
+}
+
+// SearchEvents обрабатывает GET /search_events?user_id=...&q=...: полнотекстовый поиск событий
+// с необязательными интервалом from, to и тегами tags, постранично
+func (h *Handler) SearchEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	userID, calendars, err := h.calendars(r)
+	q := query.Get("q")
+	tags := formList(r, "tags")
+
+	if userID == "" || (q == "" && len(tags) == 0) {
+		h.sendErrorResponse(w, "user_id и q или tags обязательны", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	limit, err := queryInt(query.Get("limit"), defaultPageLimit)
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		h.sendErrorResponse(w, fmt.Sprintf("limit должен быть от 1 до %d", maxPageLimit), http.StatusBadRequest)
+		return
+	}
+	offset, err := queryInt(query.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		h.sendErrorResponse(w, "offset должен быть неотрицательным числом", http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.calendarService.SearchEvents(userID, q, query.Get("from"), query.Get("to"), tags, calendars...)
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	h.sendSuccessResponse(w, paginateResults(results, offset, limit))
+}
+
+// GetFreeBusy обрабатывает GET /free_busy?users=...&from=...&to=...&min_duration=...
+func (h *Handler) GetFreeBusy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	freeBusy, err := h.freeBusy(r)
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	h.sendSuccessResponse(w, freeBusy)
+}
+
+// ShareCalendar обрабатывает POST /share_calendar: выдает доступ к календарю пользователя
+func (h *Handler) ShareCalendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req types.ShareRequest
+	if err := h.parseRequest(r, &req); err != nil {
+		h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := self(r, req.UserID)
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	grant, err := h.calendarService.ShareCalendar(userID, req.GranteeID, req.Access)
+	if err != nil {
+		h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.sendSuccessResponse(w, grant)
+}
+
+// UnshareCalendar обрабатывает POST /unshare_calendar: отзывает доступ к календарю пользователя
+func (h *Handler) UnshareCalendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req types.ShareRequest
+	if err := h.parseRequest(r, &req); err != nil {
+		h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := self(r, req.UserID)
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	if err := h.calendarService.UnshareCalendar(userID, req.GranteeID); err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	h.sendSuccessResponse(w, "Доступ к календарю отозван")
+}
+
+// GetCalendarShares обрабатывает GET /calendar_shares
+func (h *Handler) GetCalendarShares(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := self(r, r.URL.Query().Get("user_id"))
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	shares, err := h.calendarService.ListShares(userID)
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	h.sendSuccessResponse(w, shares)
+}
+
+// RespondInvitation обрабатывает POST /respond_invitation: ответ пользователя на приглашение
+func (h *Handler) RespondInvitation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req types.RSVPRequest
+	if err := h.parseRequest(r, &req); err != nil {
+		h.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := self(r, req.UserID)
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	invitation, err := h.calendarService.RespondToInvitation(req.ID, userID, req.Status)
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	h.sendSuccessResponse(w, invitation)
+}
+
+// GetInvitations обрабатывает GET /invitations?user_id=...&status=...
+func (h *Handler) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := self(r, r.URL.Query().Get("user_id"))
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	invitations, err := h.calendarService.Invitations(userID, r.URL.Query().Get("status"))
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	h.sendSuccessResponse(w, invitations)
+}
+
+// updatePatch возвращает изменение события или серии по запросу обновления v1. Время и текст в v1 обязательны,
+// правило повторения заменяет вместе с ним и исключенные даты, остальные незаданные поля не меняются
+func updatePatch(req *types.UpdateEventRequest) types.EventPatch {
+	patch := types.EventPatch{
+		Text:        &req.Text,
+		EventTime:   req.EventTime,
+		Reminders:   req.Reminders,
+		Title:       req.Title,
+		Description: req.Description,
+		Location:    req.Location,
+		Categories:  req.Categories,
+		Color:       req.Color,
+		Attendees:   req.Attendees,
+	}
+	if req.RRule != nil {
+		patch.RRule = req.RRule
+		patch.ExDates = req.ExDates
+	}
+	return patch
+}
+
+// freeBusy разбирает запрос занятости и возвращает его результат. Границы интервала задаются
+// в часовом поясе пользователя запроса, а без него - первого из users
+func (h *Handler) freeBusy(r *http.Request) (*types.FreeBusy, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	query := r.URL.Query()
+
+	users := formList(r, "users")
+	userID := actor(r, query.Get("user_id"))
+	if userID == "" && len(users) > 0 {
+		userID = users[0]
+	}
+
+	var minFree time.Duration
+	if value := query.Get("min_duration"); value != "" {
+		var err error
+		if minFree, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("некорректный min_duration: %q", value)
+		}
+	}
+
+	return h.calendarService.FreeBusy(userID, users, query.Get("from"), query.Get("to"), minFree)
+}
+
+// conflictService возвращает сервис, сохраняющий события в режиме проверки пересечений mode
+func (h *Handler) conflictService(mode string) (*calendar.Service, error) {
+	switch mode {
+	case "", types.ConflictsAllow, types.ConflictsWarn:
+		return h.calendarService, nil
+	case types.ConflictsReject:
+		return h.calendarService.RejectingConflicts(), nil
+	default:
+		return nil, fmt.Errorf("conflicts должен быть %s, %s или %s", types.ConflictsAllow, types.ConflictsWarn, types.ConflictsReject)
+	}
+}
+
+// withConflicts дополняет сохраненное событие событиями, с которыми оно пересекается, если режим mode - warn
+func (h *Handler) withConflicts(event *types.Event, mode string) (*types.EventWithConflicts, error) {
+	result := &types.EventWithConflicts{Event: event}
+	if mode != types.ConflictsWarn {
+		return result, nil
+	}
+
+	conflicts, err := h.calendarService.Conflicts(event)
+	if err != nil {
+		return nil, err
+	}
+	result.Conflicts = conflicts
+	return result, nil
+}
+
+// actor возвращает пользователя, от имени которого выполняется запрос: владельца токена,
+// а без аутентификации - пользователя из запроса
+func actor(r *http.Request, requested string) string {
+	if userID, ok := middleware.UserID(r.Context()); ok {
+		return userID
+	}
+	return requested
+}
+
+// self возвращает пользователя запроса к его собственным данным. Аутентифицированный пользователь
+// не может указать в запросе другого
+func self(r *http.Request, requested string) (string, error) {
+	userID := actor(r, requested)
+	if requested != "" && requested != userID {
+		return "", fmt.Errorf("%w: данные другого пользователя недоступны", calendar.ErrPermissionDenied)
+	}
+	return userID, nil
+}
+
+// calendarOwner возвращает владельца календаря из запроса (по умолчанию - пользователя запроса)
+// и проверяет, что у пользователя запроса есть к нему доступ access
+func (h *Handler) calendarOwner(r *http.Request, requested, access string) (string, error) {
+	userID := actor(r, requested)
+	if requested == "" || requested == userID {
+		return userID, nil
+	}
+	return requested, h.calendarService.CheckAccess(userID, requested, access)
+}
+
+// calendars возвращает пользователя запроса событий и календари, которые нужно показать:
+// календарь из user_id (по умолчанию - свой) и, с include_shared=true, все доступные ему общие
+func (h *Handler) calendars(r *http.Request) (string, []string, error) {
+	query := r.URL.Query()
+	requested := query.Get("user_id")
+	userID := actor(r, requested)
+	if userID == "" {
+		return "", nil, nil
+	}
+
+	owner := userID
+	if requested != "" {
+		owner = requested
+	}
+	calendars := []string{owner}
+
+	if includeShared, _ := strconv.ParseBool(query.Get("include_shared")); includeShared {
+		shared, err := h.calendarService.SharedCalendars(userID)
+		if err != nil {
+			return userID, nil, err
+		}
+		calendars = append(calendars, userID)
+		calendars = append(calendars, shared...)
+	}
+	return userID, calendars, nil
 }
 
 func (h *Handler) parseRequest(r *http.Request, v interface{}) error {
@@ -177,16 +787,66 @@ func (h *Handler) parseFormToStruct(r *http.Request, v interface{}) error {
 	switch req := v.(type) {
 	case *types.CreateEventRequest:
 		req.UserID = r.FormValue("user_id")
-		req.Date = r.FormValue("date")
+		req.EventTime = formEventTime(r)
 		req.Text = r.FormValue("text")
+		req.RRule = r.FormValue("rrule")
+		req.ExDates = formList(r, "exdates")
+		reminders, err := formInts(r, "reminders")
+		if err != nil {
+			return err
+		}
+		req.Reminders = reminders
+		req.Conflicts = r.FormValue("conflicts")
+		req.Title = r.FormValue("title")
+		req.Description = r.FormValue("description")
+		req.Location = r.FormValue("location")
+		req.Categories = formList(r, "categories")
+		req.Color = r.FormValue("color")
+		req.Attendees = formList(r, "attendees")
 	case *types.UpdateEventRequest:
 		req.ID = r.FormValue("id")
 		req.UserID = r.FormValue("user_id")
-		req.Date = r.FormValue("date")
+		req.EventTime = formEventTime(r)
 		req.Text = r.FormValue("text")
+		req.OccurrenceDate = r.FormValue("occurrence_date")
+		if _, ok := r.Form["rrule"]; ok {
+			rule := r.FormValue("rrule")
+			req.RRule = &rule
+		}
+		req.ExDates = formList(r, "exdates")
+		if _, ok := r.Form["reminders"]; ok {
+			reminders, err := formInts(r, "reminders")
+			if err != nil {
+				return err
+			}
+			req.Reminders = append([]int{}, reminders...)
+		}
+		req.Conflicts = r.FormValue("conflicts")
+		req.Title = formOptional(r, "title")
+		req.Description = formOptional(r, "description")
+		req.Location = formOptional(r, "location")
+		req.Color = formOptional(r, "color")
+		if _, ok := r.Form["categories"]; ok {
+			req.Categories = append([]string{}, formList(r, "categories")...)
+		}
+		if _, ok := r.Form["attendees"]; ok {
+			req.Attendees = append([]string{}, formList(r, "attendees")...)
+		}
 	case *types.DeleteEventRequest:
 		req.ID = r.FormValue("id")
 		req.UserID = r.FormValue("user_id")
+		req.OccurrenceDate = r.FormValue("occurrence_date")
+	case *types.UpdateSettingsRequest:
+		req.UserID = r.FormValue("user_id")
+		req.TimeZone = r.FormValue("time_zone")
+	case *types.ShareRequest:
+		req.UserID = r.FormValue("user_id")
+		req.GranteeID = r.FormValue("grantee_id")
+		req.Access = r.FormValue("access")
+	case *types.RSVPRequest:
+		req.UserID = r.FormValue("user_id")
+		req.ID = r.FormValue("id")
+		req.Status = r.FormValue("status")
 	default:
 		return errors.New("неподдерживаемый тип запроса")
 	}
@@ -194,6 +854,62 @@ func (h *Handler) parseFormToStruct(r *http.Request, v interface{}) error {
 	return nil
 }
 
+// formEventTime читает время события из полей формы
+func formEventTime(r *http.Request) types.EventTime {
+	allDay, _ := strconv.ParseBool(r.FormValue("all_day"))
+	return types.EventTime{
+		Date:     r.FormValue("date"),
+		Start:    r.FormValue("start"),
+		End:      r.FormValue("end"),
+		Duration: r.FormValue("duration"),
+		TimeZone: r.FormValue("time_zone"),
+		AllDay:   allDay || r.FormValue("all_day") == "on",
+	}
+}
+
+// formList читает список из повторяющихся полей формы или из одного поля через запятую
+func formList(r *http.Request, key string) []string {
+	var values []string
+	for _, value := range r.Form[key] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
+// formOptional читает необязательное поле формы: nil, если поля нет, в том числе пустого
+func formOptional(r *http.Request, key string) *string {
+	if _, ok := r.Form[key]; !ok {
+		return nil
+	}
+	value := r.FormValue(key)
+	return &value
+}
+
+// queryList читает список параметра запроса в формате formList
+func queryList(r *http.Request, key string) []string {
+	if err := r.ParseForm(); err != nil {
+		return nil
+	}
+	return formList(r, key)
+}
+
+// formInts читает список целых чисел в формате formList
+func formInts(r *http.Request, key string) ([]int, error) {
+	var values []int
+	for _, item := range formList(r, key) {
+		value, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("некорректное значение %s: %q", key, item)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
 func (h *Handler) sendSuccessResponse(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -207,6 +923,19 @@ func (h *Handler) sendSuccessResponse(w http.ResponseWriter, data interface{}) {
 	}
 }
 
+// sendServiceError отправляет ошибку сервиса: отказ в доступе - 403, пересечение событий - 409, остальные - 503
+func (h *Handler) sendServiceError(w http.ResponseWriter, err error) {
+	if errors.Is(err, calendar.ErrPermissionDenied) {
+		h.sendErrorResponse(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, calendar.ErrConflict) {
+		h.sendErrorResponse(w, err.Error(), http.StatusConflict)
+		return
+	}
+	h.sendErrorResponse(w, err.Error(), http.StatusServiceUnavailable)
+}
+
 func (h *Handler) sendErrorResponse(w http.ResponseWriter, message string, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"calendar/internal/calendar"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateEvent_AppliesAllFieldsAtOnce(t *testing.T) {
+	service := calendar.NewService(calendar.NewMemoryStore())
+	handler := NewHandler(service)
+	event, err := service.CreateEvent("user1", "2024-01-10", "Встреча")
+	require.NoError(t, err)
+
+	update := func(form url.Values) *httptest.ResponseRecorder {
+		form.Set("id", event.ID)
+		form.Set("user_id", "user1")
+		req := httptest.NewRequest(http.MethodPost, "/update_event", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.UpdateEvent(w, req)
+		return w
+	}
+
+	// Одно некорректное поле не дает сохранить и остальные
+	w := update(url.Values{"date": {"2024-01-11"}, "text": {"Перенесено"}, "rrule": {"FREQ=BOGUS"}})
+	assert.NotEqual(t, http.StatusOK, w.Code)
+	w = update(url.Values{"date": {"2024-01-11"}, "text": {"Перенесено"}, "reminders": {"-5"}})
+	assert.NotEqual(t, http.StatusOK, w.Code)
+	w = update(url.Values{"date": {"2024-01-11"}, "text": {"Перенесено"}, "color": {"red"}})
+	assert.NotEqual(t, http.StatusOK, w.Code)
+	stored, err := service.GetEvent(event.ID, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, "Встреча", stored.Text)
+	assert.Equal(t, "2024-01-10", stored.Date.Format("2006-01-02"))
+
+	w = update(url.Values{"text": {"Перенесено"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = update(url.Values{"date": {"2024-01-11"}, "text": {"Перенесено"}, "rrule": {"FREQ=WEEKLY;COUNT=2"}, "reminders": {"15"}, "location": {"Офис"}})
+	require.Equal(t, http.StatusOK, w.Code)
+	stored, err = service.GetEvent(event.ID, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, "Перенесено", stored.Text)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=2", stored.RRule)
+	assert.Equal(t, []int{15}, stored.Reminders)
+	assert.Equal(t, "Офис", stored.Location)
+}
@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"calendar/internal/calendar"
+	"calendar/internal/types"
+)
+
+// Коды ошибок API v2
+const (
+	codeInvalidRequest       = "invalid_request"
+	codeUnsupportedMediaType = "unsupported_media_type"
+	codeForbidden            = "forbidden"
+	codeNotFound             = "not_found"
+	codeConflict             = "conflict"
+	codePreconditionFailed   = "precondition_failed"
+	codeInternal             = "internal_error"
+)
+
+// maxJSONBodySize ограничивает размер тела запросов API v2
+const maxJSONBodySize = 1 << 20
+
+// RegisterV2 регистрирует ресурсный API v2 в mux
+func (h *Handler) RegisterV2(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v2/users/{user}/events", h.createEventV2)
+	mux.HandleFunc("GET /api/v2/users/{user}/events", h.listEventsV2)
+	mux.HandleFunc("GET /api/v2/users/{user}/events/{id}", h.getEventV2)
+	mux.HandleFunc("PUT /api/v2/users/{user}/events/{id}", h.replaceEventV2)
+	mux.HandleFunc("PATCH /api/v2/users/{user}/events/{id}", h.patchEventV2)
+	mux.HandleFunc("DELETE /api/v2/users/{user}/events/{id}", h.deleteEventV2)
+	mux.HandleFunc("PUT /api/v2/users/{user}/events/{id}/rsvp", h.respondInvitationV2)
+	mux.HandleFunc("GET /api/v2/users/{user}/invitations", h.listInvitationsV2)
+	mux.HandleFunc("GET /api/v2/freebusy", h.freeBusyV2)
+	mux.HandleFunc("GET /api/v2/users/{user}/shares", h.listSharesV2)
+	mux.HandleFunc("PUT /api/v2/users/{user}/shares/{grantee}", h.shareCalendarV2)
+	mux.HandleFunc("DELETE /api/v2/users/{user}/shares/{grantee}", h.unshareCalendarV2)
+}
+
+// createEventV2 обрабатывает POST /api/v2/users/{user}/events
+func (h *Handler) createEventV2(w http.ResponseWriter, r *http.Request) {
+	var input types.EventInput
+	if !h.decodeJSONV2(w, r, &input) {
+		return
+	}
+
+	userID, err := h.calendarOwner(r, r.PathValue("user"), types.AccessWrite)
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	mode := r.URL.Query().Get("conflicts")
+	service, err := h.conflictService(mode)
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	event, err := service.ScheduleEvent(userID, input)
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v2/users/%s/events/%s", userID, event.ID))
+	h.sendSavedEventV2(w, event, mode, http.StatusCreated)
+}
+
+// listEventsV2 обрабатывает GET /api/v2/users/{user}/events?from=...&to=... с постраничной выдачей
+// и необязательным фильтром categories
+func (h *Handler) listEventsV2(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from := query.Get("from")
+	to := query.Get("to")
+	if from == "" || to == "" {
+		h.sendAPIError(w, http.StatusBadRequest, codeInvalidRequest, "from и to обязательны")
+		return
+	}
+
+	limit, err := queryInt(query.Get("limit"), defaultPageLimit)
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		h.sendAPIError(w, http.StatusBadRequest, codeInvalidRequest, fmt.Sprintf("limit должен быть от 1 до %d", maxPageLimit))
+		return
+	}
+	offset, err := queryInt(query.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		h.sendAPIError(w, http.StatusBadRequest, codeInvalidRequest, "offset должен быть неотрицательным числом")
+		return
+	}
+
+	owner := r.PathValue("user")
+	userID := actor(r, owner)
+	calendars := []string{owner}
+	if includeShared, _ := strconv.ParseBool(query.Get("include_shared")); includeShared {
+		shared, err := h.calendarService.SharedCalendars(userID)
+		if err != nil {
+			h.sendErrorV2(w, err)
+			return
+		}
+		calendars = append(calendars, userID)
+		calendars = append(calendars, shared...)
+	}
+
+	events, err := h.calendarService.GetEventsInRange(userID, from, to, calendars...)
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	events = calendar.FilterByCategories(events, queryList(r, "categories"))
+	h.sendJSON(w, http.StatusOK, paginate(events, offset, limit))
+}
+
+// getEventV2 обрабатывает GET /api/v2/users/{user}/events/{id}. If-None-Match с текущим ETag дает 304
+func (h *Handler) getEventV2(w http.ResponseWriter, r *http.Request) {
+	event, err := h.pathEvent(r)
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	etag := calendar.EventETag(event)
+	if r.Header.Get("If-None-Match") == etag {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.sendEventV2(w, event, http.StatusOK)
+}
+
+// replaceEventV2 обрабатывает PUT /api/v2/users/{user}/events/{id}
+func (h *Handler) replaceEventV2(w http.ResponseWriter, r *http.Request) {
+	var input types.EventInput
+	if !h.decodeJSONV2(w, r, &input) {
+		return
+	}
+
+	mode := r.URL.Query().Get("conflicts")
+	service, err := h.conflictService(mode)
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	if _, err := h.pathEvent(r); err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	event, err := service.ReplaceEvent(r.PathValue("id"), actor(r, r.PathValue("user")), r.Header.Get("If-Match"), input)
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	h.sendSavedEventV2(w, event, mode, http.StatusOK)
+}
+
+// patchEventV2 обрабатывает PATCH /api/v2/users/{user}/events/{id}
+func (h *Handler) patchEventV2(w http.ResponseWriter, r *http.Request) {
+	var patch types.EventPatch
+	if !h.decodeJSONV2(w, r, &patch) {
+		return
+	}
+
+	mode := r.URL.Query().Get("conflicts")
+	service, err := h.conflictService(mode)
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	if _, err := h.pathEvent(r); err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	event, err := service.PatchEvent(r.PathValue("id"), actor(r, r.PathValue("user")), r.Header.Get("If-Match"), patch)
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	h.sendSavedEventV2(w, event, mode, http.StatusOK)
+}
+
+// deleteEventV2 обрабатывает DELETE /api/v2/users/{user}/events/{id}
+func (h *Handler) deleteEventV2(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.pathEvent(r); err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	err := h.calendarService.DeleteEventIfMatch(r.PathValue("id"), actor(r, r.PathValue("user")), r.Header.Get("If-Match"))
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondInvitationV2 обрабатывает PUT /api/v2/users/{user}/events/{id}/rsvp: ответ пользователя
+// на приглашение в его календаре
+func (h *Handler) respondInvitationV2(w http.ResponseWriter, r *http.Request) {
+	var input types.RSVPInput
+	if !h.decodeJSONV2(w, r, &input) {
+		return
+	}
+
+	userID, err := self(r, r.PathValue("user"))
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	if _, err := h.pathEvent(r); err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	invitation, err := h.calendarService.RespondToInvitation(r.PathValue("id"), userID, input.Status)
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	h.sendEventV2(w, invitation, http.StatusOK)
+}
+
+// listInvitationsV2 обрабатывает GET /api/v2/users/{user}/invitations?status=...
+func (h *Handler) listInvitationsV2(w http.ResponseWriter, r *http.Request) {
+	userID, err := self(r, r.PathValue("user"))
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	invitations, err := h.calendarService.Invitations(userID, r.URL.Query().Get("status"))
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, invitations)
+}
+
+// freeBusyV2 обрабатывает GET /api/v2/freebusy?users=...&from=...&to=...&min_duration=...
+func (h *Handler) freeBusyV2(w http.ResponseWriter, r *http.Request) {
+	freeBusy, err := h.freeBusy(r)
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, freeBusy)
+}
+
+// listSharesV2 обрабатывает GET /api/v2/users/{user}/shares
+func (h *Handler) listSharesV2(w http.ResponseWriter, r *http.Request) {
+	userID, err := self(r, r.PathValue("user"))
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	shares, err := h.calendarService.ListShares(userID)
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, shares)
+}
+
+// shareCalendarV2 обрабатывает PUT /api/v2/users/{user}/shares/{grantee}
+func (h *Handler) shareCalendarV2(w http.ResponseWriter, r *http.Request) {
+	var input types.ShareInput
+	if !h.decodeJSONV2(w, r, &input) {
+		return
+	}
+
+	userID, err := self(r, r.PathValue("user"))
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	grant, err := h.calendarService.ShareCalendar(userID, r.PathValue("grantee"), input.Access)
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, grant)
+}
+
+// unshareCalendarV2 обрабатывает DELETE /api/v2/users/{user}/shares/{grantee}
+func (h *Handler) unshareCalendarV2(w http.ResponseWriter, r *http.Request) {
+	userID, err := self(r, r.PathValue("user"))
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	if err := h.calendarService.UnshareCalendar(userID, r.PathValue("grantee")); err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeJSONV2 разбирает тело запроса в формате JSON без неизвестных полей.
+// При ошибке отправляет ответ и возвращает false
+// pathEvent возвращает событие {id} из календаря {user} пути. Событие другого календаря не найдено,
+// даже если у пользователя запроса есть к нему доступ
+func (h *Handler) pathEvent(r *http.Request) (*types.Event, error) {
+	owner := r.PathValue("user")
+	event, err := h.calendarService.GetEvent(r.PathValue("id"), actor(r, owner))
+	if err != nil {
+		return nil, err
+	}
+	if event.UserID != owner {
+		return nil, calendar.ErrEventNotFound
+	}
+	return event, nil
+}
+
+func (h *Handler) decodeJSONV2(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		h.sendAPIError(w, http.StatusUnsupportedMediaType, codeUnsupportedMediaType, "ожидается Content-Type: application/json")
+		return false
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJSONBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		h.sendAPIError(w, http.StatusBadRequest, codeInvalidRequest, fmt.Sprintf("некорректный JSON: %v", err))
+		return false
+	}
+	return true
+}
+
+// sendEventV2 отправляет событие с его ETag
+func (h *Handler) sendEventV2(w http.ResponseWriter, event *types.Event, statusCode int) {
+	w.Header().Set("ETag", calendar.EventETag(event))
+	h.sendJSON(w, statusCode, event)
+}
+
+// sendSavedEventV2 отправляет сохраненное событие с его ETag, а в режиме warn - и события, с которыми оно пересекается
+func (h *Handler) sendSavedEventV2(w http.ResponseWriter, event *types.Event, mode string, statusCode int) {
+	result, err := h.withConflicts(event, mode)
+	if err != nil {
+		h.sendErrorV2(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", calendar.EventETag(event))
+	h.sendJSON(w, statusCode, result)
+}
+
+// sendErrorV2 отправляет ошибку сервиса с кодом, соответствующим ее виду.
+// Ошибки без вида - это ошибки во входных данных
+func (h *Handler) sendErrorV2(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, calendar.ErrEventNotFound), errors.Is(err, calendar.ErrGrantNotFound):
+		h.sendAPIError(w, http.StatusNotFound, codeNotFound, err.Error())
+	case errors.Is(err, calendar.ErrPermissionDenied):
+		h.sendAPIError(w, http.StatusForbidden, codeForbidden, err.Error())
+	case errors.Is(err, calendar.ErrConflict):
+		h.sendAPIError(w, http.StatusConflict, codeConflict, err.Error())
+	case errors.Is(err, calendar.ErrPreconditionFailed):
+		h.sendAPIError(w, http.StatusPreconditionFailed, codePreconditionFailed, err.Error())
+	case errors.Is(err, calendar.ErrStorage):
+		h.sendAPIError(w, http.StatusInternalServerError, codeInternal, err.Error())
+	default:
+		h.sendAPIError(w, http.StatusBadRequest, codeInvalidRequest, err.Error())
+	}
+}
+
+func (h *Handler) sendAPIError(w http.ResponseWriter, statusCode int, code, message string) {
+	h.sendJSON(w, statusCode, types.ErrorResponse{
+		Error: types.APIError{Code: code, Message: message},
+	})
+}
+
+func (h *Handler) sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
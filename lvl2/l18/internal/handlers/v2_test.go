@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"calendar/internal/auth"
+	"calendar/internal/calendar"
+	"calendar/internal/middleware"
+	"calendar/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newV2Server(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	NewHandler(calendar.NewService(calendar.NewMemoryStore())).RegisterV2(mux)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newAuthServer запускает API v2 и события на день за AuthMiddleware и возвращает токены пользователей
+func newAuthServer(t *testing.T, users ...string) (*httptest.Server, map[string]map[string]string) {
+	handler := NewHandler(calendar.NewService(calendar.NewMemoryStore()))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events_for_day", handler.GetEventsForDay)
+	handler.RegisterV2(mux)
+
+	tokens := auth.NewTokens("secret")
+	server := httptest.NewServer(middleware.NewAuth(tokens).AuthMiddleware(mux))
+	t.Cleanup(server.Close)
+
+	headers := make(map[string]map[string]string, len(users))
+	for _, user := range users {
+		headers[user] = map[string]string{"Authorization": "Bearer " + tokens.Issue(user, time.Hour)}
+	}
+	return server, headers
+}
+
+func doV2(t *testing.T, method, url, body string, header map[string]string) *http.Response {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func decodeV2[T any](t *testing.T, resp *http.Response) T {
+	var v T
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&v))
+	return v
+}
+
+func TestV2_EventLifecycle(t *testing.T) {
+	server := newV2Server(t)
+	events := server.URL + "/api/v2/users/user1/events"
+
+	resp := doV2(t, http.MethodPost, events, `{"text": "Встреча", "start": "2024-01-10T10:00:00Z", "duration": "1h"}`, nil)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := decodeV2[types.Event](t, resp)
+	etag := resp.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "/api/v2/users/user1/events/"+created.ID, resp.Header.Get("Location"))
+
+	resp = doV2(t, http.MethodGet, events+"/"+created.ID, "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, etag, resp.Header.Get("ETag"))
+
+	resp = doV2(t, http.MethodGet, events+"/"+created.ID, "", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	resp = doV2(t, http.MethodPatch, events+"/"+created.ID, `{"text": "Встреча перенесена", "date": "2024-01-11"}`, map[string]string{"If-Match": etag})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	patched := decodeV2[types.Event](t, resp)
+	assert.Equal(t, "Встреча перенесена", patched.Text)
+	newETag := resp.Header.Get("ETag")
+	assert.NotEqual(t, etag, newETag)
+
+	// Изменение по устаревшему ETag отклоняется
+	resp = doV2(t, http.MethodPut, events+"/"+created.ID, `{"text": "Другое", "date": "2024-01-12"}`, map[string]string{"If-Match": etag})
+	require.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	assert.Equal(t, "precondition_failed", decodeV2[types.ErrorResponse](t, resp).Error.Code)
+
+	resp = doV2(t, http.MethodPut, events+"/"+created.ID, `{"text": "Другое", "date": "2024-01-12"}`, map[string]string{"If-Match": newETag})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, decodeV2[types.Event](t, resp).AllDay)
+
+	resp = doV2(t, http.MethodGet, events+"?from=2024-01-01&to=2024-02-01&limit=1", "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	page := decodeV2[types.EventsPage](t, resp)
+	assert.Equal(t, 1, page.Total)
+	require.Len(t, page.Events, 1)
+	assert.Nil(t, page.NextOffset)
+
+	resp = doV2(t, http.MethodDelete, events+"/"+created.ID, "", nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp = doV2(t, http.MethodGet, events+"/"+created.ID, "", nil)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "not_found", decodeV2[types.ErrorResponse](t, resp).Error.Code)
+}
+
+func TestV2_Errors(t *testing.T) {
+	server := newV2Server(t)
+	events := server.URL + "/api/v2/users/user1/events"
+
+	resp := doV2(t, http.MethodPost, events, `{"text": "Встреча", "date": "2024-01-10"}`, nil)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := decodeV2[types.Event](t, resp)
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		header map[string]string
+		status int
+		code   string
+	}{
+		{"чужое событие", http.MethodPatch, server.URL + "/api/v2/users/user2/events/" + created.ID, `{"text": "Чужое"}`, nil, http.StatusForbidden, "forbidden"},
+		{"пустой текст", http.MethodPost, events, `{"text": "", "date": "2024-01-10"}`, nil, http.StatusBadRequest, "invalid_request"},
+		{"неизвестное поле", http.MethodPost, events, `{"text": "Встреча", "day": "2024-01-10"}`, nil, http.StatusBadRequest, "invalid_request"},
+		{"не JSON", http.MethodPost, events, `text=Встреча`, map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, http.StatusUnsupportedMediaType, "unsupported_media_type"},
+		{"интервал без to", http.MethodGet, events + "?from=2024-01-01", "", nil, http.StatusBadRequest, "invalid_request"},
+		{"некорректный limit", http.MethodGet, events + "?from=2024-01-01&to=2024-02-01&limit=0", "", nil, http.StatusBadRequest, "invalid_request"},
+		{"нет события", http.MethodDelete, events + "/missing", "", nil, http.StatusNotFound, "not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doV2(t, tt.method, tt.url, tt.body, tt.header)
+			require.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.code, decodeV2[types.ErrorResponse](t, resp).Error.Code)
+		})
+	}
+}
+
+func TestV2_AuthAndSharing(t *testing.T) {
+	server, as := newAuthServer(t, "alice", "bob")
+	aliceEvents := server.URL + "/api/v2/users/alice/events"
+
+	resp := doV2(t, http.MethodGet, aliceEvents+"?from=2024-01-01&to=2024-02-01", "", nil)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "unauthorized", decodeV2[types.ErrorResponse](t, resp).Error.Code)
+
+	resp = doV2(t, http.MethodPost, aliceEvents, `{"text": "Встреча", "date": "2024-01-10"}`, as["alice"])
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := decodeV2[types.Event](t, resp)
+
+	// Без доступа bob не видит и не меняет календарь alice, даже указав ее в пути
+	resp = doV2(t, http.MethodGet, aliceEvents+"/"+created.ID, "", as["bob"])
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = doV2(t, http.MethodPost, aliceEvents, `{"text": "Чужое", "date": "2024-01-10"}`, as["bob"])
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = doV2(t, http.MethodPut, server.URL+"/api/v2/users/bob/shares/alice", `{"access": "write"}`, as["alice"])
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = doV2(t, http.MethodPut, server.URL+"/api/v2/users/alice/shares/bob", `{"access": "read"}`, as["alice"])
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = doV2(t, http.MethodGet, server.URL+"/api/v2/users/bob/events?from=2024-01-01&to=2024-02-01&include_shared=true", "", as["bob"])
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	page := decodeV2[types.EventsPage](t, resp)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, "alice", page.Events[0].Owner)
+
+	resp = doV2(t, http.MethodGet, server.URL+"/events_for_day?date=2024-01-10&include_shared=true", "", as["bob"])
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, decodeV2[struct{ Result []*types.Event }](t, resp).Result, 1)
+
+	resp = doV2(t, http.MethodPatch, aliceEvents+"/"+created.ID, `{"text": "Изменено"}`, as["bob"])
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = doV2(t, http.MethodPut, server.URL+"/api/v2/users/alice/shares/bob", `{"access": "write"}`, as["alice"])
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = doV2(t, http.MethodPatch, aliceEvents+"/"+created.ID, `{"text": "Изменено"}`, as["bob"])
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "alice", decodeV2[types.Event](t, resp).UserID)
+
+	resp = doV2(t, http.MethodGet, server.URL+"/api/v2/users/bob/shares", "", as["bob"])
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	shares := decodeV2[types.CalendarShares](t, resp)
+	assert.Equal(t, []*types.Grant{{OwnerID: "alice", GranteeID: "bob", Access: types.AccessWrite}}, shares.SharedWithMe)
+
+	resp = doV2(t, http.MethodDelete, server.URL+"/api/v2/users/alice/shares/bob", "", as["alice"])
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp = doV2(t, http.MethodDelete, server.URL+"/api/v2/users/alice/shares/bob", "", as["alice"])
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = doV2(t, http.MethodDelete, aliceEvents+"/"+created.ID, "", as["bob"])
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestV2_ConflictsAndFreeBusy(t *testing.T) {
+	server := newV2Server(t)
+	events := server.URL + "/api/v2/users/user1/events"
+
+	resp := doV2(t, http.MethodPost, events, `{"text": "Встреча", "start": "2024-01-10T10:00:00Z", "duration": "1h"}`, nil)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := decodeV2[types.Event](t, resp)
+
+	resp = doV2(t, http.MethodPost, events+"?conflicts=reject", `{"text": "Пересечение", "start": "2024-01-10T10:30:00Z", "duration": "1h"}`, nil)
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Equal(t, "conflict", decodeV2[types.ErrorResponse](t, resp).Error.Code)
+
+	resp = doV2(t, http.MethodPost, events+"?conflicts=maybe", `{"text": "Пересечение", "start": "2024-01-10T10:30:00Z", "duration": "1h"}`, nil)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = doV2(t, http.MethodPost, events+"?conflicts=warn", `{"text": "Пересечение", "start": "2024-01-10T10:30:00Z", "duration": "1h"}`, nil)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	warned := decodeV2[types.EventWithConflicts](t, resp)
+	assert.Equal(t, "Пересечение", warned.Text)
+	require.Len(t, warned.Conflicts, 1)
+	assert.Equal(t, created.ID, warned.Conflicts[0].ID)
+
+	resp = doV2(t, http.MethodGet, server.URL+"/api/v2/freebusy?users=user1,user2&from=2024-01-10T09:00:00Z&to=2024-01-10T12:00:00Z&min_duration=30m", "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	freeBusy := decodeV2[types.FreeBusy](t, resp)
+	require.Len(t, freeBusy.Busy["user1"], 1)
+	assert.Empty(t, freeBusy.Busy["user2"])
+	assert.True(t, freeBusy.Busy["user1"][0].End.Equal(time.Date(2024, 1, 10, 11, 30, 0, 0, time.UTC)))
+	require.Len(t, freeBusy.Free, 2)
+	assert.True(t, freeBusy.Free[0].End.Equal(time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)))
+
+	resp = doV2(t, http.MethodGet, server.URL+"/api/v2/freebusy?from=2024-01-10&to=2024-01-11", "", nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestV2_Invitations(t *testing.T) {
+	server, as := newAuthServer(t, "alice", "bob")
+
+	resp := doV2(t, http.MethodPost, server.URL+"/api/v2/users/alice/events",
+		`{"text": "Ретро", "date": "2024-01-10", "title": "Ретроспектива", "categories": ["работа"], "color": "#00AA00", "attendees": ["bob"]}`, as["alice"])
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := decodeV2[types.Event](t, resp)
+	assert.Equal(t, "#00aa00", created.Color)
+
+	resp = doV2(t, http.MethodGet, server.URL+"/api/v2/users/alice/events?from=2024-01-01&to=2024-02-01&categories=дом", "", as["alice"])
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, decodeV2[types.EventsPage](t, resp).Events)
+
+	resp = doV2(t, http.MethodGet, server.URL+"/api/v2/users/bob/invitations?status=needs_action", "", as["bob"])
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	invitations := decodeV2[[]*types.Event](t, resp)
+	require.Len(t, invitations, 1)
+	assert.Equal(t, "alice", invitations[0].Organizer)
+	invitationURL := server.URL + "/api/v2/users/bob/events/" + invitations[0].ID
+
+	// Приглашение меняет только организатор, а ответить может только сам участник
+	resp = doV2(t, http.MethodPatch, invitationURL, `{"text": "Изменено"}`, as["bob"])
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = doV2(t, http.MethodPut, invitationURL+"/rsvp", `{"status": "accepted"}`, as["alice"])
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = doV2(t, http.MethodPut, invitationURL+"/rsvp", `{"status": "maybe"}`, as["bob"])
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = doV2(t, http.MethodPut, invitationURL+"/rsvp", `{"status": "accepted"}`, as["bob"])
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = doV2(t, http.MethodGet, server.URL+"/api/v2/users/alice/events/"+created.ID, "", as["alice"])
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []types.Attendee{{UserID: "bob", Status: types.RSVPAccepted}}, decodeV2[types.Event](t, resp).Attendees)
+}
+
+func TestV2_PathOwnerAndIfMatchAny(t *testing.T) {
+	server, as := newAuthServer(t, "alice", "bob")
+
+	resp := doV2(t, http.MethodPost, server.URL+"/api/v2/users/alice/events", `{"text": "Встреча", "date": "2024-01-10"}`, as["alice"])
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := decodeV2[types.Event](t, resp)
+	resp = doV2(t, http.MethodPut, server.URL+"/api/v2/users/alice/shares/bob", `{"access": "write"}`, as["alice"])
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Событие alice не находится по пути календаря bob, хотя у bob есть доступ на запись
+	wrongPath := server.URL + "/api/v2/users/bob/events/" + created.ID
+	resp = doV2(t, http.MethodPut, wrongPath, `{"text": "Другое", "date": "2024-01-12"}`, as["bob"])
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp = doV2(t, http.MethodPatch, wrongPath, `{"text": "Другое"}`, as["bob"])
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp = doV2(t, http.MethodDelete, wrongPath, "", as["bob"])
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	event := server.URL + "/api/v2/users/alice/events/" + created.ID
+	resp = doV2(t, http.MethodPatch, event, `{"text": "Изменено"}`, map[string]string{"Authorization": as["bob"]["Authorization"], "If-Match": "*"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+
+	resp = doV2(t, http.MethodPatch, event, `{"text": "Еще раз"}`, map[string]string{"Authorization": as["alice"]["Authorization"], "If-Match": `"stale", ` + etag})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = doV2(t, http.MethodDelete, event, "", map[string]string{"Authorization": as["alice"]["Authorization"], "If-Match": "*"})
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp = doV2(t, http.MethodDelete, event, "", map[string]string{"Authorization": as["alice"]["Authorization"], "If-Match": "*"})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,444 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets - максимальная длина строки содержимого без переноса (RFC 5545, 3.1)
+const maxLineOctets = 75
+
+// Property представляет свойство компонента: DTSTART;VALUE=DATE:20231231
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Param возвращает значение параметра свойства
+func (p *Property) Param(name string) string {
+	return p.Params[strings.ToUpper(name)]
+}
+
+// Component представляет компонент iCalendar (VCALENDAR, VEVENT и т.д.)
+type Component struct {
+	Name       string
+	Properties []*Property
+	Components []*Component
+}
+
+// NewComponent создает пустой компонент
+func NewComponent(name string) *Component {
+	return &Component{Name: name}
+}
+
+// Get возвращает первое свойство с именем name или nil
+func (c *Component) Get(name string) *Property {
+	for _, property := range c.Properties {
+		if property.Name == name {
+			return property
+		}
+	}
+	return nil
+}
+
+// GetAll возвращает все свойства с именем name
+func (c *Component) GetAll(name string) []*Property {
+	var properties []*Property
+	for _, property := range c.Properties {
+		if property.Name == name {
+			properties = append(properties, property)
+		}
+	}
+	return properties
+}
+
+// Value возвращает значение первого свойства с именем name или пустую строку
+func (c *Component) Value(name string) string {
+	if property := c.Get(name); property != nil {
+		return property.Value
+	}
+	return ""
+}
+
+// Add добавляет свойство с параметрами в формате "ИМЯ", "ЗНАЧЕНИЕ"
+func (c *Component) Add(name, value string, params ...string) {
+	property := &Property{Name: name, Value: value}
+	if len(params) > 0 {
+		property.Params = make(map[string]string, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			property.Params[params[i]] = params[i+1]
+		}
+	}
+	c.Properties = append(c.Properties, property)
+}
+
+// Parse читает поток iCalendar и возвращает корневой компонент VCALENDAR
+func Parse(r io.Reader) (*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var stack []*Component
+	var root *Component
+
+	for number, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		property, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: %v", number+1, err)
+		}
+
+		switch property.Name {
+		case "BEGIN":
+			component := NewComponent(strings.ToUpper(property.Value))
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, component)
+			} else if root != nil {
+				return nil, fmt.Errorf("строка %d: допускается только один корневой компонент", number+1)
+			} else {
+				root = component
+			}
+			stack = append(stack, component)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(property.Value) {
+				return nil, fmt.Errorf("строка %d: непарный END:%s", number+1, property.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("строка %d: свойство %s вне компонента", number+1, property.Name)
+			}
+			current := stack[len(stack)-1]
+			current.Properties = append(current.Properties, property)
+		}
+	}
+
+	if root == nil || root.Name != "VCALENDAR" {
+		return nil, errors.New("ожидается компонент VCALENDAR")
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("компонент %s не закрыт", stack[len(stack)-1].Name)
+	}
+	return root, nil
+}
+
+// Write записывает компонент в формате iCalendar с переносом длинных строк и окончаниями CRLF
+func Write(w io.Writer, c *Component) error {
+	bw := bufio.NewWriter(w)
+	if err := writeComponent(bw, c); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func writeComponent(w *bufio.Writer, c *Component) error {
+	if err := writeLine(w, "BEGIN:"+c.Name); err != nil {
+		return err
+	}
+	for _, property := range c.Properties {
+		if err := writeLine(w, formatProperty(property)); err != nil {
+			return err
+		}
+	}
+	for _, child := range c.Components {
+		if err := writeComponent(w, child); err != nil {
+			return err
+		}
+	}
+	return writeLine(w, "END:"+c.Name)
+}
+
+func formatProperty(p *Property) string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+	for _, name := range sortedKeys(p.Params) {
+		value := p.Params[name]
+		if strings.ContainsAny(value, ";:,") {
+			value = `"` + value + `"`
+		}
+		b.WriteString(";" + name + "=" + value)
+	}
+	b.WriteString(":" + p.Value)
+	return b.String()
+}
+
+// writeLine переносит строку длиннее 75 октетов, не разрывая символы UTF-8
+func writeLine(w *bufio.Writer, line string) error {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, err := w.WriteString(line[:cut] + "\r\n "); err != nil {
+			return err
+		}
+		line = line[cut:]
+		// Продолжение начинается с пробела, который входит в длину строки
+		limit = maxLineOctets - 1
+	}
+	_, err := w.WriteString(line + "\r\n")
+	return err
+}
+
+// unfold читает строки содержимого, склеивая перенесенные (начинающиеся с пробела или табуляции)
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать iCalendar: %v", err)
+	}
+	return lines, nil
+}
+
+// parseLine разбирает строку вида NAME;PARAM=VALUE;PARAM="VA:LUE":value
+func parseLine(line string) (*Property, error) {
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return nil, fmt.Errorf("некорректная строка %q", line)
+	}
+	property := &Property{Name: strings.ToUpper(line[:i])}
+	rest := line[i:]
+
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("некорректный параметр свойства %s", property.Name)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("незакрытая кавычка в параметре %s", name)
+			}
+			value = rest[1 : end+1]
+			rest = rest[end+2:]
+		} else {
+			end := strings.IndexAny(rest, ";:")
+			if end < 0 {
+				return nil, fmt.Errorf("нет значения у свойства %s", property.Name)
+			}
+			value = rest[:end]
+			rest = rest[end:]
+		}
+
+		if property.Params == nil {
+			property.Params = make(map[string]string)
+		}
+		property.Params[name] = value
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return nil, fmt.Errorf("нет значения у свойства %s", property.Name)
+	}
+	property.Value = rest[1:]
+	return property, nil
+}
+
+// EscapeText экранирует значение типа TEXT
+func EscapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// UnescapeText снимает экранирование значения типа TEXT
+func UnescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// ParseDateTime разбирает значение DATE или DATE-TIME с учетом параметров VALUE и TZID.
+// allDay сообщает, что значение задано датой без времени
+func ParseDateTime(p *Property) (t time.Time, allDay bool, err error) {
+	value := p.Value
+	if p.Param("VALUE") == "DATE" || len(value) == len("20060102") {
+		t, err = time.Parse("20060102", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("некорректная дата %q", value)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("некорректное время %q", value)
+		}
+		return t, false, nil
+	}
+
+	location := time.UTC
+	if tzid := p.Param("TZID"); tzid != "" {
+		location, err = time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("неизвестный часовой пояс %q", tzid)
+		}
+	}
+	t, err = time.ParseInLocation("20060102T150405", value, location)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("некорректное время %q", value)
+	}
+	return t, false, nil
+}
+
+// FormatDate форматирует значение типа DATE
+func FormatDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+// FormatUTC форматирует значение типа DATE-TIME в UTC
+func FormatUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// FormatLocal форматирует значение типа DATE-TIME по местным часам t для свойства с TZID
+func FormatLocal(t time.Time) string {
+	return t.Format("20060102T150405")
+}
+
+// FormatDuration форматирует значение типа DURATION: -PT15M, P1D
+func FormatDuration(d time.Duration) string {
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+	b.WriteByte('P')
+
+	if days := d / (24 * time.Hour); days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+		d -= days * 24 * time.Hour
+	}
+	if d == 0 {
+		if b.Len() <= 2 {
+			b.WriteString("T0S")
+		}
+		return b.String()
+	}
+
+	b.WriteByte('T')
+	if hours := d / time.Hour; hours > 0 {
+		fmt.Fprintf(&b, "%dH", hours)
+		d -= hours * time.Hour
+	}
+	if minutes := d / time.Minute; minutes > 0 {
+		fmt.Fprintf(&b, "%dM", minutes)
+		d -= minutes * time.Minute
+	}
+	if seconds := d / time.Second; seconds > 0 {
+		fmt.Fprintf(&b, "%dS", seconds)
+	}
+	return b.String()
+}
+
+// ParseDuration разбирает значение типа DURATION: P1W, P1DT2H30M, -PT15M.
+// День считается равным 24 часам
+func ParseDuration(value string) (time.Duration, error) {
+	invalid := fmt.Errorf("некорректная длительность %q", value)
+
+	s := value
+	sign := time.Duration(1)
+	if strings.HasPrefix(s, "-") {
+		sign = -1
+		s = s[1:]
+	} else {
+		s = strings.TrimPrefix(s, "+")
+	}
+	if !strings.HasPrefix(s, "P") || len(s) == 1 {
+		return 0, invalid
+	}
+	s = s[1:]
+
+	var total time.Duration
+	inTime := false
+	for s != "" {
+		if s[0] == 'T' {
+			if inTime {
+				return 0, invalid
+			}
+			inTime = true
+			s = s[1:]
+			continue
+		}
+
+		digits := 0
+		for digits < len(s) && s[digits] >= '0' && s[digits] <= '9' {
+			digits++
+		}
+		if digits == 0 || digits == len(s) {
+			return 0, invalid
+		}
+		n, err := strconv.Atoi(s[:digits])
+		if err != nil {
+			return 0, invalid
+		}
+		amount := time.Duration(n)
+		unit := s[digits]
+		s = s[digits+1:]
+
+		switch {
+		case !inTime && unit == 'W':
+			total += amount * 7 * 24 * time.Hour
+		case !inTime && unit == 'D':
+			total += amount * 24 * time.Hour
+		case inTime && unit == 'H':
+			total += amount * time.Hour
+		case inTime && unit == 'M':
+			total += amount * time.Minute
+		case inTime && unit == 'S':
+			total += amount * time.Second
+		default:
+			return 0, invalid
+		}
+	}
+	return sign * total, nil
+}
+
+func sortedKeys(params map[string]string) []string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,176 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	input := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:event-1@example.com\r\n" +
+		"DTSTART;TZID=\"Europe/Moscow\":20231231T230000\r\n" +
+		"SUMMARY:Новый год\\, праздник\\; \r\n" +
+		" с продолжением\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	cal, err := Parse(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, "VCALENDAR", cal.Name)
+	assert.Equal(t, "2.0", cal.Value("VERSION"))
+	require.Len(t, cal.Components, 1)
+
+	vevent := cal.Components[0]
+	assert.Equal(t, "event-1@example.com", vevent.Value("UID"))
+	assert.Equal(t, "Новый год, праздник; с продолжением", UnescapeText(vevent.Value("SUMMARY")))
+
+	dtstart := vevent.Get("DTSTART")
+	require.NotNil(t, dtstart)
+	assert.Equal(t, "Europe/Moscow", dtstart.Param("tzid"))
+
+	start, allDay, err := ParseDateTime(dtstart)
+	require.NoError(t, err)
+	assert.False(t, allDay)
+	assert.Equal(t, time.Date(2023, 12, 31, 20, 0, 0, 0, time.UTC), start.UTC())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "пустой поток", input: ""},
+		{name: "не VCALENDAR", input: "BEGIN:VEVENT\nEND:VEVENT\n"},
+		{name: "незакрытый компонент", input: "BEGIN:VCALENDAR\nBEGIN:VEVENT\nEND:VCALENDAR\n"},
+		{name: "свойство без значения", input: "BEGIN:VCALENDAR\nVERSION\nEND:VCALENDAR\n"},
+		{name: "незакрытая кавычка", input: "BEGIN:VCALENDAR\nX;A=\"b:c\nEND:VCALENDAR\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestWrite_FoldsLongLines(t *testing.T) {
+	cal := NewComponent("VCALENDAR")
+	vevent := NewComponent("VEVENT")
+	summary := strings.Repeat("Очень длинное описание события ", 10)
+	vevent.Add("SUMMARY", EscapeText(summary))
+	vevent.Add("DTSTART", "20231231", "VALUE", "DATE")
+	cal.Components = append(cal.Components, vevent)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, cal))
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineOctets)
+	}
+	assert.Contains(t, buf.String(), "DTSTART;VALUE=DATE:20231231\r\n")
+
+	parsed, err := Parse(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, summary, UnescapeText(parsed.Components[0].Value("SUMMARY")))
+}
+
+func TestParseDateTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		property Property
+		want     time.Time
+		allDay   bool
+		wantErr  bool
+	}{
+		{
+			name:     "дата",
+			property: Property{Params: map[string]string{"VALUE": "DATE"}, Value: "20231231"},
+			want:     time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+			allDay:   true,
+		},
+		{
+			name:     "UTC",
+			property: Property{Value: "20231231T100000Z"},
+			want:     time.Date(2023, 12, 31, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "плавающее время",
+			property: Property{Value: "20231231T100000"},
+			want:     time.Date(2023, 12, 31, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "неизвестный пояс",
+			property: Property{Params: map[string]string{"TZID": "Mars/Olympus"}, Value: "20231231T100000"},
+			wantErr:  true,
+		},
+		{
+			name:     "мусор",
+			property: Property{Value: "завтра"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, allDay, err := ParseDateTime(&tt.property)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got))
+			assert.Equal(t, tt.allDay, allDay)
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "PT1H30M", want: 90 * time.Minute},
+		{value: "P1DT2H", want: 26 * time.Hour},
+		{value: "P2W", want: 14 * 24 * time.Hour},
+		{value: "-PT15M", want: -15 * time.Minute},
+		{value: "PT45S", want: 45 * time.Second},
+		{value: "P", wantErr: true},
+		{value: "1H", wantErr: true},
+		{value: "P1H", wantErr: true},
+		{value: "PT1D", wantErr: true},
+		{value: "PT1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDuration(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for _, d := range []time.Duration{-15 * time.Minute, 24 * time.Hour, 26*time.Hour + 30*time.Minute, 0, 45 * time.Second} {
+		formatted := FormatDuration(d)
+		parsed, err := ParseDuration(formatted)
+		require.NoError(t, err, formatted)
+		assert.Equal(t, d, parsed, formatted)
+	}
+	assert.Equal(t, "-PT15M", FormatDuration(-15*time.Minute))
+	assert.Equal(t, "-P1D", FormatDuration(-24*time.Hour))
+	assert.Equal(t, "PT0S", FormatDuration(0))
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"calendar/internal/auth"
+)
+
+// userIDKey - ключ пользователя запроса в контексте
+type userIDKey struct{}
+
+// Auth представляет middleware аутентификации по токену доступа
+type Auth struct {
+	tokens *auth.Tokens
+}
+
+// NewAuth создает middleware, проверяющее токены через tokens
+func NewAuth(tokens *auth.Tokens) *Auth {
+	return &Auth{
+		tokens: tokens,
+	}
+}
+
+// AuthMiddleware пропускает только запросы с действительным токеном и кладет пользователя токена в контекст.
+// Токен передается в заголовке Authorization: Bearer или, для подписок на календарь, в параметре access_token
+func (a *Auth) AuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("access_token")
+		if header := r.Header.Get("Authorization"); header != "" {
+			scheme, value, _ := strings.Cut(header, " ")
+			if !strings.EqualFold(scheme, "Bearer") {
+				sendUnauthorized(w, r, "ожидается заголовок Authorization: Bearer")
+				return
+			}
+			token = strings.TrimSpace(value)
+		}
+		if token == "" {
+			sendUnauthorized(w, r, "требуется токен доступа")
+			return
+		}
+
+		userID, err := a.tokens.Verify(token)
+		if err != nil {
+			sendUnauthorized(w, r, err.Error())
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userIDKey{}, userID)))
+	})
+}
+
+// UserID возвращает пользователя, аутентифицированного AuthMiddleware. Без аутентификации ok = false
+func UserID(ctx context.Context) (userID string, ok bool) {
+	userID, ok = ctx.Value(userIDKey{}).(string)
+	return userID, ok
+}
+
+// sendUnauthorized отвечает 401 в формате ошибок API, к которому относится запрос
+func sendUnauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="calendar"`)
+	sendError(w, r, http.StatusUnauthorized, "unauthorized", message)
+}
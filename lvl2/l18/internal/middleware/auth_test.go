@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"calendar/internal/auth"
+	"calendar/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	tokens := auth.NewTokens("secret")
+	handler := NewAuth(tokens).AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserID(r.Context())
+		require.True(t, ok)
+		w.Write([]byte(userID))
+	}))
+	token := tokens.Issue("user1", time.Hour)
+
+	tests := []struct {
+		name   string
+		target string
+		header string
+		status int
+	}{
+		{"заголовок", "/events_for_day", "Bearer " + token, http.StatusOK},
+		{"параметр", "/events.ics?access_token=" + token, "", http.StatusOK},
+		{"без токена", "/events_for_day", "", http.StatusUnauthorized},
+		{"не Bearer", "/events_for_day", "Basic dXNlcjE6cGFzcw==", http.StatusUnauthorized},
+		{"чужой секрет", "/events_for_day", "Bearer " + auth.NewTokens("other").Issue("user1", time.Hour), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.status, rec.Code)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, "user1", rec.Body.String())
+				return
+			}
+			assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+			var response types.Response
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.NotEmpty(t, response.Error)
+		})
+	}
+
+	_, ok := UserID(httptest.NewRequest(http.MethodGet, "/", nil).Context())
+	assert.False(t, ok)
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"calendar/internal/config"
+)
+
+const (
+	corsAllowMethods  = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsAllowHeaders  = "Authorization, Content-Type, If-Match, If-None-Match, X-Request-ID"
+	corsExposeHeaders = "ETag, Location, Retry-After, X-Request-ID"
+)
+
+// CORS представляет middleware, разрешающее запросы из браузера со страниц разрешенных источников
+type CORS struct {
+	origins map[string]bool
+	any     bool
+	maxAge  string
+}
+
+// NewCORS создает middleware CORS с разрешенными источниками из cfg
+func NewCORS(cfg config.CORSConfig) *CORS {
+	cors := &CORS{
+		origins: make(map[string]bool, len(cfg.AllowedOrigins)),
+		maxAge:  strconv.Itoa(int(cfg.MaxAge.Seconds())),
+	}
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			cors.any = true
+		}
+		cors.origins[strings.TrimSuffix(origin, "/")] = true
+	}
+	return cors
+}
+
+// CORSMiddleware добавляет заголовки CORS к ответам на запросы разрешенных источников и сам отвечает
+// на предварительные запросы OPTIONS. Предварительный запрос неразрешенного источника получает 403,
+// обычный проходит дальше без заголовков CORS, и браузер не отдаст ответ странице
+func (c *CORS) CORSMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		if !c.any && !c.origins[origin] {
+			if preflight {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header.Set("Access-Control-Allow-Origin", origin)
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			header.Set("Access-Control-Allow-Methods", corsAllowMethods)
+			header.Set("Access-Control-Allow-Headers", corsAllowHeaders)
+			header.Set("Access-Control-Max-Age", c.maxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		header.Set("Access-Control-Expose-Headers", corsExposeHeaders)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"calendar/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	cors := NewCORS(config.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, MaxAge: 10 * time.Minute})
+	handler := cors.CORSMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	tests := []struct {
+		name        string
+		method      string
+		origin      string
+		preflight   bool
+		status      int
+		allowOrigin string
+	}{
+		{"без Origin", http.MethodGet, "", false, http.StatusTeapot, ""},
+		{"разрешенный", http.MethodGet, "https://app.example.com", false, http.StatusTeapot, "https://app.example.com"},
+		{"чужой", http.MethodGet, "https://evil.example.com", false, http.StatusTeapot, ""},
+		{"предварительный", http.MethodOptions, "https://app.example.com", true, http.StatusNoContent, "https://app.example.com"},
+		{"чужой предварительный", http.MethodOptions, "https://evil.example.com", true, http.StatusForbidden, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v2/users/user1/events", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.allowOrigin, rec.Header().Get("Access-Control-Allow-Origin"))
+			if tt.origin != "" {
+				assert.Contains(t, rec.Header().Values("Vary"), "Origin")
+			}
+			if tt.preflight && tt.allowOrigin != "" {
+				assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "If-Match")
+				assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+			}
+			if !tt.preflight && tt.allowOrigin != "" {
+				assert.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "ETag")
+			}
+		})
+	}
+
+	// * разрешает любой источник
+	handler = NewCORS(config.CORSConfig{AllowedOrigins: []string{"*"}}).CORSMiddleware(http.NotFoundHandler())
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://any.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "https://any.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+}
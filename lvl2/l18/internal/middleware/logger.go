@@ -1,33 +1,129 @@
 package middleware
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"runtime/debug"
 	"time"
+
+	"calendar/internal/config"
 )
 
 // Logger представляет middleware для логирования
 type Logger struct {
 	logger *log.Logger
+	// json - писать одну JSON-запись на запрос вместо текстовых строк
+	json bool
+}
+
+// accessRecord - запись журнала запросов в формате JSON
+type accessRecord struct {
+	Time       time.Time `json:"time"`
+	Level      string    `json:"level"`
+	RequestID  string    `json:"request_id,omitempty"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	RemoteAddr string    `json:"remote_addr"`
+	Status     int       `json:"status,omitempty"`
+	Bytes      int       `json:"bytes"`
+	DurationMS float64   `json:"duration_ms"`
+	Panic      string    `json:"panic,omitempty"`
+	Stack      string    `json:"stack,omitempty"`
 }
 
-// NewLogger создает новый экземпляр логгера
-func NewLogger() *Logger {
+// NewLogger создает новый экземпляр логгера с форматом журнала из cfg
+func NewLogger(cfg config.LogConfig) *Logger {
+	if cfg.Format == config.LogFormatJSON {
+		return &Logger{
+			logger: log.New(log.Writer(), "", 0),
+			json:   true,
+		}
+	}
 	return &Logger{
 		logger: log.New(log.Writer(), "", log.LstdFlags),
 	}
 }
 
-// LoggingMiddleware возвращает middleware для логирования HTTP-запросов
+// LoggingMiddleware возвращает middleware для логирования HTTP-запросов: метода, пути, кода ответа,
+// размера тела ответа, времени выполнения и id запроса
 func (l *Logger) LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		recorder := newResponseRecorder(w)
 
-		l.logger.Printf("Запрос: %s %s", r.Method, r.URL.Path)
+		if !l.json {
+			l.logger.Printf("Запрос: %s %s", r.Method, r.URL.Path)
+		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(recorder, r)
 
 		duration := time.Since(start)
-		l.logger.Printf("Запрос завершен: %s %s - %v", r.Method, r.URL.Path, duration)
+		if l.json {
+			record := newAccessRecord(r, "info", start)
+			record.Status = recorder.status
+			record.Bytes = recorder.size
+			record.DurationMS = float64(duration.Microseconds()) / 1000
+			l.write(record)
+			return
+		}
+		l.logger.Printf("Запрос завершен: %s %s - %d, %d байт, %v, id %s",
+			r.Method, r.URL.Path, recorder.status, recorder.size, duration, RequestID(r.Context()))
 	})
 }
+
+// RecoveryMiddleware перехватывает панику обработчика, логирует ее со стеком вызовов и отвечает 500
+// в формате ошибок API, если ответ еще не начат
+func (l *Logger) RecoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := newResponseRecorder(w)
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// ErrAbortHandler - штатный способ прервать ответ, сервер обрабатывает его сам
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			stack := debug.Stack()
+			if l.json {
+				record := newAccessRecord(r, "error", time.Now())
+				record.Panic = fmt.Sprint(recovered)
+				record.Stack = string(stack)
+				l.write(record)
+			} else {
+				l.logger.Printf("Паника при обработке запроса %s %s, id %s: %v\n%s",
+					r.Method, r.URL.Path, RequestID(r.Context()), recovered, stack)
+			}
+
+			if !recorder.wroteHeader {
+				sendError(recorder, r, http.StatusInternalServerError, "internal_error", "внутренняя ошибка сервера")
+			}
+		}()
+
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+func newAccessRecord(r *http.Request, level string, at time.Time) *accessRecord {
+	return &accessRecord{
+		Time:       at.UTC(),
+		Level:      level,
+		RequestID:  RequestID(r.Context()),
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+	}
+}
+
+func (l *Logger) write(record *accessRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		l.logger.Printf(`{"level":"error","error":%q}`, err.Error())
+		return
+	}
+	l.logger.Print(string(data))
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"calendar/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Len(t, seen, 32)
+	assert.Equal(t, seen, rec.Header().Get(RequestIDHeader))
+
+	// Id от балансировщика сохраняется, недопустимый заменяется новым
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "lb-42:abc")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "lb-42:abc", seen)
+	assert.Equal(t, "lb-42:abc", rec.Header().Get(RequestIDHeader))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Len(t, seen, 32)
+
+	assert.Empty(t, RequestID(httptest.NewRequest(http.MethodGet, "/", nil).Context()))
+}
+
+func TestLoggingMiddlewareJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &Logger{logger: log.New(&buf, "", 0), json: true}
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	}), RequestIDMiddleware, logger.LoggingMiddleware)
+
+	req := httptest.NewRequest(http.MethodPost, "/create_event", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var record accessRecord
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "info", record.Level)
+	assert.Equal(t, "req-1", record.RequestID)
+	assert.Equal(t, http.MethodPost, record.Method)
+	assert.Equal(t, "/create_event", record.Path)
+	assert.Equal(t, http.StatusCreated, record.Status)
+	assert.Equal(t, 5, record.Bytes)
+}
+
+func TestLoggingMiddlewareText(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &Logger{logger: log.New(&buf, "", 0)}
+	handler := logger.LoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "нет", http.StatusNotFound)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/events_for_day", nil))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "Запрос: GET /events_for_day", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "Запрос завершен: GET /events_for_day - 404, 7 байт"), lines[1])
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &Logger{logger: log.New(&buf, "", 0), json: true}
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("сломалось")
+	})
+	handler := Chain(panicking, RequestIDMiddleware, logger.LoggingMiddleware, logger.RecoveryMiddleware)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/users/user1/events", nil))
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	var response types.ErrorResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, "internal_error", response.Error.Code)
+
+	// Паника и итоговый ответ попадают в журнал с одним id запроса
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var panicRecord, accessRecord accessRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &panicRecord))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &accessRecord))
+	assert.Equal(t, "error", panicRecord.Level)
+	assert.Equal(t, "сломалось", panicRecord.Panic)
+	assert.Contains(t, panicRecord.Stack, "TestRecoveryMiddleware")
+	assert.Equal(t, rec.Header().Get(RequestIDHeader), panicRecord.RequestID)
+	assert.Equal(t, panicRecord.RequestID, accessRecord.RequestID)
+	assert.Equal(t, http.StatusInternalServerError, accessRecord.Status)
+
+	// Начатый ответ не перезаписывается, v1 получает ошибку в своем формате
+	handler = logger.RecoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("после ответа")
+	}))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events_for_day", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	handler = logger.RecoveryMiddleware(panicking)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events_for_day", nil))
+	var v1 types.Response
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&v1))
+	assert.NotEmpty(t, v1.Error)
+
+	handler = logger.RecoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"calendar/internal/config"
+)
+
+// rateLimitSweepInterval - как часто из памяти удаляются счетчики неактивных клиентов
+const rateLimitSweepInterval = time.Minute
+
+// RateLimiter ограничивает частоту запросов каждого клиента алгоритмом token bucket:
+// клиент может сделать burst запросов подряд, дальше - rps запросов в секунду
+type RateLimiter struct {
+	rps        float64
+	burst      float64
+	trustProxy bool
+
+	mutex     sync.Mutex
+	clients   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// bucket - запас запросов клиента на момент updated
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter создает ограничитель частоты запросов с настройками из cfg
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		rps:        cfg.RPS,
+		burst:      float64(cfg.Burst),
+		trustProxy: cfg.TrustProxy,
+		clients:    make(map[string]*bucket),
+		now:        time.Now,
+	}
+}
+
+// RateLimitMiddleware отвечает 429 с заголовком Retry-After клиенту, превысившему лимит
+func (l *RateLimiter) RateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, retryAfter := l.allow(l.clientKey(r))
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			sendError(w, r, http.StatusTooManyRequests, "rate_limited", "слишком много запросов, повторите позже")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allow расходует запрос из запаса клиента. Если запас исчерпан, возвращает время до следующего запроса
+func (l *RateLimiter) allow(client string) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.clients[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.clients[client] = b
+	}
+	b.refill(now, l.rps, l.burst)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.rps * float64(time.Second))
+}
+
+// sweep удаляет счетчики клиентов, у которых запас уже восполнился бы полностью:
+// для них новый счетчик ничем не отличается от старого
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for client, b := range l.clients {
+		if b.tokens+now.Sub(b.updated).Seconds()*l.rps >= l.burst {
+			delete(l.clients, client)
+		}
+	}
+}
+
+func (b *bucket) refill(now time.Time, rps, burst float64) {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed.Seconds()*rps)
+	}
+	b.updated = now
+}
+
+// clientKey возвращает IP-адрес клиента. За доверенным прокси им считается первый адрес X-Forwarded-For
+func (l *RateLimiter) clientKey(r *http.Request) string {
+	if l.trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			client, _, _ := strings.Cut(forwarded, ",")
+			if client = strings.TrimSpace(client); client != "" {
+				return client
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"calendar/internal/config"
+	"calendar/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	now := time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(config.RateLimitConfig{RPS: 1, Burst: 2})
+	limiter.now = func() time.Time { return now }
+	handler := limiter.RateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := func(remoteAddr, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, request("10.0.0.1:1000", "/events_for_day").Code)
+	assert.Equal(t, http.StatusOK, request("10.0.0.1:1001", "/events_for_day").Code)
+
+	rec := request("10.0.0.1:1002", "/api/v2/users/user1/events")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	var response types.ErrorResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, "rate_limited", response.Error.Code)
+
+	// У другого клиента свой запас, а запас первого восполняется со временем
+	assert.Equal(t, http.StatusOK, request("10.0.0.2:1000", "/events_for_day").Code)
+	now = now.Add(time.Second)
+	assert.Equal(t, http.StatusOK, request("10.0.0.1:1003", "/events_for_day").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("10.0.0.1:1004", "/events_for_day").Code)
+
+	// Счетчики неактивных клиентов удаляются
+	now = now.Add(time.Hour)
+	request("10.0.0.3:1000", "/events_for_day")
+	assert.Len(t, limiter.clients, 1)
+}
+
+func TestRateLimiter_ClientKey(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1000"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+
+	assert.Equal(t, "10.0.0.1", NewRateLimiter(config.RateLimitConfig{RPS: 1, Burst: 1}).clientKey(req))
+	assert.Equal(t, "203.0.113.7", NewRateLimiter(config.RateLimitConfig{RPS: 1, Burst: 1, TrustProxy: true}).clientKey(req))
+}
@@ -0,0 +1,62 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"calendar/internal/types"
+)
+
+const (
+	// delayedNotifyPath - эндпоинт создания уведомления в API delayed-notifier
+	delayedNotifyPath = "/api/v1/notify"
+	// delayedNotifyLead - запас до времени отправки: delayed-notifier отклоняет уведомления в прошлом
+	delayedNotifyLead = 5 * time.Second
+	// delayedMaxLateness - после этого опоздания delayed-notifier не отправляет напоминание
+	delayedMaxLateness = "15m"
+)
+
+// delayedNotification представляет запрос создания уведомления в API delayed-notifier
+type delayedNotification struct {
+	Payload          string    `json:"payload"`
+	NotificationDate time.Time `json:"notification_date"`
+	SenderID         string    `json:"sender_id"`
+	RecipientID      string    `json:"recipient_id"`
+	Channel          string    `json:"channel"`
+	MaxLateness      string    `json:"max_lateness"`
+}
+
+// DelayedNotifierClient передает напоминания сервису delayed-notifier, который доставляет их
+// по email или в Telegram. Получатель уведомления - user_id владельца события
+type DelayedNotifierClient struct {
+	baseURL string
+	token   string
+	channel string
+	client  *http.Client
+	now     func() time.Time
+}
+
+// NewDelayedNotifierClient создает клиент API delayed-notifier с базовым адресом baseURL
+func NewDelayedNotifierClient(baseURL, token, channel string, client *http.Client) *DelayedNotifierClient {
+	return &DelayedNotifierClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		channel: channel,
+		client:  client,
+		now:     time.Now,
+	}
+}
+
+// Notify создает в delayed-notifier уведомление для немедленной отправки
+func (c *DelayedNotifierClient) Notify(ctx context.Context, reminder *types.Reminder) error {
+	return postJSON(ctx, c.client, c.baseURL+delayedNotifyPath, c.token, delayedNotification{
+		Payload:          Message(reminder),
+		NotificationDate: c.now().Add(delayedNotifyLead).UTC(),
+		SenderID:         "calendar",
+		RecipientID:      reminder.UserID,
+		Channel:          c.channel,
+		MaxLateness:      delayedMaxLateness,
+	})
+}
@@ -0,0 +1,26 @@
+package notifier
+
+import (
+	"context"
+	"log"
+
+	"calendar/internal/types"
+)
+
+// LogNotifier пишет напоминания в лог, используется по умолчанию и для отладки
+type LogNotifier struct {
+	logger *log.Logger
+}
+
+// NewLogNotifier создает уведомитель, пишущий в logger
+func NewLogNotifier(logger *log.Logger) *LogNotifier {
+	return &LogNotifier{
+		logger: logger,
+	}
+}
+
+// Notify записывает напоминание в лог
+func (n *LogNotifier) Notify(ctx context.Context, reminder *types.Reminder) error {
+	n.logger.Printf("[%s] %s (событие %s)", reminder.UserID, Message(reminder), reminder.EventID)
+	return nil
+}
@@ -0,0 +1,73 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+
+	"calendar/internal/config"
+	"calendar/internal/types"
+)
+
+// Notifier доставляет напоминания о событиях
+type Notifier interface {
+	Notify(ctx context.Context, reminder *types.Reminder) error
+}
+
+// New создает способ доставки напоминаний по конфигурации
+func New(cfg config.NotifierConfig) (Notifier, error) {
+	client := &http.Client{Timeout: cfg.Timeout}
+
+	switch cfg.Kind {
+	case config.NotifierLog:
+		return NewLogNotifier(log.New(os.Stdout, "", log.LstdFlags)), nil
+	case config.NotifierWebhook:
+		return NewWebhookNotifier(cfg.URL, cfg.Token, client), nil
+	case config.NotifierDelayedNotifier:
+		return NewDelayedNotifierClient(cfg.URL, cfg.Token, cfg.Channel, client), nil
+	default:
+		return nil, fmt.Errorf("неизвестный способ доставки напоминаний: %s", cfg.Kind)
+	}
+}
+
+// Message возвращает текст напоминания для человека
+func Message(reminder *types.Reminder) string {
+	if reminder.AllDay {
+		return fmt.Sprintf("Напоминание: %s, %s", reminder.Text, reminder.Start.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("Напоминание: %s, %s", reminder.Text, reminder.Start.Format("2006-01-02 15:04 MST"))
+}
+
+// postJSON отправляет body в формате JSON и считает ошибкой любой ответ кроме 2xx
+func postJSON(ctx context.Context, client *http.Client, url, token string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("не удалось сериализовать напоминание: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("не удалось создать запрос: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("не удалось отправить напоминание: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("напоминание отклонено: %s: %s", resp.Status, bytes.TrimSpace(message))
+	}
+	return nil
+}
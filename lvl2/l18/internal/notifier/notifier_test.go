@@ -0,0 +1,94 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"calendar/internal/config"
+	"calendar/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReminder() *types.Reminder {
+	start := time.Date(2024, 1, 10, 7, 0, 0, 0, time.UTC)
+	return &types.Reminder{
+		EventID:  "event1",
+		UserID:   "user1",
+		Text:     "Встреча",
+		Start:    start,
+		Minutes:  15,
+		RemindAt: start.Add(-15 * time.Minute),
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got types.Reminder
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.URL, "secret", server.Client())
+	require.NoError(t, n.Notify(context.Background(), testReminder()))
+	assert.Equal(t, "Bearer secret", auth)
+	assert.Equal(t, "event1", got.EventID)
+	assert.Equal(t, 15, got.Minutes)
+	assert.True(t, got.Start.Equal(testReminder().Start))
+}
+
+func TestWebhookNotifier_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "недоступно", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.URL, "", server.Client())
+	err := n.Notify(context.Background(), testReminder())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "недоступно")
+}
+
+func TestDelayedNotifierClient(t *testing.T) {
+	var path string
+	var got delayedNotification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 1, 10, 6, 45, 0, 0, time.UTC)
+	client := NewDelayedNotifierClient(server.URL+"/", "", "telegram", server.Client())
+	client.now = func() time.Time { return now }
+
+	require.NoError(t, client.Notify(context.Background(), testReminder()))
+	assert.Equal(t, delayedNotifyPath, path)
+	assert.Equal(t, "user1", got.RecipientID)
+	assert.Equal(t, "calendar", got.SenderID)
+	assert.Equal(t, "telegram", got.Channel)
+	assert.Equal(t, "Напоминание: Встреча, 2024-01-10 07:00 UTC", got.Payload)
+	assert.True(t, got.NotificationDate.Equal(now.Add(delayedNotifyLead)))
+}
+
+func TestNew(t *testing.T) {
+	n, err := New(config.NotifierConfig{Kind: config.NotifierLog})
+	require.NoError(t, err)
+	assert.IsType(t, &LogNotifier{}, n)
+
+	n, err = New(config.NotifierConfig{Kind: config.NotifierDelayedNotifier, URL: "http://localhost:8081"})
+	require.NoError(t, err)
+	assert.IsType(t, &DelayedNotifierClient{}, n)
+
+	_, err = New(config.NotifierConfig{Kind: "sms"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,29 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+
+	"calendar/internal/types"
+)
+
+// WebhookNotifier отправляет напоминания POST-запросом с JSON напоминания на заданный адрес
+type WebhookNotifier struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewWebhookNotifier создает уведомитель для вебхука url. Непустой token передается в заголовке Authorization
+func NewWebhookNotifier(url, token string, client *http.Client) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		token:  token,
+		client: client,
+	}
+}
+
+// Notify отправляет напоминание на вебхук
+func (n *WebhookNotifier) Notify(ctx context.Context, reminder *types.Reminder) error {
+	return postJSON(ctx, n.client, n.url, n.token, reminder)
+}
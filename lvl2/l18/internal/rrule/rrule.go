@@ -0,0 +1,389 @@
+package rrule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency представляет частоту повторения правила
+type Frequency string
+
+// Поддерживаемые частоты повторения
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxPeriods ограничивает перебор периодов для правил, которые почти не дают повторений
+const maxPeriods = 100000
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Weekday представляет элемент BYDAY: день недели и необязательный номер в месяце (1MO, -1FR)
+type Weekday struct {
+	Day time.Weekday
+	N   int
+}
+
+// Rule представляет правило повторения RFC 5545 (подмножество: FREQ, INTERVAL, BYDAY, COUNT, UNTIL)
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []Weekday
+	Count    int
+	Until    time.Time
+	// UntilDate указывает, что UNTIL задан датой без времени и включает весь этот день
+	UntilDate bool
+}
+
+// Parse разбирает строку RRULE, например "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10".
+// Префикс "RRULE:" допускается
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("пустое правило повторения")
+	}
+
+	rule := &Rule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("некорректная часть правила повторения: %q", part)
+		}
+
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(value))
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(value)
+			if err == nil && rule.Interval < 1 {
+				err = fmt.Errorf("должен быть положительным")
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(value)
+			if err == nil && rule.Count < 1 {
+				err = fmt.Errorf("должен быть положительным")
+			}
+		case "UNTIL":
+			rule.Until, rule.UntilDate, err = parseUntil(value)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+		case "WKST":
+			if strings.ToUpper(value) != "MO" {
+				err = fmt.Errorf("поддерживается только WKST=MO")
+			}
+		default:
+			err = fmt.Errorf("не поддерживается")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("некорректный %s в правиле повторения: %v", strings.ToUpper(key), err)
+		}
+	}
+
+	if err := rule.validate(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *Rule) validate() error {
+	switch r.Freq {
+	case Daily, Weekly, Monthly, Yearly:
+	case "":
+		return fmt.Errorf("в правиле повторения не задан FREQ")
+	default:
+		return fmt.Errorf("неподдерживаемая частота повторения: %s", r.Freq)
+	}
+
+	if r.Count > 0 && !r.Until.IsZero() {
+		return fmt.Errorf("COUNT и UNTIL не могут быть заданы одновременно")
+	}
+
+	for _, day := range r.ByDay {
+		if day.N != 0 && r.Freq != Monthly {
+			return fmt.Errorf("номер дня в BYDAY поддерживается только для FREQ=MONTHLY")
+		}
+	}
+	if len(r.ByDay) > 0 && r.Freq == Yearly {
+		return fmt.Errorf("BYDAY не поддерживается для FREQ=YEARLY")
+	}
+	return nil
+}
+
+// String возвращает правило в формате RRULE без префикса
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			days = append(days, day.String())
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		if r.UntilDate {
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// String возвращает элемент BYDAY в формате RFC 5545
+func (w Weekday) String() string {
+	code := ""
+	for c, day := range weekdayCodes {
+		if day == w.Day {
+			code = c
+		}
+	}
+	if w.N != 0 {
+		return strconv.Itoa(w.N) + code
+	}
+	return code
+}
+
+// Between возвращает повторения серии с началом start, попадающие в [from, to), по возрастанию.
+// COUNT отсчитывается от start, поэтому повторения до from тоже учитываются в лимите
+func (r *Rule) Between(start, from, to time.Time) []time.Time {
+	var occurrences []time.Time
+	count := 0
+
+	firstPeriod := 0
+	if r.Count == 0 {
+		firstPeriod = r.periodBefore(start, from)
+	}
+
+	for period := firstPeriod; period < firstPeriod+maxPeriods; period++ {
+		candidates := r.candidates(start, period)
+		if len(candidates) == 0 {
+			// Период без подходящих дат (например, 31 число в коротком месяце)
+			if r.periodStart(start, period).After(to) {
+				return occurrences
+			}
+			continue
+		}
+
+		for _, candidate := range candidates {
+			if candidate.Before(start) {
+				continue
+			}
+			if r.afterUntil(candidate) || !candidate.Before(to) {
+				return occurrences
+			}
+			count++
+			if !candidate.Before(from) {
+				occurrences = append(occurrences, candidate)
+			}
+			if r.Count > 0 && count >= r.Count {
+				return occurrences
+			}
+		}
+	}
+	return occurrences
+}
+
+// Includes сообщает, что occurrence является повторением серии с началом start
+func (r *Rule) Includes(start, occurrence time.Time) bool {
+	for _, candidate := range r.Between(start, occurrence, occurrence.Add(time.Nanosecond)) {
+		if candidate.Equal(occurrence) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) afterUntil(candidate time.Time) bool {
+	if r.Until.IsZero() {
+		return false
+	}
+	if r.UntilDate {
+		y, m, d := candidate.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).After(r.Until)
+	}
+	return candidate.After(r.Until)
+}
+
+// periodStart возвращает начало периода с номером period: день, понедельник недели, первое число месяца или года
+func (r *Rule) periodStart(start time.Time, period int) time.Time {
+	step := period * r.Interval
+	y, m, d := start.Date()
+	hh, mm, ss := start.Clock()
+	loc := start.Location()
+
+	switch r.Freq {
+	case Daily:
+		return time.Date(y, m, d+step, hh, mm, ss, start.Nanosecond(), loc)
+	case Weekly:
+		offset := (int(start.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset+step*7, hh, mm, ss, start.Nanosecond(), loc)
+	case Monthly:
+		return time.Date(y, m+time.Month(step), 1, hh, mm, ss, start.Nanosecond(), loc)
+	default:
+		return time.Date(y+step, m, 1, hh, mm, ss, start.Nanosecond(), loc)
+	}
+}
+
+// periodBefore оценивает номер периода, предшествующего from, чтобы не перебирать периоды с начала серии
+func (r *Rule) periodBefore(start, from time.Time) int {
+	if !from.After(start) {
+		return 0
+	}
+
+	var periods int
+	switch r.Freq {
+	case Daily:
+		periods = int(from.Sub(start).Hours()/24) / r.Interval
+	case Weekly:
+		periods = int(from.Sub(start).Hours()/(24*7)) / r.Interval
+	case Monthly:
+		periods = ((from.Year()-start.Year())*12 + int(from.Month()-start.Month())) / r.Interval
+	default:
+		periods = (from.Year() - start.Year()) / r.Interval
+	}
+
+	// Запас в один период покрывает переходы на летнее время и неполные недели
+	if periods > 1 {
+		return periods - 1
+	}
+	return 0
+}
+
+// candidates возвращает даты повторений внутри периода по возрастанию
+func (r *Rule) candidates(start time.Time, period int) []time.Time {
+	periodStart := r.periodStart(start, period)
+	y, m, _ := periodStart.Date()
+	hh, mm, ss := start.Clock()
+	loc := start.Location()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hh, mm, ss, start.Nanosecond(), loc)
+	}
+
+	switch r.Freq {
+	case Daily:
+		if len(r.ByDay) > 0 && !r.matchesWeekday(periodStart.Weekday()) {
+			return nil
+		}
+		return []time.Time{periodStart}
+
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			return []time.Time{periodStart.AddDate(0, 0, (int(start.Weekday())+6)%7)}
+		}
+		var dates []time.Time
+		for offset := 0; offset < 7; offset++ {
+			date := periodStart.AddDate(0, 0, offset)
+			if r.matchesWeekday(date.Weekday()) {
+				dates = append(dates, date)
+			}
+		}
+		return dates
+
+	case Monthly:
+		if len(r.ByDay) == 0 {
+			if start.Day() > daysIn(y, m) {
+				return nil
+			}
+			return []time.Time{at(y, m, start.Day())}
+		}
+		var dates []time.Time
+		for day := 1; day <= daysIn(y, m); day++ {
+			if r.matchesMonthDay(y, m, day) {
+				dates = append(dates, at(y, m, day))
+			}
+		}
+		return dates
+
+	default:
+		if start.Day() > daysIn(y, start.Month()) {
+			return nil
+		}
+		return []time.Time{at(y, start.Month(), start.Day())}
+	}
+}
+
+func (r *Rule) matchesWeekday(weekday time.Weekday) bool {
+	for _, day := range r.ByDay {
+		if day.Day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthDay проверяет день месяца по BYDAY с учетом номера: 2MO - второй понедельник, -1FR - последняя пятница
+func (r *Rule) matchesMonthDay(year int, month time.Month, day int) bool {
+	weekday := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday()
+	fromStart := (day-1)/7 + 1
+	fromEnd := -((daysIn(year, month)-day)/7 + 1)
+
+	for _, byDay := range r.ByDay {
+		if byDay.Day != weekday {
+			continue
+		}
+		if byDay.N == 0 || byDay.N == fromStart || byDay.N == fromEnd {
+			return true
+		}
+	}
+	return false
+}
+
+func parseByDay(value string) ([]Weekday, error) {
+	var days []Weekday
+	for _, item := range strings.Split(strings.ToUpper(value), ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("некорректный день %q", item)
+		}
+		code := item[len(item)-2:]
+		day, ok := weekdayCodes[code]
+		if !ok {
+			return nil, fmt.Errorf("некорректный день %q", item)
+		}
+
+		weekday := Weekday{Day: day}
+		if prefix := item[:len(item)-2]; prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("некорректный номер дня %q", item)
+			}
+			weekday.N = n
+		}
+		days = append(days, weekday)
+	}
+	return days, nil
+}
+
+func parseUntil(value string) (time.Time, bool, error) {
+	if until, err := time.Parse("20060102T150405Z", value); err == nil {
+		return until, false, nil
+	}
+	until, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("ожидается YYYYMMDD или YYYYMMDDTHHMMSSZ")
+	}
+	return until, true, nil
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
@@ -0,0 +1,179 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func dates(times []time.Time) []string {
+	result := make([]string, 0, len(times))
+	for _, t := range times {
+		result = append(result, t.Format("2006-01-02"))
+	}
+	return result
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		want    string
+		wantErr bool
+	}{
+		{name: "ежедневно", rule: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{name: "с префиксом", rule: "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"},
+		{name: "номер дня в месяце", rule: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", want: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3"},
+		{name: "UNTIL датой", rule: "FREQ=YEARLY;UNTIL=20250101", want: "FREQ=YEARLY;UNTIL=20250101"},
+		{name: "UNTIL временем", rule: "FREQ=DAILY;UNTIL=20250101T100000Z", want: "FREQ=DAILY;UNTIL=20250101T100000Z"},
+		{name: "без FREQ", rule: "INTERVAL=2", wantErr: true},
+		{name: "неизвестная частота", rule: "FREQ=HOURLY", wantErr: true},
+		{name: "COUNT и UNTIL", rule: "FREQ=DAILY;COUNT=2;UNTIL=20250101", wantErr: true},
+		{name: "нулевой интервал", rule: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{name: "неизвестный день", rule: "FREQ=WEEKLY;BYDAY=XX", wantErr: true},
+		{name: "номер дня для недели", rule: "FREQ=WEEKLY;BYDAY=1MO", wantErr: true},
+		{name: "неподдерживаемая часть", rule: "FREQ=DAILY;BYHOUR=10", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rule.String())
+		})
+	}
+}
+
+func TestRule_Between(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		from  string
+		to    string
+		want  []string
+	}{
+		{
+			name:  "каждые два дня",
+			rule:  "FREQ=DAILY;INTERVAL=2",
+			start: "2024-01-01", from: "2024-01-01", to: "2024-01-08",
+			want: []string{"2024-01-01", "2024-01-03", "2024-01-05", "2024-01-07"},
+		},
+		{
+			name:  "ежедневно по будням",
+			rule:  "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			start: "2024-01-05", from: "2024-01-05", to: "2024-01-10",
+			want: []string{"2024-01-05", "2024-01-08", "2024-01-09"},
+		},
+		{
+			name:  "еженедельно в день начала",
+			rule:  "FREQ=WEEKLY",
+			start: "2024-01-03", from: "2024-01-01", to: "2024-01-25",
+			want: []string{"2024-01-03", "2024-01-10", "2024-01-17", "2024-01-24"},
+		},
+		{
+			name:  "раз в две недели по понедельникам и средам с COUNT",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=5",
+			start: "2024-01-03", from: "2024-01-01", to: "2024-03-01",
+			want: []string{"2024-01-03", "2024-01-15", "2024-01-17", "2024-01-29", "2024-01-31"},
+		},
+		{
+			name:  "COUNT учитывает повторения до окна",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: "2024-01-01", from: "2024-01-02", to: "2024-02-01",
+			want: []string{"2024-01-02", "2024-01-03"},
+		},
+		{
+			name:  "UNTIL включительно",
+			rule:  "FREQ=DAILY;UNTIL=20240103",
+			start: "2024-01-01", from: "2024-01-01", to: "2024-02-01",
+			want: []string{"2024-01-01", "2024-01-02", "2024-01-03"},
+		},
+		{
+			name:  "ежемесячно 31 числа пропускает короткие месяцы",
+			rule:  "FREQ=MONTHLY",
+			start: "2024-01-31", from: "2024-01-01", to: "2024-06-01",
+			want: []string{"2024-01-31", "2024-03-31", "2024-05-31"},
+		},
+		{
+			name:  "последняя пятница месяца",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR",
+			start: "2024-01-01", from: "2024-01-01", to: "2024-04-01",
+			want: []string{"2024-01-26", "2024-02-23", "2024-03-29"},
+		},
+		{
+			name:  "второй вторник месяца",
+			rule:  "FREQ=MONTHLY;BYDAY=2TU",
+			start: "2024-01-01", from: "2024-01-01", to: "2024-03-01",
+			want: []string{"2024-01-09", "2024-02-13"},
+		},
+		{
+			name:  "ежегодно 29 февраля",
+			rule:  "FREQ=YEARLY",
+			start: "2024-02-29", from: "2024-01-01", to: "2029-01-01",
+			want: []string{"2024-02-29", "2028-02-29"},
+		},
+		{
+			name:  "окно далеко от начала серии",
+			rule:  "FREQ=WEEKLY;BYDAY=MO",
+			start: "2000-01-03", from: "2024-01-01", to: "2024-01-15",
+			want: []string{"2024-01-01", "2024-01-08"},
+		},
+		{
+			name:  "окно до начала серии",
+			rule:  "FREQ=DAILY",
+			start: "2024-01-10", from: "2024-01-01", to: "2024-01-05",
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			require.NoError(t, err)
+
+			occurrences := rule.Between(date(tt.start), date(tt.from), date(tt.to))
+			assert.Equal(t, tt.want, dates(occurrences))
+		})
+	}
+}
+
+func TestRule_BetweenKeepsWallClock(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	rule, err := Parse("FREQ=DAILY")
+	require.NoError(t, err)
+
+	// Переход на летнее время 31 марта 2024 года не сдвигает время повторений
+	start := time.Date(2024, 3, 30, 9, 30, 0, 0, berlin)
+	occurrences := rule.Between(start, start, start.AddDate(0, 0, 2))
+	require.Len(t, occurrences, 2)
+	assert.Equal(t, 9, occurrences[1].Hour())
+	assert.Equal(t, 30, occurrences[1].Minute())
+}
+
+func TestRule_Includes(t *testing.T) {
+	rule, err := Parse("FREQ=WEEKLY;BYDAY=TU,TH")
+	require.NoError(t, err)
+
+	start := date("2024-01-02")
+	assert.True(t, rule.Includes(start, date("2024-01-04")))
+	assert.True(t, rule.Includes(start, date("2024-02-06")))
+	assert.False(t, rule.Includes(start, date("2024-01-03")))
+	assert.False(t, rule.Includes(start, date("2023-12-28")))
+}
@@ -4,33 +4,233 @@ import (
 	"time"
 )
 
-// Event представляет событие в календаре
+// Event представляет событие в календаре.
+// Событие с RRule - серия: в выборках вместо нее возвращаются повторения с RecurrenceID,
+// а измененное повторение хранится отдельным событием с SeriesID серии
 type Event struct {
-	ID     string    `json:"id"`
-	UserID string    `json:"user_id"`
-	Date   time.Time `json:"date"`
-	Text   string    `json:"text"`
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	// Date - начало события. У события на весь день - полночь первого дня в UTC
+	Date time.Time `json:"date"`
+	// End - конец события, не включая его. У события на весь день - полночь дня после последнего
+	End time.Time `json:"end,omitzero"`
+	// TimeZone - часовой пояс IANA, в котором задано время события. У событий на весь день пустой
+	TimeZone string `json:"time_zone,omitempty"`
+	// AllDay - событие на весь день: оно привязано к датам, а не к моменту времени,
+	// и попадает в те же дни в любом часовом поясе
+	AllDay bool   `json:"all_day,omitempty"`
+	Text   string `json:"text"`
+	// RRule - правило повторения RFC 5545, например FREQ=WEEKLY;BYDAY=MO
+	RRule string `json:"rrule,omitempty"`
+	// ExDates - исключенные из серии даты повторений
+	ExDates []time.Time `json:"exdates,omitempty"`
+	// SeriesID - id серии, к которой относится повторение
+	SeriesID string `json:"series_id,omitempty"`
+	// RecurrenceID - исходная дата повторения серии
+	RecurrenceID *time.Time `json:"recurrence_id,omitempty"`
+	// UID - идентификатор события во внешнем календаре, из которого оно импортировано
+	UID string `json:"uid,omitempty"`
+	// Reminders - за сколько минут до начала события отправить напоминания.
+	// У события на весь день отсчет идет от полуночи в часовом поясе пользователя
+	Reminders []int `json:"reminders,omitempty"`
+	// Owner - владелец календаря, в котором идет событие. Заполняется в выборках событий,
+	// чтобы отличать события общих календарей, и не сохраняется
+	Owner string `json:"owner,omitempty"`
+	// Title - необязательный заголовок события, Text остается его основным текстом
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Location    string `json:"location,omitempty"`
+	// Categories - категории события в нижнем регистре. По ним фильтруются выборки и поиск
+	Categories []string `json:"categories,omitempty"`
+	// Color - цвет события в календаре в формате #rrggbb
+	Color string `json:"color,omitempty"`
+	// Attendees - приглашенные пользователи и их ответы. Организатор - владелец календаря события
+	Attendees []Attendee `json:"attendees,omitempty"`
+	// Organizer - владелец календаря с исходным событием. Заполнен только у приглашения - копии события
+	// в календаре участника, которую сервис обновляет вслед за исходным событием
+	Organizer string `json:"organizer,omitempty"`
+	// InvitationOf - id исходного события приглашения
+	InvitationOf string `json:"invitation_of,omitempty"`
+}
+
+// Ответы участника на приглашение
+const (
+	RSVPNeedsAction = "needs_action"
+	RSVPAccepted    = "accepted"
+	RSVPDeclined    = "declined"
+	RSVPTentative   = "tentative"
+)
+
+// Attendee представляет приглашенного на событие пользователя и его ответ
+type Attendee struct {
+	UserID string `json:"user_id"`
+	// Status - needs_action, пока участник не ответил, затем accepted, declined или tentative
+	Status string `json:"status"`
+}
+
+// EventDetails описывает необязательные сведения о событии в запросе.
+// Attendees - id приглашаемых пользователей: каждый получает приглашение в свой календарь
+type EventDetails struct {
+	Title       string   `json:"title" form:"title"`
+	Description string   `json:"description" form:"description"`
+	Location    string   `json:"location" form:"location"`
+	Categories  []string `json:"categories" form:"categories"`
+	Color       string   `json:"color" form:"color"`
+	Attendees   []string `json:"attendees" form:"attendees"`
+}
+
+// Уровни доступа к общему календарю
+const (
+	AccessRead  = "read"
+	AccessWrite = "write"
+)
+
+// Режимы проверки пересечений при создании и изменении события
+const (
+	// ConflictsAllow - сохранять событие, даже если оно пересекается с другими
+	ConflictsAllow = "allow"
+	// ConflictsWarn - сохранять событие и вернуть события, с которыми оно пересекается
+	ConflictsWarn = "warn"
+	// ConflictsReject - не сохранять событие, пересекающееся с другими событиями календаря
+	ConflictsReject = "reject"
+)
+
+// EventWithConflicts представляет сохраненное событие и события того же календаря, с которыми оно пересекается
+type EventWithConflicts struct {
+	*Event
+	Conflicts []*Event `json:"conflicts,omitempty"`
+}
+
+// SearchResult представляет найденное событие и его релевантность запросу
+type SearchResult struct {
+	*Event
+	Score float64 `json:"score"`
+}
+
+// Interval представляет промежуток времени [Start, End)
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// FreeBusy представляет занятость пользователей в интервале [From, To)
+type FreeBusy struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Busy - занятые промежутки каждого пользователя, объединенные и упорядоченные по началу
+	Busy map[string][]Interval `json:"busy"`
+	// Free - промежутки, когда свободны все пользователи, не короче запрошенной длительности
+	Free []Interval `json:"free"`
+}
+
+// Grant представляет доступ пользователя GranteeID к календарю пользователя OwnerID
+type Grant struct {
+	OwnerID   string `json:"owner_id"`
+	GranteeID string `json:"grantee_id"`
+	// Access - read: просмотр событий, write: просмотр, создание, изменение и удаление
+	Access string `json:"access"`
+}
+
+// CalendarShares представляет общие календари пользователя
+type CalendarShares struct {
+	// SharedByMe - доступы, выданные пользователем к его календарю
+	SharedByMe []*Grant `json:"shared_by_me"`
+	// SharedWithMe - доступы к чужим календарям, выданные пользователю
+	SharedWithMe []*Grant `json:"shared_with_me"`
+}
+
+// ShareRequest представляет запрос на выдачу или отзыв доступа к календарю
+type ShareRequest struct {
+	UserID    string `json:"user_id" form:"user_id"`
+	GranteeID string `json:"grantee_id" form:"grantee_id"`
+	Access    string `json:"access" form:"access"`
+}
+
+// ShareInput представляет тело запроса API v2 на выдачу доступа к календарю
+type ShareInput struct {
+	Access string `json:"access"`
+}
+
+// RSVPRequest представляет ответ участника на приглашение.
+// ID - приглашение в календаре участника или исходное событие
+type RSVPRequest struct {
+	UserID string `json:"user_id" form:"user_id"`
+	ID     string `json:"id" form:"id"`
+	Status string `json:"status" form:"status"`
+}
+
+// RSVPInput представляет тело запроса API v2 с ответом на приглашение
+type RSVPInput struct {
+	Status string `json:"status"`
+}
+
+// Reminder представляет сработавшее напоминание о событии или повторении серии
+type Reminder struct {
+	EventID  string    `json:"event_id"`
+	UserID   string    `json:"user_id"`
+	Text     string    `json:"text"`
+	Start    time.Time `json:"start"`
+	AllDay   bool      `json:"all_day,omitempty"`
+	Minutes  int       `json:"minutes"`
+	RemindAt time.Time `json:"remind_at"`
+}
+
+// EventTime описывает время события в запросе.
+// Date без Start задает событие на весь день, Start - начало события со временем
+// (2006-01-02T15:04 в часовом поясе TimeZone или RFC 3339), конец задается End или Duration.
+// У события на весь день Start и End - даты, End - последний день включительно
+type EventTime struct {
+	Date     string `json:"date" form:"date"`
+	Start    string `json:"start" form:"start"`
+	End      string `json:"end" form:"end"`
+	Duration string `json:"duration" form:"duration"`
+	TimeZone string `json:"time_zone" form:"time_zone"`
+	AllDay   bool   `json:"all_day" form:"all_day"`
 }
 
 // CreateEventRequest представляет запрос на создание события
 type CreateEventRequest struct {
 	UserID string `json:"user_id" form:"user_id"`
-	Date   string `json:"date" form:"date"`
-	Text   string `json:"text" form:"text"`
+	EventTime
+	Text      string   `json:"text" form:"text"`
+	RRule     string   `json:"rrule" form:"rrule"`
+	ExDates   []string `json:"exdates" form:"exdates"`
+	Reminders []int    `json:"reminders" form:"reminders"`
+	// Conflicts - что делать с пересечениями с другими событиями: allow (по умолчанию), warn или reject
+	Conflicts string `json:"conflicts" form:"conflicts"`
+	EventDetails
 }
 
-// UpdateEventRequest представляет запрос на обновление события
+// UpdateEventRequest представляет запрос на обновление события.
+// С OccurrenceDate изменяется одно повторение серии, без него - событие или вся серия.
+// RRule = nil оставляет правило повторения без изменений, пустая строка делает серию одиночным событием.
+// Date без Start переносит событие со временем на другую дату, сохраняя время начала и длительность.
+// Reminders = nil оставляет напоминания без изменений, пустой список удаляет их.
+// Так же незаданные сведения о событии остаются прежними
 type UpdateEventRequest struct {
 	ID     string `json:"id" form:"id"`
 	UserID string `json:"user_id" form:"user_id"`
-	Date   string `json:"date" form:"date"`
-	Text   string `json:"text" form:"text"`
+	EventTime
+	Text           string   `json:"text" form:"text"`
+	OccurrenceDate string   `json:"occurrence_date" form:"occurrence_date"`
+	RRule          *string  `json:"rrule" form:"rrule"`
+	ExDates        []string `json:"exdates" form:"exdates"`
+	Reminders      []int    `json:"reminders" form:"reminders"`
+	Conflicts      string   `json:"conflicts" form:"conflicts"`
+	Title          *string  `json:"title" form:"title"`
+	Description    *string  `json:"description" form:"description"`
+	Location       *string  `json:"location" form:"location"`
+	Categories     []string `json:"categories" form:"categories"`
+	Color          *string  `json:"color" form:"color"`
+	Attendees      []string `json:"attendees" form:"attendees"`
 }
 
-// DeleteEventRequest представляет запрос на удаление события
+// DeleteEventRequest представляет запрос на удаление события.
+// С OccurrenceDate удаляется одно повторение серии, без него - событие или вся серия
 type DeleteEventRequest struct {
-	ID     string `json:"id" form:"id"`
-	UserID string `json:"user_id" form:"user_id"`
+	ID             string `json:"id" form:"id"`
+	UserID         string `json:"user_id" form:"user_id"`
+	OccurrenceDate string `json:"occurrence_date" form:"occurrence_date"`
 }
 
 // EventsQueryRequest представляет запрос на получение событий
@@ -39,6 +239,91 @@ type EventsQueryRequest struct {
 	Date   string `json:"date" form:"date"`
 }
 
+// UserSettings представляет настройки пользователя
+type UserSettings struct {
+	UserID string `json:"user_id"`
+	// TimeZone - часовой пояс IANA, в котором считаются границы дня, недели и месяца
+	TimeZone string `json:"time_zone"`
+}
+
+// UpdateSettingsRequest представляет запрос на изменение настроек пользователя
+type UpdateSettingsRequest struct {
+	UserID   string `json:"user_id" form:"user_id"`
+	TimeZone string `json:"time_zone" form:"time_zone"`
+}
+
+// ImportEntry представляет результат импорта одного VEVENT
+type ImportEntry struct {
+	UID     string `json:"uid,omitempty"`
+	Summary string `json:"summary,omitempty"`
+	EventID string `json:"event_id,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// ImportResult представляет отчет об импорте iCalendar
+type ImportResult struct {
+	Created []ImportEntry `json:"created"`
+	Skipped []ImportEntry `json:"skipped"`
+	Failed  []ImportEntry `json:"failed"`
+}
+
+// EventInput представляет событие целиком: тело запросов POST и PUT API v2
+// и поля нового события в ScheduleEvent и ReplaceEvent
+type EventInput struct {
+	Text string `json:"text"`
+	EventTime
+	RRule     string   `json:"rrule"`
+	ExDates   []string `json:"exdates"`
+	Reminders []int    `json:"reminders"`
+	EventDetails
+}
+
+// EventPatch представляет тело запроса PATCH API v2: незаданные поля не меняются.
+// Время задается как в EventTime, пустое EventTime оставляет время прежним.
+// Пустые списки Categories и Attendees удаляют категории и участников
+type EventPatch struct {
+	Text *string `json:"text"`
+	EventTime
+	RRule       *string  `json:"rrule"`
+	ExDates     []string `json:"exdates"`
+	Reminders   []int    `json:"reminders"`
+	Title       *string  `json:"title"`
+	Description *string  `json:"description"`
+	Location    *string  `json:"location"`
+	Categories  []string `json:"categories"`
+	Color       *string  `json:"color"`
+	Attendees   []string `json:"attendees"`
+}
+
+// APIError представляет ошибку API v2: машиночитаемый код и сообщение
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ErrorResponse представляет ответ API v2 с ошибкой
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+// EventsPage представляет страницу событий интервала
+type EventsPage struct {
+	Events []*Event `json:"events"`
+	// Total - число событий во всем интервале
+	Total int `json:"total"`
+	// NextOffset - offset следующей страницы, отсутствует на последней странице
+	NextOffset *int `json:"next_offset,omitempty"`
+}
+
+// SearchPage представляет страницу результатов поиска, упорядоченных по релевантности
+type SearchPage struct {
+	Results []*SearchResult `json:"results"`
+	// Total - сколько всего событий найдено
+	Total int `json:"total"`
+	// NextOffset - offset следующей страницы, отсутствует на последней странице
+	NextOffset *int `json:"next_offset,omitempty"`
+}
+
 // Response представляет стандартный ответ API
 type Response struct {
 	Result interface{} `json:"result,omitempty"`
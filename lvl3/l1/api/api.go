@@ -0,0 +1,11 @@
+// Package api содержит OpenAPI спецификацию HTTP API и protobuf описание gRPC API сервиса
+package api
+
+import _ "embed"
+
+//go:generate protoc -I proto --go_out=.. --go_opt=module=delayed-notifier --go-grpc_out=.. --go-grpc_opt=module=delayed-notifier notifier.proto
+
+// OpenAPISpec содержит OpenAPI 3 документ, описывающий маршруты и DTO сервиса
+//
+//go:embed openapi.json
+var OpenAPISpec []byte
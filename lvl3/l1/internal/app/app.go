@@ -2,23 +2,32 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"delayed-notifier/internal/config"
 	"delayed-notifier/internal/service"
+	"delayed-notifier/internal/tracing"
 
 	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
 )
 
 // App представляет основное приложение
 type App struct {
+	ctx           context.Context
 	cfg           *config.Config
+	applied       *config.Config
 	deps          *Dependencies
 	workerManager *service.Manager
 	httpServer    *http.Server
+	grpcServer    *grpc.Server
+	shutdownTrace tracing.ShutdownFunc
 }
 
 // Initialize создает и инициализирует приложение
@@ -28,6 +37,14 @@ func Initialize(ctx context.Context, cancel context.CancelFunc) (*App, error) {
 		return nil, err
 	}
 
+	log.Logger = log.Hook(tracing.LogHook{})
+
+	shutdownTrace, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup tracing: %w", err)
+	}
+	log.Info().Str("exporter", cfg.Tracing.Exporter).Msg("Tracing configured")
+
 	if cfg.Telegram.BotToken == "" {
 		log.Warn().Msg("Telegram bot token not set, telegram notifications will fail")
 	}
@@ -43,11 +60,26 @@ func Initialize(ctx context.Context, cancel context.CancelFunc) (*App, error) {
 		return nil, err
 	}
 
+	if err := builder.WithRetention(); err != nil {
+		builder.Rm.CloseAll()
+		return nil, err
+	}
+
+	if err := builder.WithDigest(); err != nil {
+		builder.Rm.CloseAll()
+		return nil, err
+	}
+
 	if err := builder.WithCache(); err != nil {
 		builder.Rm.CloseAll()
 		return nil, err
 	}
 
+	if err := builder.WithEvents(); err != nil {
+		builder.Rm.CloseAll()
+		return nil, err
+	}
+
 	if err := builder.WithSenders(); err != nil {
 		builder.Rm.CloseAll()
 		return nil, err
@@ -65,12 +97,17 @@ func Initialize(ctx context.Context, cancel context.CancelFunc) (*App, error) {
 
 	workerManager := service.NewManager(ctx, cancel, deps.RabbitMQConsumer, deps.NotificationService.(*service.NotifierService), cfg.Worker)
 	httpServer := NewHTTPServer(cfg, deps)
+	grpcServer := NewGRPCServer(deps)
 
 	return &App{
+		ctx:           ctx,
 		cfg:           cfg,
+		applied:       cfg,
 		deps:          deps,
 		workerManager: workerManager,
 		httpServer:    httpServer,
+		grpcServer:    grpcServer,
+		shutdownTrace: shutdownTrace,
 	}, nil
 }
 
@@ -80,6 +117,25 @@ func (a *App) Run() error {
 		return err
 	}
 
+	if a.deps.StatusBus != nil {
+		go a.deps.StatusBus.Run(a.ctx)
+	}
+
+	if a.deps.RetentionJob != nil {
+		a.deps.RetentionJob.Start(a.ctx)
+	}
+
+	if a.deps.DigestJob != nil {
+		a.deps.DigestJob.Start(a.ctx)
+	}
+
+	a.watchConfig()
+
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", a.cfg.GRPC.Port))
+	if err != nil {
+		return fmt.Errorf("failed to listen gRPC port: %w", err)
+	}
+
 	go func() {
 		log.Info().Int("port", a.cfg.HTTP.Port).Msg("Starting HTTP server on port")
 		if err := a.httpServer.ListenAndServe(); err != nil {
@@ -87,9 +143,32 @@ func (a *App) Run() error {
 		}
 	}()
 
+	go func() {
+		log.Info().Int("port", a.cfg.GRPC.Port).Msg("Starting gRPC server on port")
+		if err := a.grpcServer.Serve(grpcListener); err != nil {
+			log.Error().Err(err).Msg("gRPC server start error")
+		}
+	}()
+
 	return nil
 }
 
+// stopGRPCServer корректно останавливает gRPC сервер, прерывая вызовы по таймауту
+func (a *App) stopGRPCServer() {
+	stopped := make(chan struct{})
+	go func() {
+		a.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(a.cfg.GRPC.ShutdownTimeout):
+		log.Warn().Msg("gRPC graceful stop timed out, forcing stop")
+		a.grpcServer.Stop()
+	}
+}
+
 // WaitForShutdown ждет завершения приложения
 func (a *App) WaitForShutdown(ctx context.Context, cancel context.CancelFunc) {
 	sigChan := make(chan os.Signal, 1)
@@ -111,9 +190,25 @@ func (a *App) WaitForShutdown(ctx context.Context, cancel context.CancelFunc) {
 		log.Error().Err(err).Msg("Server shutdown error")
 	}
 
+	a.stopGRPCServer()
+
+	if a.deps.RetentionJob != nil {
+		a.deps.RetentionJob.Wait()
+	}
+
+	if a.deps.DigestJob != nil {
+		a.deps.DigestJob.Wait()
+	}
+
 	if err := a.deps.Close(); err != nil {
 		log.Error().Err(err).Msg("Failed to close dependencies")
 	}
 
+	traceCtx, traceCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer traceCancel()
+	if err := a.shutdownTrace(traceCtx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush traces")
+	}
+
 	log.Info().Msg("Application stopped gracefully")
 }
@@ -4,14 +4,21 @@ import (
 	"fmt"
 	"strconv"
 
+	"delayed-notifier/internal/archive"
+	"delayed-notifier/internal/auth"
 	"delayed-notifier/internal/cache"
 	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/digest"
 	"delayed-notifier/internal/dto"
+	"delayed-notifier/internal/events"
+	"delayed-notifier/internal/grpcapi"
 	"delayed-notifier/internal/handlers"
 	"delayed-notifier/internal/queue"
 	"delayed-notifier/internal/repository"
 	"delayed-notifier/internal/sender"
 	"delayed-notifier/internal/service"
+	"delayed-notifier/internal/tenant"
+	"delayed-notifier/internal/unsubscribe"
 	"delayed-notifier/internal/validation"
 
 	"github.com/wb-go/wbf/rabbitmq"
@@ -50,11 +57,22 @@ type DependencyBuilder struct {
 
 	conn          *rabbitmq.Connection
 	channel       *rabbitmq.Channel
-	consumer      *rabbitmq.Consumer
+	consumer      *queue.Consumer
 	repo          repository.NotificationRepository
+	postgres      *repository.PostgresRepository
+	queryRepo     repository.NotificationQueryRepository
+	suppressRepo  repository.SuppressionRepository
+	unsubscribe   *unsubscribe.Links
+	retentionJob  *service.RetentionJob
+	digestRepo    repository.DigestRepository
+	digestRender  *digest.Renderer
+	redisClient   *redis.Client
 	cache         cache.StatusCache
 	senderFactory *sender.Factory
 	publisher     queue.Publisher
+	statusBroker  *events.Broker
+	statusEvents  events.Publisher
+	statusBus     *events.RedisBus
 }
 
 // NewDependencyBuilder создает новый билдер зависимостей
@@ -90,23 +108,76 @@ func (db *DependencyBuilder) WithDatabase() error {
 	}
 
 	db.repo = repo
+	db.postgres = repo
+	db.queryRepo = repo
+	db.suppressRepo = repo
+	db.Rm.AddResource(repo.Close)
+	return nil
+}
+
+// WithRetention инициализирует задачу архивации устаревших уведомлений
+func (db *DependencyBuilder) WithRetention() error {
+	if db.postgres == nil {
+		return fmt.Errorf("database not initialized, call WithDatabase first")
+	}
+
+	archiver, err := initArchiver(db.config, db.postgres)
+	if err != nil {
+		return fmt.Errorf("failed to initialize archiver: %w", err)
+	}
+
+	db.retentionJob = service.NewRetentionJob(db.postgres, archiver, db.config.Retention)
+	return nil
+}
+
+// WithDigest инициализирует буфер и шаблон дайджестов
+func (db *DependencyBuilder) WithDigest() error {
+	if db.postgres == nil {
+		return fmt.Errorf("database not initialized, call WithDatabase first")
+	}
+
+	renderer, err := digest.NewRenderer(db.config.Digest.Template)
+	if err != nil {
+		return fmt.Errorf("failed to initialize digest template: %w", err)
+	}
+
+	db.digestRepo = db.postgres
+	db.digestRender = renderer
 	return nil
 }
 
 // WithCache инициализирует кэш
 func (db *DependencyBuilder) WithCache() error {
-	cache, err := initCache(db.config)
+	redisClient := initRedis(db.config)
+
+	cache, err := initCache(redisClient)
 	if err != nil {
 		return fmt.Errorf("failed to initialize cache: %w", err)
 	}
 
+	db.redisClient = redisClient
 	db.cache = cache
+	db.Rm.AddResource(func() error { return redisClient.Close() })
+	return nil
+}
+
+// WithEvents инициализирует шину событий изменения статусов
+func (db *DependencyBuilder) WithEvents() error {
+	if db.redisClient == nil {
+		return fmt.Errorf("redis client not initialized, call WithCache first")
+	}
+
+	db.statusBroker = events.NewBroker()
+	db.statusBus = events.NewRedisBus(db.redisClient, db.config.Redis.EventsChannel, db.statusBroker)
+	db.statusEvents = db.statusBus
 	return nil
 }
 
-// WithSenders инициализирует отправители
+// WithSenders инициализирует отправители и ссылки отписки в письмах
 func (db *DependencyBuilder) WithSenders() error {
-	senderFactory, err := initSenders(db.config)
+	db.unsubscribe = unsubscribe.NewLinks(db.config.Suppression)
+
+	senderFactory, err := initSenders(db.config, db.unsubscribe)
 	if err != nil {
 		return fmt.Errorf("failed to initialize senders: %w", err)
 	}
@@ -129,31 +200,75 @@ func (db *DependencyBuilder) WithPublisher() error {
 // Build создает финальную структуру зависимостей
 func (db *DependencyBuilder) Build() (*Dependencies, error) {
 	validator := validation.NewValidator()
+	authenticator := auth.NewAuthenticator(db.config.Auth)
+
+	if db.statusBroker == nil {
+		db.statusBroker = events.NewBroker()
+	}
+	if db.statusEvents == nil {
+		db.statusEvents = db.statusBroker
+	}
 
 	notificationService := service.NewNotifierService(
 		db.repo,
 		db.cache,
 		db.publisher,
 		db.senderFactory,
+		db.statusEvents,
 		db.config.Redis.NotificationTTL,
 	)
 
-	notificationHandler := handlers.NewNotificationHandler(
-		db.repo,
-		db.cache,
-		db.publisher,
-		db.senderFactory,
-		db.config.Redis.NotificationTTL,
-		validator,
-	)
+	notificationService.SetQuotas(tenant.NewQuotas(db.config.Tenants))
+	notificationService.SetRetryConfig(db.config.Retry)
+
+	notificationHandler := handlers.NewNotificationHandler(notificationService, validator)
+
+	var digestJob *service.DigestJob
+	if db.digestRepo != nil {
+		digestJob = service.NewDigestJob(db.digestRepo, notificationService, db.senderFactory, db.digestRender, db.config.Digest)
+		if db.config.Digest.Enabled {
+			notificationService.EnableDigest()
+		}
+	}
+
+	eventsHandler := handlers.NewEventsHandler(db.statusBroker, notificationService, validator)
+	grpcHandler := grpcapi.NewServer(notificationService, validator, db.statusBroker)
+
+	var retentionHandler *handlers.RetentionHandler
+	if db.retentionJob != nil {
+		retentionHandler = handlers.NewRetentionHandler(db.retentionJob)
+	}
+
+	var suppressionHandler *handlers.SuppressionHandler
+	if db.suppressRepo != nil {
+		suppressionService := service.NewSuppressionService(db.suppressRepo)
+		notificationService.SetSuppressions(suppressionService)
+		suppressionHandler = handlers.NewSuppressionHandler(suppressionService, db.unsubscribe, validator)
+	}
+
+	var dashboardHandler *handlers.DashboardHandler
+	if db.queryRepo != nil {
+		dashboardHandler = handlers.NewDashboardHandler(service.NewDashboardService(db.queryRepo), validator)
+	}
 
 	return &Dependencies{
 		NotificationRepo:    db.repo,
 		NotificationService: notificationService,
 		NotificationHandler: notificationHandler,
+		EventsHandler:       eventsHandler,
+		GRPCHandler:         grpcHandler,
+		Authenticator:       authenticator,
+		RetentionHandler:    retentionHandler,
+		DashboardHandler:    dashboardHandler,
+		SuppressionHandler:  suppressionHandler,
+		RetentionJob:        db.retentionJob,
+		DigestJob:           digestJob,
+		StatusBroker:        db.statusBroker,
+		StatusBus:           db.statusBus,
 		QueuePublisher:      db.publisher,
 		StatusCache:         db.cache,
 		SenderFactory:       db.senderFactory,
+		UnsubscribeLinks:    db.unsubscribe,
 		Validator:           validator,
 		RabbitMQConn:        db.conn,
 		RabbitMQChannel:     db.channel,
@@ -167,22 +282,40 @@ type Dependencies struct {
 	NotificationRepo    repository.NotificationRepository
 	NotificationService service.NotificationService
 	NotificationHandler handlers.NotificationHandler
+	EventsHandler       *handlers.EventsHandler
+	GRPCHandler         *grpcapi.Server
+	Authenticator       *auth.Authenticator
+	RetentionHandler    *handlers.RetentionHandler
+	DashboardHandler    *handlers.DashboardHandler
+	SuppressionHandler  *handlers.SuppressionHandler
+	RetentionJob        *service.RetentionJob
+	DigestJob           *service.DigestJob
+	StatusBroker        *events.Broker
+	StatusBus           *events.RedisBus
 	StatusCache         cache.StatusCache
 	SenderFactory       *sender.Factory
+	UnsubscribeLinks    *unsubscribe.Links
 	Validator           *validation.Validator
 	QueuePublisher      queue.Publisher
 	RabbitMQConn        *rabbitmq.Connection
 	RabbitMQChannel     *rabbitmq.Channel
-	RabbitMQConsumer    *rabbitmq.Consumer
+	RabbitMQConsumer    *queue.Consumer
 	resourceManager     *ResourceManager
 }
 
-func initRepository(cfg *config.Config) (repository.NotificationRepository, error) {
+func initRepository(cfg *config.Config) (*repository.PostgresRepository, error) {
 	dsn := cfg.DBConfig.GetDSN()
 	return repository.NewPostgresRepository(dsn, &cfg.DBConfig)
 }
 
-func initCache(cfg *config.Config) (cache.StatusCache, error) {
+func initArchiver(cfg *config.Config, repo *repository.PostgresRepository) (archive.Archiver, error) {
+	if cfg.Retention.Archive == config.RetentionArchiveFile {
+		return archive.NewFileArchiver(cfg.Retention.ArchiveDir)
+	}
+	return repository.NewPostgresArchiver(repo), nil
+}
+
+func initRedis(cfg *config.Config) *redis.Client {
 	redisClient := redis.New(cfg.Redis.URL, cfg.Redis.Password, cfg.Redis.DB)
 
 	redisClient.Client.Options().PoolSize = cfg.Redis.PoolSize
@@ -192,34 +325,74 @@ func initCache(cfg *config.Config) (cache.StatusCache, error) {
 	redisClient.Client.Options().IdleTimeout = cfg.Redis.IdleTimeout
 	redisClient.Client.Options().IdleCheckFrequency = cfg.Redis.IdleCheckFreq
 
+	return redisClient
+}
+
+func initCache(redisClient *redis.Client) (cache.StatusCache, error) {
 	return cache.NewRedisCache(redisClient), nil
 }
 
-func initSenders(cfg *config.Config) (*sender.Factory, error) {
-	telegramSender := sender.NewTelegramSender(cfg.Telegram.BotToken, cfg.Telegram.ChatID)
+func initSenders(cfg *config.Config, links *unsubscribe.Links) (*sender.Factory, error) {
+	emailSender, err := newEmailSender(cfg.Email, links)
+	if err != nil {
+		return nil, err
+	}
+
+	factory := sender.NewFactory(newTelegramSender(cfg.Telegram), emailSender)
+	if links != nil {
+		factory.SetUnsubscribeLinks(links)
+	}
+
+	for tenantID, tenantConfig := range cfg.Tenants {
+		var telegramSender *sender.TelegramSender
+		if tenantConfig.Telegram != nil {
+			telegramSender = newTelegramSender(*tenantConfig.Telegram)
+		}
+
+		var tenantEmailSender *sender.EmailSender
+		if tenantConfig.Email != nil {
+			tenantEmailSender, err = newEmailSender(*tenantConfig.Email, links)
+			if err != nil {
+				return nil, fmt.Errorf("tenant %s: %w", tenantID, err)
+			}
+		}
 
-	smtpPort, err := strconv.Atoi(cfg.Email.SMTPPort)
+		factory.SetTenantProfile(tenantID, telegramSender, tenantEmailSender)
+	}
+
+	return factory, nil
+}
+
+func newTelegramSender(cfg config.TelegramConfig) *sender.TelegramSender {
+	return sender.NewTelegramSender(cfg.BotToken, cfg.ChatID)
+}
+
+func newEmailSender(cfg config.EmailConfig, links *unsubscribe.Links) (*sender.EmailSender, error) {
+	smtpPort, err := strconv.Atoi(cfg.SMTPPort)
 	if err != nil {
 		return nil, fmt.Errorf("invalid SMTP port: %w", err)
 	}
 
 	emailConfig := dto.EmailConfig{
-		SMTPHost:  cfg.Email.SMTPHost,
+		SMTPHost:  cfg.SMTPHost,
 		SMTPPort:  smtpPort,
-		Username:  cfg.Email.Username,
-		Password:  cfg.Email.Password,
-		FromEmail: cfg.Email.FromEmail,
-		FromName:  cfg.Email.FromName,
+		Username:  cfg.Username,
+		Password:  cfg.Password,
+		FromEmail: cfg.FromEmail,
+		FromName:  cfg.FromName,
 	}
 	emailSender, err := sender.NewEmailSender(emailConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create email sender: %w", err)
 	}
+	if links != nil {
+		emailSender.SetUnsubscribeLinks(links)
+	}
 
-	return sender.NewFactory(telegramSender, emailSender), nil
+	return emailSender, nil
 }
 
-func initQueue(cfg *config.Config) (*rabbitmq.Connection, *rabbitmq.Channel, *rabbitmq.Consumer, error) {
+func initQueue(cfg *config.Config) (*rabbitmq.Connection, *rabbitmq.Channel, *queue.Consumer, error) {
 	conn, err := rabbitmq.Connect(cfg.RabbitMQ.URL, cfg.RabbitMQ.MaxRetries, cfg.RabbitMQ.RetryDelay)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
@@ -239,7 +412,7 @@ func initQueue(cfg *config.Config) (*rabbitmq.Connection, *rabbitmq.Channel, *ra
 
 	consumerConfig := rabbitmq.NewConsumerConfig(cfg.RabbitMQ.QueueName)
 	consumerConfig.AutoAck = false
-	consumer := rabbitmq.NewConsumer(channel, consumerConfig)
+	consumer := queue.NewConsumer(channel, consumerConfig)
 
 	return conn, channel, consumer, nil
 }
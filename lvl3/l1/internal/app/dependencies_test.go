@@ -214,6 +214,12 @@ func (m *mockRepository) GetByStatus(status domain.Status) ([]*domain.Notificati
 	return nil, nil
 }
 func (m *mockRepository) CancelByID(ctx context.Context, id string) error { return nil }
+func (m *mockRepository) RescheduleByID(ctx context.Context, id string, notificationDate time.Time, from []domain.Status) (*domain.Notification, error) {
+	return nil, nil
+}
+func (m *mockRepository) StoreWithinLimit(ctx context.Context, notification domain.Notification, since time.Time, limit int) error {
+	return nil
+}
 
 type mockCache struct{}
 
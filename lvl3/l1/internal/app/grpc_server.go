@@ -0,0 +1,22 @@
+package app
+
+import (
+	"delayed-notifier/internal/grpcapi"
+	"delayed-notifier/pkg/notifierpb"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+// NewGRPCServer создает новый gRPC сервер
+func NewGRPCServer(deps *Dependencies) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(grpcapi.UnaryAuthInterceptor(deps.Authenticator)),
+		grpc.ChainStreamInterceptor(grpcapi.StreamAuthInterceptor(deps.Authenticator)),
+	)
+
+	notifierpb.RegisterNotifierServiceServer(server, deps.GRPCHandler)
+
+	return server
+}
@@ -0,0 +1,493 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"delayed-notifier/api"
+	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/sender"
+	"delayed-notifier/internal/service"
+	"delayed-notifier/internal/tenant"
+	"delayed-notifier/internal/unsubscribe"
+	"delayed-notifier/pkg/client"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPISpec_IsValid(t *testing.T) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(api.OpenAPISpec)
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+}
+
+func TestOpenAPI_ClientRoundTrip(t *testing.T) {
+	server, links, violations := newValidatedServer(t)
+	defer server.Close()
+
+	c := client.NewClient(server.URL+"/api/v1", server.Client())
+	ctx := context.Background()
+
+	created, err := c.CreateNotification(ctx, client.CreateNotificationRequest{
+		Payload:          "Hello World",
+		NotificationDate: time.Now().Add(time.Hour),
+		RecipientID:      "user@example.com",
+		Channel:          client.ChannelTelegram,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, client.StatusPending, created.Status)
+
+	notification, err := c.GetNotification(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, notification.ID)
+	assert.Equal(t, "Hello World", notification.Payload)
+	assert.Equal(t, client.ChannelTelegram, notification.Channel)
+
+	require.NoError(t, c.CancelNotification(ctx, created.ID))
+
+	err = c.CancelNotification(ctx, created.ID)
+	assert.True(t, client.IsConflict(err))
+
+	_, err = c.GetNotification(ctx, "550e8400-e29b-41d4-a716-446655440000")
+	assert.True(t, client.IsNotFound(err))
+
+	err = c.CancelNotification(ctx, "550e8400-e29b-41d4-a716-446655440000")
+	assert.True(t, client.IsNotFound(err))
+
+	_, err = c.CreateNotification(ctx, client.CreateNotificationRequest{
+		Payload:          "",
+		NotificationDate: time.Now().Add(-time.Hour),
+		RecipientID:      "user@example.com",
+		Channel:          client.ChannelTelegram,
+	})
+	assert.True(t, client.IsValidationError(err))
+	var apiErr *client.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.ElementsMatch(t, []client.FieldViolation{
+		{Field: "payload", Code: "required", Message: "payload cannot be empty"},
+		{Field: "notification_date", Code: "past_date", Message: "notification_date cannot be in the past"},
+	}, apiErr.Details)
+
+	resent, err := c.ResendNotification(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, client.StatusPending, resent.Status)
+
+	_, err = c.ResendNotification(ctx, created.ID)
+	var pendingErr *client.APIError
+	require.ErrorAs(t, err, &pendingErr)
+	assert.Equal(t, client.CodePending, pendingErr.Code)
+
+	notifyAt := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	rescheduled, err := c.RescheduleNotification(ctx, created.ID, notifyAt)
+	require.NoError(t, err)
+	assert.True(t, notifyAt.Equal(rescheduled.NotificationDate))
+
+	history, err := c.GetNotificationHistory(ctx, created.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+	assert.Equal(t, []client.Status{client.StatusPending, client.StatusCancelled, client.StatusPending, client.StatusPending},
+		[]client.Status{history[0].Status, history[1].Status, history[2].Status, history[3].Status})
+
+	list, err := c.ListNotifications(ctx, client.ListNotificationsOptions{Status: client.StatusPending, Limit: 10})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, list.Total)
+	require.Len(t, list.Notifications, 1)
+	assert.Equal(t, created.ID, list.Notifications[0].ID)
+
+	counts, err := c.GetStatusCounts(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, counts.Counts[client.StatusPending])
+	assert.EqualValues(t, 0, counts.Counts[client.StatusSent])
+
+	_, err = c.ListNotifications(ctx, client.ListNotificationsOptions{Limit: 1000})
+	assert.True(t, client.IsValidationError(err))
+
+	expiresAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	suppression, err := c.Suppress(ctx, client.ChannelEmail, "user+news@example.com", client.SuppressRequest{
+		Reason:    client.SuppressionBounced,
+		ExpiresAt: &expiresAt,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "user+news@example.com", suppression.RecipientID)
+	assert.Equal(t, client.SuppressionBounced, suppression.Reason)
+
+	suppression, err = c.GetSuppression(ctx, client.ChannelEmail, "user+news@example.com")
+	require.NoError(t, err)
+	require.NotNil(t, suppression.ExpiresAt)
+	assert.True(t, expiresAt.Equal(*suppression.ExpiresAt))
+
+	suppressions, err := c.ListSuppressions(ctx, client.ListSuppressionsOptions{Reason: client.SuppressionBounced})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, suppressions.Total)
+
+	_, err = c.Suppress(ctx, "sms", "user@example.com", client.SuppressRequest{Reason: "spam"})
+	assert.True(t, client.IsValidationError(err))
+
+	require.NoError(t, c.Unsuppress(ctx, client.ChannelEmail, "user+news@example.com"))
+	_, err = c.GetSuppression(ctx, client.ChannelEmail, "user+news@example.com")
+	assert.True(t, client.IsNotFound(err))
+	assert.True(t, client.IsNotFound(c.Unsuppress(ctx, client.ChannelEmail, "user+news@example.com")))
+
+	unsubscribeURL := links.URL(tenant.DefaultID, "user@example.com", domain.ChannelEmail)
+	resp, err := server.Client().Get(strings.Replace(unsubscribeURL, testUnsubscribeURL, server.URL+"/api/v1/unsubscribe", 1))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = server.Client().PostForm(strings.Replace(unsubscribeURL, testUnsubscribeURL, server.URL+"/api/v1/unsubscribe", 1),
+		url.Values{"List-Unsubscribe": {"One-Click"}})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	suppression, err = c.GetSuppression(ctx, client.ChannelEmail, "user@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, client.SuppressionUnsubscribed, suppression.Reason)
+	assert.Nil(t, suppression.ExpiresAt)
+
+	resp, err = server.Client().Get(server.URL + "/api/v1/unsubscribe?token=forged")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	stats, err := c.GetRetentionStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "table", stats.Archive)
+
+	resp, err = server.Client().Get(server.URL + "/api/v1/openapi.json")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Empty(t, violations())
+}
+
+// testUnsubscribeURL адрес страницы отписки в ссылках тестового сервера
+const testUnsubscribeURL = "https://notifier.example.com/api/v1/unsubscribe"
+
+// newValidatedServer поднимает HTTP сервер приложения, проверяющий каждый
+// запрос и ответ на соответствие OpenAPI спецификации
+func newValidatedServer(t *testing.T) (*httptest.Server, *unsubscribe.Links, func() []string) {
+	t.Helper()
+
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(api.OpenAPISpec)
+	require.NoError(t, err)
+
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	// Страница отписки отвечает HTML, который проверяется только по типу содержимого
+	openapi3filter.RegisterBodyDecoder("text/html", openapi3filter.FileBodyDecoder)
+
+	cfg := &config.Config{
+		Redis:     config.RedisConfig{NotificationTTL: time.Hour},
+		Retention: config.RetentionConfig{Interval: time.Hour, BatchSize: 100, Archive: config.RetentionArchiveTable, Sent: time.Hour},
+	}
+
+	builder := NewDependencyBuilder(cfg)
+	repo := &memoryRepository{notifications: make(map[string]domain.Notification)}
+	builder.repo = repo
+	builder.queryRepo = repo
+	builder.suppressRepo = &memorySuppressionRepository{suppressions: make(map[string]domain.Suppression)}
+	builder.unsubscribe = unsubscribe.NewLinks(config.SuppressionConfig{
+		UnsubscribeURL:    testUnsubscribeURL,
+		UnsubscribeSecret: "test-secret",
+	})
+	builder.cache = &mockCache{}
+	builder.publisher = &mockPublisher{}
+	builder.senderFactory = sender.NewFactory(nil, nil)
+	builder.retentionJob = service.NewRetentionJob(&memoryRetentionRepository{}, tableArchiver{}, cfg.Retention)
+
+	deps, err := builder.Build()
+	require.NoError(t, err)
+
+	handler := createRouter(deps)
+
+	var (
+		mu         sync.Mutex
+		violations []string
+	)
+	report := func(msg string) {
+		mu.Lock()
+		defer mu.Unlock()
+		violations = append(violations, msg)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := router.FindRoute(r)
+		if err != nil {
+			report(r.Method + " " + r.URL.Path + ": " + err.Error())
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		validateOpenAPIExchange(r, w, handler, route, pathParams, report)
+	}))
+
+	return server, builder.unsubscribe, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), violations...)
+	}
+}
+
+func validateOpenAPIExchange(r *http.Request, w http.ResponseWriter, handler http.Handler, route *routers.Route, pathParams map[string]string, report func(string)) {
+	ctx := r.Context()
+
+	requestInput := &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: pathParams,
+		Route:      route,
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	requestValid := openapi3filter.ValidateRequest(ctx, requestInput) == nil
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, r)
+
+	// Запросы, нарушающие спецификацию, допустимо отклонять любым описанным ответом
+	if requestValid || recorder.Code >= http.StatusBadRequest {
+		responseInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: requestInput,
+			Status:                 recorder.Code,
+			Header:                 recorder.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(recorder.Body.Bytes())),
+		}
+		if err := openapi3filter.ValidateResponse(ctx, responseInput); err != nil {
+			report(r.Method + " " + r.URL.Path + " response: " + err.Error())
+		}
+	} else {
+		report(r.Method + " " + r.URL.Path + " request accepted despite violating spec")
+	}
+
+	for key, values := range recorder.Header() {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(recorder.Code)
+	w.Write(recorder.Body.Bytes())
+}
+
+type memoryRepository struct {
+	mu            sync.Mutex
+	notifications map[string]domain.Notification
+	history       map[string][]domain.StatusChange
+}
+
+// record сохраняет уведомление и, как триггер БД, дописывает запись истории
+func (m *memoryRepository) record(notification domain.Notification) {
+	if m.history == nil {
+		m.history = make(map[string][]domain.StatusChange)
+	}
+	m.notifications[notification.ID] = notification
+	m.history[notification.ID] = append(m.history[notification.ID], domain.StatusChange{
+		Status:           notification.Status,
+		NotificationDate: notification.NotificationDate,
+		ChangedAt:        time.Now(),
+	})
+}
+
+func (m *memoryRepository) Store(ctx context.Context, notification domain.Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.record(notification)
+	return nil
+}
+
+func (m *memoryRepository) LoadByID(ctx context.Context, id string) (*domain.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	notification, ok := m.notifications[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &notification, nil
+}
+
+func (m *memoryRepository) LoadStatusByID(ctx context.Context, id string) (domain.Status, error) {
+	notification, err := m.LoadByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return notification.Status, nil
+}
+
+func (m *memoryRepository) UpdateStatusByID(ctx context.Context, id string, status domain.Status) (*domain.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	notification, ok := m.notifications[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	notification.Status = status
+	m.record(notification)
+	return &notification, nil
+}
+
+func (m *memoryRepository) CancelByID(ctx context.Context, id string) error {
+	status, err := m.LoadStatusByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := domain.StatusConflictError(status); err != nil {
+		return err
+	}
+	_, err = m.UpdateStatusByID(ctx, id, domain.StatusCancelled)
+	return err
+}
+
+func (m *memoryRepository) RescheduleByID(ctx context.Context, id string, notificationDate time.Time, from []domain.Status) (*domain.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	notification, ok := m.notifications[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if !slices.Contains(from, notification.Status) {
+		if err := domain.StatusConflictError(notification.Status); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrPending
+	}
+	notification.Status = domain.StatusPending
+	notification.NotificationDate = notificationDate
+	m.record(notification)
+	return &notification, nil
+}
+
+func (m *memoryRepository) StoreWithinLimit(ctx context.Context, notification domain.Notification, since time.Time, limit int) error {
+	return m.Store(ctx, notification)
+}
+
+func (m *memoryRepository) List(ctx context.Context, filter domain.NotificationFilter) (domain.NotificationPage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	page := domain.NotificationPage{Notifications: []domain.Notification{}}
+	for _, notification := range m.notifications {
+		if filter.Status != "" && notification.Status != filter.Status {
+			continue
+		}
+		page.Total++
+		if len(page.Notifications) < filter.Limit {
+			page.Notifications = append(page.Notifications, notification)
+		}
+	}
+	return page, nil
+}
+
+func (m *memoryRepository) LoadHistory(ctx context.Context, id string) ([]domain.StatusChange, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.notifications[id]; !ok {
+		return nil, domain.ErrNotFound
+	}
+	return m.history[id], nil
+}
+
+func (m *memoryRepository) CountStatuses(ctx context.Context) (map[domain.Status]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := make(map[domain.Status]int64)
+	for _, notification := range m.notifications {
+		counts[notification.Status]++
+	}
+	return counts, nil
+}
+
+type memorySuppressionRepository struct {
+	mu           sync.Mutex
+	suppressions map[string]domain.Suppression
+}
+
+func suppressionMapKey(tenantID, recipientID string, channel domain.Channel) string {
+	return tenantID + "/" + string(channel) + "/" + recipientID
+}
+
+func (m *memorySuppressionRepository) UpsertSuppression(ctx context.Context, suppression domain.Suppression) (*domain.Suppression, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.suppressions[suppressionMapKey(suppression.TenantID, suppression.RecipientID, suppression.Channel)] = suppression
+	return &suppression, nil
+}
+
+func (m *memorySuppressionRepository) DeleteSuppression(ctx context.Context, tenantID, recipientID string, channel domain.Channel) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := suppressionMapKey(tenantID, recipientID, channel)
+	if _, ok := m.suppressions[key]; !ok {
+		return domain.ErrSuppressionNotFound
+	}
+	delete(m.suppressions, key)
+	return nil
+}
+
+func (m *memorySuppressionRepository) LoadSuppression(ctx context.Context, tenantID, recipientID string, channel domain.Channel) (*domain.Suppression, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	suppression, ok := m.suppressions[suppressionMapKey(tenantID, recipientID, channel)]
+	if !ok {
+		return nil, domain.ErrSuppressionNotFound
+	}
+	return &suppression, nil
+}
+
+func (m *memorySuppressionRepository) ListSuppressions(ctx context.Context, tenantID string, filter domain.SuppressionFilter) (domain.SuppressionPage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	page := domain.SuppressionPage{Suppressions: []domain.Suppression{}}
+	for _, suppression := range m.suppressions {
+		if suppression.TenantID != tenantID || !suppression.IsActive(filter.ActiveAt) {
+			continue
+		}
+		if filter.Reason != "" && suppression.Reason != filter.Reason {
+			continue
+		}
+		page.Total++
+		if len(page.Suppressions) < filter.Limit {
+			page.Suppressions = append(page.Suppressions, suppression)
+		}
+	}
+	return page, nil
+}
+
+type memoryRetentionRepository struct{}
+
+func (m *memoryRetentionRepository) LoadExpired(ctx context.Context, status domain.Status, olderThan time.Time, limit int) ([]domain.Notification, error) {
+	return nil, nil
+}
+func (m *memoryRetentionRepository) DeleteExpired(ctx context.Context, ids []string, status domain.Status, olderThan time.Time) (int64, error) {
+	return 0, nil
+}
+func (m *memoryRetentionRepository) CountByStatus(ctx context.Context) (map[domain.Status]int64, error) {
+	return map[domain.Status]int64{domain.StatusPending: 1}, nil
+}
+func (m *memoryRetentionRepository) CountExpired(ctx context.Context, status domain.Status, olderThan time.Time) (int64, error) {
+	return 0, nil
+}
+
+type tableArchiver struct{}
+
+func (tableArchiver) Archive(ctx context.Context, notifications []domain.Notification) error {
+	return nil
+}
+func (tableArchiver) Name() string { return "table" }
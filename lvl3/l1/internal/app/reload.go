@@ -0,0 +1,138 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/queue"
+	"delayed-notifier/internal/service"
+	"delayed-notifier/internal/tenant"
+
+	"github.com/rs/zerolog/log"
+)
+
+// hotReloadable перечисляет префиксы параметров, изменения которых применяются без перезапуска
+var hotReloadable = []string{
+	"worker.count",
+	"retry.publisher_",
+	"retry.max_retries",
+	"telegram.",
+	"email.",
+	"tenants.",
+}
+
+// requiresRestart сообщает, что изменение параметра вступит в силу только после перезапуска
+func requiresRestart(change config.Change) bool {
+	for _, key := range hotReloadable {
+		if strings.HasPrefix(change.Key, key) {
+			return false
+		}
+	}
+	return true
+}
+
+// watchConfig перечитывает конфигурацию по SIGHUP и, если включено reload.watch,
+// при изменении файла. Перезагрузки выполняются последовательно до отмены контекста приложения
+func (a *App) watchConfig() {
+	triggers := make(chan struct{}, 1)
+	trigger := func() {
+		select {
+		case triggers <- struct{}{}:
+		default:
+		}
+	}
+
+	if a.cfg.Reload.Watch {
+		if _, err := config.WatchFile(a.ctx, trigger); err != nil {
+			log.Warn().Err(err).Msg("Config file watch disabled, use SIGHUP to reload configuration")
+		}
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(hup)
+
+		for {
+			select {
+			case <-a.ctx.Done():
+				return
+			case <-hup:
+				log.Info().Msg("SIGHUP received, reloading configuration")
+			case <-triggers:
+				log.Info().Msg("Configuration file changed, reloading configuration")
+			}
+
+			a.reloadConfig()
+		}
+	}()
+}
+
+// reloadConfig перечитывает и валидирует конфигурацию и применяет изменения.
+// Если новая конфигурация некорректна, продолжает работать прежняя
+func (a *App) reloadConfig() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Error().Err(err).Msg("Configuration reload rejected, keeping current configuration")
+		return
+	}
+
+	if err := a.applyConfig(cfg); err != nil {
+		log.Error().Err(err).Msg("Configuration reload failed, keeping current configuration")
+	}
+}
+
+// applyConfig логирует отличия cfg от действующей конфигурации и применяет те,
+// что поддерживают перезагрузку. Отправители собираются заранее, поэтому ошибка
+// в их настройках не оставляет конфигурацию примененной наполовину
+func (a *App) applyConfig(cfg *config.Config) error {
+	changes := config.Diff(a.applied, cfg)
+	if len(changes) == 0 {
+		log.Info().Msg("Configuration unchanged")
+		return nil
+	}
+
+	senderFactory, err := initSenders(cfg, a.deps.UnsubscribeLinks)
+	if err != nil {
+		return fmt.Errorf("failed to initialize senders: %w", err)
+	}
+
+	for _, change := range changes {
+		event := log.Info()
+		if requiresRestart(change) {
+			event = log.Warn().Bool("requires_restart", true)
+		}
+		event.
+			Str("key", change.Key).
+			Str("old", change.Old).
+			Str("new", change.New).
+			Msg("Configuration changed")
+	}
+
+	if a.deps.SenderFactory != nil {
+		a.deps.SenderFactory.Replace(senderFactory)
+	}
+
+	if notifier, ok := a.deps.NotificationService.(*service.NotifierService); ok {
+		notifier.SetQuotas(tenant.NewQuotas(cfg.Tenants))
+		notifier.SetRetryConfig(cfg.Retry)
+	}
+
+	if publisher, ok := a.deps.QueuePublisher.(*queue.RabbitMQPublisher); ok {
+		publisher.SetRetryConfig(cfg.Retry)
+	}
+
+	if a.workerManager != nil {
+		a.workerManager.Resize(cfg.Worker.Count)
+	}
+
+	a.applied = cfg
+	log.Info().Int("changes", len(changes)).Msg("Configuration reloaded")
+
+	return nil
+}
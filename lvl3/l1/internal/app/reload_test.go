@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reloadTestConfig() *config.Config {
+	return &config.Config{
+		Telegram: config.TelegramConfig{BotToken: "old_token", ChatID: 1},
+		Email: config.EmailConfig{
+			SMTPHost:  "smtp.example.com",
+			SMTPPort:  "587",
+			Username:  "test@example.com",
+			Password:  "test_password",
+			FromEmail: "test@example.com",
+		},
+		Worker: config.WorkerConfig{Count: 2},
+		Retry:  config.RetryConfig{MaxRetries: 3},
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := reloadTestConfig()
+	senderFactory, err := initSenders(cfg, nil)
+	require.NoError(t, err)
+	notifier := service.NewNotifierService(&mockRepository{}, &mockCache{}, &mockPublisher{}, senderFactory, nil, time.Hour)
+
+	manager := service.NewManager(ctx, cancel, nil, notifier, cfg.Worker)
+	manager.Resize(cfg.Worker.Count)
+	defer manager.Stop()
+
+	a := &App{
+		ctx:           ctx,
+		cfg:           cfg,
+		applied:       cfg,
+		workerManager: manager,
+		deps: &Dependencies{
+			NotificationService: notifier,
+			SenderFactory:       senderFactory,
+		},
+	}
+
+	before, err := senderFactory.GetSender(domain.ChannelTelegram)
+	require.NoError(t, err)
+
+	updated := reloadTestConfig()
+	updated.Telegram.BotToken = "new_token"
+	updated.Worker.Count = 4
+
+	require.NoError(t, a.applyConfig(updated))
+
+	assert.Equal(t, 4, manager.WorkerCount())
+	assert.Same(t, updated, a.applied)
+
+	after, err := senderFactory.GetSender(domain.ChannelTelegram)
+	require.NoError(t, err)
+	assert.NotSame(t, before, after, "senders are rebuilt from the new config")
+}
+
+func TestApplyConfig_InvalidSendersKeepCurrent(t *testing.T) {
+	cfg := reloadTestConfig()
+	senderFactory, err := initSenders(cfg, nil)
+	require.NoError(t, err)
+
+	a := &App{
+		cfg:     cfg,
+		applied: cfg,
+		deps:    &Dependencies{SenderFactory: senderFactory},
+	}
+
+	updated := reloadTestConfig()
+	updated.Email.SMTPPort = "not-a-port"
+
+	assert.Error(t, a.applyConfig(updated))
+	assert.Same(t, cfg, a.applied)
+}
+
+func TestRequiresRestart(t *testing.T) {
+	assert.False(t, requiresRestart(config.Change{Key: "worker.count"}))
+	assert.False(t, requiresRestart(config.Change{Key: "retry.publisher_attempts"}))
+	assert.False(t, requiresRestart(config.Change{Key: "tenants.billing.daily_limit"}))
+	assert.True(t, requiresRestart(config.Change{Key: "http.port"}))
+	assert.True(t, requiresRestart(config.Change{Key: "retry.consumer_attempts"}))
+}
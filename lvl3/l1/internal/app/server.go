@@ -6,8 +6,12 @@ import (
 	"time"
 
 	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/handlers"
+	"delayed-notifier/internal/metrics"
 
 	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // NewHTTPServer создает новый HTTP сервер
@@ -25,6 +29,7 @@ func NewHTTPServer(cfg *config.Config, deps *Dependencies) *http.Server {
 
 func createRouter(deps *Dependencies) *chi.Mux {
 	r := chi.NewRouter()
+	r.Use(otelhttp.NewMiddleware("http.server"), routeSpanName)
 
 	r.Handle("/web/*", http.StripPrefix("/web/", http.FileServer(http.Dir("./web"))))
 
@@ -32,11 +37,60 @@ func createRouter(deps *Dependencies) *chi.Mux {
 		http.Redirect(w, r, "/web/", http.StatusFound)
 	})
 
+	r.Handle("/metrics", metrics.Handler())
+
 	r.Route("/api/v1", func(r chi.Router) {
-		r.Post("/notify", deps.NotificationHandler.CreateNotification)
-		r.Get("/notify/{id}", deps.NotificationHandler.GetNotificationStatus)
-		r.Delete("/notify/{id}", deps.NotificationHandler.CancelNotification)
+		r.Get("/openapi.json", handlers.GetOpenAPISpec)
+
+		// Страница отписки открывается по подписанной ссылке из письма без токена доступа
+		if deps.SuppressionHandler != nil {
+			r.Get("/unsubscribe", deps.SuppressionHandler.UnsubscribePage)
+			r.Post("/unsubscribe", deps.SuppressionHandler.Unsubscribe)
+		}
+
+		r.Group(func(r chi.Router) {
+			r.Use(deps.Authenticator.Middleware)
+
+			r.Post("/notify", deps.NotificationHandler.CreateNotification)
+			r.Get("/notify/events", deps.EventsHandler.StreamEvents)
+			r.Get("/notify/{id}/events", deps.EventsHandler.StreamNotificationEvents)
+			r.Get("/notify/{id}", deps.NotificationHandler.GetNotificationStatus)
+			r.Delete("/notify/{id}", deps.NotificationHandler.CancelNotification)
+			r.Post("/notify/{id}/reschedule", deps.NotificationHandler.RescheduleNotification)
+			r.Post("/notify/{id}/resend", deps.NotificationHandler.ResendNotification)
+
+			if deps.DashboardHandler != nil {
+				r.Get("/notify", deps.DashboardHandler.ListNotifications)
+				r.Get("/notify/stats", deps.DashboardHandler.GetStatusCounts)
+				r.Get("/notify/{id}/history", deps.DashboardHandler.GetHistory)
+			}
+
+			if deps.SuppressionHandler != nil {
+				r.Get("/suppressions", deps.SuppressionHandler.ListSuppressions)
+				r.Get("/suppressions/{channel}/{recipient_id}", deps.SuppressionHandler.GetSuppression)
+				r.Put("/suppressions/{channel}/{recipient_id}", deps.SuppressionHandler.PutSuppression)
+				r.Delete("/suppressions/{channel}/{recipient_id}", deps.SuppressionHandler.DeleteSuppression)
+			}
+
+			if deps.RetentionHandler != nil {
+				r.Get("/retention/stats", deps.RetentionHandler.GetStats)
+			}
+		})
 	})
 
 	return r
 }
+
+// routeSpanName называет HTTP спан по шаблону маршрута chi, например
+// "GET /api/v1/notify/{id}", чтобы имена спанов не зависели от ID
+func routeSpanName(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+
+		routeContext := chi.RouteContext(r.Context())
+		if routeContext == nil || routeContext.RoutePattern() == "" {
+			return
+		}
+		trace.SpanFromContext(r.Context()).SetName(r.Method + " " + routeContext.RoutePattern())
+	})
+}
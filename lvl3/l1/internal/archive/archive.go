@@ -0,0 +1,13 @@
+package archive
+
+import (
+	"context"
+
+	"delayed-notifier/internal/domain"
+)
+
+// Archiver определяет интерфейс для сохранения уведомлений в архив перед удалением
+type Archiver interface {
+	Archive(ctx context.Context, notifications []domain.Notification) error
+	Name() string
+}
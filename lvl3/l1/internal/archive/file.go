@@ -0,0 +1,100 @@
+package archive
+
+import (
+	"compress/gzip"
+	"context"
+	"delayed-notifier/internal/domain"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const archiveFilePermissions = 0o640
+
+// FileArchiver архивирует уведомления в gzip NDJSON файлы, по файлу на пачку
+type FileArchiver struct {
+	dir string
+	seq atomic.Uint64
+	now func() time.Time
+}
+
+// NewFileArchiver создает новый файловый архиватор и каталог для архивов
+func NewFileArchiver(dir string) (*FileArchiver, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create archive dir: %w", err)
+	}
+
+	return &FileArchiver{
+		dir: dir,
+		now: time.Now,
+	}, nil
+}
+
+// Name возвращает название архива
+func (a *FileArchiver) Name() string {
+	return "file"
+}
+
+// Archive записывает уведомления в новый gzip NDJSON файл.
+// Файл сначала пишется во временный, затем атомарно переименовывается
+func (a *FileArchiver) Archive(ctx context.Context, notifications []domain.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	name := fmt.Sprintf("notifications-%s-%06d.ndjson.gz", a.now().UTC().Format("20060102T150405"), a.seq.Add(1))
+	path := filepath.Join(a.dir, name)
+	tmpPath := path + ".tmp"
+
+	if err := a.writeFile(ctx, tmpPath, notifications); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to finalize archive file: %w", err)
+	}
+
+	log.Debug().
+		Str("file", path).
+		Int("count", len(notifications)).
+		Msg("Notifications archived to file")
+
+	return nil
+}
+
+func (a *FileArchiver) writeFile(ctx context.Context, path string, notifications []domain.Notification) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, archiveFilePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	encoder := json.NewEncoder(gz)
+
+	for _, notification := range notifications {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := encoder.Encode(notification); err != nil {
+			return fmt.Errorf("failed to encode archived notification: %w", err)
+		}
+	}
+
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to flush archive file: %w", err)
+	}
+
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync archive file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"delayed-notifier/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileArchiver_Archive(t *testing.T) {
+	dir := t.TempDir()
+
+	archiver, err := NewFileArchiver(dir)
+	require.NoError(t, err)
+
+	notifications := []domain.Notification{
+		{ID: "first", Payload: "Hello", Status: domain.StatusSent, Channel: domain.ChannelEmail, CreatedDate: time.Now().UTC()},
+		{ID: "second", Payload: "World", Status: domain.StatusFailed, Channel: domain.ChannelTelegram, CreatedDate: time.Now().UTC()},
+	}
+
+	require.NoError(t, archiver.Archive(context.Background(), notifications))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.ndjson.gz"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	file, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+
+	var archived []domain.Notification
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var notification domain.Notification
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &notification))
+		archived = append(archived, notification)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, archived, 2)
+	assert.Equal(t, "first", archived[0].ID)
+	assert.Equal(t, domain.StatusFailed, archived[1].Status)
+}
+
+func TestFileArchiver_EmptyBatch(t *testing.T) {
+	dir := t.TempDir()
+
+	archiver, err := NewFileArchiver(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, archiver.Archive(context.Background(), nil))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/tenant"
+)
+
+var (
+	// ErrMissingToken возвращается, когда запрос не содержит токен доступа
+	ErrMissingToken = errors.New("missing access token")
+	// ErrInvalidToken возвращается, когда токен доступа не найден
+	ErrInvalidToken = errors.New("invalid access token")
+)
+
+type subjectKey struct{}
+
+// Authenticator проверяет токены доступа к API. Один экземпляр используется
+// HTTP и gRPC серверами
+type Authenticator struct {
+	tokens map[string]string
+}
+
+// NewAuthenticator создает новый аутентификатор. Если токены не заданы,
+// аутентификация отключена и все запросы пропускаются
+func NewAuthenticator(cfg config.AuthConfig) *Authenticator {
+	tokens := make(map[string]string, len(cfg.Tokens))
+	for token, subject := range cfg.Tokens {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens[token] = subject
+		}
+	}
+
+	return &Authenticator{tokens: tokens}
+}
+
+// Enabled сообщает, включена ли аутентификация
+func (a *Authenticator) Enabled() bool {
+	return len(a.tokens) > 0
+}
+
+// Authenticate проверяет токен и возвращает субъекта, которому он выдан
+func (a *Authenticator) Authenticate(token string) (string, error) {
+	if token == "" {
+		return "", ErrMissingToken
+	}
+
+	for known, subject := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(token)) == 1 {
+			return subject, nil
+		}
+	}
+
+	return "", ErrInvalidToken
+}
+
+// WithSubject сохраняет аутентифицированного субъекта в контексте.
+// Субъект также становится тенантом запроса
+func WithSubject(ctx context.Context, subject string) context.Context {
+	ctx = tenant.WithID(ctx, subject)
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFromContext возвращает аутентифицированного субъекта из контекста
+func SubjectFromContext(ctx context.Context) (string, bool) {
+	subject, ok := ctx.Value(subjectKey{}).(string)
+	return subject, ok
+}
+
+// ParseBearer извлекает токен из значения заголовка Authorization
+func ParseBearer(header string) string {
+	const prefix = "bearer "
+	if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+		return strings.TrimSpace(header[len(prefix):])
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"delayed-notifier/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	authenticator := NewAuthenticator(config.AuthConfig{Tokens: map[string]string{"secret": "billing"}})
+
+	subject, err := authenticator.Authenticate("secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "billing", subject)
+
+	_, err = authenticator.Authenticate("")
+	assert.ErrorIs(t, err, ErrMissingToken)
+
+	_, err = authenticator.Authenticate("wrong")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestAuthenticator_Middleware(t *testing.T) {
+	authenticator := NewAuthenticator(config.AuthConfig{Tokens: map[string]string{"secret": "billing"}})
+
+	var gotSubject string
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSubject, _ = SubjectFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		prepare    func(r *http.Request)
+		wantStatus int
+	}{
+		{name: "no token", prepare: func(r *http.Request) {}, wantStatus: http.StatusUnauthorized},
+		{name: "bearer", prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") }, wantStatus: http.StatusOK},
+		{name: "api key", prepare: func(r *http.Request) { r.Header.Set("X-API-Key", "secret") }, wantStatus: http.StatusOK},
+		{name: "query", prepare: func(r *http.Request) { r.URL.RawQuery = "access_token=secret" }, wantStatus: http.StatusOK},
+		{name: "wrong token", prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSubject = ""
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/notify/events", nil)
+			tt.prepare(req)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.wantStatus, recorder.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, "billing", gotSubject)
+			}
+		})
+	}
+}
+
+func TestAuthenticator_Disabled(t *testing.T) {
+	authenticator := NewAuthenticator(config.AuthConfig{})
+	assert.False(t, authenticator.Enabled())
+
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+}
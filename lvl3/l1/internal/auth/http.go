@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+// accessTokenParam позволяет передать токен в query, так как EventSource
+// в браузере не умеет отправлять заголовки
+const accessTokenParam = "access_token"
+
+// Middleware проверяет токен доступа HTTP запросов. Токен передается
+// в заголовке Authorization: Bearer, X-API-Key или параметре access_token
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		subject, err := a.Authenticate(tokenFromRequest(r))
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Msg("Unauthorized request")
+			writeUnauthorized(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithSubject(r.Context(), subject)))
+	})
+}
+
+func tokenFromRequest(r *http.Request) string {
+	if token := ParseBearer(r.Header.Get("Authorization")); token != "" {
+		return token
+	}
+	if token := r.Header.Get("X-API-Key"); token != "" {
+		return token
+	}
+	return r.URL.Query().Get(accessTokenParam)
+}
+
+func writeUnauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	w.WriteHeader(http.StatusUnauthorized)
+
+	if encodeErr := json.NewEncoder(w).Encode(map[string]string{"error": err.Error(), "code": "unauthorized"}); encodeErr != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"reflect"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -12,15 +15,25 @@ import (
 
 // Config содержит конфигурацию приложения
 type Config struct {
-	HTTP     HTTPConfig     `mapstructure:"http"`
-	DBConfig DBConfig       `mapstructure:"postgres"`
-	RabbitMQ RabbitMQConfig `mapstructure:"rabbitmq"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
-	Telegram TelegramConfig `mapstructure:"telegram"`
-	Email    EmailConfig    `mapstructure:"email"`
-	Worker   WorkerConfig   `mapstructure:"worker"`
-	Retry    RetryConfig    `mapstructure:"retry"`
+	HTTP        HTTPConfig        `mapstructure:"http"`
+	GRPC        GRPCConfig        `mapstructure:"grpc"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+	DBConfig    DBConfig          `mapstructure:"postgres"`
+	RabbitMQ    RabbitMQConfig    `mapstructure:"rabbitmq"`
+	Redis       RedisConfig       `mapstructure:"redis"`
+	Logging     LoggingConfig     `mapstructure:"logging"`
+	Telegram    TelegramConfig    `mapstructure:"telegram"`
+	Email       EmailConfig       `mapstructure:"email"`
+	Worker      WorkerConfig      `mapstructure:"worker"`
+	Retry       RetryConfig       `mapstructure:"retry"`
+	Retention   RetentionConfig   `mapstructure:"retention"`
+	Digest      DigestConfig      `mapstructure:"digest"`
+	Tracing     TracingConfig     `mapstructure:"tracing"`
+	Reload      ReloadConfig      `mapstructure:"reload"`
+	Suppression SuppressionConfig `mapstructure:"suppression"`
+	// Tenants задает квоты и профили отправителей тенантов по их идентификатору.
+	// Идентификатор тенанта совпадает с именем клиента в auth.tokens
+	Tenants map[string]TenantConfig `mapstructure:"tenants" ignored:"true"`
 }
 
 // HTTPConfig содержит конфигурацию HTTP сервера
@@ -31,6 +44,18 @@ type HTTPConfig struct {
 	IdleTimeout  time.Duration `mapstructure:"idle_timeout" envconfig:"HTTP_IDLE_TIMEOUT" default:"60s"`
 }
 
+// GRPCConfig содержит конфигурацию gRPC сервера
+type GRPCConfig struct {
+	Port            int           `mapstructure:"port" envconfig:"GRPC_SERVER_PORT" default:"9090"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" envconfig:"GRPC_SHUTDOWN_TIMEOUT" default:"10s"`
+}
+
+// AuthConfig содержит конфигурацию аутентификации API.
+// Tokens сопоставляет токен доступа с именем клиента, пустой список отключает аутентификацию
+type AuthConfig struct {
+	Tokens map[string]string `mapstructure:"tokens" envconfig:"AUTH_TOKENS"`
+}
+
 // DBConfig содержит конфигурацию базы данных
 type DBConfig struct {
 	Host            string        `mapstructure:"host" envconfig:"POSTGRES_HOST" default:"localhost"`
@@ -66,6 +91,7 @@ type RedisConfig struct {
 	PoolTimeout     time.Duration `mapstructure:"pool_timeout" envconfig:"REDIS_POOL_TIMEOUT" default:"30s"`
 	IdleTimeout     time.Duration `mapstructure:"idle_timeout" envconfig:"REDIS_IDLE_TIMEOUT" default:"5m"`
 	IdleCheckFreq   time.Duration `mapstructure:"idle_check_freq" envconfig:"REDIS_IDLE_CHECK_FREQ" default:"1m"`
+	EventsChannel   string        `mapstructure:"events_channel" envconfig:"REDIS_EVENTS_CHANNEL" default:"notification_status_events"`
 }
 
 // LoggingConfig содержит конфигурацию логирования
@@ -90,6 +116,18 @@ type EmailConfig struct {
 	FromName  string `mapstructure:"from_name" envconfig:"EMAIL_FROM_NAME" default:"Notification Service"`
 }
 
+// TenantConfig содержит квоты и профиль отправителей тенанта.
+// Нулевые квоты не ограничивают тенанта, незаданные отправители заменяются общими
+type TenantConfig struct {
+	// DailyLimit - уведомлений в сутки (UTC), общий лимит для всех реплик
+	DailyLimit int `mapstructure:"daily_limit"`
+	// MaxConcurrentSends - одновременных отправок в одном процессе: лимит не делится между репликами,
+	// при N репликах тенант отправляет до N × MaxConcurrentSends уведомлений одновременно
+	MaxConcurrentSends int             `mapstructure:"max_concurrent_sends"`
+	Telegram           *TelegramConfig `mapstructure:"telegram"`
+	Email              *EmailConfig    `mapstructure:"email"`
+}
+
 // WorkerConfig содержит конфигурацию воркеров
 type WorkerConfig struct {
 	Count          int           `mapstructure:"count" envconfig:"WORKER_COUNT" default:"3"`
@@ -107,14 +145,93 @@ type RetryConfig struct {
 	MaxRetries        int           `mapstructure:"max_retries" envconfig:"RETRY_MAX_RETRIES" default:"3"`
 }
 
-// LoadConfig загружает конфигурацию из файла и переменных окружения
-func LoadConfig() (*Config, error) {
-	var cfg Config
+// RetentionConfig содержит конфигурацию хранения и архивации уведомлений.
+// Нулевой срок хранения для статуса отключает архивацию уведомлений с этим статусом
+type RetentionConfig struct {
+	Enabled    bool          `mapstructure:"enabled" envconfig:"RETENTION_ENABLED" default:"false"`
+	Interval   time.Duration `mapstructure:"interval" envconfig:"RETENTION_INTERVAL" default:"1h"`
+	BatchSize  int           `mapstructure:"batch_size" envconfig:"RETENTION_BATCH_SIZE" default:"500"`
+	Archive    string        `mapstructure:"archive" envconfig:"RETENTION_ARCHIVE" default:"table"`
+	ArchiveDir string        `mapstructure:"archive_dir" envconfig:"RETENTION_ARCHIVE_DIR" default:"./archive"`
+	Sent       time.Duration `mapstructure:"sent" envconfig:"RETENTION_SENT" default:"720h"`
+	Failed     time.Duration `mapstructure:"failed" envconfig:"RETENTION_FAILED" default:"720h"`
+	Cancelled  time.Duration `mapstructure:"cancelled" envconfig:"RETENTION_CANCELLED" default:"168h"`
+	Expired    time.Duration `mapstructure:"expired" envconfig:"RETENTION_EXPIRED" default:"168h"`
+	Suppressed time.Duration `mapstructure:"suppressed" envconfig:"RETENTION_SUPPRESSED" default:"168h"`
+	Pending    time.Duration `mapstructure:"pending" envconfig:"RETENTION_PENDING" default:"0s"`
+}
 
+const (
+	// RetentionArchiveTable архивирует уведомления в партиционированную таблицу
+	RetentionArchiveTable = "table"
+	// RetentionArchiveFile архивирует уведомления в gzip NDJSON файлы
+	RetentionArchiveFile = "file"
+)
+
+// DigestConfig содержит конфигурацию дайджестов.
+// Уведомления с флагом digest копятся по получателю и каналу и раз в Interval
+// отправляются одним сообщением, собранным по шаблону Template (пустой путь — шаблон по умолчанию)
+type DigestConfig struct {
+	Enabled  bool          `mapstructure:"enabled" envconfig:"DIGEST_ENABLED" default:"false"`
+	Interval time.Duration `mapstructure:"interval" envconfig:"DIGEST_INTERVAL" default:"1h"`
+	MaxItems int           `mapstructure:"max_items" envconfig:"DIGEST_MAX_ITEMS" default:"50"`
+	Template string        `mapstructure:"template" envconfig:"DIGEST_TEMPLATE"`
+}
+
+// TracingConfig содержит конфигурацию трассировки OpenTelemetry.
+// Exporter none отключает экспорт спанов, stdout печатает их в stdout,
+// otlp отправляет их по OTLP/gRPC на Endpoint
+type TracingConfig struct {
+	Exporter    string  `mapstructure:"exporter" envconfig:"TRACING_EXPORTER" default:"none"`
+	Endpoint    string  `mapstructure:"endpoint" envconfig:"TRACING_ENDPOINT" default:"localhost:4317"`
+	Insecure    bool    `mapstructure:"insecure" envconfig:"TRACING_INSECURE" default:"true"`
+	ServiceName string  `mapstructure:"service_name" envconfig:"TRACING_SERVICE_NAME" default:"delayed-notifier"`
+	SampleRatio float64 `mapstructure:"sample_ratio" envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
+}
+
+// ReloadConfig содержит конфигурацию перечитывания конфигурации без перезапуска.
+// По SIGHUP конфигурация перечитывается всегда, Watch дополнительно следит за файлом
+type ReloadConfig struct {
+	Watch bool `mapstructure:"watch" envconfig:"CONFIG_WATCH" default:"true"`
+}
+
+// SuppressionConfig содержит конфигурацию ссылок отписки в email уведомлениях.
+// UnsubscribeURL — публичный адрес страницы отписки, к которому добавляется токен,
+// подписанный UnsubscribeSecret. Пустой адрес отключает ссылки отписки
+type SuppressionConfig struct {
+	UnsubscribeURL    string `mapstructure:"unsubscribe_url" envconfig:"UNSUBSCRIBE_URL"`
+	UnsubscribeSecret string `mapstructure:"unsubscribe_secret" envconfig:"UNSUBSCRIBE_SECRET"`
+}
+
+const (
+	// TracingExporterNone отключает экспорт спанов
+	TracingExporterNone = "none"
+	// TracingExporterStdout печатает спаны в stdout
+	TracingExporterStdout = "stdout"
+	// TracingExporterOTLP отправляет спаны по OTLP/gRPC
+	TracingExporterOTLP = "otlp"
+)
+
+// LoadConfig загружает конфигурацию из файла и переменных окружения.
+// Значения по умолчанию перекрываются файлом, а файл — явно заданными переменными окружения.
+// Повторный вызов перечитывает файл, что используется при перезагрузке конфигурации
+func LoadConfig() (*Config, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(".")
 
+	if err := godotenv.Load(); err != nil {
+		log.Info().Msg(".env file not found, using system environment variables")
+	}
+
+	var fromEnv Config
+	if err := envconfig.Process("", &fromEnv); err != nil {
+		return nil, err
+	}
+
+	cfg := fromEnv
+	cfg.Auth.Tokens = nil
+
 	if err := viper.ReadInConfig(); err == nil {
 		log.Info().Str("config_file", viper.ConfigFileUsed()).Msg("Using configuration file")
 		if err := viper.Unmarshal(&cfg); err != nil {
@@ -124,12 +241,8 @@ func LoadConfig() (*Config, error) {
 		log.Info().Msg("YAML config not found, using defaults and env")
 	}
 
-	if err := godotenv.Load(); err != nil {
-		log.Info().Msg(".env file not found, using system environment variables")
-	}
-	if err := envconfig.Process("", &cfg); err != nil {
-		return nil, err
-	}
+	applyEnvOverrides(reflect.ValueOf(&cfg).Elem(), reflect.ValueOf(&fromEnv).Elem())
+
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
@@ -137,6 +250,27 @@ func LoadConfig() (*Config, error) {
 	return &cfg, nil
 }
 
+// applyEnvOverrides переносит из fromEnv значения полей, для которых явно задана
+// переменная окружения. envconfig подставляет значения по умолчанию и для незаданных
+// переменных, поэтому его результат нельзя накладывать на значения из файла целиком
+func applyEnvOverrides(dst, fromEnv reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Type().Field(i)
+
+		key := field.Tag.Get("envconfig")
+		if key == "" {
+			if field.Type.Kind() == reflect.Struct {
+				applyEnvOverrides(dst.Field(i), fromEnv.Field(i))
+			}
+			continue
+		}
+
+		if _, ok := os.LookupEnv(key); ok {
+			dst.Field(i).Set(fromEnv.Field(i))
+		}
+	}
+}
+
 // GetDSN возвращает строку подключения к базе данных
 func (d *DBConfig) GetDSN() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
@@ -148,6 +282,12 @@ func (c *Config) Validate() error {
 	if c.HTTP.Port <= 0 || c.HTTP.Port > 65535 {
 		return fmt.Errorf("invalid HTTP port: %d", c.HTTP.Port)
 	}
+	if c.GRPC.Port <= 0 || c.GRPC.Port > 65535 {
+		return fmt.Errorf("invalid gRPC port: %d", c.GRPC.Port)
+	}
+	if c.GRPC.Port == c.HTTP.Port {
+		return fmt.Errorf("gRPC port must differ from HTTP port: %d", c.GRPC.Port)
+	}
 	if c.RabbitMQ.URL == "" {
 		return fmt.Errorf("RabbitMQ URL is required")
 	}
@@ -160,6 +300,108 @@ func (c *Config) Validate() error {
 	if c.Redis.NotificationTTL <= 0 {
 		return fmt.Errorf("redis NotificationTTL must be positive")
 	}
+	if c.Worker.Count <= 0 {
+		return fmt.Errorf("worker count must be positive")
+	}
+	if c.Retry.MaxRetries < 0 {
+		return fmt.Errorf("retry MaxRetries must be non-negative")
+	}
+	if err := c.Retention.Validate(); err != nil {
+		return err
+	}
+	if err := c.Digest.Validate(); err != nil {
+		return err
+	}
+	for id, tenantConfig := range c.Tenants {
+		if err := tenantConfig.Validate(); err != nil {
+			return fmt.Errorf("tenant %s: %w", id, err)
+		}
+	}
+	if err := c.Tracing.Validate(); err != nil {
+		return err
+	}
+	if err := c.Suppression.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Validate валидирует конфигурацию хранения
+func (r *RetentionConfig) Validate() error {
+	if !r.Enabled {
+		return nil
+	}
+	if r.Interval <= 0 {
+		return fmt.Errorf("retention interval must be positive")
+	}
+	if r.BatchSize <= 0 {
+		return fmt.Errorf("retention batch size must be positive")
+	}
+	if r.Archive != RetentionArchiveTable && r.Archive != RetentionArchiveFile {
+		return fmt.Errorf("invalid retention archive: %s", r.Archive)
+	}
+	if r.Archive == RetentionArchiveFile && r.ArchiveDir == "" {
+		return fmt.Errorf("retention archive dir is required for file archive")
+	}
+	if r.Sent < 0 || r.Failed < 0 || r.Cancelled < 0 || r.Expired < 0 || r.Suppressed < 0 || r.Pending < 0 {
+		return fmt.Errorf("retention periods must be non-negative")
+	}
+	return nil
+}
+
+// Validate валидирует конфигурацию дайджестов
+func (d *DigestConfig) Validate() error {
+	if !d.Enabled {
+		return nil
+	}
+	if d.Interval <= 0 {
+		return fmt.Errorf("digest interval must be positive")
+	}
+	if d.MaxItems <= 0 {
+		return fmt.Errorf("digest max items must be positive")
+	}
+	return nil
+}
 
+// Validate валидирует конфигурацию тенанта
+func (t *TenantConfig) Validate() error {
+	if t.DailyLimit < 0 {
+		return fmt.Errorf("daily limit must be non-negative")
+	}
+	if t.MaxConcurrentSends < 0 {
+		return fmt.Errorf("max concurrent sends must be non-negative")
+	}
+	return nil
+}
+
+// Validate валидирует конфигурацию ссылок отписки
+func (s *SuppressionConfig) Validate() error {
+	if s.UnsubscribeURL == "" {
+		return nil
+	}
+	if s.UnsubscribeSecret == "" {
+		return fmt.Errorf("unsubscribe secret is required when unsubscribe URL is set")
+	}
+	if _, err := url.ParseRequestURI(s.UnsubscribeURL); err != nil {
+		return fmt.Errorf("invalid unsubscribe URL: %w", err)
+	}
+	return nil
+}
+
+// Validate валидирует конфигурацию трассировки
+func (t *TracingConfig) Validate() error {
+	switch t.Exporter {
+	case TracingExporterNone, TracingExporterStdout:
+	case TracingExporterOTLP:
+		if t.Endpoint == "" {
+			return fmt.Errorf("tracing endpoint is required for otlp exporter")
+		}
+	default:
+		return fmt.Errorf("invalid tracing exporter: %s", t.Exporter)
+	}
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio must be in [0, 1]: %v", t.SampleRatio)
+	}
 	return nil
 }
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// watchDebounce объединяет серию событий от одного сохранения файла редактором
+const watchDebounce = 200 * time.Millisecond
+
+// WatchFile вызывает onChange после каждого изменения файла конфигурации до отмены контекста.
+// Следит за каталогом файла, чтобы замечать замену файла переименованием.
+// Возвращает false, если конфигурация загружена не из файла
+func WatchFile(ctx context.Context, onChange func()) (bool, error) {
+	file := viper.ConfigFileUsed()
+	if file == "" {
+		return false, nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return false, fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		watcher.Close()
+		return false, fmt.Errorf("failed to watch config directory: %w", err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var debounce *time.Timer
+		for {
+			select {
+			case <-ctx.Done():
+				if debounce != nil {
+					debounce.Stop()
+				}
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Base(event.Name) != filepath.Base(file) || !event.Has(fsnotify.Write|fsnotify.Create) {
+					continue
+				}
+				if debounce != nil {
+					debounce.Stop()
+				}
+				debounce = time.AfterFunc(watchDebounce, onChange)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Warn().Err(err).Str("config_file", file).Msg("Config watcher error")
+			}
+		}
+	}()
+
+	log.Info().Str("config_file", file).Msg("Watching configuration file for changes")
+	return true, nil
+}
@@ -0,0 +1,91 @@
+package digest
+
+import (
+	"bytes"
+	"delayed-notifier/internal/domain"
+	"fmt"
+	"os"
+	"text/template"
+	"time"
+)
+
+// DefaultTemplate шаблон дайджеста по умолчанию
+const DefaultTemplate = `You have {{.Count}} new notification{{if ne .Count 1}}s{{end}}:
+{{range .Notifications}}
+- [{{.NotificationDate.Format "2006-01-02 15:04"}}] {{.Payload}}{{end}}
+`
+
+// Item описывает одно уведомление в дайджесте
+type Item struct {
+	ID               string
+	Payload          string
+	SenderID         string
+	NotificationDate time.Time
+}
+
+// Data содержит данные, доступные в шаблоне дайджеста
+type Data struct {
+	ID            string
+	RecipientID   string
+	Channel       domain.Channel
+	Count         int
+	CreatedAt     time.Time
+	Notifications []Item
+}
+
+// Renderer собирает текст дайджеста из уведомлений по шаблону text/template
+type Renderer struct {
+	tmpl *template.Template
+}
+
+// NewRenderer создает рендерер с шаблоном из файла templateFile.
+// Пустой путь означает шаблон по умолчанию
+func NewRenderer(templateFile string) (*Renderer, error) {
+	text := DefaultTemplate
+	if templateFile != "" {
+		content, err := os.ReadFile(templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read digest template: %w", err)
+		}
+		text = string(content)
+	}
+
+	return NewRendererFromString(text)
+}
+
+// NewRendererFromString создает рендерер с шаблоном из строки
+func NewRendererFromString(text string) (*Renderer, error) {
+	tmpl, err := template.New("digest").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse digest template: %w", err)
+	}
+
+	return &Renderer{tmpl: tmpl}, nil
+}
+
+// Render собирает текст дайджеста для уведомлений notifications
+func (r *Renderer) Render(d domain.Digest, notifications []domain.Notification) (string, error) {
+	data := Data{
+		ID:            d.ID,
+		RecipientID:   d.RecipientID,
+		Channel:       d.Channel,
+		Count:         len(notifications),
+		CreatedAt:     d.CreatedAt,
+		Notifications: make([]Item, 0, len(notifications)),
+	}
+	for _, notification := range notifications {
+		data.Notifications = append(data.Notifications, Item{
+			ID:               notification.ID,
+			Payload:          notification.Payload,
+			SenderID:         notification.SenderID,
+			NotificationDate: notification.NotificationDate,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := r.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,50 @@
+package digest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"delayed-notifier/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer_DefaultTemplate(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	date := time.Date(2024, 12, 31, 10, 30, 0, 0, time.UTC)
+	text, err := renderer.Render(domain.Digest{ID: "digest", RecipientID: "user123", Channel: domain.ChannelTelegram}, []domain.Notification{
+		{ID: "first", Payload: "Build passed", NotificationDate: date},
+		{ID: "second", Payload: "Review requested", NotificationDate: date.Add(time.Minute)},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "You have 2 new notifications:\n\n- [2024-12-31 10:30] Build passed\n- [2024-12-31 10:31] Review requested\n", text)
+}
+
+func TestRenderer_TemplateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "digest.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(`{{.RecipientID}}:{{range .Notifications}} {{.Payload}}{{end}}`), 0o600))
+
+	renderer, err := NewRenderer(path)
+	require.NoError(t, err)
+
+	text, err := renderer.Render(domain.Digest{RecipientID: "user123"}, []domain.Notification{
+		{Payload: "one"},
+		{Payload: "two"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "user123: one two", text)
+}
+
+func TestNewRenderer_InvalidTemplate(t *testing.T) {
+	_, err := NewRendererFromString("{{.Count")
+	assert.Error(t, err)
+
+	_, err = NewRenderer(filepath.Join(t.TempDir(), "missing.tmpl"))
+	assert.Error(t, err)
+}
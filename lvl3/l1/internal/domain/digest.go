@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// DigestStatus представляет статус отправки дайджеста
+type DigestStatus string
+
+const (
+	// DigestStatusPending указывает, что дайджест собран и отправляется
+	DigestStatusPending DigestStatus = "pending"
+	// DigestStatusSent указывает, что дайджест успешно отправлен
+	DigestStatusSent DigestStatus = "sent"
+	// DigestStatusFailed указывает, что дайджест не удалось отправить,
+	// входящие в него уведомления возвращены в буфер
+	DigestStatusFailed DigestStatus = "failed"
+)
+
+// Digest представляет одну отправку дайджеста получателю по каналу
+type Digest struct {
+	ID                string       `json:"id" db:"id"`
+	TenantID          string       `json:"tenant_id" db:"tenant_id"`
+	RecipientID       string       `json:"recipient_id" db:"recipient_id"`
+	Channel           Channel      `json:"channel" db:"channel"`
+	Status            DigestStatus `json:"status" db:"status"`
+	NotificationCount int          `json:"notification_count" db:"notification_count"`
+	Error             string       `json:"error,omitempty" db:"error"`
+	CreatedAt         time.Time    `json:"created_at" db:"created_at"`
+	SentAt            *time.Time   `json:"sent_at,omitempty" db:"sent_at"`
+}
+
+// DigestGroup определяет буфер дайджеста: уведомления одного получателя тенанта в одном канале
+type DigestGroup struct {
+	TenantID    string  `json:"tenant_id"`
+	RecipientID string  `json:"recipient_id"`
+	Channel     Channel `json:"channel"`
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrNotFound возвращается, когда уведомление не найдено
+	ErrNotFound = errors.New("notification not found")
+	// ErrAlreadySent возвращается при попытке изменить уже отправленное уведомление
+	ErrAlreadySent = errors.New("notification already sent")
+	// ErrAlreadyFailed возвращается при попытке изменить уведомление, которое не удалось отправить
+	ErrAlreadyFailed = errors.New("notification already failed")
+	// ErrCancelled возвращается, когда уведомление было отменено
+	ErrCancelled = errors.New("notification cancelled")
+	// ErrExpired возвращается, когда срок актуальности уведомления истек
+	ErrExpired = errors.New("notification expired")
+	// ErrSuppressed возвращается, когда уведомление не отправлено из-за подавления получателя
+	ErrSuppressed = errors.New("notification suppressed")
+	// ErrSuppressionNotFound возвращается, когда получатель отсутствует в списке подавления
+	ErrSuppressionNotFound = errors.New("suppression not found")
+	// ErrPending возвращается при попытке повторно отправить уведомление, которое еще ожидает отправки
+	ErrPending = errors.New("notification is still pending")
+	// ErrValidation возвращается, когда запрос не прошел валидацию
+	ErrValidation = errors.New("validation failed")
+	// ErrQuotaExceeded возвращается, когда тенант исчерпал квоту уведомлений
+	ErrQuotaExceeded = errors.New("tenant quota exceeded")
+)
+
+// StatusConflictError возвращает ошибку, соответствующую финальному статусу уведомления,
+// или nil, если уведомление еще можно изменить
+func StatusConflictError(status Status) error {
+	switch status {
+	case StatusSent:
+		return ErrAlreadySent
+	case StatusFailed:
+		return ErrAlreadyFailed
+	case StatusCancelled:
+		return ErrCancelled
+	case StatusExpired:
+		return ErrExpired
+	case StatusSuppressed:
+		return ErrSuppressed
+	default:
+		return nil
+	}
+}
+
+// FieldViolation описывает нарушение правила валидации для одного поля
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Err     error  `json:"-"`
+}
+
+// ValidationError содержит все нарушения валидации запроса.
+// errors.Is срабатывает для ErrValidation и для ошибки каждого нарушения
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+// NewValidationError создает ошибку валидации из списка нарушений
+func NewValidationError(violations ...FieldViolation) *ValidationError {
+	return &ValidationError{Violations: violations}
+}
+
+// Add добавляет нарушение валидации
+func (e *ValidationError) Add(field, code string, err error) {
+	e.Violations = append(e.Violations, FieldViolation{
+		Field:   field,
+		Code:    code,
+		Message: err.Error(),
+		Err:     err,
+	})
+}
+
+// HasViolations сообщает, есть ли нарушения валидации
+func (e *ValidationError) HasViolations() bool {
+	return len(e.Violations) > 0
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Message)
+	}
+	return ErrValidation.Error() + ": " + strings.Join(messages, "; ")
+}
+
+// Unwrap возвращает ErrValidation и ошибки всех нарушений
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Violations)+1)
+	errs = append(errs, ErrValidation)
+	for _, violation := range e.Violations {
+		if violation.Err != nil {
+			errs = append(errs, violation.Err)
+		}
+	}
+	return errs
+}
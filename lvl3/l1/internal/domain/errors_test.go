@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationError(t *testing.T) {
+	errEmpty := errors.New("payload cannot be empty")
+	errChannel := errors.New("invalid channel")
+
+	validationErr := NewValidationError()
+	assert.False(t, validationErr.HasViolations())
+
+	validationErr.Add("payload", "required", errEmpty)
+	validationErr.Add("channel", "invalid", errChannel)
+
+	err := fmt.Errorf("create notification: %w", validationErr)
+
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.ErrorIs(t, err, errEmpty)
+	assert.ErrorIs(t, err, errChannel)
+	assert.NotErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, "validation failed: payload cannot be empty; invalid channel", validationErr.Error())
+
+	var target *ValidationError
+	assert.True(t, errors.As(err, &target))
+	assert.Len(t, target.Violations, 2)
+	assert.Equal(t, "payload", target.Violations[0].Field)
+}
+
+func TestStatusConflictError(t *testing.T) {
+	assert.NoError(t, StatusConflictError(StatusPending))
+	assert.ErrorIs(t, StatusConflictError(StatusSent), ErrAlreadySent)
+	assert.ErrorIs(t, StatusConflictError(StatusFailed), ErrAlreadyFailed)
+	assert.ErrorIs(t, StatusConflictError(StatusCancelled), ErrCancelled)
+	assert.ErrorIs(t, StatusConflictError(StatusExpired), ErrExpired)
+	assert.ErrorIs(t, StatusConflictError(StatusSuppressed), ErrSuppressed)
+}
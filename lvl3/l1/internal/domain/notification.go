@@ -6,15 +6,25 @@ import (
 
 // Notification представляет сущность уведомления в домене
 type Notification struct {
-	ID               string    `json:"id" db:"id"`
-	Payload          string    `json:"payload" db:"payload"`
-	CreatedDate      time.Time `json:"date_created" db:"date_created"`
-	Status           Status    `json:"status" db:"status"`
-	NotificationDate time.Time `json:"notification_date" db:"notification_date"`
-	SenderID         string    `json:"sender_id" db:"sender_id"`
-	RecipientID      string    `json:"recipient_id" db:"recipient_id"`
-	Channel          Channel   `json:"channel" db:"channel"`
-	Retries          int       `json:"retries" db:"retries"`
+	ID               string     `json:"id" db:"id"`
+	TenantID         string     `json:"tenant_id" db:"tenant_id"`
+	Payload          string     `json:"payload" db:"payload"`
+	CreatedDate      time.Time  `json:"date_created" db:"date_created"`
+	Status           Status     `json:"status" db:"status"`
+	NotificationDate time.Time  `json:"notification_date" db:"notification_date"`
+	SenderID         string     `json:"sender_id" db:"sender_id"`
+	RecipientID      string     `json:"recipient_id" db:"recipient_id"`
+	Channel          Channel    `json:"channel" db:"channel"`
+	Retries          int        `json:"retries" db:"retries"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	Digest           bool       `json:"digest" db:"digest"`
+	DigestID         *string    `json:"digest_id,omitempty" db:"digest_id"`
+}
+
+// IsExpired сообщает, что срок актуальности уведомления истек к моменту now.
+// Уведомление без ExpiresAt не устаревает
+func (n *Notification) IsExpired(now time.Time) bool {
+	return n.ExpiresAt != nil && now.After(*n.ExpiresAt)
 }
 
 // Status представляет статус уведомления
@@ -29,8 +39,15 @@ const (
 	StatusFailed Status = "failed"
 	// StatusCancelled указывает, что уведомление было отменено
 	StatusCancelled Status = "cancelled"
+	// StatusExpired указывает, что уведомление не отправлено, так как истек срок его актуальности
+	StatusExpired Status = "expired"
+	// StatusSuppressed указывает, что уведомление не отправлено, так как получатель в списке подавления
+	StatusSuppressed Status = "suppressed"
 )
 
+// Statuses перечисляет все статусы уведомления
+var Statuses = []Status{StatusPending, StatusSent, StatusFailed, StatusCancelled, StatusExpired, StatusSuppressed}
+
 // Channel представляет канал отправки уведомления
 type Channel string
 
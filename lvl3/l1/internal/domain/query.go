@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// NotificationFilter задает условия выборки уведомлений. Пустые поля не ограничивают выборку
+type NotificationFilter struct {
+	Status      Status
+	Channel     Channel
+	RecipientID string
+	Limit       int
+	Offset      int
+}
+
+// NotificationPage содержит страницу выборки уведомлений, упорядоченных от новых к старым,
+// и общее число уведомлений, подходящих под фильтр
+type NotificationPage struct {
+	Notifications []Notification
+	Total         int64
+}
+
+// StatusChange описывает запись истории уведомления: статус и дату отправки,
+// действовавшие начиная с ChangedAt
+type StatusChange struct {
+	Status           Status    `json:"status" db:"status"`
+	NotificationDate time.Time `json:"notification_date" db:"notification_date"`
+	ChangedAt        time.Time `json:"changed_at" db:"changed_at"`
+}
@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// SuppressionReason описывает причину, по которой получателю не отправляются уведомления
+type SuppressionReason string
+
+const (
+	// SuppressionUnsubscribed указывает, что получатель отписался по ссылке из письма
+	SuppressionUnsubscribed SuppressionReason = "unsubscribed"
+	// SuppressionBounced указывает, что письма получателю не доставляются
+	SuppressionBounced SuppressionReason = "bounced"
+	// SuppressionComplaint указывает, что получатель пожаловался на рассылку
+	SuppressionComplaint SuppressionReason = "complaint"
+	// SuppressionManual указывает, что получатель добавлен в список вручную
+	SuppressionManual SuppressionReason = "manual"
+)
+
+// SuppressionReasons перечисляет все причины подавления
+var SuppressionReasons = []SuppressionReason{
+	SuppressionUnsubscribed,
+	SuppressionBounced,
+	SuppressionComplaint,
+	SuppressionManual,
+}
+
+// Suppression представляет запись списка подавления: получателю тенанта
+// не отправляются уведомления по каналу до ExpiresAt
+type Suppression struct {
+	TenantID    string            `json:"tenant_id" db:"tenant_id"`
+	RecipientID string            `json:"recipient_id" db:"recipient_id"`
+	Channel     Channel           `json:"channel" db:"channel"`
+	Reason      SuppressionReason `json:"reason" db:"reason"`
+	ExpiresAt   *time.Time        `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
+}
+
+// IsActive сообщает, что подавление действует в момент now.
+// Запись без ExpiresAt действует бессрочно
+func (s *Suppression) IsActive(now time.Time) bool {
+	return s.ExpiresAt == nil || now.Before(*s.ExpiresAt)
+}
+
+// SuppressionFilter задает условия выборки списка подавления. Пустые поля не ограничивают выборку,
+// записи, истекшие к ActiveAt, не выбираются
+type SuppressionFilter struct {
+	Channel     Channel
+	RecipientID string
+	Reason      SuppressionReason
+	ActiveAt    time.Time
+	Limit       int
+	Offset      int
+}
+
+// SuppressionPage содержит страницу списка подавления и общее число подходящих записей
+type SuppressionPage struct {
+	Suppressions []Suppression
+	Total        int64
+}
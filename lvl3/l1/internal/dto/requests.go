@@ -2,6 +2,7 @@ package dto
 
 import (
 	"delayed-notifier/internal/domain"
+	"fmt"
 	"time"
 )
 
@@ -13,6 +14,37 @@ type CreateNotificationRequest struct {
 	RecipientID      string         `json:"recipient_id"`
 	Channel          domain.Channel `json:"channel"`
 	EmailConfig      *EmailConfig   `json:"email_config,omitempty"`
+	// ExpiresAt задает момент, после которого уведомление не отправляется
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// MaxLateness задает допустимое опоздание относительно NotificationDate, например "15m".
+	// Взаимоисключается с ExpiresAt
+	MaxLateness string `json:"max_lateness,omitempty"`
+	// Digest помечает уведомление для отправки в составе дайджеста получателю
+	Digest bool `json:"digest,omitempty"`
+}
+
+// ResolveExpiresAt вычисляет срок актуальности уведомления из ExpiresAt или MaxLateness.
+// Возвращает nil, если срок не задан, и ошибку, если MaxLateness не положительная длительность
+func (r *CreateNotificationRequest) ResolveExpiresAt() (*time.Time, error) {
+	if r.ExpiresAt != nil {
+		expiresAt := *r.ExpiresAt
+		return &expiresAt, nil
+	}
+
+	if r.MaxLateness == "" {
+		return nil, nil
+	}
+
+	maxLateness, err := time.ParseDuration(r.MaxLateness)
+	if err != nil {
+		return nil, err
+	}
+	if maxLateness <= 0 {
+		return nil, fmt.Errorf("max_lateness must be positive, got %s", r.MaxLateness)
+	}
+
+	expiresAt := r.NotificationDate.Add(maxLateness)
+	return &expiresAt, nil
 }
 
 // EmailConfig содержит конфигурацию для email
@@ -36,6 +68,70 @@ type CancelNotificationQuery struct {
 	ID string `form:"id" binding:"required,uuid"`
 }
 
+// RescheduleNotificationRequest представляет запрос на перенос уведомления на новую дату
+type RescheduleNotificationRequest struct {
+	NotificationDate time.Time `json:"notification_date"`
+}
+
+// ListNotificationsQuery представляет параметры выборки уведомлений для панели управления
+type ListNotificationsQuery struct {
+	Status      domain.Status
+	Channel     domain.Channel
+	RecipientID string
+	Limit       int
+	Offset      int
+}
+
+// ToFilter преобразует параметры выборки в доменный фильтр
+func (q *ListNotificationsQuery) ToFilter() domain.NotificationFilter {
+	return domain.NotificationFilter{
+		Status:      q.Status,
+		Channel:     q.Channel,
+		RecipientID: q.RecipientID,
+		Limit:       q.Limit,
+		Offset:      q.Offset,
+	}
+}
+
+// SuppressRequest представляет запрос на добавление получателя в список подавления
+type SuppressRequest struct {
+	Channel     domain.Channel           `json:"-"`
+	RecipientID string                   `json:"-"`
+	Reason      domain.SuppressionReason `json:"reason"`
+	// ExpiresAt задает момент окончания подавления, без него подавление бессрочное
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ToDomain преобразует запрос в доменную запись списка подавления
+func (r *SuppressRequest) ToDomain() domain.Suppression {
+	return domain.Suppression{
+		RecipientID: r.RecipientID,
+		Channel:     r.Channel,
+		Reason:      r.Reason,
+		ExpiresAt:   r.ExpiresAt,
+	}
+}
+
+// ListSuppressionsQuery представляет параметры выборки списка подавления
+type ListSuppressionsQuery struct {
+	Channel     domain.Channel
+	RecipientID string
+	Reason      domain.SuppressionReason
+	Limit       int
+	Offset      int
+}
+
+// ToFilter преобразует параметры выборки в доменный фильтр
+func (q *ListSuppressionsQuery) ToFilter() domain.SuppressionFilter {
+	return domain.SuppressionFilter{
+		Channel:     q.Channel,
+		RecipientID: q.RecipientID,
+		Reason:      q.Reason,
+		Limit:       q.Limit,
+		Offset:      q.Offset,
+	}
+}
+
 // ToDomain преобразует DTO в доменную модель
 func (r *CreateNotificationRequest) ToDomain() *domain.Notification {
 	return &domain.Notification{
@@ -45,6 +141,8 @@ func (r *CreateNotificationRequest) ToDomain() *domain.Notification {
 		SenderID:         r.SenderID,
 		Channel:          r.Channel,
 		Status:           domain.StatusPending,
+		ExpiresAt:        r.ExpiresAt,
+		Digest:           r.Digest,
 		Retries:          0,
 		CreatedDate:      time.Now(),
 	}
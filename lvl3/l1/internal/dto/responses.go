@@ -0,0 +1,165 @@
+package dto
+
+import (
+	"delayed-notifier/internal/domain"
+	"time"
+)
+
+// CreateNotificationResponse представляет ответ на создание уведомления
+type CreateNotificationResponse struct {
+	ID     string        `json:"id"`
+	Status domain.Status `json:"status"`
+}
+
+// NotificationResponse представляет уведомление в ответах API
+type NotificationResponse struct {
+	ID               string         `json:"id"`
+	Status           domain.Status  `json:"status"`
+	Payload          string         `json:"payload"`
+	Channel          domain.Channel `json:"channel"`
+	NotificationDate string         `json:"notification_date"`
+	RecipientID      string         `json:"recipient_id"`
+	ExpiresAt        string         `json:"expires_at,omitempty"`
+	Digest           bool           `json:"digest,omitempty"`
+	DigestID         string         `json:"digest_id,omitempty"`
+}
+
+// CancelNotificationResponse представляет ответ на отмену уведомления
+type CancelNotificationResponse struct {
+	Status string `json:"status"`
+}
+
+// NotificationListResponse представляет страницу уведомлений
+type NotificationListResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	Total         int64                  `json:"total"`
+	Limit         int                    `json:"limit"`
+	Offset        int                    `json:"offset"`
+}
+
+// StatusChangeResponse представляет запись истории статусов уведомления
+type StatusChangeResponse struct {
+	Status           domain.Status `json:"status"`
+	NotificationDate string        `json:"notification_date"`
+	ChangedAt        string        `json:"changed_at"`
+}
+
+// NotificationHistoryResponse представляет историю статусов уведомления
+type NotificationHistoryResponse struct {
+	ID      string                 `json:"id"`
+	History []StatusChangeResponse `json:"history"`
+}
+
+// StatusCountsResponse представляет количество уведомлений в разрезе статусов
+type StatusCountsResponse struct {
+	Counts map[domain.Status]int64 `json:"counts"`
+	Total  int64                   `json:"total"`
+}
+
+// SuppressionResponse представляет запись списка подавления в ответах API
+type SuppressionResponse struct {
+	RecipientID string                   `json:"recipient_id"`
+	Channel     domain.Channel           `json:"channel"`
+	Reason      domain.SuppressionReason `json:"reason"`
+	ExpiresAt   string                   `json:"expires_at,omitempty"`
+	CreatedAt   string                   `json:"created_at"`
+}
+
+// DeleteSuppressionResponse представляет ответ на удаление получателя из списка подавления
+type DeleteSuppressionResponse struct {
+	Status string `json:"status"`
+}
+
+// SuppressionListResponse представляет страницу списка подавления
+type SuppressionListResponse struct {
+	Suppressions []SuppressionResponse `json:"suppressions"`
+	Total        int64                 `json:"total"`
+	Limit        int                   `json:"limit"`
+	Offset       int                   `json:"offset"`
+}
+
+// NewSuppressionResponse преобразует запись списка подавления в DTO ответа
+func NewSuppressionResponse(suppression *domain.Suppression) SuppressionResponse {
+	response := SuppressionResponse{
+		RecipientID: suppression.RecipientID,
+		Channel:     suppression.Channel,
+		Reason:      suppression.Reason,
+		CreatedAt:   suppression.CreatedAt.Format(time.RFC3339),
+	}
+	if suppression.ExpiresAt != nil {
+		response.ExpiresAt = suppression.ExpiresAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+// NewSuppressionListResponse преобразует страницу списка подавления в DTO ответа
+func NewSuppressionListResponse(page domain.SuppressionPage, filter domain.SuppressionFilter) SuppressionListResponse {
+	suppressions := make([]SuppressionResponse, 0, len(page.Suppressions))
+	for i := range page.Suppressions {
+		suppressions = append(suppressions, NewSuppressionResponse(&page.Suppressions[i]))
+	}
+	return SuppressionListResponse{
+		Suppressions: suppressions,
+		Total:        page.Total,
+		Limit:        filter.Limit,
+		Offset:       filter.Offset,
+	}
+}
+
+// NewNotificationListResponse преобразует страницу уведомлений в DTO ответа
+func NewNotificationListResponse(page domain.NotificationPage, filter domain.NotificationFilter) NotificationListResponse {
+	notifications := make([]NotificationResponse, 0, len(page.Notifications))
+	for i := range page.Notifications {
+		notifications = append(notifications, NewNotificationResponse(&page.Notifications[i]))
+	}
+	return NotificationListResponse{
+		Notifications: notifications,
+		Total:         page.Total,
+		Limit:         filter.Limit,
+		Offset:        filter.Offset,
+	}
+}
+
+// NewNotificationHistoryResponse преобразует историю статусов в DTO ответа
+func NewNotificationHistoryResponse(id string, history []domain.StatusChange) NotificationHistoryResponse {
+	changes := make([]StatusChangeResponse, 0, len(history))
+	for _, change := range history {
+		changes = append(changes, StatusChangeResponse{
+			Status:           change.Status,
+			NotificationDate: change.NotificationDate.Format(time.RFC3339),
+			ChangedAt:        change.ChangedAt.Format(time.RFC3339),
+		})
+	}
+	return NotificationHistoryResponse{ID: id, History: changes}
+}
+
+// NewStatusCountsResponse преобразует количество уведомлений по статусам в DTO ответа.
+// Статусы без уведомлений возвращаются с нулевым количеством
+func NewStatusCountsResponse(counts map[domain.Status]int64) StatusCountsResponse {
+	response := StatusCountsResponse{Counts: make(map[domain.Status]int64, len(domain.Statuses))}
+	for _, status := range domain.Statuses {
+		response.Counts[status] = counts[status]
+		response.Total += counts[status]
+	}
+	return response
+}
+
+// NewNotificationResponse преобразует доменную модель в DTO ответа
+func NewNotificationResponse(notification *domain.Notification) NotificationResponse {
+	response := NotificationResponse{
+		ID:               notification.ID,
+		Status:           notification.Status,
+		Payload:          notification.Payload,
+		Channel:          notification.Channel,
+		NotificationDate: notification.NotificationDate.Format(time.RFC3339),
+		RecipientID:      notification.RecipientID,
+		Digest:           notification.Digest,
+	}
+	if notification.ExpiresAt != nil {
+		response.ExpiresAt = notification.ExpiresAt.Format(time.RFC3339)
+	}
+	if notification.DigestID != nil {
+		response.DigestID = *notification.DigestID
+	}
+	return response
+}
@@ -0,0 +1,119 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"delayed-notifier/internal/tenant"
+
+	"github.com/rs/zerolog/log"
+)
+
+const subscriptionBufferSize = 16
+
+// Subscription представляет подписку на события изменения статуса
+type Subscription struct {
+	C <-chan StatusEvent
+
+	ch       chan StatusEvent
+	filterID string
+	tenantID string
+	broker   *Broker
+	once     sync.Once
+}
+
+// Close отменяет подписку и закрывает канал событий
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.broker.unsubscribe(s)
+	})
+}
+
+// Broker рассылает события изменения статуса локальным подписчикам
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBroker создает новый брокер событий
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe создает подписку на события всех тенантов. Если filterID не пустой,
+// подписчик получает только события уведомления с этим ID
+func (b *Broker) Subscribe(filterID string) *Subscription {
+	return b.SubscribeTenant("", filterID)
+}
+
+// SubscribeTenant создает подписку на события тенанта tenantID.
+// Пустой tenantID означает подписку без ограничения по тенанту
+func (b *Broker) SubscribeTenant(tenantID, filterID string) *Subscription {
+	ch := make(chan StatusEvent, subscriptionBufferSize)
+	sub := &Subscription{
+		C:        ch,
+		ch:       ch,
+		filterID: filterID,
+		tenantID: tenantID,
+		broker:   b,
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub
+}
+
+// Publish рассылает событие всем подходящим подписчикам
+func (b *Broker) Publish(ctx context.Context, event StatusEvent) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers {
+		if sub.filterID != "" && sub.filterID != event.ID {
+			continue
+		}
+		if sub.tenantID != "" && sub.tenantID != eventTenant(event) {
+			continue
+		}
+
+		select {
+		case sub.ch <- event:
+		default:
+			log.Warn().
+				Str("id", event.ID).
+				Str("status", string(event.Status)).
+				Msg("Status event subscriber is too slow, event dropped")
+		}
+	}
+
+	return nil
+}
+
+// SubscribersCount возвращает количество активных подписчиков
+func (b *Broker) SubscribersCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
+
+// eventTenant возвращает тенанта события; события без тенанта относятся к тенанту по умолчанию
+func eventTenant(event StatusEvent) string {
+	if event.TenantID == "" {
+		return tenant.DefaultID
+	}
+	return event.TenantID
+}
+
+func (b *Broker) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.ch)
+	}
+}
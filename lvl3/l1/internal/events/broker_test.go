@@ -0,0 +1,104 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroker_PublishToAllSubscribers(t *testing.T) {
+	broker := NewBroker()
+
+	first := broker.Subscribe("")
+	defer first.Close()
+	second := broker.Subscribe("")
+	defer second.Close()
+
+	event := StatusEvent{ID: "test-id", Status: domain.StatusSent, Timestamp: time.Now()}
+	require.NoError(t, broker.Publish(context.Background(), event))
+
+	assert.Equal(t, event, <-first.C)
+	assert.Equal(t, event, <-second.C)
+}
+
+func TestBroker_FilterByID(t *testing.T) {
+	broker := NewBroker()
+
+	sub := broker.Subscribe("wanted-id")
+	defer sub.Close()
+
+	require.NoError(t, broker.Publish(context.Background(), StatusEvent{ID: "other-id", Status: domain.StatusSent}))
+	require.NoError(t, broker.Publish(context.Background(), StatusEvent{ID: "wanted-id", Status: domain.StatusFailed}))
+
+	event := <-sub.C
+	assert.Equal(t, "wanted-id", event.ID)
+	assert.Equal(t, domain.StatusFailed, event.Status)
+
+	select {
+	case unexpected := <-sub.C:
+		t.Fatalf("unexpected event: %+v", unexpected)
+	default:
+	}
+}
+
+func TestBroker_Close(t *testing.T) {
+	broker := NewBroker()
+
+	sub := broker.Subscribe("")
+	assert.Equal(t, 1, broker.SubscribersCount())
+
+	sub.Close()
+	sub.Close()
+
+	assert.Equal(t, 0, broker.SubscribersCount())
+	_, ok := <-sub.C
+	assert.False(t, ok)
+}
+
+func TestBroker_SlowSubscriberDoesNotBlock(t *testing.T) {
+	broker := NewBroker()
+
+	sub := broker.Subscribe("")
+	defer sub.Close()
+
+	for i := 0; i < subscriptionBufferSize*2; i++ {
+		require.NoError(t, broker.Publish(context.Background(), StatusEvent{ID: "test-id", Status: domain.StatusPending}))
+	}
+
+	assert.Len(t, sub.C, subscriptionBufferSize)
+}
+
+func TestStatusEvent_IsFinal(t *testing.T) {
+	assert.False(t, StatusEvent{Status: domain.StatusPending}.IsFinal())
+	assert.True(t, StatusEvent{Status: domain.StatusSent}.IsFinal())
+	assert.True(t, StatusEvent{Status: domain.StatusFailed}.IsFinal())
+	assert.True(t, StatusEvent{Status: domain.StatusCancelled}.IsFinal())
+}
+
+func TestBroker_FilterByTenant(t *testing.T) {
+	broker := NewBroker()
+
+	sub := broker.SubscribeTenant("billing", "")
+	defer sub.Close()
+	defaultSub := broker.SubscribeTenant(tenant.DefaultID, "")
+	defer defaultSub.Close()
+
+	require.NoError(t, broker.Publish(context.Background(), StatusEvent{ID: "other-id", TenantID: "crm", Status: domain.StatusSent}))
+	require.NoError(t, broker.Publish(context.Background(), StatusEvent{ID: "legacy-id", Status: domain.StatusSent}))
+	require.NoError(t, broker.Publish(context.Background(), StatusEvent{ID: "billing-id", TenantID: "billing", Status: domain.StatusSent}))
+
+	assert.Equal(t, "billing-id", (<-sub.C).ID)
+	assert.Equal(t, "legacy-id", (<-defaultSub.C).ID)
+
+	select {
+	case unexpected := <-sub.C:
+		t.Fatalf("unexpected event: %+v", unexpected)
+	default:
+	}
+}
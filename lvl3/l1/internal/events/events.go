@@ -0,0 +1,32 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"delayed-notifier/internal/domain"
+)
+
+// StatusEvent описывает изменение статуса уведомления
+type StatusEvent struct {
+	ID        string         `json:"id"`
+	TenantID  string         `json:"tenant_id,omitempty"`
+	Status    domain.Status  `json:"status"`
+	Channel   domain.Channel `json:"channel,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// IsFinal сообщает, является ли статус события конечным
+func (e StatusEvent) IsFinal() bool {
+	switch e.Status {
+	case domain.StatusSent, domain.StatusFailed, domain.StatusCancelled, domain.StatusExpired, domain.StatusSuppressed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Publisher определяет интерфейс для публикации событий изменения статуса
+type Publisher interface {
+	Publish(ctx context.Context, event StatusEvent) error
+}
@@ -0,0 +1,75 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/wb-go/wbf/redis"
+)
+
+// RedisBus публикует события изменения статуса в Redis pub/sub
+// и пересылает полученные из Redis события локальному брокеру,
+// чтобы клиенты любой реплики сервиса видели все обновления
+type RedisBus struct {
+	client  *redis.Client
+	channel string
+	broker  *Broker
+}
+
+// NewRedisBus создает новую шину событий поверх Redis pub/sub
+func NewRedisBus(client *redis.Client, channel string, broker *Broker) *RedisBus {
+	return &RedisBus{
+		client:  client,
+		channel: channel,
+		broker:  broker,
+	}
+}
+
+// Publish публикует событие в канал Redis
+func (b *RedisBus) Publish(ctx context.Context, event StatusEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal status event: %w", err)
+	}
+
+	if err := b.client.Client.Publish(ctx, b.channel, body).Err(); err != nil {
+		return fmt.Errorf("failed to publish status event: %w", err)
+	}
+
+	return nil
+}
+
+// Run подписывается на канал Redis и пересылает события локальному брокеру
+// до отмены контекста
+func (b *RedisBus) Run(ctx context.Context) {
+	pubsub := b.client.Client.Subscribe(ctx, b.channel)
+	defer pubsub.Close()
+
+	log.Info().Str("channel", b.channel).Msg("Subscribed to status events channel")
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Str("channel", b.channel).Msg("Status events subscription stopped")
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				log.Warn().Str("channel", b.channel).Msg("Status events channel closed")
+				return
+			}
+
+			var event StatusEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				log.Error().Err(err).Msg("Failed to unmarshal status event")
+				continue
+			}
+
+			if err := b.broker.Publish(ctx, event); err != nil {
+				log.Error().Err(err).Str("id", event.ID).Msg("Failed to broadcast status event")
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package grpcapi
+
+import (
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/dto"
+	"delayed-notifier/internal/events"
+	"delayed-notifier/pkg/notifierpb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var statusToProto = map[domain.Status]notifierpb.Status{
+	domain.StatusPending:    notifierpb.Status_STATUS_PENDING,
+	domain.StatusSent:       notifierpb.Status_STATUS_SENT,
+	domain.StatusFailed:     notifierpb.Status_STATUS_FAILED,
+	domain.StatusCancelled:  notifierpb.Status_STATUS_CANCELLED,
+	domain.StatusExpired:    notifierpb.Status_STATUS_EXPIRED,
+	domain.StatusSuppressed: notifierpb.Status_STATUS_SUPPRESSED,
+}
+
+var channelToProto = map[domain.Channel]notifierpb.Channel{
+	domain.ChannelEmail:    notifierpb.Channel_CHANNEL_EMAIL,
+	domain.ChannelTelegram: notifierpb.Channel_CHANNEL_TELEGRAM,
+}
+
+var channelFromProto = map[notifierpb.Channel]domain.Channel{
+	notifierpb.Channel_CHANNEL_EMAIL:    domain.ChannelEmail,
+	notifierpb.Channel_CHANNEL_TELEGRAM: domain.ChannelTelegram,
+}
+
+// toCreateRequest преобразует gRPC запрос в DTO сервиса
+func toCreateRequest(req *notifierpb.CreateNotificationRequest) dto.CreateNotificationRequest {
+	createReq := dto.CreateNotificationRequest{
+		Payload:     req.GetPayload(),
+		SenderID:    req.GetSenderId(),
+		RecipientID: req.GetRecipientId(),
+		Channel:     channelFromProto[req.GetChannel()],
+		Digest:      req.GetDigest(),
+	}
+
+	if req.GetNotificationDate() != nil {
+		createReq.NotificationDate = req.GetNotificationDate().AsTime()
+	}
+
+	if req.GetExpiresAt() != nil {
+		expiresAt := req.GetExpiresAt().AsTime()
+		createReq.ExpiresAt = &expiresAt
+	}
+
+	if req.GetMaxLateness() != nil {
+		createReq.MaxLateness = req.GetMaxLateness().AsDuration().String()
+	}
+
+	if cfg := req.GetEmailConfig(); cfg != nil {
+		createReq.EmailConfig = &dto.EmailConfig{
+			Subject:   cfg.GetSubject(),
+			FromName:  cfg.GetFromName(),
+			FromEmail: cfg.GetFromEmail(),
+			SMTPHost:  cfg.GetSmtpHost(),
+			SMTPPort:  int(cfg.GetSmtpPort()),
+			Username:  cfg.GetUsername(),
+			Password:  cfg.GetPassword(),
+		}
+	}
+
+	return createReq
+}
+
+// toProtoNotification преобразует доменную модель в gRPC сообщение
+func toProtoNotification(notification *domain.Notification) *notifierpb.Notification {
+	protoNotification := &notifierpb.Notification{
+		Id:               notification.ID,
+		Status:           statusToProto[notification.Status],
+		Payload:          notification.Payload,
+		Channel:          channelToProto[notification.Channel],
+		NotificationDate: timestamppb.New(notification.NotificationDate),
+		DateCreated:      timestamppb.New(notification.CreatedDate),
+		SenderId:         notification.SenderID,
+		RecipientId:      notification.RecipientID,
+		Retries:          int32(notification.Retries),
+		Digest:           notification.Digest,
+	}
+
+	if notification.ExpiresAt != nil {
+		protoNotification.ExpiresAt = timestamppb.New(*notification.ExpiresAt)
+	}
+
+	if notification.DigestID != nil {
+		protoNotification.DigestId = *notification.DigestID
+	}
+
+	return protoNotification
+}
+
+// toProtoEvent преобразует событие изменения статуса в gRPC сообщение
+func toProtoEvent(event events.StatusEvent) *notifierpb.StatusEvent {
+	return &notifierpb.StatusEvent{
+		Id:        event.ID,
+		Status:    statusToProto[event.Status],
+		Channel:   channelToProto[event.Channel],
+		Timestamp: timestamppb.New(event.Timestamp),
+	}
+}
@@ -0,0 +1,75 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"delayed-notifier/internal/domain"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+const errorDomain = "delayed-notifier"
+
+// toStatusError сопоставляет доменную ошибку с gRPC статусом.
+// Ошибки валидации передаются с деталями BadRequest по каждому полю,
+// конфликты статусов — с ErrorInfo, в котором reason совпадает с кодом HTTP API
+func toStatusError(err error) error {
+	var validationErr *domain.ValidationError
+
+	switch {
+	case errors.As(err, &validationErr):
+		badRequest := &errdetails.BadRequest{}
+		for _, violation := range validationErr.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       violation.Field,
+				Description: violation.Message,
+				Reason:      violation.Code,
+			})
+		}
+		return withDetails(status.New(codes.InvalidArgument, validationErr.Error()), badRequest)
+	case errors.Is(err, domain.ErrNotFound):
+		return status.Error(codes.NotFound, domain.ErrNotFound.Error())
+	case errors.Is(err, domain.ErrAlreadySent):
+		return conflictError(domain.ErrAlreadySent, "already_sent")
+	case errors.Is(err, domain.ErrAlreadyFailed):
+		return conflictError(domain.ErrAlreadyFailed, "already_failed")
+	case errors.Is(err, domain.ErrCancelled):
+		return conflictError(domain.ErrCancelled, "cancelled")
+	case errors.Is(err, domain.ErrExpired):
+		return conflictError(domain.ErrExpired, "expired")
+	case errors.Is(err, domain.ErrSuppressed):
+		return conflictError(domain.ErrSuppressed, "suppressed")
+	case errors.Is(err, domain.ErrPending):
+		return conflictError(domain.ErrPending, "pending")
+	case errors.Is(err, domain.ErrQuotaExceeded):
+		return withDetails(status.New(codes.ResourceExhausted, domain.ErrQuotaExceeded.Error()), &errdetails.ErrorInfo{
+			Reason: "quota_exceeded",
+			Domain: errorDomain,
+		})
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func conflictError(err error, reason string) error {
+	return withDetails(status.New(codes.FailedPrecondition, err.Error()), &errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: errorDomain,
+	})
+}
+
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
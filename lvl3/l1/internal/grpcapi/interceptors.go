@@ -0,0 +1,69 @@
+package grpcapi
+
+import (
+	"context"
+
+	"delayed-notifier/internal/auth"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryAuthInterceptor проверяет токен доступа unary вызовов
+func UnaryAuthInterceptor(authenticator *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, authenticator, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor проверяет токен доступа потоковых вызовов
+func StreamAuthInterceptor(authenticator *auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), authenticator, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authenticate проверяет токен из метаданных authorization (Bearer) или x-api-key
+func authenticate(ctx context.Context, authenticator *auth.Authenticator, method string) (context.Context, error) {
+	if !authenticator.Enabled() {
+		return ctx, nil
+	}
+
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = auth.ParseBearer(values[0])
+		}
+		if values := md.Get("x-api-key"); token == "" && len(values) > 0 {
+			token = values[0]
+		}
+	}
+
+	subject, err := authenticator.Authenticate(token)
+	if err != nil {
+		log.Warn().Err(err).Str("method", method).Msg("Unauthorized gRPC call")
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return auth.WithSubject(ctx, subject), nil
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
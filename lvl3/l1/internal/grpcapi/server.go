@@ -0,0 +1,161 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"delayed-notifier/internal/events"
+	"delayed-notifier/internal/service"
+	"delayed-notifier/internal/tenant"
+	"delayed-notifier/internal/validation"
+	"delayed-notifier/pkg/notifierpb"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Server реализует gRPC API поверх service.NotificationService
+type Server struct {
+	notifierpb.UnimplementedNotifierServiceServer
+
+	service   service.NotificationService
+	validator *validation.Validator
+	broker    *events.Broker
+}
+
+// NewServer создает новый gRPC обработчик уведомлений
+func NewServer(service service.NotificationService, validator *validation.Validator, broker *events.Broker) *Server {
+	return &Server{
+		service:   service,
+		validator: validator,
+		broker:    broker,
+	}
+}
+
+// CreateNotification создает новое отложенное уведомление
+func (s *Server) CreateNotification(ctx context.Context, req *notifierpb.CreateNotificationRequest) (*notifierpb.CreateNotificationResponse, error) {
+	createReq := toCreateRequest(req)
+
+	if err := s.validator.ValidateCreateNotificationRequest(&createReq); err != nil {
+		log.Warn().Err(err).Msg("Validation failed for gRPC CreateNotification")
+		return nil, toStatusError(err)
+	}
+
+	notification, err := s.service.CreateNotification(ctx, createReq)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create notification via gRPC")
+		return nil, toStatusError(err)
+	}
+
+	return &notifierpb.CreateNotificationResponse{
+		Id:     notification.ID,
+		Status: statusToProto[notification.Status],
+	}, nil
+}
+
+// GetNotification получает уведомление по ID
+func (s *Server) GetNotification(ctx context.Context, req *notifierpb.GetNotificationRequest) (*notifierpb.Notification, error) {
+	if err := s.validator.ValidateNotificationID(req.GetId()); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	notification, err := s.service.GetNotification(ctx, req.GetId())
+	if err != nil {
+		log.Warn().Err(err).Str("id", req.GetId()).Msg("Failed to get notification via gRPC")
+		return nil, toStatusError(err)
+	}
+
+	return toProtoNotification(notification), nil
+}
+
+// GetStatus получает статус уведомления по ID
+func (s *Server) GetStatus(ctx context.Context, req *notifierpb.GetStatusRequest) (*notifierpb.GetStatusResponse, error) {
+	if err := s.validator.ValidateNotificationID(req.GetId()); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	notificationStatus, err := s.service.GetStatus(ctx, req.GetId())
+	if err != nil {
+		log.Warn().Err(err).Str("id", req.GetId()).Msg("Failed to get notification status via gRPC")
+		return nil, toStatusError(err)
+	}
+
+	return &notifierpb.GetStatusResponse{
+		Id:     req.GetId(),
+		Status: statusToProto[notificationStatus],
+	}, nil
+}
+
+// CancelNotification отменяет уведомление по ID
+func (s *Server) CancelNotification(ctx context.Context, req *notifierpb.CancelNotificationRequest) (*notifierpb.CancelNotificationResponse, error) {
+	if err := s.validator.ValidateNotificationID(req.GetId()); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	if err := s.service.CancelNotification(ctx, req.GetId()); err != nil {
+		log.Warn().Err(err).Str("id", req.GetId()).Msg("Failed to cancel notification via gRPC")
+		return nil, toStatusError(err)
+	}
+
+	return &notifierpb.CancelNotificationResponse{
+		Id:     req.GetId(),
+		Status: notifierpb.Status_STATUS_CANCELLED,
+	}, nil
+}
+
+// WatchStatus отдает поток изменений статусов уведомлений
+func (s *Server) WatchStatus(req *notifierpb.WatchStatusRequest, stream notifierpb.NotifierService_WatchStatusServer) error {
+	ctx := stream.Context()
+	id := req.GetId()
+
+	if id != "" {
+		if err := s.validator.ValidateNotificationID(id); err != nil {
+			return toStatusError(err)
+		}
+	}
+
+	sub := s.broker.SubscribeTenant(tenant.Scope(ctx), id)
+	defer sub.Close()
+
+	untilFinal := id != ""
+	if untilFinal {
+		notification, err := s.service.GetNotification(ctx, id)
+		if err != nil {
+			return toStatusError(err)
+		}
+
+		current, err := s.service.GetStatus(ctx, id)
+		if err != nil {
+			current = notification.Status
+		}
+
+		initial := events.StatusEvent{
+			ID:        notification.ID,
+			Status:    current,
+			Channel:   notification.Channel,
+			Timestamp: time.Now(),
+		}
+		if err := stream.Send(toProtoEvent(initial)); err != nil {
+			return err
+		}
+		if initial.IsFinal() {
+			return nil
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(toProtoEvent(event)); err != nil {
+				return err
+			}
+			if untilFinal && event.IsFinal() {
+				return nil
+			}
+		}
+	}
+}
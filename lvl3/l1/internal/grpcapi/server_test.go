@@ -0,0 +1,246 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"delayed-notifier/internal/auth"
+	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/dto"
+	"delayed-notifier/internal/events"
+	"delayed-notifier/internal/validation"
+	"delayed-notifier/pkg/notifierpb"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestServer_NotificationLifecycle(t *testing.T) {
+	client, _ := newTestClient(t, config.AuthConfig{})
+	ctx := context.Background()
+
+	created, err := client.CreateNotification(ctx, &notifierpb.CreateNotificationRequest{
+		Payload:          "Hello",
+		NotificationDate: timestamppb.New(time.Now().Add(time.Hour)),
+		RecipientId:      "user123",
+		Channel:          notifierpb.Channel_CHANNEL_TELEGRAM,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, notifierpb.Status_STATUS_PENDING, created.GetStatus())
+
+	notification, err := client.GetNotification(ctx, &notifierpb.GetNotificationRequest{Id: created.GetId()})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", notification.GetPayload())
+	assert.Equal(t, notifierpb.Channel_CHANNEL_TELEGRAM, notification.GetChannel())
+
+	cancelled, err := client.CancelNotification(ctx, &notifierpb.CancelNotificationRequest{Id: created.GetId()})
+	require.NoError(t, err)
+	assert.Equal(t, notifierpb.Status_STATUS_CANCELLED, cancelled.GetStatus())
+
+	current, err := client.GetStatus(ctx, &notifierpb.GetStatusRequest{Id: created.GetId()})
+	require.NoError(t, err)
+	assert.Equal(t, notifierpb.Status_STATUS_CANCELLED, current.GetStatus())
+}
+
+func TestServer_Errors(t *testing.T) {
+	client, _ := newTestClient(t, config.AuthConfig{})
+	ctx := context.Background()
+
+	_, err := client.CreateNotification(ctx, &notifierpb.CreateNotificationRequest{
+		NotificationDate: timestamppb.New(time.Now().Add(time.Hour)),
+		RecipientId:      "user123",
+		Channel:          notifierpb.Channel_CHANNEL_TELEGRAM,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	details := status.Convert(err).Details()
+	require.Len(t, details, 1)
+	badRequest, ok := details[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	require.Len(t, badRequest.GetFieldViolations(), 1)
+	assert.Equal(t, "payload", badRequest.GetFieldViolations()[0].GetField())
+	assert.Equal(t, validation.CodeRequired, badRequest.GetFieldViolations()[0].GetReason())
+
+	_, err = client.GetNotification(ctx, &notifierpb.GetNotificationRequest{Id: "not-a-uuid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.GetNotification(ctx, &notifierpb.GetNotificationRequest{Id: uuid.NewString()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.CancelNotification(ctx, &notifierpb.CancelNotificationRequest{Id: uuid.NewString()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	created, err := client.CreateNotification(ctx, &notifierpb.CreateNotificationRequest{
+		Payload:          "Hello",
+		NotificationDate: timestamppb.New(time.Now().Add(time.Hour)),
+		RecipientId:      "user123",
+		Channel:          notifierpb.Channel_CHANNEL_TELEGRAM,
+	})
+	require.NoError(t, err)
+
+	_, err = client.CancelNotification(ctx, &notifierpb.CancelNotificationRequest{Id: created.GetId()})
+	require.NoError(t, err)
+
+	_, err = client.CancelNotification(ctx, &notifierpb.CancelNotificationRequest{Id: created.GetId()})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	details = status.Convert(err).Details()
+	require.Len(t, details, 1)
+	errorInfo, ok := details[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, "cancelled", errorInfo.GetReason())
+}
+
+func TestServer_WatchStatus(t *testing.T) {
+	client, svc := newTestClient(t, config.AuthConfig{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	created, err := client.CreateNotification(ctx, &notifierpb.CreateNotificationRequest{
+		Payload:          "Hello",
+		NotificationDate: timestamppb.New(time.Now().Add(time.Hour)),
+		RecipientId:      "user123",
+		Channel:          notifierpb.Channel_CHANNEL_TELEGRAM,
+	})
+	require.NoError(t, err)
+
+	stream, err := client.WatchStatus(ctx, &notifierpb.WatchStatusRequest{Id: created.GetId()})
+	require.NoError(t, err)
+
+	initial, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, notifierpb.Status_STATUS_PENDING, initial.GetStatus())
+
+	require.Eventually(t, func() bool { return svc.broker.SubscribersCount() == 1 }, time.Second, 10*time.Millisecond)
+	svc.publish(created.GetId(), domain.StatusSent)
+
+	final, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, notifierpb.Status_STATUS_SENT, final.GetStatus())
+
+	_, err = stream.Recv()
+	assert.Error(t, err)
+}
+
+func TestServer_Auth(t *testing.T) {
+	client, _ := newTestClient(t, config.AuthConfig{Tokens: map[string]string{"secret": "billing"}})
+
+	_, err := client.GetStatus(context.Background(), &notifierpb.GetStatusRequest{Id: uuid.NewString()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	_, err = client.GetStatus(ctx, &notifierpb.GetStatusRequest{Id: uuid.NewString()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func newTestClient(t *testing.T, authConfig config.AuthConfig) (notifierpb.NotifierServiceClient, *fakeService) {
+	t.Helper()
+
+	svc := &fakeService{
+		notifications: make(map[string]domain.Notification),
+		broker:        events.NewBroker(),
+	}
+	authenticator := auth.NewAuthenticator(authConfig)
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryAuthInterceptor(authenticator)),
+		grpc.ChainStreamInterceptor(StreamAuthInterceptor(authenticator)),
+	)
+	notifierpb.RegisterNotifierServiceServer(server, NewServer(svc, validation.NewValidator(), svc.broker))
+
+	listener := bufconn.Listen(1024 * 1024)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return notifierpb.NewNotifierServiceClient(conn), svc
+}
+
+type fakeService struct {
+	mu            sync.Mutex
+	notifications map[string]domain.Notification
+	broker        *events.Broker
+}
+
+func (f *fakeService) publish(id string, notificationStatus domain.Status) {
+	f.mu.Lock()
+	notification := f.notifications[id]
+	notification.Status = notificationStatus
+	f.notifications[id] = notification
+	f.mu.Unlock()
+
+	f.broker.Publish(context.Background(), events.StatusEvent{ID: id, Status: notificationStatus, Timestamp: time.Now()})
+}
+
+func (f *fakeService) CreateNotification(ctx context.Context, req dto.CreateNotificationRequest) (*domain.Notification, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	notification := *req.ToDomain()
+	notification.ID = uuid.NewString()
+	f.notifications[notification.ID] = notification
+	return &notification, nil
+}
+
+func (f *fakeService) GetNotification(ctx context.Context, id string) (*domain.Notification, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	notification, ok := f.notifications[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &notification, nil
+}
+
+func (f *fakeService) GetStatus(ctx context.Context, id string) (domain.Status, error) {
+	notification, err := f.GetNotification(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return notification.Status, nil
+}
+
+func (f *fakeService) CancelNotification(ctx context.Context, id string) error {
+	notification, err := f.GetNotification(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := domain.StatusConflictError(notification.Status); err != nil {
+		return err
+	}
+	f.publish(id, domain.StatusCancelled)
+	return nil
+}
+
+func (f *fakeService) RescheduleNotification(ctx context.Context, id string, notificationDate time.Time) (*domain.Notification, error) {
+	return nil, domain.ErrNotFound
+}
+
+func (f *fakeService) ResendNotification(ctx context.Context, id string) (*domain.Notification, error) {
+	return nil, domain.ErrNotFound
+}
+
+func (f *fakeService) ProcessTelegramNotification(ctx context.Context, notification domain.Notification) error {
+	return nil
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/dto"
+	"delayed-notifier/internal/service"
+	"delayed-notifier/internal/validation"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultListLimit размер страницы, если параметр limit не задан
+const defaultListLimit = 20
+
+// errInvalidInteger возвращается, когда параметр запроса не является целым числом
+var errInvalidInteger = errors.New("must be an integer")
+
+// DashboardHandler обрабатывает HTTP запросы панели управления уведомлениями
+type DashboardHandler struct {
+	dashboard *service.DashboardService
+	validator *validation.Validator
+}
+
+// NewDashboardHandler создает новый обработчик панели управления
+func NewDashboardHandler(dashboard *service.DashboardService, validator *validation.Validator) *DashboardHandler {
+	return &DashboardHandler{
+		dashboard: dashboard,
+		validator: validator,
+	}
+}
+
+// ListNotifications обрабатывает GET /api/v1/notify запросы
+func (h *DashboardHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	query, err := parseListQuery(r)
+	if err == nil {
+		err = h.validator.ValidateListNotificationsQuery(&query)
+	}
+	if err != nil {
+		log.Warn().Ctx(ctx).Err(err).Msg("Invalid notification list query")
+		SendError(w, err)
+		return
+	}
+
+	filter := query.ToFilter()
+	page, err := h.dashboard.ListNotifications(ctx, filter)
+	if err != nil {
+		log.Error().Ctx(ctx).Err(err).Msg("Failed to list notifications")
+		SendError(w, err)
+		return
+	}
+
+	SendSuccessResponse(w, dto.NewNotificationListResponse(page, filter))
+}
+
+// GetStatusCounts обрабатывает GET /api/v1/notify/stats запросы
+func (h *DashboardHandler) GetStatusCounts(w http.ResponseWriter, r *http.Request) {
+	counts, err := h.dashboard.CountByStatus(r.Context())
+	if err != nil {
+		log.Error().Ctx(r.Context()).Err(err).Msg("Failed to count notifications by status")
+		SendError(w, err)
+		return
+	}
+
+	SendSuccessResponse(w, dto.NewStatusCountsResponse(counts))
+}
+
+// GetHistory обрабатывает GET /api/v1/notify/{id}/history запросы
+func (h *DashboardHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id := chi.URLParam(r, "id")
+	if err := h.validator.ValidateNotificationID(id); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Str("id", id).Msg("Invalid notification ID format in GetHistory")
+		SendError(w, err)
+		return
+	}
+
+	history, err := h.dashboard.GetHistory(ctx, id)
+	if err != nil {
+		logServiceError(ctx, err).Str("id", id).Msg("Failed to get notification history")
+		SendError(w, err)
+		return
+	}
+
+	SendSuccessResponse(w, dto.NewNotificationHistoryResponse(id, history))
+}
+
+// parseListQuery читает параметры выборки уведомлений из строки запроса
+func parseListQuery(r *http.Request) (dto.ListNotificationsQuery, error) {
+	values := r.URL.Query()
+	query := dto.ListNotificationsQuery{
+		Status:      domain.Status(values.Get("status")),
+		Channel:     domain.Channel(values.Get("channel")),
+		RecipientID: values.Get("recipient_id"),
+		Limit:       defaultListLimit,
+	}
+
+	if err := parsePage(values, &query.Limit, &query.Offset); err != nil {
+		return query, err
+	}
+	return query, nil
+}
+
+// parsePage читает limit и offset из строки запроса, оставляя незаданные без изменений.
+// Нечисловые значения возвращаются как нарушения валидации
+func parsePage(values url.Values, limit, offset *int) error {
+	validationErr := domain.NewValidationError()
+	for _, param := range []struct {
+		field  string
+		target *int
+	}{
+		{"limit", limit},
+		{"offset", offset},
+	} {
+		field, target := param.field, param.target
+		raw := values.Get(field)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			validationErr.Add(field, validation.CodeInvalidFormat, fmt.Errorf("%s %w", field, errInvalidInteger))
+			continue
+		}
+		*target = value
+	}
+
+	if validationErr.HasViolations() {
+		return validationErr
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"delayed-notifier/internal/domain"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+var (
+	// ErrInvalidContentType возвращается, когда тип контента запроса не JSON
+	ErrInvalidContentType = errors.New("invalid content type")
+	// ErrInvalidJSON возвращается, когда тело запроса не является корректным JSON
+	ErrInvalidJSON = errors.New("invalid JSON")
+)
+
+// Машиночитаемые коды ошибок API, возвращаемые в поле code
+const (
+	CodeInvalidContentType = "invalid_content_type"
+	CodeInvalidJSON        = "invalid_json"
+	CodeValidationFailed   = "validation_failed"
+	CodeNotFound           = "not_found"
+	CodeAlreadySent        = "already_sent"
+	CodeAlreadyFailed      = "already_failed"
+	CodeCancelled          = "cancelled"
+	CodeExpired            = "expired"
+	CodePending            = "pending"
+	CodeSuppressed         = "suppressed"
+	CodeQuotaExceeded      = "quota_exceeded"
+	CodeBadRequest         = "bad_request"
+	CodeInternal           = "internal_error"
+)
+
+// ErrorResponse описывает ошибку API
+type ErrorResponse struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Details    []domain.FieldViolation
+}
+
+// NewErrorResponse сопоставляет ошибку с HTTP статусом, кодом и деталями ответа.
+// Неизвестные ошибки скрываются за internal_error, чтобы не раскрывать детали реализации
+func NewErrorResponse(err error) ErrorResponse {
+	var validationErr *domain.ValidationError
+
+	switch {
+	case errors.As(err, &validationErr):
+		return ErrorResponse{
+			StatusCode: http.StatusBadRequest,
+			Code:       CodeValidationFailed,
+			Message:    domain.ErrValidation.Error(),
+			Details:    validationErr.Violations,
+		}
+	case errors.Is(err, ErrInvalidContentType):
+		return ErrorResponse{StatusCode: http.StatusBadRequest, Code: CodeInvalidContentType, Message: "Content-Type must be application/json"}
+	case errors.Is(err, ErrInvalidJSON):
+		return ErrorResponse{StatusCode: http.StatusBadRequest, Code: CodeInvalidJSON, Message: err.Error()}
+	case errors.Is(err, domain.ErrNotFound):
+		return ErrorResponse{StatusCode: http.StatusNotFound, Code: CodeNotFound, Message: domain.ErrNotFound.Error()}
+	case errors.Is(err, domain.ErrSuppressionNotFound):
+		return ErrorResponse{StatusCode: http.StatusNotFound, Code: CodeNotFound, Message: domain.ErrSuppressionNotFound.Error()}
+	case errors.Is(err, domain.ErrAlreadySent):
+		return ErrorResponse{StatusCode: http.StatusConflict, Code: CodeAlreadySent, Message: domain.ErrAlreadySent.Error()}
+	case errors.Is(err, domain.ErrAlreadyFailed):
+		return ErrorResponse{StatusCode: http.StatusConflict, Code: CodeAlreadyFailed, Message: domain.ErrAlreadyFailed.Error()}
+	case errors.Is(err, domain.ErrCancelled):
+		return ErrorResponse{StatusCode: http.StatusConflict, Code: CodeCancelled, Message: domain.ErrCancelled.Error()}
+	case errors.Is(err, domain.ErrExpired):
+		return ErrorResponse{StatusCode: http.StatusConflict, Code: CodeExpired, Message: domain.ErrExpired.Error()}
+	case errors.Is(err, domain.ErrSuppressed):
+		return ErrorResponse{StatusCode: http.StatusConflict, Code: CodeSuppressed, Message: domain.ErrSuppressed.Error()}
+	case errors.Is(err, domain.ErrPending):
+		return ErrorResponse{StatusCode: http.StatusConflict, Code: CodePending, Message: domain.ErrPending.Error()}
+	case errors.Is(err, domain.ErrQuotaExceeded):
+		return ErrorResponse{StatusCode: http.StatusTooManyRequests, Code: CodeQuotaExceeded, Message: domain.ErrQuotaExceeded.Error()}
+	default:
+		return ErrorResponse{StatusCode: http.StatusInternalServerError, Code: CodeInternal, Message: "internal error"}
+	}
+}
+
+// SendError отправляет JSON ответ с ошибкой, сопоставленной через NewErrorResponse
+func SendError(w http.ResponseWriter, err error) {
+	writeErrorResponse(w, NewErrorResponse(err))
+}
+
+// SendErrorResponse отправляет JSON ответ с ошибкой
+func SendErrorResponse(w http.ResponseWriter, message string, statusCode int) {
+	writeErrorResponse(w, ErrorResponse{
+		StatusCode: statusCode,
+		Code:       defaultErrorCode(statusCode),
+		Message:    message,
+	})
+}
+
+func writeErrorResponse(w http.ResponseWriter, errResp ErrorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errResp.StatusCode)
+
+	response := Response{
+		Error:   errResp.Message,
+		Code:    errResp.Code,
+		Details: errResp.Details,
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+func defaultErrorCode(statusCode int) string {
+	switch {
+	case statusCode == http.StatusNotFound:
+		return CodeNotFound
+	case statusCode >= http.StatusInternalServerError:
+		return CodeInternal
+	default:
+		return CodeBadRequest
+	}
+}
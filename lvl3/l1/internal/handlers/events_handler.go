@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"delayed-notifier/internal/events"
+	"delayed-notifier/internal/service"
+	"delayed-notifier/internal/tenant"
+	"delayed-notifier/internal/validation"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	sseEventStatus    = "status"
+	sseHeartbeatDelay = 15 * time.Second
+)
+
+// EventsHandler отдает поток изменений статусов уведомлений через Server-Sent Events
+type EventsHandler struct {
+	broker    *events.Broker
+	service   service.NotificationService
+	validator *validation.Validator
+}
+
+// NewEventsHandler создает новый обработчик SSE потоков
+func NewEventsHandler(broker *events.Broker, service service.NotificationService, validator *validation.Validator) *EventsHandler {
+	return &EventsHandler{
+		broker:    broker,
+		service:   service,
+		validator: validator,
+	}
+}
+
+// StreamEvents обрабатывает GET /api/v1/notify/events запросы
+func (h *EventsHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	sub := h.broker.SubscribeTenant(tenant.Scope(r.Context()), "")
+	defer sub.Close()
+
+	log.Info().Ctx(r.Context()).Str("remote_addr", r.RemoteAddr).Msg("Status events stream opened")
+	h.stream(w, r, sub, nil)
+	log.Info().Ctx(r.Context()).Str("remote_addr", r.RemoteAddr).Msg("Status events stream closed")
+}
+
+// StreamNotificationEvents обрабатывает GET /api/v1/notify/{id}/events запросы
+func (h *EventsHandler) StreamNotificationEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := chi.URLParam(r, "id")
+
+	if err := h.validator.ValidateNotificationID(id); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Str("id", id).Msg("Invalid notification ID format in StreamNotificationEvents")
+		SendError(w, err)
+		return
+	}
+
+	sub := h.broker.SubscribeTenant(tenant.Scope(ctx), id)
+	defer sub.Close()
+
+	notification, err := h.service.GetNotification(ctx, id)
+	if err != nil {
+		logServiceError(ctx, err).Str("id", id).Msg(msgFailedToGetNotification)
+		SendError(w, err)
+		return
+	}
+
+	status, err := h.service.GetStatus(ctx, id)
+	if err != nil {
+		status = notification.Status
+	}
+
+	initial := events.StatusEvent{
+		ID:        notification.ID,
+		Status:    status,
+		Channel:   notification.Channel,
+		Timestamp: time.Now(),
+	}
+
+	log.Info().Ctx(ctx).Str("id", id).Str("remote_addr", r.RemoteAddr).Msg("Notification events stream opened")
+	h.stream(w, r, sub, &initial)
+	log.Info().Ctx(ctx).Str("id", id).Str("remote_addr", r.RemoteAddr).Msg("Notification events stream closed")
+}
+
+// stream пишет события подписки в ответ до закрытия соединения клиентом.
+// Если передано начальное событие, оно отправляется первым, а поток
+// завершается после получения конечного статуса
+func (h *EventsHandler) stream(w http.ResponseWriter, r *http.Request, sub *events.Subscription, initial *events.StatusEvent) {
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Debug().Err(err).Msg("Failed to reset write deadline for SSE stream")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		log.Error().Err(err).Msg("Streaming is not supported by response writer")
+		return
+	}
+
+	untilFinal := initial != nil
+	if initial != nil {
+		if err := writeSSEEvent(w, rc, *initial); err != nil {
+			return
+		}
+		if initial.IsFinal() {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatDelay)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if err := writeSSEEvent(w, rc, event); err != nil {
+				return
+			}
+			if untilFinal && event.IsFinal() {
+				return
+			}
+		}
+	}
+}
+
+func writeSSEEvent(w http.ResponseWriter, rc *http.ResponseController, event events.StatusEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Error().Err(err).Str("id", event.ID).Msg("Failed to marshal status event")
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", sseEventStatus, data); err != nil {
+		return err
+	}
+
+	return rc.Flush()
+}
@@ -1,33 +1,28 @@
 package handlers
 
 import (
-	"delayed-notifier/internal/cache"
+	"context"
+	"delayed-notifier/internal/domain"
 	"delayed-notifier/internal/dto"
-	"delayed-notifier/internal/queue"
-	"delayed-notifier/internal/repository"
-	"delayed-notifier/internal/sender"
 	"delayed-notifier/internal/service"
 	"delayed-notifier/internal/validation"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
-var (
-	// ErrInvalidContentType возвращается, когда тип контента запроса не JSON
-	ErrInvalidContentType = errors.New("invalid content type")
-)
-
 const (
-	msgFailedToCreateNotification  = "Failed to create notification"
-	msgFailedToParseNotificationID = "Failed to parse notification ID as UUID"
-	msgFailedToGetNotification     = "Failed to get notification"
-	msgFailedToCancelNotification  = "Failed to cancel notification"
+	msgFailedToCreateNotification     = "Failed to create notification"
+	msgFailedToParseNotificationID    = "Failed to parse notification ID as UUID"
+	msgFailedToGetNotification        = "Failed to get notification"
+	msgFailedToCancelNotification     = "Failed to cancel notification"
+	msgFailedToRescheduleNotification = "Failed to reschedule notification"
+	msgFailedToResendNotification     = "Failed to resend notification"
 )
 
 // NotificationHandler определяет интерфейс для HTTP обработчиков уведомлений
@@ -35,25 +30,20 @@ type NotificationHandler interface {
 	CreateNotification(w http.ResponseWriter, r *http.Request)
 	GetNotificationStatus(w http.ResponseWriter, r *http.Request)
 	CancelNotification(w http.ResponseWriter, r *http.Request)
+	RescheduleNotification(w http.ResponseWriter, r *http.Request)
+	ResendNotification(w http.ResponseWriter, r *http.Request)
 }
 
 // Handler обрабатывает HTTP запросы для уведомлений
 type Handler struct {
-	service   *service.NotifierService
+	service   service.NotificationService
 	validator *validation.Validator
 }
 
 // NewNotificationHandler создает новый обработчик уведомлений
-func NewNotificationHandler(
-	repo repository.NotificationRepository,
-	cache cache.StatusCache,
-	publisher queue.Publisher,
-	senderFactory *sender.Factory,
-	notificationTTL time.Duration,
-	validator *validation.Validator,
-) *Handler {
+func NewNotificationHandler(service service.NotificationService, validator *validation.Validator) *Handler {
 	return &Handler{
-		service:   service.NewNotifierService(repo, cache, publisher, senderFactory, notificationTTL),
+		service:   service,
 		validator: validator,
 	}
 }
@@ -63,7 +53,7 @@ func (h *Handler) CreateNotification(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	if r.Method != http.MethodPost {
-		log.Warn().Str("method", r.Method).Msg("Unsupported HTTP method for CreateNotification")
+		log.Warn().Ctx(ctx).Str("method", r.Method).Msg("Unsupported HTTP method for CreateNotification")
 		http.Error(w, "Method not supported", http.StatusMethodNotAllowed)
 		return
 	}
@@ -71,37 +61,34 @@ func (h *Handler) CreateNotification(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateNotificationRequest
 
 	if err := parseRequest(w, r, &req); err != nil {
-		log.Error().Err(err).Msg("Failed to parse request body")
-		if errors.Is(err, ErrInvalidContentType) {
-			SendErrorResponse(w, "Invalid Content-Type", http.StatusBadRequest)
-		} else {
-			SendErrorResponse(w, "Invalid JSON", http.StatusBadRequest)
-		}
+		log.Warn().Ctx(ctx).Err(err).Msg("Failed to parse request body")
+		SendError(w, err)
 		return
 	}
 
 	if err := h.validator.ValidateCreateNotificationRequest(&req); err != nil {
-		log.Warn().Err(err).Msg("Validation failed for CreateNotificationRequest")
-		SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		log.Warn().Ctx(ctx).Err(err).Msg("Validation failed for CreateNotificationRequest")
+		SendError(w, err)
 		return
 	}
 
 	notification, err := h.service.CreateNotification(ctx, req)
 	if err != nil {
-		log.Error().Err(err).Msg(msgFailedToCreateNotification)
-		SendErrorResponse(w, "Unknown error", http.StatusInternalServerError)
+		log.Error().Ctx(ctx).Err(err).Msg(msgFailedToCreateNotification)
+		SendError(w, err)
 		return
 	}
 
 	log.Info().
+		Ctx(ctx).
 		Str("notification_id", notification.ID).
 		Str("channel", string(notification.Channel)).
 		Str("recipient_id", notification.RecipientID).
 		Msg("Notification created successfully")
 
-	SendSuccessResponse(w, map[string]any{
-		"id":     notification.ID,
-		"status": notification.Status,
+	SendSuccessResponse(w, dto.CreateNotificationResponse{
+		ID:     notification.ID,
+		Status: notification.Status,
 	})
 }
 
@@ -110,52 +97,40 @@ func (h *Handler) GetNotificationStatus(w http.ResponseWriter, r *http.Request)
 	ctx := r.Context()
 
 	if r.Method != http.MethodGet {
-		log.Warn().Str("method", r.Method).Msg("Unsupported HTTP method for GetNotificationStatus")
+		log.Warn().Ctx(ctx).Str("method", r.Method).Msg("Unsupported HTTP method for GetNotificationStatus")
 		http.Error(w, "Method not supported", http.StatusMethodNotAllowed)
 		return
 	}
 
 	idStr := chi.URLParam(r, "id")
 
-	if idStr == "" {
-		log.Warn().Msg("Missing ID parameter in GetNotificationStatus")
-		SendErrorResponse(w, "ID parameter is required", http.StatusBadRequest)
-		return
-	}
-
 	if err := h.validator.ValidateNotificationID(idStr); err != nil {
-		log.Warn().Err(err).Str("id", idStr).Msg("Invalid notification ID format")
-		SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		log.Warn().Ctx(ctx).Err(err).Str("id", idStr).Msg("Invalid notification ID format")
+		SendError(w, err)
 		return
 	}
 
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		log.Error().Err(err).Str("id", idStr).Msg(msgFailedToParseNotificationID)
+		log.Error().Ctx(ctx).Err(err).Str("id", idStr).Msg(msgFailedToParseNotificationID)
 		SendErrorResponse(w, "Invalid ID format", http.StatusBadRequest)
 		return
 	}
 
 	notification, err := h.service.GetNotification(ctx, id.String())
 	if err != nil {
-		log.Error().Err(err).Str("id", id.String()).Msg(msgFailedToGetNotification)
-		SendErrorResponse(w, "Notification not found", http.StatusNotFound)
+		logServiceError(ctx, err).Str("id", id.String()).Msg(msgFailedToGetNotification)
+		SendError(w, err)
 		return
 	}
 
 	log.Info().
+		Ctx(ctx).
 		Str("notification_id", notification.ID).
 		Str("status", string(notification.Status)).
 		Msg("Notification status retrieved successfully")
 
-	SendSuccessResponse(w, map[string]any{
-		"id":                notification.ID,
-		"status":            notification.Status,
-		"payload":           notification.Payload,
-		"channel":           notification.Channel,
-		"notification_date": notification.NotificationDate.Format(time.RFC3339),
-		"recipient_id":      notification.RecipientID,
-	})
+	SendSuccessResponse(w, dto.NewNotificationResponse(notification))
 }
 
 // CancelNotification обрабатывает DELETE /api/v1/notify/{id} запросы
@@ -163,53 +138,124 @@ func (h *Handler) CancelNotification(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	if r.Method != http.MethodDelete {
-		log.Warn().Str("method", r.Method).Msg("Unsupported HTTP method for CancelNotification")
+		log.Warn().Ctx(ctx).Str("method", r.Method).Msg("Unsupported HTTP method for CancelNotification")
 		http.Error(w, "Method not supported", http.StatusMethodNotAllowed)
 		return
 	}
 
 	idStr := chi.URLParam(r, "id")
-	if idStr == "" {
-		log.Warn().Msg("Missing ID parameter in CancelNotification")
-		SendErrorResponse(w, "ID parameter is required", http.StatusBadRequest)
-		return
-	}
 
 	if err := h.validator.ValidateNotificationID(idStr); err != nil {
-		log.Warn().Err(err).Str("id", idStr).Msg("Invalid notification ID format in CancelNotification")
-		SendErrorResponse(w, err.Error(), http.StatusBadRequest)
+		log.Warn().Ctx(ctx).Err(err).Str("id", idStr).Msg("Invalid notification ID format in CancelNotification")
+		SendError(w, err)
 		return
 	}
 
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		log.Error().Err(err).Str("id", idStr).Msg(msgFailedToParseNotificationID)
+		log.Error().Ctx(ctx).Err(err).Str("id", idStr).Msg(msgFailedToParseNotificationID)
 		SendErrorResponse(w, "Invalid ID format", http.StatusBadRequest)
 		return
 	}
 
 	if err := h.service.CancelNotification(ctx, id.String()); err != nil {
-		log.Error().Err(err).Str("id", id.String()).Msg(msgFailedToCancelNotification)
-		SendErrorResponse(w, "Unknown error", http.StatusInternalServerError)
+		logServiceError(ctx, err).Str("id", id.String()).Msg(msgFailedToCancelNotification)
+		SendError(w, err)
 		return
 	}
 
 	log.Info().
+		Ctx(ctx).
 		Str("notification_id", id.String()).
 		Msg("Notification cancelled successfully")
 
-	SendSuccessResponse(w, map[string]any{
-		"status": "OK",
+	SendSuccessResponse(w, dto.CancelNotificationResponse{
+		Status: "OK",
 	})
 }
 
+// RescheduleNotification обрабатывает POST /api/v1/notify/{id}/reschedule запросы
+func (h *Handler) RescheduleNotification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id := chi.URLParam(r, "id")
+	if err := h.validator.ValidateNotificationID(id); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Str("id", id).Msg("Invalid notification ID format in RescheduleNotification")
+		SendError(w, err)
+		return
+	}
+
+	var req dto.RescheduleNotificationRequest
+	if err := parseRequest(w, r, &req); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Msg("Failed to parse request body")
+		SendError(w, err)
+		return
+	}
+
+	if err := h.validator.ValidateRescheduleRequest(&req); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Msg("Validation failed for RescheduleNotificationRequest")
+		SendError(w, err)
+		return
+	}
+
+	notification, err := h.service.RescheduleNotification(ctx, id, req.NotificationDate)
+	if err != nil {
+		logServiceError(ctx, err).Str("id", id).Msg(msgFailedToRescheduleNotification)
+		SendError(w, err)
+		return
+	}
+
+	log.Info().
+		Ctx(ctx).
+		Str("notification_id", notification.ID).
+		Time("notify_at", notification.NotificationDate).
+		Msg("Notification rescheduled successfully")
+
+	SendSuccessResponse(w, dto.NewNotificationResponse(notification))
+}
+
+// ResendNotification обрабатывает POST /api/v1/notify/{id}/resend запросы
+func (h *Handler) ResendNotification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id := chi.URLParam(r, "id")
+	if err := h.validator.ValidateNotificationID(id); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Str("id", id).Msg("Invalid notification ID format in ResendNotification")
+		SendError(w, err)
+		return
+	}
+
+	notification, err := h.service.ResendNotification(ctx, id)
+	if err != nil {
+		logServiceError(ctx, err).Str("id", id).Msg(msgFailedToResendNotification)
+		SendError(w, err)
+		return
+	}
+
+	log.Info().
+		Ctx(ctx).
+		Str("notification_id", notification.ID).
+		Msg("Notification resent successfully")
+
+	SendSuccessResponse(w, dto.NewNotificationResponse(notification))
+}
+
+// logServiceError выбирает уровень логирования: ожидаемые доменные ошибки
+// логируются как предупреждения, остальные как ошибки
+func logServiceError(ctx context.Context, err error) *zerolog.Event {
+	if NewErrorResponse(err).StatusCode < http.StatusInternalServerError {
+		return log.Warn().Ctx(ctx).Err(err)
+	}
+	return log.Error().Ctx(ctx).Err(err)
+}
+
 func parseRequest(w http.ResponseWriter, r *http.Request, req any) error {
 	if r.Header.Get("Content-Type") != "application/json" {
 		return ErrInvalidContentType
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
-		return err
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
 	}
 
 	return nil
@@ -217,8 +263,10 @@ func parseRequest(w http.ResponseWriter, r *http.Request, req any) error {
 
 // Response представляет стандартный ответ API
 type Response struct {
-	Result any    `json:"result,omitempty"`
-	Error  string `json:"error,omitempty"`
+	Result  any                     `json:"result,omitempty"`
+	Error   string                  `json:"error,omitempty"`
+	Code    string                  `json:"code,omitempty"`
+	Details []domain.FieldViolation `json:"details,omitempty"`
 }
 
 // SendSuccessResponse отправляет успешный JSON ответ
@@ -234,17 +282,3 @@ func SendSuccessResponse(w http.ResponseWriter, data any) {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
-
-// SendErrorResponse отправляет JSON ответ с ошибкой
-func SendErrorResponse(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-
-	response := Response{
-		Error: message,
-	}
-
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-	}
-}
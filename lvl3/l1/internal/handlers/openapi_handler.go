@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"net/http"
+
+	"delayed-notifier/api"
+
+	"github.com/rs/zerolog/log"
+)
+
+// GetOpenAPISpec обрабатывает GET /api/v1/openapi.json запросы
+func GetOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(api.OpenAPISpec); err != nil {
+		log.Error().Err(err).Msg("Failed to write OpenAPI spec")
+	}
+}
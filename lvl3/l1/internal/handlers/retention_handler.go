@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"delayed-notifier/internal/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RetentionHandler обрабатывает HTTP запросы статистики хранения уведомлений
+type RetentionHandler struct {
+	job *service.RetentionJob
+}
+
+// NewRetentionHandler создает новый обработчик статистики хранения
+func NewRetentionHandler(job *service.RetentionJob) *RetentionHandler {
+	return &RetentionHandler{job: job}
+}
+
+// GetStats обрабатывает GET /api/v1/retention/stats запросы
+func (h *RetentionHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.job.Stats(r.Context())
+	if err != nil {
+		log.Error().Ctx(r.Context()).Err(err).Msg("Failed to get retention stats")
+		SendError(w, err)
+		return
+	}
+
+	SendSuccessResponse(w, stats)
+}
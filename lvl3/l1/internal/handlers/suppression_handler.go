@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/dto"
+	"delayed-notifier/internal/service"
+	"delayed-notifier/internal/unsubscribe"
+	"delayed-notifier/internal/validation"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const msgFailedToUpdateSuppression = "Failed to update suppression"
+
+// SuppressionHandler обрабатывает HTTP запросы управления списком подавления и страницу отписки
+type SuppressionHandler struct {
+	suppressions *service.SuppressionService
+	links        *unsubscribe.Links
+	validator    *validation.Validator
+}
+
+// NewSuppressionHandler создает новый обработчик списка подавления.
+// Без ссылок отписки страница отписки отвечает, что ссылка недействительна
+func NewSuppressionHandler(suppressions *service.SuppressionService, links *unsubscribe.Links, validator *validation.Validator) *SuppressionHandler {
+	return &SuppressionHandler{
+		suppressions: suppressions,
+		links:        links,
+		validator:    validator,
+	}
+}
+
+// ListSuppressions обрабатывает GET /api/v1/suppressions запросы
+func (h *SuppressionHandler) ListSuppressions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	query, err := parseSuppressionsQuery(r)
+	if err == nil {
+		err = h.validator.ValidateListSuppressionsQuery(&query)
+	}
+	if err != nil {
+		log.Warn().Ctx(ctx).Err(err).Msg("Invalid suppression list query")
+		SendError(w, err)
+		return
+	}
+
+	filter := query.ToFilter()
+	page, err := h.suppressions.ListSuppressions(ctx, filter)
+	if err != nil {
+		log.Error().Ctx(ctx).Err(err).Msg("Failed to list suppressions")
+		SendError(w, err)
+		return
+	}
+
+	SendSuccessResponse(w, dto.NewSuppressionListResponse(page, filter))
+}
+
+// GetSuppression обрабатывает GET /api/v1/suppressions/{channel}/{recipient_id} запросы
+func (h *SuppressionHandler) GetSuppression(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	channel, recipientID := suppressionKey(r)
+	if err := h.validator.ValidateSuppressionKey(channel, recipientID); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Msg("Invalid suppression key in GetSuppression")
+		SendError(w, err)
+		return
+	}
+
+	suppression, err := h.suppressions.GetSuppression(ctx, recipientID, channel)
+	if err != nil {
+		logServiceError(ctx, err).Str("channel", string(channel)).Msg("Failed to get suppression")
+		SendError(w, err)
+		return
+	}
+
+	SendSuccessResponse(w, dto.NewSuppressionResponse(suppression))
+}
+
+// PutSuppression обрабатывает PUT /api/v1/suppressions/{channel}/{recipient_id} запросы
+func (h *SuppressionHandler) PutSuppression(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req dto.SuppressRequest
+	if err := parseRequest(w, r, &req); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Msg("Failed to parse request body")
+		SendError(w, err)
+		return
+	}
+
+	req.Channel, req.RecipientID = suppressionKey(r)
+	if err := h.validator.ValidateSuppressRequest(&req); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Msg("Validation failed for SuppressRequest")
+		SendError(w, err)
+		return
+	}
+
+	suppression, err := h.suppressions.Suppress(ctx, req.ToDomain())
+	if err != nil {
+		log.Error().Ctx(ctx).Err(err).Str("channel", string(req.Channel)).Msg(msgFailedToUpdateSuppression)
+		SendError(w, err)
+		return
+	}
+
+	SendSuccessResponse(w, dto.NewSuppressionResponse(suppression))
+}
+
+// DeleteSuppression обрабатывает DELETE /api/v1/suppressions/{channel}/{recipient_id} запросы
+func (h *SuppressionHandler) DeleteSuppression(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	channel, recipientID := suppressionKey(r)
+	if err := h.validator.ValidateSuppressionKey(channel, recipientID); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Msg("Invalid suppression key in DeleteSuppression")
+		SendError(w, err)
+		return
+	}
+
+	if err := h.suppressions.Unsuppress(ctx, recipientID, channel); err != nil {
+		logServiceError(ctx, err).Str("channel", string(channel)).Msg(msgFailedToUpdateSuppression)
+		SendError(w, err)
+		return
+	}
+
+	SendSuccessResponse(w, dto.DeleteSuppressionResponse{Status: "deleted"})
+}
+
+// unsubscribePage шаблон публичной страницы отписки
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <title>Unsubscribe</title>
+    <link rel="stylesheet" href="/web/style.css">
+</head>
+<body>
+<div class="container">
+    <h1>Unsubscribe</h1>
+    <div class="section">
+    {{- if .Error}}
+        <div class="error">{{.Error}}</div>
+    {{- else if .Done}}
+        <p>{{.RecipientID}} will no longer receive {{.Channel}} notifications.</p>
+    {{- else}}
+        <p>Stop sending {{.Channel}} notifications to {{.RecipientID}}?</p>
+        <form method="post">
+            <input type="hidden" name="token" value="{{.Token}}">
+            <button type="submit">Unsubscribe</button>
+        </form>
+    {{- end}}
+    </div>
+</div>
+</body>
+</html>
+`))
+
+type unsubscribeView struct {
+	Token       string
+	RecipientID string
+	Channel     domain.Channel
+	Done        bool
+	Error       string
+}
+
+// UnsubscribePage обрабатывает GET /api/v1/unsubscribe: показывает подтверждение отписки.
+// Отписка выполняется только по POST, чтобы по ссылке не отписывали сканеры писем
+func (h *SuppressionHandler) UnsubscribePage(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	subject, err := h.links.Verify(token)
+	if err != nil {
+		log.Warn().Ctx(r.Context()).Err(err).Msg("Invalid unsubscribe link")
+		renderUnsubscribePage(w, http.StatusBadRequest, unsubscribeView{Error: "This unsubscribe link is invalid."})
+		return
+	}
+
+	renderUnsubscribePage(w, http.StatusOK, unsubscribeView{
+		Token:       token,
+		RecipientID: subject.RecipientID,
+		Channel:     subject.Channel,
+	})
+}
+
+// Unsubscribe обрабатывает POST /api/v1/unsubscribe: отписывает получателя из токена.
+// Поддерживает отписку в один клик по заголовку List-Unsubscribe-Post (RFC 8058)
+func (h *SuppressionHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	subject, err := h.links.Verify(r.FormValue("token"))
+	if err != nil {
+		log.Warn().Ctx(ctx).Err(err).Msg("Invalid unsubscribe link")
+		renderUnsubscribePage(w, http.StatusBadRequest, unsubscribeView{Error: "This unsubscribe link is invalid."})
+		return
+	}
+
+	if err := h.suppressions.Unsubscribe(ctx, subject.TenantID, subject.RecipientID, subject.Channel); err != nil {
+		log.Error().Ctx(ctx).Err(err).Str("tenant_id", subject.TenantID).Msg(msgFailedToUpdateSuppression)
+		renderUnsubscribePage(w, http.StatusInternalServerError, unsubscribeView{Error: "Failed to unsubscribe, please try again later."})
+		return
+	}
+
+	renderUnsubscribePage(w, http.StatusOK, unsubscribeView{
+		RecipientID: subject.RecipientID,
+		Channel:     subject.Channel,
+		Done:        true,
+	})
+}
+
+func renderUnsubscribePage(w http.ResponseWriter, statusCode int, view unsubscribeView) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if err := unsubscribePage.Execute(w, view); err != nil {
+		log.Error().Err(err).Msg("Failed to render unsubscribe page")
+	}
+}
+
+// suppressionKey читает канал и получателя из пути запроса
+func suppressionKey(r *http.Request) (domain.Channel, string) {
+	recipientID := chi.URLParam(r, "recipient_id")
+	if unescaped, err := url.PathUnescape(recipientID); err == nil {
+		recipientID = unescaped
+	}
+	return domain.Channel(chi.URLParam(r, "channel")), recipientID
+}
+
+// parseSuppressionsQuery читает параметры выборки списка подавления из строки запроса
+func parseSuppressionsQuery(r *http.Request) (dto.ListSuppressionsQuery, error) {
+	values := r.URL.Query()
+	query := dto.ListSuppressionsQuery{
+		Channel:     domain.Channel(values.Get("channel")),
+		RecipientID: values.Get("recipient_id"),
+		Reason:      domain.SuppressionReason(values.Get("reason")),
+		Limit:       defaultListLimit,
+	}
+
+	if err := parsePage(values, &query.Limit, &query.Offset); err != nil {
+		return query, err
+	}
+	return query, nil
+}
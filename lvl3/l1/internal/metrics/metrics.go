@@ -0,0 +1,106 @@
+// Package metrics содержит Prometheus метрики сервиса уведомлений
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/tenant"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "notifier"
+
+	unknownChannel = "unknown"
+)
+
+// Квоты тенанта для метрики превышения
+const (
+	QuotaDaily           = "daily"
+	QuotaConcurrentSends = "concurrent_sends"
+)
+
+var (
+	statusTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notification_status_total",
+		Help:      "Number of notification status transitions, by tenant, channel and target status.",
+	}, []string{"tenant", "channel", "status"})
+
+	deliveryLateness = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "notification_lateness_seconds",
+		Help:      "Delay between notification_date and the moment the notification was sent or expired.",
+		Buckets:   []float64{1, 5, 15, 60, 300, 900, 3600, 4 * 3600, 24 * 3600},
+	}, []string{"tenant", "channel", "status"})
+
+	digestSends = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "digest_sends_total",
+		Help:      "Number of digest send attempts, by tenant, channel and result.",
+	}, []string{"tenant", "channel", "result"})
+
+	digestSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "digest_size",
+		Help:      "Number of notifications combined into a sent digest.",
+		Buckets:   []float64{1, 2, 5, 10, 20, 50, 100},
+	}, []string{"tenant", "channel"})
+
+	quotaExceeded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tenant_quota_exceeded_total",
+		Help:      "Number of requests and sends rejected or deferred by tenant quotas.",
+	}, []string{"tenant", "quota"})
+)
+
+// RecordStatus учитывает переход уведомления тенанта в статус status
+func RecordStatus(tenantID string, channel domain.Channel, status domain.Status) {
+	statusTransitions.WithLabelValues(tenantLabel(tenantID), channelLabel(channel), string(status)).Inc()
+}
+
+// ObserveLateness учитывает опоздание отправки или истечения уведомления относительно notification_date
+func ObserveLateness(notification domain.Notification, status domain.Status, now time.Time) {
+	lateness := now.Sub(notification.NotificationDate)
+	if lateness < 0 {
+		lateness = 0
+	}
+	deliveryLateness.WithLabelValues(tenantLabel(notification.TenantID), channelLabel(notification.Channel), string(status)).Observe(lateness.Seconds())
+}
+
+// RecordDigest учитывает попытку отправки дайджеста из size уведомлений с результатом sent или failed
+func RecordDigest(tenantID string, channel domain.Channel, status domain.DigestStatus, size int) {
+	digestSends.WithLabelValues(tenantLabel(tenantID), channelLabel(channel), string(status)).Inc()
+	if status == domain.DigestStatusSent {
+		digestSize.WithLabelValues(tenantLabel(tenantID), channelLabel(channel)).Observe(float64(size))
+	}
+}
+
+// RecordQuotaExceeded учитывает срабатывание квоты тенанта
+func RecordQuotaExceeded(tenantID, quota string) {
+	quotaExceeded.WithLabelValues(tenantLabel(tenantID), quota).Inc()
+}
+
+// Handler возвращает HTTP обработчик для экспорта метрик
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+func channelLabel(channel domain.Channel) string {
+	if channel == "" {
+		return unknownChannel
+	}
+	return string(channel)
+}
+
+func tenantLabel(tenantID string) string {
+	if tenantID == "" {
+		return tenant.DefaultID
+	}
+	return tenantID
+}
@@ -0,0 +1,72 @@
+package queue
+
+import (
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/rs/zerolog/log"
+	"github.com/wb-go/wbf/rabbitmq"
+	"github.com/wb-go/wbf/retry"
+)
+
+// Message представляет сообщение из очереди вместе с его заголовками
+type Message struct {
+	Body    []byte
+	Headers amqp091.Table
+}
+
+// Consumer читает сообщения из очереди RabbitMQ.
+// В отличие от rabbitmq.Consumer передает заголовки сообщения,
+// в которых хранится контекст трассировки
+type Consumer struct {
+	channel *rabbitmq.Channel
+	config  *rabbitmq.ConsumerConfig
+}
+
+// NewConsumer создает новый потребитель очереди
+func NewConsumer(channel *rabbitmq.Channel, config *rabbitmq.ConsumerConfig) *Consumer {
+	return &Consumer{
+		channel: channel,
+		config:  config,
+	}
+}
+
+// Consume начинает потребление сообщений и отправляет их в msgChan
+func (c *Consumer) Consume(msgChan chan<- Message) error {
+	deliveries, err := c.channel.Consume(
+		c.config.Queue,
+		c.config.Consumer,
+		c.config.AutoAck,
+		c.config.Exclusive,
+		c.config.NoLocal,
+		c.config.NoWait,
+		c.config.Args,
+	)
+	if err != nil {
+		return err
+	}
+
+	for delivery := range deliveries {
+		if !c.config.AutoAck {
+			if err := delivery.Ack(false); err != nil {
+				log.Error().Err(err).Msg("Failed to ack message")
+
+				if err := delivery.Nack(false, true); err != nil {
+					log.Error().Err(err).Msg("Failed to nack message")
+				}
+			}
+		}
+
+		msgChan <- Message{
+			Body:    delivery.Body,
+			Headers: delivery.Headers,
+		}
+	}
+
+	return nil
+}
+
+// ConsumeWithRetry начинает потребление сообщений с повторными попытками при ошибке подписки
+func (c *Consumer) ConsumeWithRetry(msgChan chan<- Message, strategy retry.Strategy) error {
+	return retry.Do(func() error {
+		return c.Consume(msgChan)
+	}, strategy)
+}
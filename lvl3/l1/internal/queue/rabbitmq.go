@@ -3,13 +3,17 @@ package queue
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/tracing"
 
 	"github.com/rabbitmq/amqp091-go"
 	"github.com/wb-go/wbf/rabbitmq"
 	"github.com/wb-go/wbf/retry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Publisher определяет интерфейс для публикации сообщений в очередь
@@ -21,24 +25,33 @@ type Publisher interface {
 // RabbitMQPublisher реализует Publisher используя RabbitMQ
 type RabbitMQPublisher struct {
 	publisher *rabbitmq.Publisher
-	strategy  retry.Strategy
+
+	mu       sync.RWMutex
+	strategy retry.Strategy
 }
 
 // NewRabbitMQPublisher создает новый RabbitMQ издатель
 func NewRabbitMQPublisher(publisher *rabbitmq.Publisher, retryConfig config.RetryConfig) *RabbitMQPublisher {
-	return &RabbitMQPublisher{
-		publisher: publisher,
-		strategy: retry.Strategy{
-			Attempts: retryConfig.PublisherAttempts,
-			Delay:    retryConfig.PublisherDelay,
-			Backoff:  retryConfig.PublisherBackoff,
-		},
+	p := &RabbitMQPublisher{publisher: publisher}
+	p.SetRetryConfig(retryConfig)
+	return p
+}
+
+// SetRetryConfig заменяет стратегию повторных попыток публикации
+func (p *RabbitMQPublisher) SetRetryConfig(retryConfig config.RetryConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.strategy = retry.Strategy{
+		Attempts: retryConfig.PublisherAttempts,
+		Delay:    retryConfig.PublisherDelay,
+		Backoff:  retryConfig.PublisherBackoff,
 	}
 }
 
 // Publish публикует сообщение в очередь
 func (p *RabbitMQPublisher) Publish(ctx context.Context, body []byte, routingKey, contentType string) error {
-	return p.publisher.PublishWithRetry(body, routingKey, contentType, p.strategy)
+	return p.publish(ctx, body, routingKey, contentType, amqp091.Table{})
 }
 
 // PublishDelayed публикует сообщение с задержкой в очередь
@@ -47,11 +60,36 @@ func (p *RabbitMQPublisher) PublishDelayed(ctx context.Context, body []byte, rou
 		"x-delay": int64(delay / time.Millisecond),
 	}
 
+	return p.publish(ctx, body, routingKey, contentType, headers)
+}
+
+// publish публикует сообщение в спане producer и передает контекст трассировки в заголовках
+func (p *RabbitMQPublisher) publish(ctx context.Context, body []byte, routingKey, contentType string, headers amqp091.Table) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "rabbitmq.publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.operation.type", "publish"),
+			attribute.String("messaging.rabbitmq.destination.routing_key", routingKey),
+		),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if delay, ok := headers["x-delay"].(int64); ok {
+		span.SetAttributes(attribute.Int64("messaging.rabbitmq.delay_ms", delay))
+	}
+
+	tracing.InjectAMQP(ctx, headers)
+
 	options := rabbitmq.PublishingOptions{
 		Headers: headers,
 	}
 
-	return p.publisher.PublishWithRetry(body, routingKey, contentType, p.strategy, options)
+	p.mu.RLock()
+	strategy := p.strategy
+	p.mu.RUnlock()
+
+	return p.publisher.PublishWithRetry(body, routingKey, contentType, strategy, options)
 }
 
 // SetupQueue создает и настраивает инфраструктуру RabbitMQ (exchange, queue, привязки)
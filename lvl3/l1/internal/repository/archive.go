@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"delayed-notifier/internal/domain"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// PostgresArchiver архивирует уведомления в партиционированную таблицу notifications_archive
+type PostgresArchiver struct {
+	db *sql.DB
+}
+
+// NewPostgresArchiver создает новый архиватор поверх соединения репозитория
+func NewPostgresArchiver(repo *PostgresRepository) *PostgresArchiver {
+	return &PostgresArchiver{db: repo.db}
+}
+
+// Name возвращает название архива
+func (a *PostgresArchiver) Name() string {
+	return "table"
+}
+
+// Archive сохраняет уведомления в архивную таблицу, создавая помесячные партиции при необходимости
+func (a *PostgresArchiver) Archive(ctx context.Context, notifications []domain.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	months := make(map[time.Time]struct{})
+	for _, notification := range notifications {
+		months[monthStart(notification.CreatedDate)] = struct{}{}
+	}
+	for month := range months {
+		a.ensurePartition(ctx, month)
+	}
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin archive transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO notifications_archive (id, payload, date_created, status, notification_date, sender_id, recipient_id, channel, retries, expires_at, digest, digest_id, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (id, date_created) DO NOTHING
+	`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare archive statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, notification := range notifications {
+		if _, err := stmt.ExecContext(ctx,
+			notification.ID,
+			notification.Payload,
+			notification.CreatedDate,
+			notification.Status,
+			notification.NotificationDate,
+			notification.SenderID,
+			notification.RecipientID,
+			notification.Channel,
+			notification.Retries,
+			notification.ExpiresAt,
+			notification.Digest,
+			notification.DigestID,
+			notification.TenantID,
+		); err != nil {
+			log.Error().
+				Err(err).
+				Str("id", notification.ID).
+				Msg("Failed to archive notification in PostgreSQL")
+			return fmt.Errorf("failed to archive notification: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit archive transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ensurePartition создает партицию архива за месяц month, если ее еще нет.
+// При ошибке строки попадут в партицию по умолчанию
+func (a *PostgresArchiver) ensurePartition(ctx context.Context, month time.Time) {
+	name := fmt.Sprintf("notifications_archive_y%04dm%02d", month.Year(), int(month.Month()))
+	query := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s PARTITION OF notifications_archive FOR VALUES FROM ('%s') TO ('%s')`,
+		name,
+		month.Format(time.RFC3339),
+		month.AddDate(0, 1, 0).Format(time.RFC3339),
+	)
+
+	if _, err := a.db.ExecContext(ctx, query); err != nil {
+		log.Warn().
+			Err(err).
+			Str("partition", name).
+			Msg("Failed to create archive partition, default partition will be used")
+	}
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"delayed-notifier/internal/domain"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DigestRepository определяет интерфейс для операций с буфером и отправками дайджестов
+type DigestRepository interface {
+	LoadDigestGroups(ctx context.Context, dueBefore time.Time) ([]domain.DigestGroup, error)
+	ClaimDigest(ctx context.Context, digest domain.Digest, dueBefore time.Time, limit int) ([]domain.Notification, error)
+	CompleteDigest(ctx context.Context, id string, sentAt time.Time) error
+	ReleaseDigest(ctx context.Context, id string, reason string) error
+	RetryDigest(ctx context.Context, id string, reason string, retryAt time.Time) error
+}
+
+// LoadDigestGroups возвращает буферы тенант-получатель-канал, в которых есть уведомления,
+// наступившие к моменту dueBefore. Уведомления неотправленного дайджеста ждут времени следующей попытки
+func (r *PostgresRepository) LoadDigestGroups(ctx context.Context, dueBefore time.Time) ([]domain.DigestGroup, error) {
+	query := `
+		SELECT DISTINCT tenant_id, recipient_id, channel
+		FROM notifications
+		WHERE digest AND status = $1 AND digest_id IS NULL AND notification_date <= $2
+			AND (digest_retry_at IS NULL OR digest_retry_at <= $2)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.StatusPending, dueBefore)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Failed to load digest groups from PostgreSQL")
+		return nil, fmt.Errorf("failed to load digest groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []domain.DigestGroup
+	for rows.Next() {
+		var group domain.DigestGroup
+		if err := rows.Scan(&group.TenantID, &group.RecipientID, &group.Channel); err != nil {
+			return nil, fmt.Errorf("failed to scan digest group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate digest groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// ClaimDigest создает запись дайджеста и закрепляет за ней до limit наступивших уведомлений
+// из буфера получателя. Уже закрепленные другой репликой уведомления пропускаются.
+// Если забирать нечего, дайджест не создается и возвращается пустой список
+func (r *PostgresRepository) ClaimDigest(ctx context.Context, digest domain.Digest, dueBefore time.Time, limit int) ([]domain.Notification, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin digest transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO digests (id, tenant_id, recipient_id, channel, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, digest.ID, digest.TenantID, digest.RecipientID, digest.Channel, domain.DigestStatusPending, digest.CreatedAt)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("digest_id", digest.ID).
+			Msg("Failed to create digest in PostgreSQL")
+		return nil, fmt.Errorf("failed to create digest: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE notifications
+		SET digest_id = $1
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE digest AND status = $2 AND digest_id IS NULL
+				AND tenant_id = $3 AND recipient_id = $4 AND channel = $5 AND notification_date <= $6
+				AND (digest_retry_at IS NULL OR digest_retry_at <= $6)
+			ORDER BY notification_date
+			LIMIT $7
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, payload, date_created, status, notification_date, sender_id, recipient_id, channel, retries, expires_at, digest, digest_id, tenant_id
+	`, digest.ID, domain.StatusPending, digest.TenantID, digest.RecipientID, digest.Channel, dueBefore, limit)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("digest_id", digest.ID).
+			Msg("Failed to claim digest notifications in PostgreSQL")
+		return nil, fmt.Errorf("failed to claim digest notifications: %w", err)
+	}
+
+	var notifications []domain.Notification
+	for rows.Next() {
+		var notification domain.Notification
+		if err := rows.Scan(
+			&notification.ID,
+			&notification.Payload,
+			&notification.CreatedDate,
+			&notification.Status,
+			&notification.NotificationDate,
+			&notification.SenderID,
+			&notification.RecipientID,
+			&notification.Channel,
+			&notification.Retries,
+			&notification.ExpiresAt,
+			&notification.Digest,
+			&notification.DigestID,
+			&notification.TenantID,
+		); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan digest notification: %w", err)
+		}
+		notifications = append(notifications, notification)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate digest notifications: %w", err)
+	}
+
+	if len(notifications) == 0 {
+		return nil, nil
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE digests SET notification_count = $2 WHERE id = $1`,
+		digest.ID, len(notifications),
+	); err != nil {
+		return nil, fmt.Errorf("failed to update digest size: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit digest transaction: %w", err)
+	}
+
+	log.Debug().
+		Str("digest_id", digest.ID).
+		Int("count", len(notifications)).
+		Msg("Digest notifications claimed in PostgreSQL")
+
+	return notifications, nil
+}
+
+// CompleteDigest помечает дайджест отправленным
+func (r *PostgresRepository) CompleteDigest(ctx context.Context, id string, sentAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE digests SET status = $2, sent_at = $3 WHERE id = $1`,
+		id, domain.DigestStatusSent, sentAt,
+	)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("digest_id", id).
+			Msg("Failed to complete digest in PostgreSQL")
+		return fmt.Errorf("failed to complete digest: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseDigest помечает дайджест неотправленным и возвращает его
+// неотправленные уведомления в буфер без учета попытки
+func (r *PostgresRepository) ReleaseDigest(ctx context.Context, id string, reason string) error {
+	return r.releaseDigest(ctx, id, reason,
+		`UPDATE notifications SET digest_id = NULL WHERE digest_id = $1 AND status = $2`,
+	)
+}
+
+// RetryDigest помечает дайджест неотправленным, увеличивает счетчик попыток его неотправленных
+// уведомлений и возвращает их в буфер не раньше retryAt
+func (r *PostgresRepository) RetryDigest(ctx context.Context, id string, reason string, retryAt time.Time) error {
+	return r.releaseDigest(ctx, id, reason,
+		`UPDATE notifications SET digest_id = NULL, retries = retries + 1, digest_retry_at = $3 WHERE digest_id = $1 AND status = $2`,
+		retryAt,
+	)
+}
+
+// releaseDigest помечает дайджест неотправленным и выполняет release для его уведомлений
+// в статусе pending с параметрами (id дайджеста, статус, args...)
+func (r *PostgresRepository) releaseDigest(ctx context.Context, id string, reason string, release string, args ...any) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin digest transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE digests SET status = $2, error = $3 WHERE id = $1`,
+		id, domain.DigestStatusFailed, reason,
+	); err != nil {
+		return fmt.Errorf("failed to mark digest as failed: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, release, append([]any{id, domain.StatusPending}, args...)...); err != nil {
+		return fmt.Errorf("failed to release digest notifications: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().
+			Err(err).
+			Str("digest_id", id).
+			Msg("Failed to release digest in PostgreSQL")
+		return fmt.Errorf("failed to commit digest transaction: %w", err)
+	}
+
+	return nil
+}
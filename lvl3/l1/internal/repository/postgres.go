@@ -5,10 +5,12 @@ import (
 	"database/sql"
 	"delayed-notifier/internal/config"
 	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/tenant"
 	"errors"
 	"fmt"
+	"time"
 
-	_ "github.com/lib/pq" // Драйвер PostgreSQL
+	"github.com/lib/pq" // Драйвер PostgreSQL
 	"github.com/rs/zerolog/log"
 )
 
@@ -19,9 +21,14 @@ type NotificationRepository interface {
 	LoadStatusByID(ctx context.Context, id string) (domain.Status, error)
 	UpdateStatusByID(ctx context.Context, id string, status domain.Status) (*domain.Notification, error)
 	CancelByID(ctx context.Context, id string) error
+	RescheduleByID(ctx context.Context, id string, notificationDate time.Time, from []domain.Status) (*domain.Notification, error)
+	StoreWithinLimit(ctx context.Context, notification domain.Notification, since time.Time, limit int) error
 }
 
-// PostgresRepository реализует NotificationRepository используя PostgreSQL
+// PostgresRepository реализует NotificationRepository используя PostgreSQL.
+// Запросы по ID ограничиваются тенантом из контекста (tenant.Scope): уведомление
+// другого тенанта считается ненайденным. Без тенанта в контексте, например в воркерах,
+// ограничение не применяется
 type PostgresRepository struct {
 	db *sql.DB
 }
@@ -47,8 +54,8 @@ func NewPostgresRepository(dsn string, cfg *config.DBConfig) (*PostgresRepositor
 // Store сохраняет уведомление в базу данных
 func (r *PostgresRepository) Store(ctx context.Context, notification domain.Notification) error {
 	query := `
-		INSERT INTO notifications (id, payload, date_created, status, notification_date, sender_id, recipient_id, channel, retries)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO notifications (id, payload, date_created, status, notification_date, sender_id, recipient_id, channel, retries, expires_at, digest, digest_id, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		ON CONFLICT (id) DO UPDATE SET
 			payload = EXCLUDED.payload,
 			status = EXCLUDED.status,
@@ -56,7 +63,10 @@ func (r *PostgresRepository) Store(ctx context.Context, notification domain.Noti
 			sender_id = EXCLUDED.sender_id,
 			recipient_id = EXCLUDED.recipient_id,
 			channel = EXCLUDED.channel,
-			retries = EXCLUDED.retries
+			retries = EXCLUDED.retries,
+			expires_at = EXCLUDED.expires_at,
+			digest = EXCLUDED.digest,
+			digest_id = EXCLUDED.digest_id
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -69,6 +79,10 @@ func (r *PostgresRepository) Store(ctx context.Context, notification domain.Noti
 		notification.RecipientID,
 		notification.Channel,
 		notification.Retries,
+		notification.ExpiresAt,
+		notification.Digest,
+		notification.DigestID,
+		notification.TenantID,
 	)
 
 	if err != nil {
@@ -89,13 +103,13 @@ func (r *PostgresRepository) Store(ctx context.Context, notification domain.Noti
 // LoadByID получает уведомление по ID из базы данных
 func (r *PostgresRepository) LoadByID(ctx context.Context, id string) (*domain.Notification, error) {
 	query := `
-		SELECT id, payload, date_created, status, notification_date, sender_id, recipient_id, channel, retries
+		SELECT id, payload, date_created, status, notification_date, sender_id, recipient_id, channel, retries, expires_at, digest, digest_id, tenant_id
 		FROM notifications
-		WHERE id = $1
+		WHERE id = $1 AND ($2 = '' OR tenant_id = $2)
 	`
 
 	var notification domain.Notification
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, tenant.Scope(ctx)).Scan(
 		&notification.ID,
 		&notification.Payload,
 		&notification.CreatedDate,
@@ -105,11 +119,15 @@ func (r *PostgresRepository) LoadByID(ctx context.Context, id string) (*domain.N
 		&notification.RecipientID,
 		&notification.Channel,
 		&notification.Retries,
+		&notification.ExpiresAt,
+		&notification.Digest,
+		&notification.DigestID,
+		&notification.TenantID,
 	)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("запись с id %s не найдена", id)
+			return nil, fmt.Errorf("notification %s: %w", id, domain.ErrNotFound)
 		}
 		log.Error().
 			Err(err).
@@ -123,14 +141,14 @@ func (r *PostgresRepository) LoadByID(ctx context.Context, id string) (*domain.N
 
 // LoadStatusByID получает статус уведомления по ID из базы данных
 func (r *PostgresRepository) LoadStatusByID(ctx context.Context, id string) (domain.Status, error) {
-	query := `SELECT status FROM notifications WHERE id = $1`
+	query := `SELECT status FROM notifications WHERE id = $1 AND ($2 = '' OR tenant_id = $2)`
 
 	var status domain.Status
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&status)
+	err := r.db.QueryRowContext(ctx, query, id, tenant.Scope(ctx)).Scan(&status)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", fmt.Errorf("запись с id %s не найдена", id)
+			return "", fmt.Errorf("notification %s: %w", id, domain.ErrNotFound)
 		}
 		log.Error().
 			Err(err).
@@ -147,12 +165,12 @@ func (r *PostgresRepository) UpdateStatusByID(ctx context.Context, id string, st
 	query := `
 		UPDATE notifications 
 		SET status = $2 
-		WHERE id = $1
-		RETURNING id, payload, date_created, status, notification_date, sender_id, recipient_id, channel, retries
+		WHERE id = $1 AND ($3 = '' OR tenant_id = $3)
+		RETURNING id, payload, date_created, status, notification_date, sender_id, recipient_id, channel, retries, expires_at, digest, digest_id, tenant_id
 	`
 
 	var notification domain.Notification
-	err := r.db.QueryRowContext(ctx, query, id, status).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, status, tenant.Scope(ctx)).Scan(
 		&notification.ID,
 		&notification.Payload,
 		&notification.CreatedDate,
@@ -162,11 +180,15 @@ func (r *PostgresRepository) UpdateStatusByID(ctx context.Context, id string, st
 		&notification.RecipientID,
 		&notification.Channel,
 		&notification.Retries,
+		&notification.ExpiresAt,
+		&notification.Digest,
+		&notification.DigestID,
+		&notification.TenantID,
 	)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("запись с id %s не найдена", id)
+			return nil, fmt.Errorf("notification %s: %w", id, domain.ErrNotFound)
 		}
 		log.Error().
 			Err(err).
@@ -184,15 +206,16 @@ func (r *PostgresRepository) UpdateStatusByID(ctx context.Context, id string, st
 	return &notification, nil
 }
 
-// CancelByID отменяет уведомление по ID в базе данных
+// CancelByID отменяет уведомление по ID в базе данных.
+// Отменить можно только уведомление в статусе pending
 func (r *PostgresRepository) CancelByID(ctx context.Context, id string) error {
 	query := `
 		UPDATE notifications 
 		SET status = $2 
-		WHERE id = $1
+		WHERE id = $1 AND status = $3 AND ($4 = '' OR tenant_id = $4)
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id, domain.StatusCancelled)
+	result, err := r.db.ExecContext(ctx, query, id, domain.StatusCancelled, domain.StatusPending, tenant.Scope(ctx))
 	if err != nil {
 		log.Error().
 			Err(err).
@@ -207,7 +230,11 @@ func (r *PostgresRepository) CancelByID(ctx context.Context, id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("запись с id %s не найдена", id)
+		status, err := r.LoadStatusByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("cannot cancel notification %s: %w", id, domain.StatusConflictError(status))
 	}
 
 	log.Debug().
@@ -217,6 +244,131 @@ func (r *PostgresRepository) CancelByID(ctx context.Context, id string) error {
 	return nil
 }
 
+// RescheduleByID возвращает уведомление в статус pending с новой датой отправки
+// и сбрасывает счетчик попыток. Срок актуальности, истекающий раньше новой даты, сбрасывается.
+// Изменить можно только уведомление в одном из статусов from;
+// уведомление, уже собранное в дайджест, не изменяется до завершения его отправки
+func (r *PostgresRepository) RescheduleByID(ctx context.Context, id string, notificationDate time.Time, from []domain.Status) (*domain.Notification, error) {
+	query := `
+		UPDATE notifications
+		SET status = $2, notification_date = $3, retries = 0, digest_id = NULL, digest_retry_at = NULL,
+			expires_at = CASE WHEN expires_at > $3 THEN expires_at END
+		WHERE id = $1 AND status = ANY($4) AND (status <> $2 OR digest_id IS NULL) AND ($5 = '' OR tenant_id = $5)
+		RETURNING id, payload, date_created, status, notification_date, sender_id, recipient_id, channel, retries, expires_at, digest, digest_id, tenant_id
+	`
+
+	statuses := make([]string, 0, len(from))
+	for _, status := range from {
+		statuses = append(statuses, string(status))
+	}
+
+	var notification domain.Notification
+	err := r.db.QueryRowContext(ctx, query, id, domain.StatusPending, notificationDate, pq.Array(statuses), tenant.Scope(ctx)).Scan(
+		&notification.ID,
+		&notification.Payload,
+		&notification.CreatedDate,
+		&notification.Status,
+		&notification.NotificationDate,
+		&notification.SenderID,
+		&notification.RecipientID,
+		&notification.Channel,
+		&notification.Retries,
+		&notification.ExpiresAt,
+		&notification.Digest,
+		&notification.DigestID,
+		&notification.TenantID,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status, err := r.LoadStatusByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			conflictErr := domain.StatusConflictError(status)
+			if conflictErr == nil {
+				conflictErr = domain.ErrPending
+			}
+			return nil, fmt.Errorf("cannot reschedule notification %s: %w", id, conflictErr)
+		}
+		log.Error().
+			Err(err).
+			Str("id", id).
+			Msg("Failed to reschedule notification in PostgreSQL")
+		return nil, fmt.Errorf("failed to reschedule notification: %w", err)
+	}
+
+	log.Debug().
+		Str("id", id).
+		Time("notify_at", notificationDate).
+		Msg("Notification rescheduled in PostgreSQL")
+
+	return &notification, nil
+}
+
+// StoreWithinLimit сохраняет новое уведомление, если тенант создал начиная с since меньше limit уведомлений,
+// иначе возвращает domain.ErrQuotaExceeded. Подсчет и вставка выполняются в одной транзакции под
+// advisory-блокировкой тенанта, поэтому параллельные запросы не превышают лимит
+func (r *PostgresRepository) StoreWithinLimit(ctx context.Context, notification domain.Notification, since time.Time, limit int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin quota transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('daily_quota:' || $1))`, notification.TenantID); err != nil {
+		return fmt.Errorf("failed to lock tenant quota: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO notifications (id, payload, date_created, status, notification_date, sender_id, recipient_id, channel, retries, expires_at, digest, digest_id, tenant_id)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+		WHERE (SELECT COUNT(*) FROM notifications WHERE tenant_id = $13 AND date_created >= $14) < $15
+	`,
+		notification.ID,
+		notification.Payload,
+		notification.CreatedDate,
+		notification.Status,
+		notification.NotificationDate,
+		notification.SenderID,
+		notification.RecipientID,
+		notification.Channel,
+		notification.Retries,
+		notification.ExpiresAt,
+		notification.Digest,
+		notification.DigestID,
+		notification.TenantID,
+		since,
+		limit,
+	)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("id", notification.ID).
+			Str("tenant_id", notification.TenantID).
+			Msg("Failed to store notification within quota in PostgreSQL")
+		return fmt.Errorf("failed to store notification: %w", err)
+	}
+
+	stored, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check stored notification: %w", err)
+	}
+	if stored == 0 {
+		return domain.ErrQuotaExceeded
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit quota transaction: %w", err)
+	}
+
+	log.Debug().
+		Str("id", notification.ID).
+		Msg("Notification stored in PostgreSQL")
+
+	return nil
+}
+
 // Close закрывает соединение с базой данных
 func (r *PostgresRepository) Close() error {
 	if r.db != nil {
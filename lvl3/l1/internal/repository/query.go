@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/tenant"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// NotificationQueryRepository определяет интерфейс просмотра уведомлений для панели управления.
+// Выборки ограничиваются тенантом из контекста так же, как запросы по ID
+type NotificationQueryRepository interface {
+	List(ctx context.Context, filter domain.NotificationFilter) (domain.NotificationPage, error)
+	LoadHistory(ctx context.Context, id string) ([]domain.StatusChange, error)
+	CountStatuses(ctx context.Context) (map[domain.Status]int64, error)
+}
+
+// List возвращает страницу уведомлений, подходящих под фильтр, от новых к старым
+func (r *PostgresRepository) List(ctx context.Context, filter domain.NotificationFilter) (domain.NotificationPage, error) {
+	query := `
+		SELECT id, payload, date_created, status, notification_date, sender_id, recipient_id, channel, retries, expires_at, digest, digest_id, tenant_id,
+			COUNT(*) OVER ()
+		FROM notifications
+		WHERE ($1 = '' OR tenant_id = $1)
+			AND ($2 = '' OR status = $2)
+			AND ($3 = '' OR channel = $3)
+			AND ($4 = '' OR recipient_id = $4)
+		ORDER BY date_created DESC, id
+		LIMIT $5 OFFSET $6
+	`
+
+	rows, err := r.db.QueryContext(ctx, query,
+		tenant.Scope(ctx),
+		filter.Status,
+		filter.Channel,
+		filter.RecipientID,
+		filter.Limit,
+		filter.Offset,
+	)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Failed to list notifications from PostgreSQL")
+		return domain.NotificationPage{}, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	page := domain.NotificationPage{Notifications: []domain.Notification{}}
+	for rows.Next() {
+		var notification domain.Notification
+		if err := rows.Scan(
+			&notification.ID,
+			&notification.Payload,
+			&notification.CreatedDate,
+			&notification.Status,
+			&notification.NotificationDate,
+			&notification.SenderID,
+			&notification.RecipientID,
+			&notification.Channel,
+			&notification.Retries,
+			&notification.ExpiresAt,
+			&notification.Digest,
+			&notification.DigestID,
+			&notification.TenantID,
+			&page.Total,
+		); err != nil {
+			return domain.NotificationPage{}, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		page.Notifications = append(page.Notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return domain.NotificationPage{}, fmt.Errorf("failed to iterate notifications: %w", err)
+	}
+
+	// За пределами последней страницы оконная функция не возвращает строк
+	if len(page.Notifications) == 0 && filter.Offset > 0 {
+		total, err := r.countFiltered(ctx, filter)
+		if err != nil {
+			return domain.NotificationPage{}, err
+		}
+		page.Total = total
+	}
+
+	return page, nil
+}
+
+func (r *PostgresRepository) countFiltered(ctx context.Context, filter domain.NotificationFilter) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM notifications
+		WHERE ($1 = '' OR tenant_id = $1)
+			AND ($2 = '' OR status = $2)
+			AND ($3 = '' OR channel = $3)
+			AND ($4 = '' OR recipient_id = $4)
+	`
+
+	var total int64
+	err := r.db.QueryRowContext(ctx, query,
+		tenant.Scope(ctx),
+		filter.Status,
+		filter.Channel,
+		filter.RecipientID,
+	).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	return total, nil
+}
+
+// LoadHistory возвращает историю статусов уведомления в порядке изменений.
+// История ведется триггером БД при каждой смене статуса или даты отправки
+func (r *PostgresRepository) LoadHistory(ctx context.Context, id string) ([]domain.StatusChange, error) {
+	query := `
+		SELECT h.status, h.notification_date, h.changed_at
+		FROM notification_status_history h
+		JOIN notifications n ON n.id = h.notification_id
+		WHERE h.notification_id = $1 AND ($2 = '' OR n.tenant_id = $2)
+		ORDER BY h.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id, tenant.Scope(ctx))
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("id", id).
+			Msg("Failed to load notification history from PostgreSQL")
+		return nil, fmt.Errorf("failed to load notification history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []domain.StatusChange{}
+	for rows.Next() {
+		var change domain.StatusChange
+		if err := rows.Scan(&change.Status, &change.NotificationDate, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification history: %w", err)
+		}
+		history = append(history, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notification history: %w", err)
+	}
+
+	// Уведомления, созданные до появления истории, не имеют записей
+	if len(history) == 0 {
+		if _, err := r.LoadStatusByID(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	return history, nil
+}
+
+// CountStatuses возвращает количество уведомлений тенанта из контекста в разрезе статусов
+func (r *PostgresRepository) CountStatuses(ctx context.Context) (map[domain.Status]int64, error) {
+	return r.CountByStatus(ctx)
+}
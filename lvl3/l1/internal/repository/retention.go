@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/tenant"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// RetentionRepository определяет интерфейс для операций очистки устаревших уведомлений
+type RetentionRepository interface {
+	LoadExpired(ctx context.Context, status domain.Status, olderThan time.Time, limit int) ([]domain.Notification, error)
+	DeleteExpired(ctx context.Context, ids []string, status domain.Status, olderThan time.Time) (int64, error)
+	CountByStatus(ctx context.Context) (map[domain.Status]int64, error)
+	CountExpired(ctx context.Context, status domain.Status, olderThan time.Time) (int64, error)
+}
+
+// LoadExpired получает пачку уведомлений со статусом status, не изменявшихся с olderThan
+func (r *PostgresRepository) LoadExpired(ctx context.Context, status domain.Status, olderThan time.Time, limit int) ([]domain.Notification, error) {
+	query := `
+		SELECT id, payload, date_created, status, notification_date, sender_id, recipient_id, channel, retries, expires_at, digest, digest_id, tenant_id
+		FROM notifications
+		WHERE status = $1 AND updated_at < $2
+		ORDER BY updated_at
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, status, olderThan, limit)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("status", string(status)).
+			Msg("Failed to load expired notifications from PostgreSQL")
+		return nil, fmt.Errorf("failed to load expired notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []domain.Notification
+	for rows.Next() {
+		var notification domain.Notification
+		if err := rows.Scan(
+			&notification.ID,
+			&notification.Payload,
+			&notification.CreatedDate,
+			&notification.Status,
+			&notification.NotificationDate,
+			&notification.SenderID,
+			&notification.RecipientID,
+			&notification.Channel,
+			&notification.Retries,
+			&notification.ExpiresAt,
+			&notification.Digest,
+			&notification.DigestID,
+			&notification.TenantID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan expired notification: %w", err)
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate expired notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// DeleteExpired удаляет уведомления из списка ID, которые по-прежнему имеют статус status и не изменялись
+// с olderThan. Уведомление, которое после LoadExpired отправили повторно или перенесли, не удаляется
+func (r *PostgresRepository) DeleteExpired(ctx context.Context, ids []string, status domain.Status, olderThan time.Time) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM notifications WHERE id = ANY($1) AND status = $2 AND updated_at < $3`,
+		pq.Array(ids), status, olderThan,
+	)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Int("count", len(ids)).
+			Msg("Failed to delete notifications from PostgreSQL")
+		return 0, fmt.Errorf("failed to delete notifications: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	log.Debug().
+		Int64("deleted", deleted).
+		Msg("Notifications deleted from PostgreSQL")
+
+	return deleted, nil
+}
+
+// CountByStatus возвращает количество уведомлений тенанта из контекста (tenant.Scope) в разрезе статусов
+func (r *PostgresRepository) CountByStatus(ctx context.Context) (map[domain.Status]int64, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT status, COUNT(*) FROM notifications WHERE ($1 = '' OR tenant_id = $1) GROUP BY status`,
+		tenant.Scope(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count notifications by status: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[domain.Status]int64)
+	for rows.Next() {
+		var (
+			status domain.Status
+			count  int64
+		)
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan notifications count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notifications count: %w", err)
+	}
+
+	return counts, nil
+}
+
+// CountExpired возвращает количество уведомлений тенанта из контекста со статусом status, не изменявшихся с olderThan
+func (r *PostgresRepository) CountExpired(ctx context.Context, status domain.Status, olderThan time.Time) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM notifications WHERE status = $1 AND updated_at < $2 AND ($3 = '' OR tenant_id = $3)`,
+		status, olderThan, tenant.Scope(ctx),
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count expired notifications: %w", err)
+	}
+
+	return count, nil
+}
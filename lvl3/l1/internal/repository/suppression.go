@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"delayed-notifier/internal/domain"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SuppressionRepository определяет интерфейс для операций со списком подавления.
+// Тенант передается явно: записи управляются через API от имени тенанта запроса,
+// а проверяются воркерами по тенанту уведомления
+type SuppressionRepository interface {
+	UpsertSuppression(ctx context.Context, suppression domain.Suppression) (*domain.Suppression, error)
+	DeleteSuppression(ctx context.Context, tenantID, recipientID string, channel domain.Channel) error
+	LoadSuppression(ctx context.Context, tenantID, recipientID string, channel domain.Channel) (*domain.Suppression, error)
+	ListSuppressions(ctx context.Context, tenantID string, filter domain.SuppressionFilter) (domain.SuppressionPage, error)
+}
+
+// UpsertSuppression добавляет получателя в список подавления или заменяет
+// причину и срок существующей записи
+func (r *PostgresRepository) UpsertSuppression(ctx context.Context, suppression domain.Suppression) (*domain.Suppression, error) {
+	query := `
+		INSERT INTO suppressions (tenant_id, recipient_id, channel, reason, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id, recipient_id, channel) DO UPDATE SET
+			reason = EXCLUDED.reason,
+			expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at
+		RETURNING tenant_id, recipient_id, channel, reason, expires_at, created_at
+	`
+
+	var stored domain.Suppression
+	err := r.db.QueryRowContext(ctx, query,
+		suppression.TenantID,
+		suppression.RecipientID,
+		suppression.Channel,
+		suppression.Reason,
+		suppression.ExpiresAt,
+		suppression.CreatedAt,
+	).Scan(
+		&stored.TenantID,
+		&stored.RecipientID,
+		&stored.Channel,
+		&stored.Reason,
+		&stored.ExpiresAt,
+		&stored.CreatedAt,
+	)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("tenant_id", suppression.TenantID).
+			Str("channel", string(suppression.Channel)).
+			Msg("Failed to store suppression in PostgreSQL")
+		return nil, fmt.Errorf("failed to store suppression: %w", err)
+	}
+
+	return &stored, nil
+}
+
+// DeleteSuppression удаляет получателя из списка подавления
+func (r *PostgresRepository) DeleteSuppression(ctx context.Context, tenantID, recipientID string, channel domain.Channel) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM suppressions WHERE tenant_id = $1 AND recipient_id = $2 AND channel = $3`,
+		tenantID, recipientID, channel,
+	)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("tenant_id", tenantID).
+			Str("channel", string(channel)).
+			Msg("Failed to delete suppression from PostgreSQL")
+		return fmt.Errorf("failed to delete suppression: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrSuppressionNotFound
+	}
+
+	return nil
+}
+
+// LoadSuppression возвращает запись списка подавления получателя, в том числе истекшую
+func (r *PostgresRepository) LoadSuppression(ctx context.Context, tenantID, recipientID string, channel domain.Channel) (*domain.Suppression, error) {
+	query := `
+		SELECT tenant_id, recipient_id, channel, reason, expires_at, created_at
+		FROM suppressions
+		WHERE tenant_id = $1 AND recipient_id = $2 AND channel = $3
+	`
+
+	var suppression domain.Suppression
+	err := r.db.QueryRowContext(ctx, query, tenantID, recipientID, channel).Scan(
+		&suppression.TenantID,
+		&suppression.RecipientID,
+		&suppression.Channel,
+		&suppression.Reason,
+		&suppression.ExpiresAt,
+		&suppression.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSuppressionNotFound
+		}
+		log.Error().
+			Err(err).
+			Str("tenant_id", tenantID).
+			Str("channel", string(channel)).
+			Msg("Failed to load suppression from PostgreSQL")
+		return nil, fmt.Errorf("failed to load suppression: %w", err)
+	}
+
+	return &suppression, nil
+}
+
+// ListSuppressions возвращает страницу действующих на момент filter.ActiveAt записей
+// списка подавления тенанта от новых к старым
+func (r *PostgresRepository) ListSuppressions(ctx context.Context, tenantID string, filter domain.SuppressionFilter) (domain.SuppressionPage, error) {
+	where := `
+		WHERE tenant_id = $1
+			AND ($2 = '' OR channel = $2)
+			AND ($3 = '' OR recipient_id = $3)
+			AND ($4 = '' OR reason = $4)
+			AND (expires_at IS NULL OR expires_at > $5)
+	`
+	args := []any{tenantID, filter.Channel, filter.RecipientID, filter.Reason, filter.ActiveAt}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT tenant_id, recipient_id, channel, reason, expires_at, created_at
+		FROM suppressions`+where+`
+		ORDER BY created_at DESC, recipient_id, channel
+		LIMIT $6 OFFSET $7
+	`, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("tenant_id", tenantID).
+			Msg("Failed to list suppressions from PostgreSQL")
+		return domain.SuppressionPage{}, fmt.Errorf("failed to list suppressions: %w", err)
+	}
+	defer rows.Close()
+
+	page := domain.SuppressionPage{Suppressions: []domain.Suppression{}}
+	for rows.Next() {
+		var suppression domain.Suppression
+		if err := rows.Scan(
+			&suppression.TenantID,
+			&suppression.RecipientID,
+			&suppression.Channel,
+			&suppression.Reason,
+			&suppression.ExpiresAt,
+			&suppression.CreatedAt,
+		); err != nil {
+			return domain.SuppressionPage{}, fmt.Errorf("failed to scan suppression: %w", err)
+		}
+		page.Suppressions = append(page.Suppressions, suppression)
+	}
+
+	if err := rows.Err(); err != nil {
+		return domain.SuppressionPage{}, fmt.Errorf("failed to iterate suppressions: %w", err)
+	}
+
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM suppressions`+where, args...).Scan(&page.Total); err != nil {
+		return domain.SuppressionPage{}, fmt.Errorf("failed to count suppressions: %w", err)
+	}
+
+	return page, nil
+}
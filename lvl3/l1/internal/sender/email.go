@@ -6,8 +6,10 @@ import (
 	"delayed-notifier/internal/domain"
 	"delayed-notifier/internal/dto"
 	"fmt"
+	"html"
 	"net/smtp"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/jordan-wright/email"
@@ -45,13 +47,29 @@ Channel: %s
 Date: %s
 
 This is an automated message from the notification service.
+`
+
+	// HTMLUnsubscribeFooter блок со ссылкой отписки, добавляемый в конец HTML письма
+	HTMLUnsubscribeFooter = `<p><small>Don't want to receive these notifications? <a href="%s">Unsubscribe</a></small></p>
+		`
+
+	// TextUnsubscribeFooter строка со ссылкой отписки, добавляемая в конец текстового письма
+	TextUnsubscribeFooter = `
+To stop receiving these notifications, unsubscribe: %s
 `
 )
 
+// UnsubscribeLinker формирует ссылку отписки получателя тенанта от канала.
+// Пустая ссылка означает, что отписка отключена
+type UnsubscribeLinker interface {
+	URL(tenantID, recipientID string, channel domain.Channel) string
+}
+
 // EmailSender обрабатывает email уведомления
 type EmailSender struct {
 	config dto.EmailConfig
 	auth   smtp.Auth
+	links  UnsubscribeLinker
 }
 
 // emailRegex компилируется один раз для оптимизации
@@ -98,6 +116,11 @@ func validateEmailConfig(config dto.EmailConfig) error {
 	return nil
 }
 
+// SetUnsubscribeLinks включает ссылку отписки и заголовки List-Unsubscribe в письмах
+func (s *EmailSender) SetUnsubscribeLinks(links UnsubscribeLinker) {
+	s.links = links
+}
+
 // Send отправляет email уведомление с дефолтной конфигурацией
 func (s *EmailSender) Send(ctx context.Context, notification domain.Notification) error {
 	return s.sendEmail(ctx, notification, DefaultSubject)
@@ -166,12 +189,33 @@ func (s *EmailSender) createEmail(notification domain.Notification, subject, fro
 
 	formattedDate := notification.NotificationDate.Format(time.RFC3339)
 
-	e.HTML = []byte(fmt.Sprintf(HTMLTemplate, subject, notification.Payload, notification.Channel, formattedDate))
-	e.Text = []byte(fmt.Sprintf(TextTemplate, subject, notification.Payload, notification.Channel, formattedDate))
+	htmlBody := fmt.Sprintf(HTMLTemplate, subject, notification.Payload, notification.Channel, formattedDate)
+	textBody := fmt.Sprintf(TextTemplate, subject, notification.Payload, notification.Channel, formattedDate)
+
+	if unsubscribeURL := s.unsubscribeURL(notification); unsubscribeURL != "" {
+		footer := fmt.Sprintf(HTMLUnsubscribeFooter, html.EscapeString(unsubscribeURL))
+		htmlBody = strings.Replace(htmlBody, "</body>", footer+"</body>", 1)
+		textBody += fmt.Sprintf(TextUnsubscribeFooter, unsubscribeURL)
+
+		// RFC 8058: почтовые клиенты показывают кнопку отписки и отправляют POST на ссылку
+		e.Headers.Set("List-Unsubscribe", "<"+unsubscribeURL+">")
+		e.Headers.Set("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+
+	e.HTML = []byte(htmlBody)
+	e.Text = []byte(textBody)
 
 	return e
 }
 
+// unsubscribeURL возвращает ссылку отписки получателя уведомления или пустую строку
+func (s *EmailSender) unsubscribeURL(notification domain.Notification) string {
+	if s.links == nil {
+		return ""
+	}
+	return s.links.URL(notification.TenantID, notification.RecipientID, domain.ChannelEmail)
+}
+
 // sendEmailMessage отправляет email с использованием текущей авторизации
 func (s *EmailSender) sendEmailMessage(e *email.Email, addr, notificationID, recipient string) error {
 	var err error
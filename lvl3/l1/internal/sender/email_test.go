@@ -1,8 +1,10 @@
 package sender
 
 import (
+	"delayed-notifier/internal/domain"
 	"delayed-notifier/internal/dto"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -80,3 +82,41 @@ func TestEmailSender_NewEmailSender_InvalidConfig(t *testing.T) {
 		})
 	}
 }
+
+type staticLinker string
+
+func (l staticLinker) URL(tenantID, recipientID string, channel domain.Channel) string {
+	return string(l) + "?tenant=" + tenantID + "&channel=" + string(channel)
+}
+
+func TestEmailSender_CreateEmail_Unsubscribe(t *testing.T) {
+	sender, err := NewEmailSender(dto.EmailConfig{
+		SMTPHost:  "smtp.gmail.com",
+		SMTPPort:  587,
+		Username:  "test@gmail.com",
+		Password:  "password",
+		FromEmail: "test@gmail.com",
+	})
+	require.NoError(t, err)
+
+	notification := domain.Notification{
+		TenantID:         "billing",
+		RecipientID:      "user@example.com",
+		Channel:          domain.ChannelEmail,
+		Payload:          "Hello",
+		NotificationDate: time.Now(),
+	}
+
+	e := sender.createEmail(notification, "Subject", "test@gmail.com")
+	assert.Empty(t, e.Headers.Get("List-Unsubscribe"))
+	assert.NotContains(t, string(e.Text), "Unsubscribe")
+
+	sender.SetUnsubscribeLinks(staticLinker("https://notifier.example.com/unsubscribe"))
+	e = sender.createEmail(notification, "Subject", "test@gmail.com")
+
+	link := "https://notifier.example.com/unsubscribe?tenant=billing&channel=email"
+	assert.Equal(t, "<"+link+">", e.Headers.Get("List-Unsubscribe"))
+	assert.Equal(t, "List-Unsubscribe=One-Click", e.Headers.Get("List-Unsubscribe-Post"))
+	assert.Contains(t, string(e.Text), link)
+	assert.Contains(t, string(e.HTML), "tenant=billing&amp;channel=email")
+}
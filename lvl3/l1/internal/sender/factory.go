@@ -4,12 +4,17 @@ import (
 	"delayed-notifier/internal/domain"
 	"delayed-notifier/internal/dto"
 	"fmt"
+	"sync"
 )
 
-// Factory создает отправители каналов для различных типов уведомлений
+// Factory создает отправители каналов для различных типов уведомлений.
+// Отправители можно заменить на лету через Replace
 type Factory struct {
+	mu       sync.RWMutex
 	telegram *TelegramSender
 	email    *EmailSender
+	tenants  map[string]*Factory
+	links    UnsubscribeLinker
 }
 
 // NewFactory создает новую фабрику отправителей
@@ -17,11 +22,64 @@ func NewFactory(telegram *TelegramSender, email *EmailSender) *Factory {
 	return &Factory{
 		telegram: telegram,
 		email:    email,
+		tenants:  make(map[string]*Factory),
 	}
 }
 
+// SetTenantProfile задает отправители тенанта по умолчанию.
+// Незаданный (nil) отправитель заменяется общим
+func (f *Factory) SetTenantProfile(tenantID string, telegram *TelegramSender, email *EmailSender) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	profile := &Factory{telegram: f.telegram, email: f.email}
+	if telegram != nil {
+		profile.telegram = telegram
+	}
+	if email != nil {
+		profile.email = email
+	}
+	f.tenants[tenantID] = profile
+}
+
+// SetUnsubscribeLinks включает ссылку отписки в письмах, которые отправляются
+// с пользовательской конфигурацией (GetEmailSenderWithConfig)
+func (f *Factory) SetUnsubscribeLinks(links UnsubscribeLinker) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.links = links
+}
+
+// GetTenantSender возвращает отправитель канала из профиля тенанта,
+// а если профиль не задан — общий отправитель
+func (f *Factory) GetTenantSender(tenantID string, channel domain.Channel) (ChannelSender, error) {
+	f.mu.RLock()
+	profile, ok := f.tenants[tenantID]
+	f.mu.RUnlock()
+
+	if ok {
+		return profile.GetSender(channel)
+	}
+	return f.GetSender(channel)
+}
+
+// Replace атомарно заменяет общие отправители и профили тенантов отправителями из other.
+// Уже начатые отправки завершаются старыми отправителями
+func (f *Factory) Replace(other *Factory) {
+	other.mu.RLock()
+	telegram, email, tenants, links := other.telegram, other.email, other.tenants, other.links
+	other.mu.RUnlock()
+
+	f.mu.Lock()
+	f.telegram, f.email, f.tenants, f.links = telegram, email, tenants, links
+	f.mu.Unlock()
+}
+
 // GetSender возвращает отправитель канала для указанного типа канала
 func (f *Factory) GetSender(channel domain.Channel) (ChannelSender, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
 	switch channel {
 	case domain.ChannelTelegram:
 		if f.telegram == nil {
@@ -40,5 +98,14 @@ func (f *Factory) GetSender(channel domain.Channel) (ChannelSender, error) {
 
 // GetEmailSenderWithConfig возвращает email отправитель с пользовательской конфигурацией
 func (f *Factory) GetEmailSenderWithConfig(emailConfig dto.EmailConfig) (*EmailSender, error) {
-	return NewEmailSender(emailConfig)
+	emailSender, err := NewEmailSender(emailConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.RLock()
+	emailSender.SetUnsubscribeLinks(f.links)
+	f.mu.RUnlock()
+
+	return emailSender, nil
 }
@@ -0,0 +1,68 @@
+package sender
+
+import (
+	"testing"
+
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFactory_GetTenantSender(t *testing.T) {
+	shared := NewTelegramSender("shared-token", 1)
+	billing := NewTelegramSender("billing-token", 2)
+
+	factory := NewFactory(shared, nil)
+	factory.SetTenantProfile("billing", billing, nil)
+
+	got, err := factory.GetTenantSender("billing", domain.ChannelTelegram)
+	require.NoError(t, err)
+	assert.Same(t, billing, got)
+
+	got, err = factory.GetTenantSender("crm", domain.ChannelTelegram)
+	require.NoError(t, err)
+	assert.Same(t, shared, got, "tenant without profile uses shared sender")
+
+	_, err = factory.GetTenantSender("billing", domain.ChannelEmail)
+	assert.Error(t, err, "profile falls back to shared email sender, which is not configured")
+}
+
+func TestFactory_Replace(t *testing.T) {
+	factory := NewFactory(NewTelegramSender("old-token", 1), nil)
+	factory.SetTenantProfile("billing", NewTelegramSender("old-billing-token", 2), nil)
+
+	shared := NewTelegramSender("new-token", 1)
+	replacement := NewFactory(shared, nil)
+	factory.Replace(replacement)
+
+	got, err := factory.GetSender(domain.ChannelTelegram)
+	require.NoError(t, err)
+	assert.Same(t, shared, got)
+
+	got, err = factory.GetTenantSender("billing", domain.ChannelTelegram)
+	require.NoError(t, err)
+	assert.Same(t, shared, got, "profiles missing from the new config are dropped")
+}
+
+func TestFactory_GetEmailSenderWithConfig_Unsubscribe(t *testing.T) {
+	factory := NewFactory(nil, nil)
+	factory.SetUnsubscribeLinks(staticLinker("https://notifier.example.com/unsubscribe"))
+
+	emailSender, err := factory.GetEmailSenderWithConfig(dto.EmailConfig{
+		SMTPHost:  "smtp.gmail.com",
+		SMTPPort:  587,
+		Username:  "test@gmail.com",
+		Password:  "password",
+		FromEmail: "test@gmail.com",
+	})
+	require.NoError(t, err)
+
+	e := emailSender.createEmail(domain.Notification{
+		TenantID:    "billing",
+		RecipientID: "user@example.com",
+		Channel:     domain.ChannelEmail,
+	}, "Subject", "test@gmail.com")
+	assert.Equal(t, "<https://notifier.example.com/unsubscribe?tenant=billing&channel=email>", e.Headers.Get("List-Unsubscribe"))
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/repository"
+)
+
+// DashboardService предоставляет выборки уведомлений для панели управления
+type DashboardService struct {
+	queries repository.NotificationQueryRepository
+}
+
+// NewDashboardService создает новый сервис панели управления
+func NewDashboardService(queries repository.NotificationQueryRepository) *DashboardService {
+	return &DashboardService{queries: queries}
+}
+
+// ListNotifications возвращает страницу уведомлений тенанта, подходящих под фильтр
+func (d *DashboardService) ListNotifications(ctx context.Context, filter domain.NotificationFilter) (domain.NotificationPage, error) {
+	return d.queries.List(ctx, filter)
+}
+
+// GetHistory возвращает историю статусов уведомления
+func (d *DashboardService) GetHistory(ctx context.Context, id string) ([]domain.StatusChange, error) {
+	return d.queries.LoadHistory(ctx, id)
+}
+
+// CountByStatus возвращает количество уведомлений тенанта в разрезе статусов
+func (d *DashboardService) CountByStatus(ctx context.Context) (map[domain.Status]int64, error) {
+	return d.queries.CountStatuses(ctx)
+}
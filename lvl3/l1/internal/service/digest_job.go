@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/digest"
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/metrics"
+	"delayed-notifier/internal/repository"
+	"delayed-notifier/internal/sender"
+	"delayed-notifier/internal/tracing"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SenderProvider возвращает отправитель канала с учетом профиля тенанта
+type SenderProvider interface {
+	GetTenantSender(tenantID string, channel domain.Channel) (sender.ChannelSender, error)
+}
+
+// DigestRun описывает результат одного прогона отправки дайджестов
+type DigestRun struct {
+	Sent          int
+	Failed        int
+	Notifications int
+}
+
+// DigestJob периодически собирает буферизованные уведомления по тенанту, получателю и каналу
+// и отправляет их одним сообщением
+type DigestJob struct {
+	repo     repository.DigestRepository
+	notifier *NotifierService
+	senders  SenderProvider
+	renderer *digest.Renderer
+	config   config.DigestConfig
+	now      func() time.Time
+
+	mu      sync.Mutex
+	running bool
+
+	wg sync.WaitGroup
+}
+
+// NewDigestJob создает новую задачу отправки дайджестов
+func NewDigestJob(
+	repo repository.DigestRepository,
+	notifier *NotifierService,
+	senders SenderProvider,
+	renderer *digest.Renderer,
+	digestConfig config.DigestConfig,
+) *DigestJob {
+	return &DigestJob{
+		repo:     repo,
+		notifier: notifier,
+		senders:  senders,
+		renderer: renderer,
+		config:   digestConfig,
+		now:      time.Now,
+	}
+}
+
+// Start запускает фоновую отправку дайджестов до отмены контекста
+func (j *DigestJob) Start(ctx context.Context) {
+	if !j.config.Enabled {
+		log.Info().Msg("Digest job disabled")
+		return
+	}
+
+	j.wg.Add(1)
+	go j.loop(ctx)
+
+	log.Info().
+		Dur("interval", j.config.Interval).
+		Int("max_items", j.config.MaxItems).
+		Msg("Started digest job")
+}
+
+// Wait ждет завершения фоновой отправки дайджестов
+func (j *DigestJob) Wait() {
+	j.wg.Wait()
+}
+
+func (j *DigestJob) loop(ctx context.Context) {
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(j.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Digest job stopped")
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := j.RunOnce(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Error().Err(err).Msg("Digest run failed")
+		}
+	}
+}
+
+// RunOnce отправляет дайджесты всем получателям с наступившими уведомлениями в буфере.
+// Ошибка отправки одного дайджеста не прерывает прогон: его уведомления возвращаются в буфер
+// до следующей попытки (см. retry)
+func (j *DigestJob) RunOnce(ctx context.Context) (DigestRun, error) {
+	j.mu.Lock()
+	if j.running {
+		j.mu.Unlock()
+		return DigestRun{}, fmt.Errorf("digest run already in progress")
+	}
+	j.running = true
+	j.mu.Unlock()
+
+	defer func() {
+		j.mu.Lock()
+		j.running = false
+		j.mu.Unlock()
+	}()
+
+	var run DigestRun
+
+	groups, err := j.repo.LoadDigestGroups(ctx, j.now())
+	if err != nil {
+		return run, err
+	}
+
+	for _, group := range groups {
+		if err := ctx.Err(); err != nil {
+			return run, err
+		}
+
+		for {
+			count, err := j.flush(ctx, group)
+			if err != nil {
+				run.Failed++
+				log.Error().
+					Err(err).
+					Str("tenant_id", group.TenantID).
+					Str("recipient_id", group.RecipientID).
+					Str("channel", string(group.Channel)).
+					Msg("Failed to send digest")
+				break
+			}
+			if count > 0 {
+				run.Sent++
+				run.Notifications += count
+			}
+			if count < j.config.MaxItems {
+				break
+			}
+		}
+	}
+
+	log.Info().
+		Int("sent", run.Sent).
+		Int("failed", run.Failed).
+		Int("notifications", run.Notifications).
+		Msg("Digest run finished")
+
+	return run, nil
+}
+
+// flush отправляет один дайджест получателю и возвращает количество забранных из буфера уведомлений
+func (j *DigestJob) flush(ctx context.Context, group domain.DigestGroup) (_ int, err error) {
+	now := j.now()
+	d := domain.Digest{
+		ID:          uuid.New().String(),
+		TenantID:    group.TenantID,
+		RecipientID: group.RecipientID,
+		Channel:     group.Channel,
+		Status:      domain.DigestStatusPending,
+		CreatedAt:   now,
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, "DigestJob.flush",
+		trace.WithAttributes(
+			attribute.String("digest.id", d.ID),
+			attribute.String("notification.channel", string(d.Channel)),
+			attribute.String("tenant.id", d.TenantID),
+		),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	claimed, err := j.repo.ClaimDigest(ctx, d, now, j.config.MaxItems)
+	if err != nil {
+		return 0, err
+	}
+	if len(claimed) == 0 {
+		return 0, nil
+	}
+	span.SetAttributes(attribute.Int("digest.size", len(claimed)))
+
+	due := make([]domain.Notification, 0, len(claimed))
+	for _, notification := range claimed {
+		if !notification.IsExpired(now) {
+			due = append(due, notification)
+			continue
+		}
+		if err := j.notifier.markAsExpired(ctx, notification); err != nil {
+			log.Error().Ctx(ctx).Err(err).Str("id", notification.ID).Msg("Failed to expire digest notification")
+		}
+	}
+	if len(due) == 0 {
+		return len(claimed), j.repo.ReleaseDigest(ctx, d.ID, "all notifications expired")
+	}
+
+	suppressed, err := j.notifier.isSuppressed(ctx, d.TenantID, d.RecipientID, d.Channel)
+	if err != nil {
+		if releaseErr := j.repo.ReleaseDigest(ctx, d.ID, err.Error()); releaseErr != nil {
+			return 0, errors.Join(err, releaseErr)
+		}
+		return 0, err
+	}
+	if suppressed {
+		for _, notification := range due {
+			if err := j.notifier.markAsSuppressed(ctx, notification); err != nil {
+				log.Error().Ctx(ctx).Err(err).Str("id", notification.ID).Msg("Failed to suppress digest notification")
+			}
+		}
+		return len(claimed), j.repo.ReleaseDigest(ctx, d.ID, "recipient suppressed")
+	}
+
+	if err := j.send(ctx, d, due); err != nil {
+		metrics.RecordDigest(d.TenantID, d.Channel, domain.DigestStatusFailed, len(due))
+		return 0, j.retry(ctx, d, due, err)
+	}
+
+	if err := j.repo.CompleteDigest(ctx, d.ID, j.now()); err != nil {
+		return 0, err
+	}
+	metrics.RecordDigest(d.TenantID, d.Channel, domain.DigestStatusSent, len(due))
+
+	for _, notification := range due {
+		if err := j.notifier.markAsSent(ctx, notification); err != nil {
+			log.Error().Ctx(ctx).Err(err).Str("id", notification.ID).Msg("Failed to mark digest notification as sent")
+		}
+	}
+
+	log.Info().
+		Ctx(ctx).
+		Str("digest_id", d.ID).
+		Str("tenant_id", d.TenantID).
+		Str("recipient_id", d.RecipientID).
+		Str("channel", string(d.Channel)).
+		Int("count", len(due)).
+		Msg("Digest sent")
+
+	return len(claimed), nil
+}
+
+// retry обрабатывает неудачную отправку дайджеста так же, как отправку отдельного уведомления:
+// уведомления, исчерпавшие попытки, получают статус failed, а остальные возвращаются в буфер
+// с увеличенным счетчиком попыток и экспоненциальной задержкой до следующей
+func (j *DigestJob) retry(ctx context.Context, d domain.Digest, due []domain.Notification, sendErr error) error {
+	attempt := 0
+	for _, notification := range due {
+		nextRetries := notification.Retries + 1
+		if int64(nextRetries) >= j.notifier.maxRetries.Load() {
+			_ = j.notifier.markAsFailed(ctx, notification, nextRetries, sendErr)
+			continue
+		}
+		attempt = max(attempt, nextRetries)
+	}
+
+	var err error
+	if attempt == 0 {
+		err = j.repo.ReleaseDigest(ctx, d.ID, sendErr.Error())
+	} else {
+		retryAt := j.now().Add(j.notifier.calculateBackoffDelay(attempt))
+		err = j.repo.RetryDigest(ctx, d.ID, sendErr.Error(), retryAt)
+	}
+	if err != nil {
+		return errors.Join(sendErr, err)
+	}
+	return sendErr
+}
+
+// send собирает текст дайджеста и отправляет его одним сообщением
+func (j *DigestJob) send(ctx context.Context, d domain.Digest, notifications []domain.Notification) error {
+	payload, err := j.renderer.Render(d, notifications)
+	if err != nil {
+		return err
+	}
+
+	channelSender, err := j.senders.GetTenantSender(d.TenantID, d.Channel)
+	if err != nil {
+		return err
+	}
+
+	sendCtx, span := tracing.Tracer().Start(ctx, "ChannelSender.Send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("digest.id", d.ID),
+			attribute.String("notification.channel", string(d.Channel)),
+		),
+	)
+	sendErr := channelSender.Send(sendCtx, domain.Notification{
+		ID:               d.ID,
+		TenantID:         d.TenantID,
+		Payload:          payload,
+		CreatedDate:      d.CreatedAt,
+		Status:           domain.StatusPending,
+		NotificationDate: d.CreatedAt,
+		RecipientID:      d.RecipientID,
+		Channel:          d.Channel,
+	})
+	tracing.End(span, sendErr)
+
+	return sendErr
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/digest"
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/sender"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigestJob_RunOnce(t *testing.T) {
+	repo := &MockRepository{}
+	digests := &memoryDigestRepository{repo: repo, digests: make(map[string]domain.Digest)}
+	channelSender := &recordingSender{}
+	notifier := NewNotifierService(repo, &MockCache{}, &MockPublisher{}, sender.NewFactory(nil, nil), nil, time.Hour)
+
+	now := time.Now()
+	expiredAt := now.Add(-time.Minute)
+	store := func(id, recipient string, channel domain.Channel, date time.Time, expiresAt *time.Time) {
+		require.NoError(t, repo.Store(context.Background(), domain.Notification{
+			ID:               id,
+			Payload:          "payload " + id,
+			Status:           domain.StatusPending,
+			NotificationDate: date,
+			RecipientID:      recipient,
+			Channel:          channel,
+			ExpiresAt:        expiresAt,
+			Digest:           true,
+		}))
+	}
+	store("first", "user123", domain.ChannelTelegram, now.Add(-2*time.Hour), nil)
+	store("second", "user123", domain.ChannelTelegram, now.Add(-time.Hour), nil)
+	store("stale", "user123", domain.ChannelTelegram, now.Add(-time.Hour), &expiredAt)
+	store("future", "user123", domain.ChannelTelegram, now.Add(time.Hour), nil)
+	store("other", "user456", domain.ChannelTelegram, now.Add(-time.Hour), nil)
+
+	renderer, err := digest.NewRendererFromString(`{{.RecipientID}}:{{range .Notifications}} {{.ID}}{{end}}`)
+	require.NoError(t, err)
+
+	job := NewDigestJob(digests, notifier, staticSenders{channelSender}, renderer, config.DigestConfig{Enabled: true, Interval: time.Hour, MaxItems: 10})
+	job.now = func() time.Time { return now }
+
+	run, err := job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, run.Sent)
+	assert.Equal(t, 4, run.Notifications)
+
+	sort.Strings(channelSender.payloads)
+	assert.Equal(t, []string{"user123: first second", "user456: other"}, channelSender.payloads)
+
+	for id, status := range map[string]domain.Status{
+		"first":  domain.StatusSent,
+		"second": domain.StatusSent,
+		"stale":  domain.StatusExpired,
+		"future": domain.StatusPending,
+		"other":  domain.StatusSent,
+	} {
+		notification, err := repo.LoadByID(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, status, notification.Status, id)
+	}
+
+	first, _ := repo.LoadByID(context.Background(), "first")
+	second, _ := repo.LoadByID(context.Background(), "second")
+	require.NotNil(t, first.DigestID)
+	assert.Equal(t, first.DigestID, second.DigestID)
+	assert.Equal(t, domain.DigestStatusSent, digests.digests[*first.DigestID].Status)
+	assert.Equal(t, 3, digests.digests[*first.DigestID].NotificationCount)
+}
+
+func TestDigestJob_SendFailureReleasesNotifications(t *testing.T) {
+	repo := &MockRepository{}
+	digests := &memoryDigestRepository{repo: repo, digests: make(map[string]domain.Digest)}
+	channelSender := &recordingSender{err: errors.New("telegram unavailable")}
+	notifier := NewNotifierService(repo, &MockCache{}, &MockPublisher{}, sender.NewFactory(nil, nil), nil, time.Hour)
+
+	require.NoError(t, repo.Store(context.Background(), domain.Notification{
+		ID:               "first",
+		Status:           domain.StatusPending,
+		NotificationDate: time.Now().Add(-time.Hour),
+		RecipientID:      "user123",
+		Channel:          domain.ChannelTelegram,
+		Digest:           true,
+	}))
+
+	renderer, err := digest.NewRenderer("")
+	require.NoError(t, err)
+
+	job := NewDigestJob(digests, notifier, staticSenders{channelSender}, renderer, config.DigestConfig{Enabled: true, Interval: time.Hour, MaxItems: 10})
+
+	run, err := job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, run.Sent)
+	assert.Equal(t, 1, run.Failed)
+
+	notification, err := repo.LoadByID(context.Background(), "first")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusPending, notification.Status)
+	assert.Nil(t, notification.DigestID)
+	assert.Equal(t, 1, notification.Retries)
+
+	require.Len(t, digests.digests, 1)
+	for _, d := range digests.digests {
+		assert.Equal(t, domain.DigestStatusFailed, d.Status)
+		assert.Equal(t, "telegram unavailable", d.Error)
+	}
+}
+
+func TestDigestJob_SendFailureRetriesWithBackoff(t *testing.T) {
+	repo := &MockRepository{}
+	digests := &memoryDigestRepository{repo: repo, digests: make(map[string]domain.Digest)}
+	channelSender := &recordingSender{err: errors.New("telegram unavailable")}
+	notifier := NewNotifierService(repo, &MockCache{}, &MockPublisher{}, sender.NewFactory(nil, nil), nil, time.Hour)
+
+	now := time.Now()
+	for _, id := range []string{"first", "second"} {
+		require.NoError(t, repo.Store(context.Background(), domain.Notification{
+			ID:               id,
+			Status:           domain.StatusPending,
+			NotificationDate: now.Add(-time.Hour),
+			RecipientID:      "user123",
+			Channel:          domain.ChannelTelegram,
+			Digest:           true,
+		}))
+	}
+
+	renderer, err := digest.NewRenderer("")
+	require.NoError(t, err)
+
+	job := NewDigestJob(digests, notifier, staticSenders{channelSender}, renderer, config.DigestConfig{Enabled: true, Interval: time.Hour, MaxItems: 10})
+	job.now = func() time.Time { return now }
+
+	run, err := job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, run.Failed)
+
+	// До истечения задержки уведомления не попадают в дайджест
+	run, err = job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, run.Failed)
+	assert.Len(t, digests.digests, 1)
+
+	// Вторая попытка после задержки notifier.calculateBackoffDelay(1)
+	now = now.Add(notifier.calculateBackoffDelay(1))
+	run, err = job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, run.Failed)
+
+	first, err := repo.LoadByID(context.Background(), "first")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusPending, first.Status)
+	assert.Equal(t, 2, first.Retries)
+
+	// Третья попытка исчерпывает defaultMaxRetries: уведомления получают статус failed и больше не отправляются
+	now = now.Add(notifier.calculateBackoffDelay(2))
+	run, err = job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, run.Failed)
+
+	for _, id := range []string{"first", "second"} {
+		notification, err := repo.LoadByID(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, domain.StatusFailed, notification.Status, id)
+	}
+
+	now = now.Add(time.Hour)
+	run, err = job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, run.Failed)
+	assert.Len(t, digests.digests, 3)
+}
+
+func TestProcessNotification_DigestBuffered(t *testing.T) {
+	repo := &MockRepository{}
+	publisher := &MockPublisher{}
+	service := NewNotifierService(repo, &MockCache{}, publisher, sender.NewFactory(nil, nil), nil, time.Hour)
+	service.EnableDigest()
+
+	notification := domain.Notification{
+		ID:               "digest-notification",
+		Status:           domain.StatusPending,
+		NotificationDate: time.Now().Add(-time.Minute),
+		RecipientID:      "user123",
+		Channel:          domain.ChannelTelegram,
+		Digest:           true,
+	}
+	require.NoError(t, repo.Store(context.Background(), notification))
+
+	require.NoError(t, service.ProcessTelegramNotification(context.Background(), notification))
+
+	stored, err := repo.LoadByID(context.Background(), notification.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusPending, stored.Status)
+	assert.False(t, publisher.PublishDelayedCalled)
+}
+
+// memoryDigestRepository хранит буфер дайджестов поверх MockRepository
+type memoryDigestRepository struct {
+	repo    *MockRepository
+	digests map[string]domain.Digest
+	retryAt map[string]time.Time
+}
+
+func (m *memoryDigestRepository) buffered(n domain.Notification, dueBefore time.Time) bool {
+	return n.Digest && n.Status == domain.StatusPending && n.DigestID == nil && !n.NotificationDate.After(dueBefore) &&
+		!m.retryAt[n.ID].After(dueBefore)
+}
+
+func (m *memoryDigestRepository) LoadDigestGroups(ctx context.Context, dueBefore time.Time) ([]domain.DigestGroup, error) {
+	seen := make(map[domain.DigestGroup]bool)
+	var groups []domain.DigestGroup
+	for _, n := range m.repo.notifications {
+		group := domain.DigestGroup{TenantID: n.TenantID, RecipientID: n.RecipientID, Channel: n.Channel}
+		if m.buffered(n, dueBefore) && !seen[group] {
+			seen[group] = true
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+func (m *memoryDigestRepository) ClaimDigest(ctx context.Context, d domain.Digest, dueBefore time.Time, limit int) ([]domain.Notification, error) {
+	var claimed []domain.Notification
+	for id, n := range m.repo.notifications {
+		if len(claimed) == limit {
+			break
+		}
+		if !m.buffered(n, dueBefore) || n.TenantID != d.TenantID || n.RecipientID != d.RecipientID || n.Channel != d.Channel {
+			continue
+		}
+		digestID := d.ID
+		n.DigestID = &digestID
+		m.repo.notifications[id] = n
+		claimed = append(claimed, n)
+	}
+	sort.Slice(claimed, func(i, j int) bool { return claimed[i].NotificationDate.Before(claimed[j].NotificationDate) })
+
+	if len(claimed) > 0 {
+		d.NotificationCount = len(claimed)
+		m.digests[d.ID] = d
+	}
+	return claimed, nil
+}
+
+func (m *memoryDigestRepository) CompleteDigest(ctx context.Context, id string, sentAt time.Time) error {
+	d := m.digests[id]
+	d.Status = domain.DigestStatusSent
+	d.SentAt = &sentAt
+	m.digests[id] = d
+	return nil
+}
+
+func (m *memoryDigestRepository) RetryDigest(ctx context.Context, id string, reason string, retryAt time.Time) error {
+	if m.retryAt == nil {
+		m.retryAt = make(map[string]time.Time)
+	}
+	for notificationID, n := range m.repo.notifications {
+		if n.DigestID != nil && *n.DigestID == id && n.Status == domain.StatusPending {
+			n.Retries++
+			m.repo.notifications[notificationID] = n
+			m.retryAt[notificationID] = retryAt
+		}
+	}
+	return m.ReleaseDigest(ctx, id, reason)
+}
+
+func (m *memoryDigestRepository) ReleaseDigest(ctx context.Context, id string, reason string) error {
+	d := m.digests[id]
+	d.Status = domain.DigestStatusFailed
+	d.Error = reason
+	m.digests[id] = d
+
+	for notificationID, n := range m.repo.notifications {
+		if n.DigestID != nil && *n.DigestID == id && n.Status == domain.StatusPending {
+			n.DigestID = nil
+			m.repo.notifications[notificationID] = n
+		}
+	}
+	return nil
+}
+
+type recordingSender struct {
+	payloads []string
+	err      error
+}
+
+func (s *recordingSender) Send(ctx context.Context, notification domain.Notification) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.payloads = append(s.payloads, notification.Payload)
+	return nil
+}
+
+type staticSenders struct {
+	sender sender.ChannelSender
+}
+
+func (s staticSenders) GetTenantSender(tenantID string, channel domain.Channel) (sender.ChannelSender, error) {
+	return s.sender, nil
+}
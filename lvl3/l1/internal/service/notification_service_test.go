@@ -4,12 +4,22 @@ import (
 	"context"
 	"delayed-notifier/internal/domain"
 	"delayed-notifier/internal/dto"
+	"delayed-notifier/internal/events"
 	"delayed-notifier/internal/sender"
+	"delayed-notifier/internal/tracing"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/rabbitmq/amqp091-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestCreateNotification(t *testing.T) {
@@ -18,7 +28,7 @@ func TestCreateNotification(t *testing.T) {
 	publisher := &MockPublisher{}
 	senderFactory := sender.NewFactory(nil, nil)
 
-	service := NewNotifierService(repo, cache, publisher, senderFactory, time.Hour)
+	service := NewNotifierService(repo, cache, publisher, senderFactory, nil, time.Hour)
 
 	req := dto.CreateNotificationRequest{
 		Payload:          "Test message",
@@ -60,7 +70,7 @@ func TestCreateNotification_WithEmailConfig(t *testing.T) {
 	publisher := &MockPublisher{}
 	senderFactory := sender.NewFactory(nil, nil)
 
-	service := NewNotifierService(repo, cache, publisher, senderFactory, time.Hour)
+	service := NewNotifierService(repo, cache, publisher, senderFactory, nil, time.Hour)
 
 	emailConfig := &dto.EmailConfig{
 		Subject:   "Test Subject",
@@ -90,13 +100,49 @@ func TestCreateNotification_WithEmailConfig(t *testing.T) {
 	assert.Contains(t, string(publisher.LastBody), "email_config")
 }
 
+func TestCreateNotification_InvalidExpiry(t *testing.T) {
+	date := time.Now().Add(time.Hour)
+	before := date.Add(-time.Minute)
+
+	for name, tc := range map[string]struct {
+		req   dto.CreateNotificationRequest
+		field string
+	}{
+		"malformed max_lateness": {dto.CreateNotificationRequest{MaxLateness: "soon"}, "max_lateness"},
+		"negative max_lateness":  {dto.CreateNotificationRequest{MaxLateness: "-5m"}, "max_lateness"},
+		"both expiry fields":     {dto.CreateNotificationRequest{MaxLateness: "5m", ExpiresAt: &date}, "max_lateness"},
+		"expires before date":    {dto.CreateNotificationRequest{ExpiresAt: &before}, "expires_at"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := &MockRepository{}
+			publisher := &MockPublisher{}
+			service := NewNotifierService(repo, &MockCache{}, publisher, sender.NewFactory(nil, nil), nil, time.Hour)
+
+			req := tc.req
+			req.Payload = "Test message"
+			req.NotificationDate = date
+			req.RecipientID = "user123"
+			req.Channel = domain.ChannelTelegram
+
+			_, err := service.CreateNotification(context.Background(), req)
+
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			require.Len(t, validationErr.Violations, 1)
+			assert.Equal(t, tc.field, validationErr.Violations[0].Field)
+			assert.Empty(t, repo.notifications)
+			assert.False(t, publisher.PublishCalled)
+		})
+	}
+}
+
 func TestCancelNotification(t *testing.T) {
 	repo := &MockRepository{}
 	cache := &MockCacheWithStorage{}
 	publisher := &MockPublisher{}
 	senderFactory := sender.NewFactory(nil, nil)
 
-	service := NewNotifierService(repo, cache, publisher, senderFactory, time.Hour)
+	service := NewNotifierService(repo, cache, publisher, senderFactory, nil, time.Hour)
 
 	notification := domain.Notification{
 		ID:               "test-id",
@@ -122,11 +168,133 @@ func TestCancelNotification(t *testing.T) {
 	assert.Equal(t, string(domain.StatusCancelled), cachedStatus)
 }
 
+func TestStatusEventsPublished(t *testing.T) {
+	repo := &MockRepository{}
+	cache := &MockCache{}
+	publisher := &MockPublisher{}
+	senderFactory := sender.NewFactory(nil, nil)
+	broker := events.NewBroker()
+
+	sub := broker.Subscribe("")
+	defer sub.Close()
+
+	service := NewNotifierService(repo, cache, publisher, senderFactory, broker, time.Hour)
+
+	req := dto.CreateNotificationRequest{
+		Payload:          "Test message",
+		NotificationDate: time.Now().Add(time.Hour),
+		RecipientID:      "user123",
+		Channel:          domain.ChannelTelegram,
+	}
+
+	notification, err := service.CreateNotification(context.Background(), req)
+	require.NoError(t, err)
+
+	created := <-sub.C
+	assert.Equal(t, notification.ID, created.ID)
+	assert.Equal(t, domain.StatusPending, created.Status)
+	assert.Equal(t, domain.ChannelTelegram, created.Channel)
+
+	err = service.CancelNotification(context.Background(), notification.ID)
+	require.NoError(t, err)
+
+	cancelled := <-sub.C
+	assert.Equal(t, notification.ID, cancelled.ID)
+	assert.Equal(t, domain.StatusCancelled, cancelled.Status)
+}
+
+func TestTracePropagatedThroughQueue(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	repo := &MockRepository{}
+	publisher := &MockPublisher{}
+	service := NewNotifierService(repo, &MockCache{}, publisher, sender.NewFactory(nil, nil), nil, time.Hour)
+
+	notification, err := service.CreateNotification(context.Background(), dto.CreateNotificationRequest{
+		Payload:          "Test message",
+		NotificationDate: time.Now().Add(time.Hour),
+		RecipientID:      "user123",
+		Channel:          domain.ChannelTelegram,
+	})
+	require.NoError(t, err)
+
+	publishSpan := trace.SpanContextFromContext(publisher.LastCtx)
+	require.True(t, publishSpan.IsValid())
+
+	headers := amqp091.Table{}
+	tracing.InjectAMQP(publisher.LastCtx, headers)
+	consumerCtx := tracing.ExtractAMQP(context.Background(), headers)
+
+	err = service.ProcessTelegramNotification(consumerCtx, *notification)
+	require.NoError(t, err)
+	assert.True(t, publisher.PublishDelayedCalled)
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	created := spans["NotifierService.CreateNotification"]
+	processed := spans["NotifierService.processNotification"]
+	require.NotNil(t, created)
+	require.NotNil(t, processed)
+
+	assert.Equal(t, created.SpanContext().TraceID(), processed.SpanContext().TraceID())
+	assert.Equal(t, publishSpan.SpanID(), processed.Parent().SpanID())
+}
+
+func TestProcessNotification_Expired(t *testing.T) {
+	repo := &MockRepository{}
+	publisher := &MockPublisher{}
+	broker := events.NewBroker()
+
+	sub := broker.Subscribe("")
+	defer sub.Close()
+
+	service := NewNotifierService(repo, &MockCache{}, publisher, sender.NewFactory(nil, nil), broker, time.Hour)
+
+	expiresAt := time.Now().Add(-time.Minute)
+	notification := domain.Notification{
+		ID:               "expired-notification",
+		Payload:          "Test message",
+		NotificationDate: time.Now().Add(-time.Hour),
+		ExpiresAt:        &expiresAt,
+		RecipientID:      "user123",
+		Channel:          domain.ChannelTelegram,
+		Status:           domain.StatusPending,
+	}
+	require.NoError(t, repo.Store(context.Background(), notification))
+
+	err := service.ProcessTelegramNotification(context.Background(), notification)
+	require.NoError(t, err)
+
+	stored, err := repo.LoadByID(context.Background(), notification.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusExpired, stored.Status)
+	assert.False(t, publisher.PublishDelayedCalled)
+
+	event := <-sub.C
+	assert.Equal(t, notification.ID, event.ID)
+	assert.Equal(t, domain.StatusExpired, event.Status)
+}
+
 type MockRepository struct {
+	mu            sync.Mutex
 	notifications map[string]domain.Notification
 }
 
 func (m *MockRepository) Store(ctx context.Context, notification domain.Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.notifications == nil {
 		m.notifications = make(map[string]domain.Notification)
 	}
@@ -182,11 +350,55 @@ func (m *MockRepository) CancelByID(ctx context.Context, id string) error {
 	return nil
 }
 
+func (m *MockRepository) RescheduleByID(ctx context.Context, id string, notificationDate time.Time, from []domain.Status) (*domain.Notification, error) {
+	notification, exists := m.notifications[id]
+	if !exists {
+		return nil, domain.ErrNotFound
+	}
+	if !slices.Contains(from, notification.Status) {
+		if err := domain.StatusConflictError(notification.Status); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrPending
+	}
+	notification.Status = domain.StatusPending
+	notification.NotificationDate = notificationDate
+	notification.Retries = 0
+	notification.DigestID = nil
+	if notification.ExpiresAt != nil && !notification.ExpiresAt.After(notificationDate) {
+		notification.ExpiresAt = nil
+	}
+	m.notifications[id] = notification
+	return &notification, nil
+}
+
+func (m *MockRepository) StoreWithinLimit(ctx context.Context, notification domain.Notification, since time.Time, limit int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, stored := range m.notifications {
+		if stored.TenantID == notification.TenantID && !stored.CreatedDate.Before(since) {
+			count++
+		}
+	}
+	if count >= limit {
+		return domain.ErrQuotaExceeded
+	}
+	if m.notifications == nil {
+		m.notifications = make(map[string]domain.Notification)
+	}
+	m.notifications[notification.ID] = notification
+	return nil
+}
+
 type MockCache struct {
+	mu      sync.Mutex
 	storage map[string]string
 }
 
 func (m *MockCache) Get(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.storage == nil {
 		return "", assert.AnError
 	}
@@ -198,6 +410,8 @@ func (m *MockCache) Get(ctx context.Context, key string) (string, error) {
 }
 
 func (m *MockCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.storage == nil {
 		m.storage = make(map[string]string)
 	}
@@ -229,6 +443,8 @@ func (m *MockCacheWithStorage) Set(ctx context.Context, key, value string, ttl t
 }
 
 type MockPublisher struct {
+	mu                   sync.Mutex
+	LastCtx              context.Context
 	PublishCalled        bool
 	PublishDelayedCalled bool
 	LastBody             []byte
@@ -238,6 +454,9 @@ type MockPublisher struct {
 }
 
 func (m *MockPublisher) Publish(ctx context.Context, body []byte, routingKey, contentType string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.LastCtx = ctx
 	m.PublishCalled = true
 	m.LastBody = body
 	m.LastRoutingKey = routingKey
@@ -246,6 +465,7 @@ func (m *MockPublisher) Publish(ctx context.Context, body []byte, routingKey, co
 }
 
 func (m *MockPublisher) PublishDelayed(ctx context.Context, body []byte, routingKey, contentType string, delay time.Duration) error {
+	m.LastCtx = ctx
 	m.PublishDelayedCalled = true
 	m.LastBody = body
 	m.LastRoutingKey = routingKey
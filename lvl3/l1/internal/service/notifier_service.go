@@ -3,17 +3,27 @@ package service
 import (
 	"context"
 	"delayed-notifier/internal/cache"
+	"delayed-notifier/internal/config"
 	"delayed-notifier/internal/domain"
 	"delayed-notifier/internal/dto"
+	"delayed-notifier/internal/events"
+	"delayed-notifier/internal/metrics"
 	"delayed-notifier/internal/queue"
 	"delayed-notifier/internal/repository"
 	"delayed-notifier/internal/sender"
+	"delayed-notifier/internal/tenant"
+	"delayed-notifier/internal/tracing"
+	"delayed-notifier/internal/validation"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -21,9 +31,20 @@ const (
 	msgFailedToCacheStatus          = "Failed to cache status"
 	msgFailedToCacheCancelledStatus = "Failed to cache cancelled status"
 	msgFailedToCancelNotification   = "Failed to cancel notification"
+	msgFailedToPublishStatusEvent   = "Failed to publish status event"
+	msgFailedToReschedule           = "Failed to reschedule notification"
+	msgFailedToResend               = "Failed to resend notification"
 
-	maxRetries       = 3
-	baseBackoffDelay = time.Second
+	defaultMaxRetries = 3
+	baseBackoffDelay  = time.Second
+
+	// tenantThrottleDelay задержка повторной постановки уведомления в очередь,
+	// когда все слоты одновременной отправки тенанта заняты
+	tenantThrottleDelay = time.Second
+
+	// staleMessageTolerance точность сравнения даты отправки из сообщения очереди с хранилищем:
+	// PostgreSQL хранит время с точностью до микросекунд
+	staleMessageTolerance = time.Millisecond
 
 	queueRoutingKey  = "notifications"
 	queueContentType = "application/json"
@@ -35,6 +56,8 @@ type NotificationService interface {
 	GetNotification(ctx context.Context, id string) (*domain.Notification, error)
 	GetStatus(ctx context.Context, id string) (domain.Status, error)
 	CancelNotification(ctx context.Context, id string) error
+	RescheduleNotification(ctx context.Context, id string, notificationDate time.Time) (*domain.Notification, error)
+	ResendNotification(ctx context.Context, id string) (*domain.Notification, error)
 	ProcessTelegramNotification(ctx context.Context, notification domain.Notification) error
 }
 
@@ -44,7 +67,12 @@ type NotifierService struct {
 	cache           cache.StatusCache
 	publisher       queue.Publisher
 	senderFactory   *sender.Factory
+	statusEvents    events.Publisher
 	notificationTTL time.Duration
+	digestEnabled   bool
+	suppressions    SuppressionChecker
+	quotas          atomic.Pointer[tenant.Quotas]
+	maxRetries      atomic.Int64
 }
 
 // NewNotifierService создает новый экземпляр NotifierService
@@ -53,24 +81,65 @@ func NewNotifierService(
 	cache cache.StatusCache,
 	publisher queue.Publisher,
 	senderFactory *sender.Factory,
+	statusEvents events.Publisher,
 	notificationTTL time.Duration,
 ) *NotifierService {
-	return &NotifierService{
+	s := &NotifierService{
 		repo:            repo,
 		cache:           cache,
 		publisher:       publisher,
 		senderFactory:   senderFactory,
+		statusEvents:    statusEvents,
 		notificationTTL: notificationTTL,
 	}
+	s.maxRetries.Store(defaultMaxRetries)
+	return s
+}
+
+// EnableDigest включает буферизацию уведомлений с флагом digest: воркер не отправляет их,
+// а оставляет в статусе pending до отправки дайджеста через DigestJob
+func (s *NotifierService) EnableDigest() {
+	s.digestEnabled = true
+}
+
+// SetSuppressions включает проверку списка подавления перед отправкой:
+// уведомления подавленным получателям получают статус suppressed и не отправляются
+func (s *NotifierService) SetSuppressions(suppressions SuppressionChecker) {
+	s.suppressions = suppressions
+}
+
+// SetQuotas задает квоты тенантов. Без квот тенанты не ограничены.
+// Квоты можно заменить на лету: занятые слоты отправки освобождаются в старых квотах
+func (s *NotifierService) SetQuotas(quotas *tenant.Quotas) {
+	s.quotas.Store(quotas)
+}
+
+// SetRetryConfig задает число попыток отправки уведомления, нулевое значение оставляет текущее
+func (s *NotifierService) SetRetryConfig(retryConfig config.RetryConfig) {
+	if retryConfig.MaxRetries > 0 {
+		s.maxRetries.Store(int64(retryConfig.MaxRetries))
+	}
 }
 
 // CreateNotification создает новое уведомление и публикует его в очередь
-func (s *NotifierService) CreateNotification(ctx context.Context, req dto.CreateNotificationRequest) (*domain.Notification, error) {
+func (s *NotifierService) CreateNotification(ctx context.Context, req dto.CreateNotificationRequest) (_ *domain.Notification, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "NotifierService.CreateNotification",
+		trace.WithAttributes(attribute.String("notification.channel", string(req.Channel))),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
-		notification := s.createNotificationFromRequest(req)
+		notification, err := s.createNotificationFromRequest(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		span.SetAttributes(
+			attribute.String("notification.id", notification.ID),
+			attribute.String("tenant.id", notification.TenantID),
+		)
 
 		if err := s.storeNotification(ctx, notification); err != nil {
 			return nil, err
@@ -84,10 +153,42 @@ func (s *NotifierService) CreateNotification(ctx context.Context, req dto.Create
 	}
 }
 
-// createNotificationFromRequest создает уведомление из запроса
-func (s *NotifierService) createNotificationFromRequest(req dto.CreateNotificationRequest) domain.Notification {
+// insertNotification сохраняет новое уведомление с учетом суточного лимита тенанта.
+// Сутки отсчитываются от полуночи UTC; лимит проверяется репозиторием вместе со вставкой,
+// поэтому параллельные запросы тенанта не превышают его
+func (s *NotifierService) insertNotification(ctx context.Context, notification domain.Notification) error {
+	tenantID := notification.TenantID
+	limit := s.quotas.Load().DailyLimit(tenantID)
+	if limit <= 0 {
+		return s.repo.Store(ctx, notification)
+	}
+
+	now := time.Now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	err := s.repo.StoreWithinLimit(ctx, notification, dayStart, limit)
+	if errors.Is(err, domain.ErrQuotaExceeded) {
+		metrics.RecordQuotaExceeded(tenantID, metrics.QuotaDaily)
+		log.Warn().
+			Ctx(ctx).
+			Str("tenant_id", tenantID).
+			Int("daily_limit", limit).
+			Msg("Tenant daily quota exceeded")
+		return fmt.Errorf("tenant %s daily limit of %d notifications: %w", tenantID, limit, domain.ErrQuotaExceeded)
+	}
+	return err
+}
+
+// createNotificationFromRequest создает уведомление из запроса для тенанта из контекста
+func (s *NotifierService) createNotificationFromRequest(ctx context.Context, req dto.CreateNotificationRequest) (domain.Notification, error) {
+	expiresAt, err := resolveExpiry(req)
+	if err != nil {
+		return domain.Notification{}, err
+	}
+
 	return domain.Notification{
 		ID:               uuid.New().String(),
+		TenantID:         tenant.IDFromContext(ctx),
 		Payload:          req.Payload,
 		CreatedDate:      time.Now(),
 		Status:           domain.StatusPending,
@@ -96,24 +197,49 @@ func (s *NotifierService) createNotificationFromRequest(req dto.CreateNotificati
 		RecipientID:      req.RecipientID,
 		Channel:          req.Channel,
 		Retries:          0,
+		ExpiresAt:        expiresAt,
+		Digest:           req.Digest,
+	}, nil
+}
+
+// resolveExpiry вычисляет срок актуальности уведомления. Некорректный срок возвращает ошибку валидации
+// и в обход валидатора, чтобы уведомление не сохранилось без него
+func resolveExpiry(req dto.CreateNotificationRequest) (*time.Time, error) {
+	validationErr := domain.NewValidationError()
+	expiresAt, err := req.ResolveExpiresAt()
+	switch {
+	case req.ExpiresAt != nil && req.MaxLateness != "":
+		validationErr.Add("max_lateness", validation.CodeConflict, validation.ErrExpiryConflict)
+	case err != nil:
+		validationErr.Add("max_lateness", validation.CodeInvalidFormat, validation.ErrInvalidMaxLateness)
+	case expiresAt != nil && !expiresAt.After(req.NotificationDate):
+		validationErr.Add("expires_at", validation.CodeInvalidValue, validation.ErrExpiresBeforeDate)
 	}
+
+	if validationErr.HasViolations() {
+		return nil, validationErr
+	}
+	return expiresAt, nil
 }
 
 // storeNotification сохраняет уведомление в репозитории и кэше
 func (s *NotifierService) storeNotification(ctx context.Context, notification domain.Notification) error {
+	if err := s.insertNotification(ctx, notification); err != nil {
+		return err
+	}
+
 	log.Info().
+		Ctx(ctx).
 		Str("id", notification.ID).
 		Str("channel", string(notification.Channel)).
 		Time("notify_at", notification.NotificationDate).
 		Msg("Notification created")
 
-	if err := s.repo.Store(ctx, notification); err != nil {
-		return err
+	if err := s.cache.Set(ctx, statusCacheKey(notification.TenantID, notification.ID), string(notification.Status), s.notificationTTL); err != nil {
+		log.Error().Ctx(ctx).Err(err).Msg("Failed to cache status in Redis")
 	}
 
-	if err := s.cache.Set(ctx, notification.ID, string(notification.Status), s.notificationTTL); err != nil {
-		log.Error().Err(err).Msg("Failed to cache status in Redis")
-	}
+	s.onStatusChanged(ctx, notification.TenantID, notification.ID, notification.Channel, notification.Status)
 
 	return nil
 }
@@ -127,16 +253,19 @@ func (s *NotifierService) publishNotification(ctx context.Context, notification
 
 	if emailConfig != nil {
 		log.Info().
+			Ctx(ctx).
 			Str("id", notification.ID).
 			Interface("email_config", emailConfig).
 			Msg("Adding email config to queue message")
 	} else {
 		log.Info().
+			Ctx(ctx).
 			Str("id", notification.ID).
 			Msg("No email config provided")
 	}
 
 	log.Info().
+		Ctx(ctx).
 		Str("id", notification.ID).
 		Str("routing_key", queueRoutingKey).
 		Msg("Publishing notification to queue")
@@ -145,7 +274,7 @@ func (s *NotifierService) publishNotification(ctx context.Context, notification
 		return err
 	}
 
-	log.Info().Str("id", notification.ID).Msg("Notification published")
+	log.Info().Ctx(ctx).Str("id", notification.ID).Msg("Notification published")
 	return nil
 }
 
@@ -155,20 +284,22 @@ func (s *NotifierService) GetStatus(ctx context.Context, id string) (domain.Stat
 	case <-ctx.Done():
 		return "", ctx.Err()
 	default:
-		statusFromRedis, err := s.cache.Get(ctx, id)
+		cacheKey := statusCacheKey(tenant.IDFromContext(ctx), id)
+
+		statusFromRedis, err := s.cache.Get(ctx, cacheKey)
 		if err == nil {
 			return domain.Status(statusFromRedis), nil
 		}
 
-		log.Debug().Str("id", id).Msg("Cache miss, falling back to storage")
+		log.Debug().Ctx(ctx).Str("id", id).Msg("Cache miss, falling back to storage")
 
 		statusFromRepo, err := s.repo.LoadStatusByID(ctx, id)
 		if err != nil {
 			return "", err
 		}
 
-		if err := s.cache.Set(ctx, id, string(statusFromRepo), s.notificationTTL); err != nil {
-			log.Warn().Err(err).Str("id", id).Msg(msgFailedToCacheStatus)
+		if err := s.cache.Set(ctx, cacheKey, string(statusFromRepo), s.notificationTTL); err != nil {
+			log.Warn().Ctx(ctx).Err(err).Str("id", id).Msg(msgFailedToCacheStatus)
 		}
 
 		return statusFromRepo, nil
@@ -190,24 +321,116 @@ func (s *NotifierService) GetNotification(ctx context.Context, id string) (*doma
 }
 
 // CancelNotification отменяет уведомление по ID
-func (s *NotifierService) CancelNotification(ctx context.Context, id string) error {
+func (s *NotifierService) CancelNotification(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "NotifierService.CancelNotification",
+		trace.WithAttributes(attribute.String("notification.id", id)),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 		if err := s.repo.CancelByID(ctx, id); err != nil {
-			log.Error().Err(err).Msg(msgFailedToCancelNotification)
+			log.Error().Ctx(ctx).Err(err).Msg(msgFailedToCancelNotification)
 			return err
 		}
 
-		if err := s.cache.Set(ctx, id, string(domain.StatusCancelled), s.notificationTTL); err != nil {
-			log.Warn().Err(err).Str("id", id).Msg(msgFailedToCacheCancelledStatus)
+		tenantID := tenant.IDFromContext(ctx)
+		if err := s.cache.Set(ctx, statusCacheKey(tenantID, id), string(domain.StatusCancelled), s.notificationTTL); err != nil {
+			log.Warn().Ctx(ctx).Err(err).Str("id", id).Msg(msgFailedToCacheCancelledStatus)
 		}
 
+		s.onStatusChanged(ctx, tenantID, id, "", domain.StatusCancelled)
+
 	}
 	return nil
 }
 
+// RescheduleNotification переносит ожидающее уведомление на новую дату отправки.
+// Сообщение с прежней датой остается в очереди и пропускается воркером
+func (s *NotifierService) RescheduleNotification(ctx context.Context, id string, notificationDate time.Time) (_ *domain.Notification, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "NotifierService.RescheduleNotification",
+		trace.WithAttributes(attribute.String("notification.id", id)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	current, err := s.repo.LoadByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBeforeExpiry(current, notificationDate); err != nil {
+		return nil, err
+	}
+
+	notification, err := s.repo.RescheduleByID(ctx, id, notificationDate, []domain.Status{domain.StatusPending})
+	if err != nil {
+		log.Error().Ctx(ctx).Err(err).Str("id", id).Msg(msgFailedToReschedule)
+		return nil, err
+	}
+
+	if err := s.publishNotification(ctx, *notification, nil); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Ctx(ctx).
+		Str("id", id).
+		Time("notify_at", notification.NotificationDate).
+		Msg("Notification rescheduled")
+
+	return notification, nil
+}
+
+// ResendNotification повторно ставит в очередь уведомление в конечном статусе с тем же ID.
+// Истекший срок актуальности сбрасывается. Пользовательская email конфигурация не хранится,
+// поэтому повторная отправка идет через отправитель тенанта по умолчанию
+func (s *NotifierService) ResendNotification(ctx context.Context, id string) (_ *domain.Notification, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "NotifierService.ResendNotification",
+		trace.WithAttributes(attribute.String("notification.id", id)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	notification, err := s.repo.RescheduleByID(ctx, id, time.Now(), resendableStatuses)
+	if err != nil {
+		log.Error().Ctx(ctx).Err(err).Str("id", id).Msg(msgFailedToResend)
+		return nil, err
+	}
+
+	if err := s.cache.Set(ctx, statusCacheKey(notification.TenantID, id), string(notification.Status), s.notificationTTL); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Str("id", id).Msg(msgFailedToCacheStatus)
+	}
+
+	s.onStatusChanged(ctx, notification.TenantID, id, notification.Channel, notification.Status)
+
+	if err := s.publishNotification(ctx, *notification, nil); err != nil {
+		return nil, err
+	}
+
+	log.Info().Ctx(ctx).Str("id", id).Msg("Notification resent")
+
+	return notification, nil
+}
+
+// resendableStatuses перечисляет статусы, из которых уведомление можно отправить повторно
+var resendableStatuses = []domain.Status{
+	domain.StatusSent,
+	domain.StatusFailed,
+	domain.StatusCancelled,
+	domain.StatusExpired,
+	domain.StatusSuppressed,
+}
+
+// checkBeforeExpiry проверяет, что новая дата отправки раньше срока актуальности уведомления
+func checkBeforeExpiry(notification *domain.Notification, notificationDate time.Time) error {
+	if notification.ExpiresAt == nil || notificationDate.Before(*notification.ExpiresAt) {
+		return nil
+	}
+	validationErr := domain.NewValidationError()
+	validationErr.Add("notification_date", validation.CodeInvalidValue, validation.ErrDateAfterExpiry)
+	return validationErr
+}
+
 // ProcessTelegramNotification обрабатывает уведомление для отправки в Telegram
 func (s *NotifierService) ProcessTelegramNotification(ctx context.Context, notification domain.Notification) error {
 	return s.processNotification(ctx, notification, nil)
@@ -219,22 +442,52 @@ func (s *NotifierService) ProcessEmailNotification(ctx context.Context, notifica
 }
 
 // processNotification универсальный метод обработки уведомлений
-func (s *NotifierService) processNotification(ctx context.Context, notification domain.Notification, emailConfig *dto.EmailConfig) error {
+func (s *NotifierService) processNotification(ctx context.Context, notification domain.Notification, emailConfig *dto.EmailConfig) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "NotifierService.processNotification",
+		trace.WithAttributes(
+			attribute.String("notification.id", notification.ID),
+			attribute.String("notification.channel", string(notification.Channel)),
+			attribute.Int("notification.retries", notification.Retries),
+		),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
-		if err := s.checkCancellation(ctx, notification.ID); err != nil {
+		if current, err := s.checkCurrentState(ctx, notification); err != nil || !current {
 			return err
 		}
 
+		if notification.IsExpired(time.Now()) {
+			return s.markAsExpired(ctx, notification)
+		}
+
 		if shouldDelay, err := s.scheduleDelayedDelivery(ctx, notification, emailConfig); err != nil {
 			return err
 		} else if shouldDelay {
 			return nil
 		}
 
-		channelSender, err := s.getChannelSender(notification.Channel, emailConfig)
+		if notification.Digest && s.digestEnabled {
+			log.Debug().Ctx(ctx).Str("id", notification.ID).Msg("Notification buffered for digest")
+			return nil
+		}
+
+		if suppressed, err := s.isSuppressed(ctx, notification.TenantID, notification.RecipientID, notification.Channel); err != nil {
+			return err
+		} else if suppressed {
+			return s.markAsSuppressed(ctx, notification)
+		}
+
+		release, ok := s.quotas.Load().AcquireSend(notification.TenantID)
+		if !ok {
+			return s.throttleSend(ctx, notification, emailConfig)
+		}
+		defer release()
+
+		channelSender, err := s.getChannelSender(notification.TenantID, notification.Channel, emailConfig)
 		if err != nil {
 			return err
 		}
@@ -247,16 +500,28 @@ func (s *NotifierService) processNotification(ctx context.Context, notification
 	}
 }
 
-func (s *NotifierService) checkCancellation(ctx context.Context, notificationID string) error {
-	status, err := s.repo.LoadStatusByID(ctx, notificationID)
+// checkCurrentState сверяет сообщение очереди с уведомлением в хранилище. Возвращает ErrCancelled
+// для отмененного уведомления и false, если сообщение устарело: уведомление перенесено
+// или повторно поставлено в очередь с другой датой отправки
+func (s *NotifierService) checkCurrentState(ctx context.Context, notification domain.Notification) (bool, error) {
+	stored, err := s.repo.LoadByID(ctx, notification.ID)
 	if err != nil {
-		return err
+		return false, err
 	}
-	if status == domain.StatusCancelled {
-		log.Info().Str("id", notificationID).Msg("Notification was cancelled, skipping processing")
-		return fmt.Errorf("notification %s was cancelled", notificationID)
+	if stored.Status == domain.StatusCancelled {
+		log.Info().Ctx(ctx).Str("id", notification.ID).Msg("Notification was cancelled, skipping processing")
+		return false, fmt.Errorf("notification %s: %w", notification.ID, domain.ErrCancelled)
 	}
-	return nil
+	if drift := stored.NotificationDate.Sub(notification.NotificationDate); drift > staleMessageTolerance || drift < -staleMessageTolerance {
+		log.Info().
+			Ctx(ctx).
+			Str("id", notification.ID).
+			Time("message_notify_at", notification.NotificationDate).
+			Time("notify_at", stored.NotificationDate).
+			Msg("Notification was rescheduled, skipping stale message")
+		return false, nil
+	}
+	return true, nil
 }
 
 func (s *NotifierService) scheduleDelayedDelivery(ctx context.Context, notification domain.Notification, emailConfig *dto.EmailConfig) (bool, error) {
@@ -270,23 +535,49 @@ func (s *NotifierService) scheduleDelayedDelivery(ctx context.Context, notificat
 		}
 
 		log.Info().
+			Ctx(ctx).
 			Str("id", notification.ID).
 			Dur("delay", delay).
 			Time("notify_at", notification.NotificationDate).
 			Msg("Scheduling delayed delivery")
 
 		if err := s.publisher.PublishDelayed(ctx, message, queueRoutingKey, queueContentType, delay); err != nil {
-			log.Error().Err(err).Str("id", notification.ID).Msg("Failed to publish delayed message")
+			log.Error().Ctx(ctx).Err(err).Str("id", notification.ID).Msg("Failed to publish delayed message")
 			return false, err
 		}
-		log.Info().Str("id", notification.ID).Msg("Delayed message published")
+		log.Info().Ctx(ctx).Str("id", notification.ID).Msg("Delayed message published")
 		return true, nil
 	}
 	return false, nil
 }
 
-func (s *NotifierService) getChannelSender(channel domain.Channel, emailConfig *dto.EmailConfig) (sender.ChannelSender, error) {
-	if channel == domain.ChannelEmail && emailConfig != nil {
+// throttleSend откладывает отправку, когда тенант занял все слоты одновременной отправки
+func (s *NotifierService) throttleSend(ctx context.Context, notification domain.Notification, emailConfig *dto.EmailConfig) error {
+	metrics.RecordQuotaExceeded(notification.TenantID, metrics.QuotaConcurrentSends)
+
+	message, err := s.buildQueueMessage(notification, emailConfig)
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Ctx(ctx).
+		Str("id", notification.ID).
+		Str("tenant_id", notification.TenantID).
+		Dur("delay", tenantThrottleDelay).
+		Msg("Tenant concurrent sends limit reached, deferring notification")
+
+	if err := s.publisher.PublishDelayed(ctx, message, queueRoutingKey, queueContentType, tenantThrottleDelay); err != nil {
+		log.Error().Ctx(ctx).Err(err).Str("id", notification.ID).Msg("Failed to republish throttled message")
+		return err
+	}
+
+	return nil
+}
+
+func (s *NotifierService) getChannelSender(tenantID string, channel domain.Channel, emailConfig *dto.EmailConfig) (sender.ChannelSender, error) {
+	// Пустая конфигурация означает отправку по умолчанию, а не SMTP без настроек
+	if channel == domain.ChannelEmail && emailConfig != nil && *emailConfig != (dto.EmailConfig{}) {
 		customEmailSender, err := s.senderFactory.GetEmailSenderWithConfig(*emailConfig)
 		if err != nil {
 			log.Error().
@@ -298,10 +589,11 @@ func (s *NotifierService) getChannelSender(channel domain.Channel, emailConfig *
 		return customEmailSender, nil
 	}
 
-	channelSender, err := s.senderFactory.GetSender(channel)
+	channelSender, err := s.senderFactory.GetTenantSender(tenantID, channel)
 	if err != nil {
 		log.Error().
 			Err(err).
+			Str("tenant_id", tenantID).
 			Str("channel", string(channel)).
 			Msg("Failed to get sender")
 		return nil, err
@@ -310,10 +602,20 @@ func (s *NotifierService) getChannelSender(channel domain.Channel, emailConfig *
 }
 
 func (s *NotifierService) handleSendWithRetry(ctx context.Context, notification domain.Notification, channelSender sender.ChannelSender, emailConfig *dto.EmailConfig) error {
-	log.Info().Str("id", notification.ID).Str("channel", string(notification.Channel)).Msg("Sending notification")
-
-	if err := channelSender.Send(ctx, notification); err != nil {
-		return s.handleSendError(ctx, notification, emailConfig, err)
+	log.Info().Ctx(ctx).Str("id", notification.ID).Str("channel", string(notification.Channel)).Msg("Sending notification")
+
+	sendCtx, span := tracing.Tracer().Start(ctx, "ChannelSender.Send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("notification.id", notification.ID),
+			attribute.String("notification.channel", string(notification.Channel)),
+		),
+	)
+	sendErr := channelSender.Send(sendCtx, notification)
+	tracing.End(span, sendErr)
+
+	if sendErr != nil {
+		return s.handleSendError(ctx, notification, emailConfig, sendErr)
 	}
 
 	return nil
@@ -323,7 +625,7 @@ func (s *NotifierService) handleSendWithRetry(ctx context.Context, notification
 func (s *NotifierService) handleSendError(ctx context.Context, notification domain.Notification, emailConfig *dto.EmailConfig, sendErr error) error {
 	nextRetries := notification.Retries + 1
 
-	if nextRetries < maxRetries {
+	if int64(nextRetries) < s.maxRetries.Load() {
 		return s.scheduleRetry(ctx, notification, emailConfig, nextRetries, sendErr)
 	}
 
@@ -333,6 +635,7 @@ func (s *NotifierService) handleSendError(ctx context.Context, notification doma
 // scheduleRetry планирует повторную попытку отправки
 func (s *NotifierService) scheduleRetry(ctx context.Context, notification domain.Notification, emailConfig *dto.EmailConfig, retryCount int, sendErr error) error {
 	log.Warn().
+		Ctx(ctx).
 		Err(sendErr).
 		Str("id", notification.ID).
 		Int("retry", retryCount).
@@ -351,13 +654,14 @@ func (s *NotifierService) scheduleRetry(ctx context.Context, notification domain
 	}
 
 	log.Info().
+		Ctx(ctx).
 		Str("id", notification.ID).
 		Int("retries", retryCount).
 		Dur("backoff", backoff).
 		Msg("Republishing message for retry")
 
 	if err := s.publisher.PublishDelayed(ctx, message, queueRoutingKey, queueContentType, backoff); err != nil {
-		log.Error().Err(err).Str("id", notification.ID).Msg("Failed to republish message for retry")
+		log.Error().Ctx(ctx).Err(err).Str("id", notification.ID).Msg("Failed to republish message for retry")
 		return err
 	}
 
@@ -369,10 +673,11 @@ func (s *NotifierService) markAsFailed(ctx context.Context, notification domain.
 	notification.Status = domain.StatusFailed
 
 	if err := s.updateNotificationStatusByID(ctx, notification.ID, domain.StatusFailed); err != nil {
-		log.Error().Err(err).Str("id", notification.ID).Msg("Failed to update status to failed")
+		log.Error().Ctx(ctx).Err(err).Str("id", notification.ID).Msg("Failed to update status to failed")
 	}
 
 	log.Error().
+		Ctx(ctx).
 		Err(sendErr).
 		Str("id", notification.ID).
 		Int("retries", retryCount).
@@ -381,12 +686,66 @@ func (s *NotifierService) markAsFailed(ctx context.Context, notification domain.
 	return sendErr
 }
 
+// markAsExpired помечает уведомление как устаревшее вместо отправки
+func (s *NotifierService) markAsExpired(ctx context.Context, notification domain.Notification) error {
+	now := time.Now()
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("notification.expired", true))
+
+	if err := s.updateNotificationStatusByID(ctx, notification.ID, domain.StatusExpired); err != nil {
+		return err
+	}
+	metrics.ObserveLateness(notification, domain.StatusExpired, now)
+
+	log.Warn().
+		Ctx(ctx).
+		Str("id", notification.ID).
+		Str("channel", string(notification.Channel)).
+		Time("expires_at", *notification.ExpiresAt).
+		Dur("lateness", now.Sub(notification.NotificationDate)).
+		Msg("Notification expired before delivery, skipping send")
+
+	return nil
+}
+
+// isSuppressed проверяет список подавления, если проверка включена
+func (s *NotifierService) isSuppressed(ctx context.Context, tenantID, recipientID string, channel domain.Channel) (bool, error) {
+	if s.suppressions == nil {
+		return false, nil
+	}
+
+	suppressed, err := s.suppressions.IsSuppressed(ctx, tenantID, recipientID, channel)
+	if err != nil {
+		log.Error().Ctx(ctx).Err(err).Str("tenant_id", tenantID).Msg("Failed to check suppression list")
+		return false, fmt.Errorf("failed to check suppression list: %w", err)
+	}
+	return suppressed, nil
+}
+
+// markAsSuppressed помечает уведомление подавленным вместо отправки
+func (s *NotifierService) markAsSuppressed(ctx context.Context, notification domain.Notification) error {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("notification.suppressed", true))
+
+	if err := s.updateNotificationStatusByID(ctx, notification.ID, domain.StatusSuppressed); err != nil {
+		return err
+	}
+
+	log.Info().
+		Ctx(ctx).
+		Str("id", notification.ID).
+		Str("channel", string(notification.Channel)).
+		Msg("Recipient is suppressed, skipping send")
+
+	return nil
+}
+
 func (s *NotifierService) markAsSent(ctx context.Context, notification domain.Notification) error {
 	if err := s.updateNotificationStatusByID(ctx, notification.ID, domain.StatusSent); err != nil {
 		return err
 	}
+	metrics.ObserveLateness(notification, domain.StatusSent, time.Now())
 
 	log.Info().
+		Ctx(ctx).
 		Str("id", notification.ID).
 		Str("channel", string(notification.Channel)).
 		Msg("Notification sent")
@@ -395,17 +754,64 @@ func (s *NotifierService) markAsSent(ctx context.Context, notification domain.No
 }
 
 func (s *NotifierService) updateNotificationStatusByID(ctx context.Context, id string, status domain.Status) error {
-	if _, err := s.repo.UpdateStatusByID(ctx, id, status); err != nil {
-		log.Error().Err(err).Str("id", id).Msg(msgFailedToUpdateStatus)
+	updated, err := s.repo.UpdateStatusByID(ctx, id, status)
+	if err != nil {
+		log.Error().Ctx(ctx).Err(err).Str("id", id).Msg(msgFailedToUpdateStatus)
 		return err
 	}
-	if err := s.cache.Set(ctx, id, string(status), s.notificationTTL); err != nil {
-		log.Warn().Err(err).Str("id", id).Msg(msgFailedToCacheStatus)
+
+	tenantID := tenant.IDFromContext(ctx)
+	var channel domain.Channel
+	if updated != nil {
+		channel = updated.Channel
+		if updated.TenantID != "" {
+			tenantID = updated.TenantID
+		}
 	}
 
+	if err := s.cache.Set(ctx, statusCacheKey(tenantID, id), string(status), s.notificationTTL); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Str("id", id).Msg(msgFailedToCacheStatus)
+	}
+
+	s.onStatusChanged(ctx, tenantID, id, channel, status)
+
 	return nil
 }
 
+// onStatusChanged учитывает смену статуса в метриках и публикует событие подписчикам
+func (s *NotifierService) onStatusChanged(ctx context.Context, tenantID, id string, channel domain.Channel, status domain.Status) {
+	metrics.RecordStatus(tenantID, channel, status)
+	s.publishStatusEvent(ctx, tenantID, id, channel, status)
+}
+
+// statusCacheKey возвращает ключ статуса в кэше. Ключи тенанта по умолчанию
+// совпадают с ID, чтобы не терять уже закэшированные статусы
+func statusCacheKey(tenantID, id string) string {
+	if tenantID == "" || tenantID == tenant.DefaultID {
+		return id
+	}
+	return tenantID + ":" + id
+}
+
+// publishStatusEvent публикует событие изменения статуса подписчикам
+func (s *NotifierService) publishStatusEvent(ctx context.Context, tenantID, id string, channel domain.Channel, status domain.Status) {
+	if s.statusEvents == nil {
+		return
+	}
+
+	event := events.StatusEvent{
+		ID:        id,
+		TenantID:  tenantID,
+		Status:    status,
+		Channel:   channel,
+		Timestamp: time.Now(),
+	}
+
+	if err := s.statusEvents.Publish(ctx, event); err != nil {
+		log.Warn().Ctx(ctx).Err(err).Str("id", id).Str("status", string(status)).Msg(msgFailedToPublishStatusEvent)
+	}
+}
+
 // buildQueueMessage создает сообщение для очереди из уведомления
 func (s *NotifierService) buildQueueMessage(notification domain.Notification, emailConfig *dto.EmailConfig) ([]byte, error) {
 	queueMessage := map[string]interface{}{
@@ -418,6 +824,15 @@ func (s *NotifierService) buildQueueMessage(notification domain.Notification, em
 		"recipient_id":      notification.RecipientID,
 		"channel":           notification.Channel,
 		"retries":           notification.Retries,
+		"tenant_id":         notification.TenantID,
+	}
+
+	if notification.ExpiresAt != nil {
+		queueMessage["expires_at"] = notification.ExpiresAt
+	}
+
+	if notification.Digest {
+		queueMessage["digest"] = true
 	}
 
 	if emailConfig != nil {
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/events"
+	"delayed-notifier/internal/sender"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRescheduleNotification(t *testing.T) {
+	repo := &MockRepository{}
+	publisher := &MockPublisher{}
+	service := NewNotifierService(repo, &MockCache{}, publisher, sender.NewFactory(nil, nil), nil, time.Hour)
+
+	expiresAt := time.Now().Add(3 * time.Hour)
+	notification := domain.Notification{
+		ID:               "rescheduled-notification",
+		Payload:          "Test message",
+		Status:           domain.StatusPending,
+		NotificationDate: time.Now().Add(time.Hour),
+		RecipientID:      "user123",
+		Channel:          domain.ChannelTelegram,
+		ExpiresAt:        &expiresAt,
+	}
+	require.NoError(t, repo.Store(context.Background(), notification))
+
+	notifyAt := time.Now().Add(2 * time.Hour)
+	rescheduled, err := service.RescheduleNotification(context.Background(), notification.ID, notifyAt)
+	require.NoError(t, err)
+	assert.Equal(t, notifyAt, rescheduled.NotificationDate)
+	assert.True(t, publisher.PublishCalled)
+
+	var message map[string]any
+	require.NoError(t, json.Unmarshal(publisher.LastBody, &message))
+	assert.Equal(t, notifyAt.Format(time.RFC3339Nano), message["notification_date"])
+
+	_, err = service.RescheduleNotification(context.Background(), notification.ID, expiresAt.Add(time.Minute))
+	assert.ErrorIs(t, err, domain.ErrValidation, "new date must be before expires_at")
+
+	require.NoError(t, service.CancelNotification(context.Background(), notification.ID))
+	_, err = service.RescheduleNotification(context.Background(), notification.ID, notifyAt)
+	assert.ErrorIs(t, err, domain.ErrCancelled)
+}
+
+func TestResendNotification(t *testing.T) {
+	repo := &MockRepository{}
+	cache := &MockCache{}
+	publisher := &MockPublisher{}
+	broker := events.NewBroker()
+	service := NewNotifierService(repo, cache, publisher, sender.NewFactory(nil, nil), broker, time.Hour)
+
+	sub := broker.Subscribe("")
+	defer sub.Close()
+
+	expiresAt := time.Now().Add(-time.Minute)
+	notification := domain.Notification{
+		ID:               "resent-notification",
+		Payload:          "Test message",
+		Status:           domain.StatusExpired,
+		NotificationDate: time.Now().Add(-time.Hour),
+		RecipientID:      "user123",
+		Channel:          domain.ChannelTelegram,
+		Retries:          2,
+		ExpiresAt:        &expiresAt,
+	}
+	require.NoError(t, repo.Store(context.Background(), notification))
+
+	resent, err := service.ResendNotification(context.Background(), notification.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusPending, resent.Status)
+	assert.Equal(t, 0, resent.Retries)
+	assert.Nil(t, resent.ExpiresAt, "expired deadline is dropped on resend")
+	assert.WithinDuration(t, time.Now(), resent.NotificationDate, time.Second)
+	assert.True(t, publisher.PublishCalled)
+
+	cachedStatus, err := cache.Get(context.Background(), notification.ID)
+	require.NoError(t, err)
+	assert.Equal(t, string(domain.StatusPending), cachedStatus)
+
+	event := <-sub.C
+	assert.Equal(t, domain.StatusPending, event.Status)
+
+	_, err = service.ResendNotification(context.Background(), notification.ID)
+	assert.ErrorIs(t, err, domain.ErrPending)
+}
+
+func TestProcessNotification_SkipsRescheduledMessage(t *testing.T) {
+	repo := &MockRepository{}
+	publisher := &MockPublisher{}
+	service := NewNotifierService(repo, &MockCache{}, publisher, sender.NewFactory(nil, nil), nil, time.Hour)
+
+	notification := domain.Notification{
+		ID:               "stale-notification",
+		Payload:          "Test message",
+		Status:           domain.StatusPending,
+		NotificationDate: time.Now().Add(-time.Minute),
+		RecipientID:      "user123",
+		Channel:          domain.ChannelTelegram,
+	}
+	stored := notification
+	stored.NotificationDate = time.Now().Add(time.Hour)
+	require.NoError(t, repo.Store(context.Background(), stored))
+
+	require.NoError(t, service.ProcessTelegramNotification(context.Background(), notification))
+
+	current, err := repo.LoadByID(context.Background(), notification.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusPending, current.Status)
+	assert.False(t, publisher.PublishCalled)
+	assert.False(t, publisher.PublishDelayedCalled)
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"delayed-notifier/internal/archive"
+	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/repository"
+	"delayed-notifier/internal/tenant"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RetentionPolicy задает срок хранения уведомлений с определенным статусом
+type RetentionPolicy struct {
+	Status domain.Status `json:"status"`
+	Period time.Duration `json:"period"`
+}
+
+// RetentionRun описывает результат одного прогона архивации
+type RetentionRun struct {
+	StartedAt  time.Time               `json:"started_at"`
+	FinishedAt time.Time               `json:"finished_at"`
+	Archived   map[domain.Status]int64 `json:"archived"`
+	Deleted    int64                   `json:"deleted"`
+	Error      string                  `json:"error,omitempty"`
+}
+
+// RetentionStatusStats содержит статистику хранения по одному статусу
+type RetentionStatusStats struct {
+	Status   domain.Status `json:"status"`
+	Period   string        `json:"period"`
+	Stored   int64         `json:"stored"`
+	Eligible int64         `json:"eligible"`
+}
+
+// RetentionStats содержит отчет о хранении и архивации уведомлений
+type RetentionStats struct {
+	Enabled       bool                   `json:"enabled"`
+	Archive       string                 `json:"archive"`
+	Interval      string                 `json:"interval"`
+	BatchSize     int                    `json:"batch_size"`
+	Statuses      []RetentionStatusStats `json:"statuses"`
+	TotalArchived int64                  `json:"total_archived"`
+	TotalDeleted  int64                  `json:"total_deleted"`
+	LastRun       *RetentionRun          `json:"last_run,omitempty"`
+}
+
+// RetentionJob периодически архивирует и удаляет устаревшие уведомления
+type RetentionJob struct {
+	repo     repository.RetentionRepository
+	archiver archive.Archiver
+	config   config.RetentionConfig
+	policies []RetentionPolicy
+	now      func() time.Time
+
+	mu            sync.Mutex
+	running       bool
+	lastRun       *RetentionRun
+	totalArchived int64
+	totalDeleted  int64
+
+	wg sync.WaitGroup
+}
+
+// NewRetentionJob создает новую задачу архивации
+func NewRetentionJob(repo repository.RetentionRepository, archiver archive.Archiver, retentionConfig config.RetentionConfig) *RetentionJob {
+	return &RetentionJob{
+		repo:     repo,
+		archiver: archiver,
+		config:   retentionConfig,
+		policies: retentionPolicies(retentionConfig),
+		now:      time.Now,
+	}
+}
+
+// retentionPolicies строит политики хранения из конфигурации, пропуская отключенные
+func retentionPolicies(cfg config.RetentionConfig) []RetentionPolicy {
+	candidates := []RetentionPolicy{
+		{Status: domain.StatusSent, Period: cfg.Sent},
+		{Status: domain.StatusFailed, Period: cfg.Failed},
+		{Status: domain.StatusCancelled, Period: cfg.Cancelled},
+		{Status: domain.StatusExpired, Period: cfg.Expired},
+		{Status: domain.StatusSuppressed, Period: cfg.Suppressed},
+		{Status: domain.StatusPending, Period: cfg.Pending},
+	}
+
+	policies := make([]RetentionPolicy, 0, len(candidates))
+	for _, policy := range candidates {
+		if policy.Period > 0 {
+			policies = append(policies, policy)
+		}
+	}
+	return policies
+}
+
+// Start запускает фоновую архивацию до отмены контекста
+func (j *RetentionJob) Start(ctx context.Context) {
+	if !j.config.Enabled {
+		log.Info().Msg("Retention job disabled")
+		return
+	}
+
+	j.wg.Add(1)
+	go j.loop(ctx)
+
+	log.Info().
+		Dur("interval", j.config.Interval).
+		Str("archive", j.archiver.Name()).
+		Int("batch_size", j.config.BatchSize).
+		Msg("Started retention job")
+}
+
+// Wait ждет завершения фоновой архивации
+func (j *RetentionJob) Wait() {
+	j.wg.Wait()
+}
+
+func (j *RetentionJob) loop(ctx context.Context) {
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(j.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.RunOnce(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Error().Err(err).Msg("Retention run failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Retention job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce выполняет один прогон архивации по всем политикам хранения
+func (j *RetentionJob) RunOnce(ctx context.Context) (RetentionRun, error) {
+	j.mu.Lock()
+	if j.running {
+		j.mu.Unlock()
+		return RetentionRun{}, fmt.Errorf("retention run already in progress")
+	}
+	j.running = true
+	j.mu.Unlock()
+
+	run := RetentionRun{
+		StartedAt: j.now(),
+		Archived:  make(map[domain.Status]int64),
+	}
+
+	var runErr error
+	for _, policy := range j.policies {
+		archived, deleted, err := j.applyPolicy(ctx, policy)
+		run.Archived[policy.Status] += archived
+		run.Deleted += deleted
+		if err != nil {
+			runErr = err
+			break
+		}
+	}
+
+	run.FinishedAt = j.now()
+	if runErr != nil {
+		run.Error = runErr.Error()
+	}
+
+	j.mu.Lock()
+	j.running = false
+	j.lastRun = &run
+	for _, archived := range run.Archived {
+		j.totalArchived += archived
+	}
+	j.totalDeleted += run.Deleted
+	j.mu.Unlock()
+
+	log.Info().
+		Int64("deleted", run.Deleted).
+		Dur("duration", run.FinishedAt.Sub(run.StartedAt)).
+		Msg("Retention run finished")
+
+	return run, runErr
+}
+
+// applyPolicy архивирует и удаляет устаревшие уведомления одного статуса пачками
+func (j *RetentionJob) applyPolicy(ctx context.Context, policy RetentionPolicy) (int64, int64, error) {
+	olderThan := j.now().Add(-policy.Period)
+
+	var archived, deleted int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return archived, deleted, err
+		}
+
+		batch, err := j.repo.LoadExpired(ctx, policy.Status, olderThan, j.config.BatchSize)
+		if err != nil {
+			return archived, deleted, err
+		}
+		if len(batch) == 0 {
+			return archived, deleted, nil
+		}
+
+		if err := j.archiver.Archive(ctx, batch); err != nil {
+			return archived, deleted, fmt.Errorf("failed to archive notifications: %w", err)
+		}
+		archived += int64(len(batch))
+
+		ids := make([]string, 0, len(batch))
+		for _, notification := range batch {
+			ids = append(ids, notification.ID)
+		}
+
+		n, err := j.repo.DeleteExpired(ctx, ids, policy.Status, olderThan)
+		if err != nil {
+			return archived, deleted, err
+		}
+		deleted += n
+
+		log.Debug().
+			Str("status", string(policy.Status)).
+			Int("batch", len(batch)).
+			Int64("deleted", n).
+			Msg("Retention batch processed")
+
+		if len(batch) < j.config.BatchSize {
+			return archived, deleted, nil
+		}
+	}
+}
+
+// Stats возвращает отчет о хранении уведомлений. Для запроса тенанта (tenant.Scope) количества считаются
+// только по его уведомлениям, а итоги прогонов, общие для всех тенантов, не раскрываются
+func (j *RetentionJob) Stats(ctx context.Context) (*RetentionStats, error) {
+	counts, err := j.repo.CountByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	periods := make(map[domain.Status]time.Duration, len(j.policies))
+	for _, policy := range j.policies {
+		periods[policy.Status] = policy.Period
+	}
+
+	now := j.now()
+	statusStats := make([]RetentionStatusStats, 0, len(domain.Statuses))
+	for _, status := range domain.Statuses {
+		stats := RetentionStatusStats{
+			Status: status,
+			Stored: counts[status],
+		}
+
+		if period, ok := periods[status]; ok {
+			stats.Period = period.String()
+			eligible, err := j.repo.CountExpired(ctx, status, now.Add(-period))
+			if err != nil {
+				return nil, err
+			}
+			stats.Eligible = eligible
+		}
+
+		statusStats = append(statusStats, stats)
+	}
+
+	stats := &RetentionStats{
+		Enabled:   j.config.Enabled,
+		Archive:   j.archiver.Name(),
+		Interval:  j.config.Interval.String(),
+		BatchSize: j.config.BatchSize,
+		Statuses:  statusStats,
+	}
+	if tenant.Scope(ctx) != "" {
+		return stats, nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	stats.TotalArchived = j.totalArchived
+	stats.TotalDeleted = j.totalDeleted
+	stats.LastRun = j.lastRun
+	return stats, nil
+}
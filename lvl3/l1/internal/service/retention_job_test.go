@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/tenant"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionJob_RunOnce(t *testing.T) {
+	now := time.Now()
+	repo := &MockRetentionRepository{updatedAt: make(map[string]time.Time)}
+
+	for i := 0; i < 5; i++ {
+		repo.add(fmt.Sprintf("old-sent-%d", i), domain.StatusSent, now.Add(-48*time.Hour))
+	}
+	repo.add("fresh-sent", domain.StatusSent, now.Add(-time.Hour))
+	repo.add("old-failed", domain.StatusFailed, now.Add(-48*time.Hour))
+	repo.add("old-pending", domain.StatusPending, now.Add(-48*time.Hour))
+
+	archiver := &MockArchiver{}
+	job := NewRetentionJob(repo, archiver, config.RetentionConfig{
+		Enabled:   true,
+		Interval:  time.Hour,
+		BatchSize: 2,
+		Sent:      24 * time.Hour,
+		Failed:    24 * time.Hour,
+	})
+
+	run, err := job.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(5), run.Archived[domain.StatusSent])
+	assert.Equal(t, int64(1), run.Archived[domain.StatusFailed])
+	assert.Equal(t, int64(6), run.Deleted)
+	assert.Equal(t, 4, archiver.Batches)
+
+	remaining := repo.ids()
+	assert.Equal(t, []string{"fresh-sent", "old-pending"}, remaining)
+	assert.Len(t, archiver.Archived, 6)
+}
+
+func TestRetentionJob_KeepsNotificationsChangedBeforeDelete(t *testing.T) {
+	now := time.Now()
+	repo := &MockRetentionRepository{updatedAt: make(map[string]time.Time)}
+	repo.add("old-sent", domain.StatusSent, now.Add(-48*time.Hour))
+	repo.add("resent", domain.StatusSent, now.Add(-48*time.Hour))
+
+	// Уведомление отправляют повторно после выборки, но до удаления
+	archiver := &MockArchiver{OnArchive: func() { repo.setStatus("resent", domain.StatusPending, now) }}
+	job := NewRetentionJob(repo, archiver, config.RetentionConfig{
+		Enabled:   true,
+		Interval:  time.Hour,
+		BatchSize: 10,
+		Sent:      24 * time.Hour,
+	})
+
+	run, err := job.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), run.Deleted)
+	assert.Equal(t, []string{"resent"}, repo.ids())
+}
+
+func TestRetentionJob_ArchiveFailureKeepsRows(t *testing.T) {
+	now := time.Now()
+	repo := &MockRetentionRepository{updatedAt: make(map[string]time.Time)}
+	repo.add("old-sent", domain.StatusSent, now.Add(-48*time.Hour))
+
+	archiver := &MockArchiver{Err: assert.AnError}
+	job := NewRetentionJob(repo, archiver, config.RetentionConfig{
+		Enabled:   true,
+		Interval:  time.Hour,
+		BatchSize: 10,
+		Sent:      24 * time.Hour,
+	})
+
+	run, err := job.RunOnce(context.Background())
+	require.Error(t, err)
+	assert.NotEmpty(t, run.Error)
+	assert.Equal(t, []string{"old-sent"}, repo.ids())
+}
+
+func TestRetentionJob_StatsScopedToTenant(t *testing.T) {
+	now := time.Now()
+	repo := &MockRetentionRepository{updatedAt: make(map[string]time.Time)}
+	repo.addForTenant("acme-old", "acme", domain.StatusSent, now.Add(-48*time.Hour))
+	repo.addForTenant("other-old", "other", domain.StatusSent, now.Add(-48*time.Hour))
+	repo.addForTenant("other-fresh", "other", domain.StatusSent, now)
+
+	job := NewRetentionJob(repo, &MockArchiver{}, config.RetentionConfig{
+		Interval:  time.Hour,
+		BatchSize: 10,
+		Sent:      24 * time.Hour,
+	})
+	_, err := job.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	// Тенант видит только свои уведомления и не видит итогов прогонов по всем тенантам
+	repo.addForTenant("acme-new", "acme", domain.StatusSent, now.Add(-48*time.Hour))
+	stats, err := job.Stats(tenant.WithID(context.Background(), "acme"))
+	require.NoError(t, err)
+	for _, s := range stats.Statuses {
+		if s.Status == domain.StatusSent {
+			assert.Equal(t, int64(1), s.Stored)
+			assert.Equal(t, int64(1), s.Eligible)
+		}
+	}
+	assert.Zero(t, stats.TotalArchived)
+	assert.Nil(t, stats.LastRun)
+
+	stats, err = job.Stats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.TotalArchived)
+	assert.NotNil(t, stats.LastRun)
+}
+
+func TestRetentionJob_Stats(t *testing.T) {
+	now := time.Now()
+	repo := &MockRetentionRepository{updatedAt: make(map[string]time.Time)}
+	repo.add("old-sent", domain.StatusSent, now.Add(-48*time.Hour))
+	repo.add("fresh-sent", domain.StatusSent, now.Add(-time.Hour))
+	repo.add("pending", domain.StatusPending, now)
+
+	job := NewRetentionJob(repo, &MockArchiver{}, config.RetentionConfig{
+		Interval:  time.Hour,
+		BatchSize: 10,
+		Sent:      24 * time.Hour,
+	})
+
+	stats, err := job.Stats(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, stats.Enabled)
+	assert.Equal(t, "mock", stats.Archive)
+	require.Len(t, stats.Statuses, 6)
+
+	for _, s := range stats.Statuses {
+		switch s.Status {
+		case domain.StatusSent:
+			assert.Equal(t, int64(2), s.Stored)
+			assert.Equal(t, int64(1), s.Eligible)
+			assert.Equal(t, "24h0m0s", s.Period)
+		case domain.StatusPending:
+			assert.Equal(t, int64(1), s.Stored)
+			assert.Zero(t, s.Eligible)
+			assert.Empty(t, s.Period)
+		}
+	}
+}
+
+type MockRetentionRepository struct {
+	notifications []domain.Notification
+	updatedAt     map[string]time.Time
+}
+
+func (m *MockRetentionRepository) add(id string, status domain.Status, updatedAt time.Time) {
+	m.addForTenant(id, "", status, updatedAt)
+}
+
+func (m *MockRetentionRepository) addForTenant(id, tenantID string, status domain.Status, updatedAt time.Time) {
+	m.notifications = append(m.notifications, domain.Notification{ID: id, Status: status, TenantID: tenantID})
+	m.updatedAt[id] = updatedAt
+}
+
+func (m *MockRetentionRepository) ids() []string {
+	ids := make([]string, 0, len(m.notifications))
+	for _, n := range m.notifications {
+		ids = append(ids, n.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (m *MockRetentionRepository) LoadExpired(ctx context.Context, status domain.Status, olderThan time.Time, limit int) ([]domain.Notification, error) {
+	var result []domain.Notification
+	for _, n := range m.notifications {
+		if n.Status == status && m.updatedAt[n.ID].Before(olderThan) && len(result) < limit {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockRetentionRepository) DeleteExpired(ctx context.Context, ids []string, status domain.Status, olderThan time.Time) (int64, error) {
+	toDelete := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		toDelete[id] = true
+	}
+
+	var kept []domain.Notification
+	var deleted int64
+	for _, n := range m.notifications {
+		if toDelete[n.ID] && n.Status == status && m.updatedAt[n.ID].Before(olderThan) {
+			deleted++
+			continue
+		}
+		kept = append(kept, n)
+	}
+	m.notifications = kept
+	return deleted, nil
+}
+
+// setStatus меняет статус уведомления, как повторная отправка или перенос
+func (m *MockRetentionRepository) setStatus(id string, status domain.Status, updatedAt time.Time) {
+	for i, n := range m.notifications {
+		if n.ID == id {
+			m.notifications[i].Status = status
+			m.updatedAt[id] = updatedAt
+		}
+	}
+}
+
+func (m *MockRetentionRepository) CountByStatus(ctx context.Context) (map[domain.Status]int64, error) {
+	counts := make(map[domain.Status]int64)
+	for _, n := range m.notifications {
+		if scope := tenant.Scope(ctx); scope == "" || n.TenantID == scope {
+			counts[n.Status]++
+		}
+	}
+	return counts, nil
+}
+
+func (m *MockRetentionRepository) CountExpired(ctx context.Context, status domain.Status, olderThan time.Time) (int64, error) {
+	var count int64
+	for _, n := range m.notifications {
+		if scope := tenant.Scope(ctx); scope != "" && n.TenantID != scope {
+			continue
+		}
+		if n.Status == status && m.updatedAt[n.ID].Before(olderThan) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+type MockArchiver struct {
+	Archived []domain.Notification
+	Batches  int
+	Err      error
+	// OnArchive вызывается перед архивацией пачки, например чтобы изменить уведомление между выборкой и удалением
+	OnArchive func()
+}
+
+func (m *MockArchiver) Archive(ctx context.Context, notifications []domain.Notification) error {
+	if m.OnArchive != nil {
+		m.OnArchive()
+	}
+	if m.Err != nil {
+		return m.Err
+	}
+	m.Batches++
+	m.Archived = append(m.Archived, notifications...)
+	return nil
+}
+
+func (m *MockArchiver) Name() string {
+	return "mock"
+}
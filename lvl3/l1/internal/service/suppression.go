@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/repository"
+	"delayed-notifier/internal/tenant"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SuppressionChecker проверяет, что получателю тенанта нельзя отправлять уведомления по каналу
+type SuppressionChecker interface {
+	IsSuppressed(ctx context.Context, tenantID, recipientID string, channel domain.Channel) (bool, error)
+}
+
+// SuppressionService управляет списком подавления получателей.
+// Записи API относятся к тенанту запроса, отписка по ссылке — к тенанту из токена
+type SuppressionService struct {
+	repo repository.SuppressionRepository
+	now  func() time.Time
+}
+
+// NewSuppressionService создает новый сервис списка подавления
+func NewSuppressionService(repo repository.SuppressionRepository) *SuppressionService {
+	return &SuppressionService{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// Suppress добавляет получателя в список подавления тенанта запроса или заменяет существующую запись
+func (s *SuppressionService) Suppress(ctx context.Context, suppression domain.Suppression) (*domain.Suppression, error) {
+	suppression.TenantID = tenant.IDFromContext(ctx)
+	suppression.CreatedAt = s.now()
+	return s.repo.UpsertSuppression(ctx, suppression)
+}
+
+// Unsuppress удаляет получателя из списка подавления тенанта запроса
+func (s *SuppressionService) Unsuppress(ctx context.Context, recipientID string, channel domain.Channel) error {
+	return s.repo.DeleteSuppression(ctx, tenant.IDFromContext(ctx), recipientID, channel)
+}
+
+// GetSuppression возвращает действующую запись списка подавления тенанта запроса.
+// Истекшая запись считается отсутствующей
+func (s *SuppressionService) GetSuppression(ctx context.Context, recipientID string, channel domain.Channel) (*domain.Suppression, error) {
+	suppression, err := s.repo.LoadSuppression(ctx, tenant.IDFromContext(ctx), recipientID, channel)
+	if err != nil {
+		return nil, err
+	}
+	if !suppression.IsActive(s.now()) {
+		return nil, domain.ErrSuppressionNotFound
+	}
+	return suppression, nil
+}
+
+// ListSuppressions возвращает страницу действующих записей списка подавления тенанта запроса
+func (s *SuppressionService) ListSuppressions(ctx context.Context, filter domain.SuppressionFilter) (domain.SuppressionPage, error) {
+	filter.ActiveAt = s.now()
+	return s.repo.ListSuppressions(ctx, tenant.IDFromContext(ctx), filter)
+}
+
+// Unsubscribe бессрочно отписывает получателя тенанта от канала по ссылке из уведомления
+func (s *SuppressionService) Unsubscribe(ctx context.Context, tenantID, recipientID string, channel domain.Channel) error {
+	_, err := s.repo.UpsertSuppression(ctx, domain.Suppression{
+		TenantID:    tenantID,
+		RecipientID: recipientID,
+		Channel:     channel,
+		Reason:      domain.SuppressionUnsubscribed,
+		CreatedAt:   s.now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Ctx(ctx).
+		Str("tenant_id", tenantID).
+		Str("channel", string(channel)).
+		Msg("Recipient unsubscribed")
+
+	return nil
+}
+
+// IsSuppressed сообщает, что получатель тенанта находится в действующем списке подавления канала
+func (s *SuppressionService) IsSuppressed(ctx context.Context, tenantID, recipientID string, channel domain.Channel) (bool, error) {
+	suppression, err := s.repo.LoadSuppression(ctx, tenantID, recipientID, channel)
+	if errors.Is(err, domain.ErrSuppressionNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return suppression.IsActive(s.now()), nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/digest"
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/events"
+	"delayed-notifier/internal/sender"
+	"delayed-notifier/internal/tenant"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuppressionService_IsSuppressed(t *testing.T) {
+	now := time.Now()
+	suppressions := NewSuppressionService(&memorySuppressionRepository{})
+	suppressions.now = func() time.Time { return now }
+
+	ctx := tenant.WithID(context.Background(), "billing")
+	expiresAt := now.Add(time.Hour)
+	_, err := suppressions.Suppress(ctx, domain.Suppression{
+		RecipientID: "user@example.com",
+		Channel:     domain.ChannelEmail,
+		Reason:      domain.SuppressionBounced,
+		ExpiresAt:   &expiresAt,
+	})
+	require.NoError(t, err)
+
+	suppressed, err := suppressions.IsSuppressed(context.Background(), "billing", "user@example.com", domain.ChannelEmail)
+	require.NoError(t, err)
+	assert.True(t, suppressed)
+
+	suppressed, err = suppressions.IsSuppressed(context.Background(), "billing", "user@example.com", domain.ChannelTelegram)
+	require.NoError(t, err)
+	assert.False(t, suppressed, "suppression is per channel")
+
+	suppressed, err = suppressions.IsSuppressed(context.Background(), "crm", "user@example.com", domain.ChannelEmail)
+	require.NoError(t, err)
+	assert.False(t, suppressed, "suppression is per tenant")
+
+	now = expiresAt.Add(time.Second)
+	suppressed, err = suppressions.IsSuppressed(context.Background(), "billing", "user@example.com", domain.ChannelEmail)
+	require.NoError(t, err)
+	assert.False(t, suppressed, "expired suppression no longer applies")
+
+	_, err = suppressions.GetSuppression(ctx, "user@example.com", domain.ChannelEmail)
+	assert.ErrorIs(t, err, domain.ErrSuppressionNotFound)
+}
+
+func TestProcessNotification_Suppressed(t *testing.T) {
+	repo := &MockRepository{}
+	publisher := &MockPublisher{}
+	broker := events.NewBroker()
+
+	sub := broker.Subscribe("")
+	defer sub.Close()
+
+	suppressions := NewSuppressionService(&memorySuppressionRepository{})
+	require.NoError(t, suppressions.Unsubscribe(context.Background(), tenant.DefaultID, "user123", domain.ChannelTelegram))
+
+	service := NewNotifierService(repo, &MockCache{}, publisher, sender.NewFactory(nil, nil), broker, time.Hour)
+	service.SetSuppressions(suppressions)
+
+	notification := domain.Notification{
+		ID:               "suppressed-notification",
+		TenantID:         tenant.DefaultID,
+		Payload:          "Test message",
+		NotificationDate: time.Now().Add(-time.Minute),
+		RecipientID:      "user123",
+		Channel:          domain.ChannelTelegram,
+		Status:           domain.StatusPending,
+	}
+	require.NoError(t, repo.Store(context.Background(), notification))
+
+	require.NoError(t, service.ProcessTelegramNotification(context.Background(), notification))
+
+	stored, err := repo.LoadByID(context.Background(), notification.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusSuppressed, stored.Status)
+	assert.False(t, publisher.PublishDelayedCalled)
+
+	event := <-sub.C
+	assert.Equal(t, domain.StatusSuppressed, event.Status)
+	assert.True(t, event.IsFinal())
+
+	resent, err := service.ResendNotification(context.Background(), notification.ID)
+	require.NoError(t, err, "suppressed notification can be resent after unsubscribe is revoked")
+	assert.Equal(t, domain.StatusPending, resent.Status)
+}
+
+func TestDigestJob_SuppressedRecipient(t *testing.T) {
+	repo := &MockRepository{}
+	digests := &memoryDigestRepository{repo: repo, digests: make(map[string]domain.Digest)}
+	channelSender := &recordingSender{}
+	notifier := NewNotifierService(repo, &MockCache{}, &MockPublisher{}, sender.NewFactory(nil, nil), nil, time.Hour)
+
+	suppressions := NewSuppressionService(&memorySuppressionRepository{})
+	require.NoError(t, suppressions.Unsubscribe(context.Background(), tenant.DefaultID, "user123", domain.ChannelTelegram))
+	notifier.SetSuppressions(suppressions)
+
+	require.NoError(t, repo.Store(context.Background(), domain.Notification{
+		ID:               "first",
+		TenantID:         tenant.DefaultID,
+		Status:           domain.StatusPending,
+		NotificationDate: time.Now().Add(-time.Hour),
+		RecipientID:      "user123",
+		Channel:          domain.ChannelTelegram,
+		Digest:           true,
+	}))
+
+	renderer, err := digest.NewRenderer("")
+	require.NoError(t, err)
+
+	job := NewDigestJob(digests, notifier, staticSenders{channelSender}, renderer, config.DigestConfig{Enabled: true, Interval: time.Hour, MaxItems: 10})
+
+	run, err := job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, run.Failed)
+	assert.Equal(t, 1, run.Notifications)
+	assert.Empty(t, channelSender.payloads)
+
+	notification, err := repo.LoadByID(context.Background(), "first")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusSuppressed, notification.Status)
+}
+
+// memorySuppressionRepository хранит список подавления в памяти
+type memorySuppressionRepository struct {
+	suppressions []domain.Suppression
+}
+
+func (m *memorySuppressionRepository) find(tenantID, recipientID string, channel domain.Channel) int {
+	for i, s := range m.suppressions {
+		if s.TenantID == tenantID && s.RecipientID == recipientID && s.Channel == channel {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *memorySuppressionRepository) UpsertSuppression(ctx context.Context, suppression domain.Suppression) (*domain.Suppression, error) {
+	if i := m.find(suppression.TenantID, suppression.RecipientID, suppression.Channel); i >= 0 {
+		m.suppressions[i] = suppression
+	} else {
+		m.suppressions = append(m.suppressions, suppression)
+	}
+	return &suppression, nil
+}
+
+func (m *memorySuppressionRepository) DeleteSuppression(ctx context.Context, tenantID, recipientID string, channel domain.Channel) error {
+	i := m.find(tenantID, recipientID, channel)
+	if i < 0 {
+		return domain.ErrSuppressionNotFound
+	}
+	m.suppressions = append(m.suppressions[:i], m.suppressions[i+1:]...)
+	return nil
+}
+
+func (m *memorySuppressionRepository) LoadSuppression(ctx context.Context, tenantID, recipientID string, channel domain.Channel) (*domain.Suppression, error) {
+	i := m.find(tenantID, recipientID, channel)
+	if i < 0 {
+		return nil, domain.ErrSuppressionNotFound
+	}
+	suppression := m.suppressions[i]
+	return &suppression, nil
+}
+
+func (m *memorySuppressionRepository) ListSuppressions(ctx context.Context, tenantID string, filter domain.SuppressionFilter) (domain.SuppressionPage, error) {
+	page := domain.SuppressionPage{Suppressions: []domain.Suppression{}}
+	for _, s := range m.suppressions {
+		if s.TenantID == tenantID && s.IsActive(filter.ActiveAt) {
+			page.Suppressions = append(page.Suppressions, s)
+		}
+	}
+	page.Total = int64(len(page.Suppressions))
+	return page, nil
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/domain"
+	"delayed-notifier/internal/dto"
+	"delayed-notifier/internal/sender"
+	"delayed-notifier/internal/tenant"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateNotification_TenantDailyQuota(t *testing.T) {
+	repo := &MockRepository{}
+	cache := &MockCache{}
+	service := NewNotifierService(repo, cache, &MockPublisher{}, sender.NewFactory(nil, nil), nil, time.Hour)
+	service.SetQuotas(tenant.NewQuotas(map[string]config.TenantConfig{
+		"billing": {DailyLimit: 1},
+	}))
+
+	req := dto.CreateNotificationRequest{
+		Payload:          "Test message",
+		NotificationDate: time.Now().Add(time.Hour),
+		RecipientID:      "user123",
+		Channel:          domain.ChannelTelegram,
+	}
+	billing := tenant.WithID(context.Background(), "billing")
+
+	notification, err := service.CreateNotification(billing, req)
+	require.NoError(t, err)
+	assert.Equal(t, "billing", notification.TenantID)
+
+	cachedStatus, err := cache.Get(context.Background(), "billing:"+notification.ID)
+	require.NoError(t, err)
+	assert.Equal(t, string(domain.StatusPending), cachedStatus)
+
+	_, err = service.CreateNotification(billing, req)
+	assert.ErrorIs(t, err, domain.ErrQuotaExceeded)
+
+	other, err := service.CreateNotification(context.Background(), req)
+	require.NoError(t, err, "quota of one tenant does not limit others")
+	assert.Equal(t, tenant.DefaultID, other.TenantID)
+}
+
+func TestCreateNotification_TenantDailyQuotaConcurrent(t *testing.T) {
+	const limit, requests = 5, 50
+
+	repo := &MockRepository{}
+	service := NewNotifierService(repo, &MockCache{}, &MockPublisher{}, sender.NewFactory(nil, nil), nil, time.Hour)
+	service.SetQuotas(tenant.NewQuotas(map[string]config.TenantConfig{
+		"billing": {DailyLimit: limit},
+	}))
+
+	req := dto.CreateNotificationRequest{
+		Payload:          "Test message",
+		NotificationDate: time.Now().Add(time.Hour),
+		RecipientID:      "user123",
+		Channel:          domain.ChannelTelegram,
+	}
+	billing := tenant.WithID(context.Background(), "billing")
+
+	var created, exceeded atomic.Int64
+	var wg sync.WaitGroup
+	for range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.CreateNotification(billing, req)
+			switch {
+			case err == nil:
+				created.Add(1)
+			case errors.Is(err, domain.ErrQuotaExceeded):
+				exceeded.Add(1)
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(limit), created.Load())
+	assert.Equal(t, int64(requests-limit), exceeded.Load())
+	assert.Len(t, repo.notifications, limit)
+}
+
+func TestProcessEmail_DefaultConfigUsesTenantProfile(t *testing.T) {
+	port, messages := startSMTPServer(t)
+	tenantEmail, err := sender.NewEmailSender(dto.EmailConfig{
+		SMTPHost:  "localhost",
+		SMTPPort:  port,
+		Username:  "billing",
+		Password:  "secret",
+		FromEmail: "billing@example.com",
+	})
+	require.NoError(t, err)
+
+	// Общего email отправителя нет: письмо может уйти только через профиль тенанта
+	senderFactory := sender.NewFactory(nil, nil)
+	senderFactory.SetTenantProfile("billing", nil, tenantEmail)
+
+	repo := &MockRepository{}
+	service := NewNotifierService(repo, &MockCache{}, &MockPublisher{}, senderFactory, nil, time.Hour)
+
+	notification := domain.Notification{
+		ID:               "tenant-email",
+		TenantID:         "billing",
+		Payload:          "Invoice is ready",
+		NotificationDate: time.Now().Add(-time.Minute),
+		RecipientID:      "user@example.com",
+		Channel:          domain.ChannelEmail,
+		Status:           domain.StatusPending,
+	}
+	require.NoError(t, repo.Store(context.Background(), notification))
+
+	manager := &Manager{service: service}
+	require.NoError(t, manager.processEmailNotification(context.Background(), notification, map[string]interface{}{}, 1))
+
+	select {
+	case message := <-messages:
+		assert.Contains(t, message, "From: <billing@example.com>")
+	case <-time.After(5 * time.Second):
+		t.Fatal("email was not sent through the tenant profile")
+	}
+
+	stored, err := repo.LoadByID(context.Background(), notification.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusSent, stored.Status)
+}
+
+type staticUnsubscribeLinks string
+
+func (l staticUnsubscribeLinks) URL(tenantID, recipientID string, channel domain.Channel) string {
+	return string(l) + "?tenant=" + tenantID + "&channel=" + string(channel)
+}
+
+func TestProcessEmail_UnsubscribeLink(t *testing.T) {
+	port, messages := startSMTPServer(t)
+	links := staticUnsubscribeLinks("https://notifier.example.com/unsubscribe")
+
+	tenantEmail, err := sender.NewEmailSender(dto.EmailConfig{
+		SMTPHost:  "localhost",
+		SMTPPort:  port,
+		Username:  "billing",
+		Password:  "secret",
+		FromEmail: "billing@example.com",
+	})
+	require.NoError(t, err)
+	tenantEmail.SetUnsubscribeLinks(links)
+
+	senderFactory := sender.NewFactory(nil, nil)
+	senderFactory.SetTenantProfile("billing", nil, tenantEmail)
+	senderFactory.SetUnsubscribeLinks(links)
+
+	repo := &MockRepository{}
+	service := NewNotifierService(repo, &MockCache{}, &MockPublisher{}, senderFactory, nil, time.Hour)
+	manager := &Manager{service: service}
+
+	customConfig := map[string]interface{}{
+		"email_config": map[string]interface{}{
+			"smtp_host":  "localhost",
+			"smtp_port":  port,
+			"username":   "custom",
+			"password":   "secret",
+			"from_email": "custom@example.com",
+		},
+	}
+	for name, messageData := range map[string]map[string]interface{}{
+		"default config": {},
+		"custom config":  customConfig,
+	} {
+		t.Run(name, func(t *testing.T) {
+			notification := domain.Notification{
+				ID:               "unsubscribe-" + strings.ReplaceAll(name, " ", "-"),
+				TenantID:         "billing",
+				Payload:          "Invoice is ready",
+				NotificationDate: time.Now().Add(-time.Minute),
+				RecipientID:      "user@example.com",
+				Channel:          domain.ChannelEmail,
+				Status:           domain.StatusPending,
+			}
+			require.NoError(t, repo.Store(context.Background(), notification))
+			require.NoError(t, manager.processEmailNotification(context.Background(), notification, messageData, 1))
+
+			var message string
+			select {
+			case message = <-messages:
+			case <-time.After(5 * time.Second):
+				t.Fatal("email was not sent")
+			}
+
+			link := "https://notifier.example.com/unsubscribe?tenant=billing&channel=email"
+			assert.Contains(t, message, "List-Unsubscribe: <"+link+">")
+			body, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(message)))
+			require.NoError(t, err)
+			assert.Contains(t, string(body), "To stop receiving these notifications, unsubscribe: "+link)
+		})
+	}
+}
+
+// startSMTPServer запускает SMTP сервер, который принимает любые письма и возвращает их через канал
+func startSMTPServer(t *testing.T) (int, <-chan string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, messages)
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port, messages
+}
+
+func serveSMTP(conn net.Conn, messages chan<- string) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	fmt.Fprint(conn, "220 localhost ESMTP\r\n")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		switch command := strings.ToUpper(strings.TrimSpace(line)); {
+		case strings.HasPrefix(command, "EHLO"):
+			fmt.Fprint(conn, "250-localhost\r\n250 AUTH PLAIN\r\n")
+		case strings.HasPrefix(command, "AUTH"):
+			fmt.Fprint(conn, "235 Authentication successful\r\n")
+		case command == "DATA":
+			fmt.Fprint(conn, "354 End data with <CR><LF>.<CR><LF>\r\n")
+			var message strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				message.WriteString(line)
+			}
+			messages <- message.String()
+			fmt.Fprint(conn, "250 OK\r\n")
+		case command == "QUIT":
+			fmt.Fprint(conn, "221 Bye\r\n")
+			return
+		default:
+			fmt.Fprint(conn, "250 OK\r\n")
+		}
+	}
+}
+
+func TestProcessNotification_TenantSendsThrottled(t *testing.T) {
+	repo := &MockRepository{}
+	publisher := &MockPublisher{}
+	quotas := tenant.NewQuotas(map[string]config.TenantConfig{
+		"billing": {MaxConcurrentSends: 1},
+	})
+	service := NewNotifierService(repo, &MockCache{}, publisher, sender.NewFactory(nil, nil), nil, time.Hour)
+	service.SetQuotas(quotas)
+
+	notification := domain.Notification{
+		ID:               "throttled-notification",
+		TenantID:         "billing",
+		Payload:          "Test message",
+		NotificationDate: time.Now().Add(-time.Minute),
+		RecipientID:      "user123",
+		Channel:          domain.ChannelTelegram,
+		Status:           domain.StatusPending,
+	}
+	require.NoError(t, repo.Store(context.Background(), notification))
+
+	release, ok := quotas.AcquireSend("billing")
+	require.True(t, ok)
+	defer release()
+
+	err := service.ProcessTelegramNotification(context.Background(), notification)
+	require.NoError(t, err)
+
+	assert.True(t, publisher.PublishDelayedCalled)
+	assert.Equal(t, tenantThrottleDelay, publisher.LastDelay)
+
+	var message map[string]interface{}
+	require.NoError(t, json.Unmarshal(publisher.LastBody, &message))
+	assert.Equal(t, "billing", message["tenant_id"])
+
+	stored, err := repo.LoadByID(context.Background(), notification.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusPending, stored.Status)
+}
@@ -5,36 +5,45 @@ import (
 	"delayed-notifier/internal/config"
 	"delayed-notifier/internal/domain"
 	"delayed-notifier/internal/dto"
+	"delayed-notifier/internal/queue"
+	"delayed-notifier/internal/tenant"
+	"delayed-notifier/internal/tracing"
 	"encoding/json"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
-	"github.com/wb-go/wbf/rabbitmq"
 	"github.com/wb-go/wbf/retry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Manager управляет фоновыми воркерами для обработки уведомлений
 type Manager struct {
-	consumer    *rabbitmq.Consumer
+	consumer    *queue.Consumer
 	service     *NotifierService
 	workerCount int
-	msgChan     chan []byte
+	msgChan     chan queue.Message
 	done        chan struct{}
 	wg          sync.WaitGroup
 	ctx         context.Context
 	cancel      context.CancelFunc
 	config      config.WorkerConfig
+
+	mu      sync.Mutex
+	workers []chan struct{}
+	nextID  int
+	stopped bool
 }
 
 // NewManager создает новый менеджер воркеров
-func NewManager(ctx context.Context, cancel context.CancelFunc, consumer *rabbitmq.Consumer, service *NotifierService, workerConfig config.WorkerConfig) *Manager {
+func NewManager(ctx context.Context, cancel context.CancelFunc, consumer *queue.Consumer, service *NotifierService, workerConfig config.WorkerConfig) *Manager {
 
 	return &Manager{
 		consumer:    consumer,
 		service:     service,
 		workerCount: workerConfig.Count,
-		msgChan:     make(chan []byte),
+		msgChan:     make(chan queue.Message),
 		done:        make(chan struct{}),
 		ctx:         ctx,
 		cancel:      cancel,
@@ -46,19 +55,67 @@ func NewManager(ctx context.Context, cancel context.CancelFunc, consumer *rabbit
 func (m *Manager) Start() error {
 	go m.startConsumer()
 
-	for i := 0; i < m.workerCount; i++ {
-		m.wg.Add(1)
-		go m.worker(i)
-	}
+	m.mu.Lock()
+	m.resize(m.workerCount)
+	m.mu.Unlock()
 
 	log.Info().Int("workers", m.workerCount).Msg("Started notification workers")
 	return nil
 }
 
+// Resize изменяет число воркеров. Лишние воркеры завершаются после обработки
+// текущего сообщения, поэтому уменьшение пула не прерывает начатую работу
+func (m *Manager) Resize(count int) {
+	if count <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopped {
+		return
+	}
+
+	previous := len(m.workers)
+	m.resize(count)
+	m.workerCount = count
+
+	log.Info().Int("from", previous).Int("to", count).Msg("Resized notification worker pool")
+}
+
+// WorkerCount возвращает текущее число воркеров
+func (m *Manager) WorkerCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.workers)
+}
+
+// resize запускает или останавливает воркеров до count, вызывается под m.mu
+func (m *Manager) resize(count int) {
+	for len(m.workers) < count {
+		stop := make(chan struct{})
+		m.workers = append(m.workers, stop)
+		m.wg.Add(1)
+		go m.worker(m.nextID, stop)
+		m.nextID++
+	}
+
+	for len(m.workers) > count {
+		last := len(m.workers) - 1
+		close(m.workers[last])
+		m.workers = m.workers[:last]
+	}
+}
+
 // Stop останавливает менеджер воркеров
 func (m *Manager) Stop() error {
 	log.Info().Msg("Stopping notification workers...")
 
+	m.mu.Lock()
+	m.stopped = true
+	m.mu.Unlock()
+
 	m.cancel()
 
 	select {
@@ -102,7 +159,7 @@ func (m *Manager) startConsumer() {
 	}
 }
 
-func (m *Manager) worker(id int) {
+func (m *Manager) worker(id int, stop <-chan struct{}) {
 	defer m.wg.Done()
 
 	log.Debug().Int("worker_id", id).Msg("Worker started")
@@ -112,6 +169,9 @@ func (m *Manager) worker(id int) {
 		case <-m.ctx.Done():
 			log.Debug().Int("worker_id", id).Msg("Worker stopped")
 			return
+		case <-stop:
+			log.Debug().Int("worker_id", id).Msg("Worker stopped by pool resize")
+			return
 		case message, ok := <-m.msgChan:
 			if !ok {
 				log.Debug().Int("worker_id", id).Msg("Message channel closed")
@@ -123,15 +183,29 @@ func (m *Manager) worker(id int) {
 	}
 }
 
-func (m *Manager) processMessage(workerID int, message []byte) {
+func (m *Manager) processMessage(workerID int, message queue.Message) {
+	ctx := tracing.ExtractAMQP(m.ctx, message.Headers)
+	ctx, span := tracing.Tracer().Start(ctx, "Manager.processMessage",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.operation.type", "process"),
+			attribute.Int("worker.id", workerID),
+		),
+	)
+	var processErr error
+	defer func() { tracing.End(span, processErr) }()
+
 	log.Info().
+		Ctx(ctx).
 		Int("worker_id", workerID).
-		Str("message", string(message)).
+		Str("message", string(message.Body)).
 		Msg("Processing message in worker")
 
 	var messageData map[string]interface{}
-	if err := json.Unmarshal(message, &messageData); err != nil {
-		log.Error().Err(err).Int("worker_id", workerID).Msg("Failed to unmarshal message")
+	if err := json.Unmarshal(message.Body, &messageData); err != nil {
+		log.Error().Ctx(ctx).Err(err).Int("worker_id", workerID).Msg("Failed to unmarshal message")
+		processErr = err
 		return
 	}
 
@@ -145,17 +219,34 @@ func (m *Manager) processMessage(workerID int, message []byte) {
 		Channel:          domain.Channel(messageData["channel"].(string)),
 		Retries:          int(messageData["retries"].(float64)),
 	}
+	if expiresAt, ok := messageData["expires_at"]; ok {
+		parsed := parseTime(expiresAt)
+		notification.ExpiresAt = &parsed
+	}
+	if digest, ok := messageData["digest"].(bool); ok {
+		notification.Digest = digest
+	}
+	notification.TenantID = tenant.DefaultID
+	if tenantID, ok := messageData["tenant_id"].(string); ok && tenantID != "" {
+		notification.TenantID = tenantID
+	}
 
-	var processErr error
+	span.SetAttributes(
+		attribute.String("notification.id", notification.ID),
+		attribute.String("notification.channel", string(notification.Channel)),
+		attribute.Int("notification.retries", notification.Retries),
+		attribute.String("tenant.id", notification.TenantID),
+	)
 
 	if notification.Channel == domain.ChannelEmail {
-		processErr = m.processEmailNotification(notification, messageData, workerID)
+		processErr = m.processEmailNotification(ctx, notification, messageData, workerID)
 	} else {
-		processErr = m.processTelegramNotification(notification, workerID)
+		processErr = m.processTelegramNotification(ctx, notification, workerID)
 	}
 
 	if processErr != nil {
 		log.Warn().
+			Ctx(ctx).
 			Err(processErr).
 			Str("id", notification.ID).
 			Int("worker_id", workerID).
@@ -176,8 +267,9 @@ func parseTime(timeData interface{}) time.Time {
 }
 
 // processEmailNotification обрабатывает email уведомления с проверкой кастомной конфигурации
-func (m *Manager) processEmailNotification(notification domain.Notification, messageData map[string]interface{}, workerID int) error {
+func (m *Manager) processEmailNotification(ctx context.Context, notification domain.Notification, messageData map[string]interface{}, workerID int) error {
 	log.Debug().
+		Ctx(ctx).
 		Str("id", notification.ID).
 		Int("worker_id", workerID).
 		Interface("message_data", messageData).
@@ -185,26 +277,29 @@ func (m *Manager) processEmailNotification(notification domain.Notification, mes
 
 	if emailConfigData, exists := messageData["email_config"]; exists {
 		log.Debug().
+			Ctx(ctx).
 			Str("id", notification.ID).
 			Int("worker_id", workerID).
 			Interface("email_config", emailConfigData).
 			Msg("Found custom email config")
-		return m.processEmailWithCustomConfig(notification, emailConfigData, workerID)
+		return m.processEmailWithCustomConfig(ctx, notification, emailConfigData, workerID)
 	}
 
 	log.Debug().
+		Ctx(ctx).
 		Str("id", notification.ID).
 		Int("worker_id", workerID).
 		Msg("No custom email config found, using default")
-	return m.processEmailWithDefaultConfig(notification, workerID)
+	return m.processEmailWithDefaultConfig(ctx, notification, workerID)
 }
 
 // processEmailWithCustomConfig обрабатывает email с кастомной конфигурацией
-func (m *Manager) processEmailWithCustomConfig(notification domain.Notification, emailConfigData interface{}, workerID int) error {
+func (m *Manager) processEmailWithCustomConfig(ctx context.Context, notification domain.Notification, emailConfigData interface{}, workerID int) error {
 	var emailConfig dto.EmailConfig
 	emailConfigBytes, err := json.Marshal(emailConfigData)
 	if err != nil {
 		log.Error().
+			Ctx(ctx).
 			Err(err).
 			Str("id", notification.ID).
 			Int("worker_id", workerID).
@@ -214,6 +309,7 @@ func (m *Manager) processEmailWithCustomConfig(notification domain.Notification,
 
 	if err := json.Unmarshal(emailConfigBytes, &emailConfig); err != nil {
 		log.Error().
+			Ctx(ctx).
 			Err(err).
 			Str("id", notification.ID).
 			Int("worker_id", workerID).
@@ -221,29 +317,31 @@ func (m *Manager) processEmailWithCustomConfig(notification domain.Notification,
 		return err
 	}
 
-	processErr := m.service.ProcessEmailNotification(m.ctx, notification, emailConfig)
-	m.logEmailProcessingResult(processErr, notification, workerID, "custom email config")
+	processErr := m.service.ProcessEmailNotification(ctx, notification, emailConfig)
+	m.logEmailProcessingResult(ctx, processErr, notification, workerID, "custom email config")
 	return processErr
 }
 
-// processEmailWithDefaultConfig обрабатывает email с дефолтной конфигурацией
-func (m *Manager) processEmailWithDefaultConfig(notification domain.Notification, workerID int) error {
-	processErr := m.service.ProcessEmailNotification(m.ctx, notification, dto.EmailConfig{})
-	m.logEmailProcessingResult(processErr, notification, workerID, "default email config")
+// processEmailWithDefaultConfig обрабатывает email с дефолтной конфигурацией:
+// письмо отправляется через профиль тенанта или общий email отправитель
+func (m *Manager) processEmailWithDefaultConfig(ctx context.Context, notification domain.Notification, workerID int) error {
+	processErr := m.service.processNotification(ctx, notification, nil)
+	m.logEmailProcessingResult(ctx, processErr, notification, workerID, "default email config")
 	return processErr
 }
 
 // processTelegramNotification обрабатывает telegram уведомления
-func (m *Manager) processTelegramNotification(notification domain.Notification, workerID int) error {
-	processErr := m.service.ProcessTelegramNotification(m.ctx, notification)
-	m.logTelegramProcessingResult(processErr, notification, workerID)
+func (m *Manager) processTelegramNotification(ctx context.Context, notification domain.Notification, workerID int) error {
+	processErr := m.service.ProcessTelegramNotification(ctx, notification)
+	m.logTelegramProcessingResult(ctx, processErr, notification, workerID)
 	return processErr
 }
 
 // logEmailProcessingResult логирует результат обработки email уведомления
-func (m *Manager) logEmailProcessingResult(processErr error, notification domain.Notification, workerID int, configType string) {
+func (m *Manager) logEmailProcessingResult(ctx context.Context, processErr error, notification domain.Notification, workerID int, configType string) {
 	if processErr != nil {
 		log.Error().
+			Ctx(ctx).
 			Err(processErr).
 			Str("id", notification.ID).
 			Int("worker_id", workerID).
@@ -251,6 +349,7 @@ func (m *Manager) logEmailProcessingResult(processErr error, notification domain
 			Msg("Failed to process notification with email config")
 	} else {
 		log.Debug().
+			Ctx(ctx).
 			Str("id", notification.ID).
 			Int("worker_id", workerID).
 			Str("config_type", configType).
@@ -259,15 +358,17 @@ func (m *Manager) logEmailProcessingResult(processErr error, notification domain
 }
 
 // logTelegramProcessingResult логирует результат обработки telegram уведомления
-func (m *Manager) logTelegramProcessingResult(processErr error, notification domain.Notification, workerID int) {
+func (m *Manager) logTelegramProcessingResult(ctx context.Context, processErr error, notification domain.Notification, workerID int) {
 	if processErr != nil {
 		log.Error().
+			Ctx(ctx).
 			Err(processErr).
 			Str("id", notification.ID).
 			Int("worker_id", workerID).
 			Msg("Failed to process notification")
 	} else {
 		log.Debug().
+			Ctx(ctx).
 			Str("id", notification.ID).
 			Int("worker_id", workerID).
 			Msg("Notification processed successfully")
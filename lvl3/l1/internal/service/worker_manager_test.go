@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"delayed-notifier/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Resize(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	manager := NewManager(ctx, cancel, nil, nil, config.WorkerConfig{Count: 2})
+
+	manager.Resize(4)
+	assert.Equal(t, 4, manager.WorkerCount())
+
+	manager.Resize(1)
+	assert.Equal(t, 1, manager.WorkerCount())
+
+	manager.Resize(0)
+	assert.Equal(t, 1, manager.WorkerCount(), "pool cannot be resized to zero")
+
+	require.NoError(t, manager.Stop())
+	manager.Wait()
+
+	manager.Resize(3)
+	assert.Equal(t, 1, manager.WorkerCount(), "stopped manager is not resized")
+}
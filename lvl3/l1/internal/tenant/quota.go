@@ -0,0 +1,63 @@
+package tenant
+
+import (
+	"sync"
+
+	"delayed-notifier/internal/config"
+)
+
+// Quotas хранит квоты тенантов и ограничивает число одновременных отправок
+type Quotas struct {
+	configs map[string]config.TenantConfig
+
+	mu    sync.Mutex
+	sends map[string]int
+}
+
+// NewQuotas создает квоты из конфигурации тенантов.
+// Тенант без конфигурации или с нулевым значением не ограничен
+func NewQuotas(tenants map[string]config.TenantConfig) *Quotas {
+	return &Quotas{
+		configs: tenants,
+		sends:   make(map[string]int),
+	}
+}
+
+// DailyLimit возвращает лимит уведомлений тенанта в сутки, 0 — без ограничения
+func (q *Quotas) DailyLimit(id string) int {
+	if q == nil {
+		return 0
+	}
+	return q.configs[id].DailyLimit
+}
+
+// AcquireSend занимает слот одновременной отправки тенанта.
+// Возвращает false, если все слоты заняты; занятый слот освобождается вызовом release.
+// Слоты хранятся в памяти процесса, поэтому лимит действует на каждую реплику отдельно
+func (q *Quotas) AcquireSend(id string) (release func(), ok bool) {
+	if q == nil {
+		return func() {}, true
+	}
+
+	limit := q.configs[id].MaxConcurrentSends
+	if limit <= 0 {
+		return func() {}, true
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.sends[id] >= limit {
+		return nil, false
+	}
+	q.sends[id]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			q.sends[id]--
+			q.mu.Unlock()
+		})
+	}, true
+}
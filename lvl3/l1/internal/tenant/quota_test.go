@@ -0,0 +1,62 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"delayed-notifier/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotas_AcquireSend(t *testing.T) {
+	quotas := NewQuotas(map[string]config.TenantConfig{
+		"billing": {MaxConcurrentSends: 2},
+	})
+
+	first, ok := quotas.AcquireSend("billing")
+	require.True(t, ok)
+	second, ok := quotas.AcquireSend("billing")
+	require.True(t, ok)
+
+	_, ok = quotas.AcquireSend("billing")
+	assert.False(t, ok)
+
+	_, ok = quotas.AcquireSend("crm")
+	assert.True(t, ok, "tenant without quota is not limited")
+
+	first()
+	first()
+	third, ok := quotas.AcquireSend("billing")
+	assert.True(t, ok)
+	_, ok = quotas.AcquireSend("billing")
+	assert.False(t, ok, "release is idempotent")
+
+	second()
+	third()
+}
+
+func TestQuotas_Nil(t *testing.T) {
+	var quotas *Quotas
+
+	assert.Equal(t, 0, quotas.DailyLimit("billing"))
+	release, ok := quotas.AcquireSend("billing")
+	assert.True(t, ok)
+	release()
+}
+
+func TestFromContext(t *testing.T) {
+	ctx := context.Background()
+
+	_, ok := FromContext(ctx)
+	assert.False(t, ok)
+	assert.Equal(t, DefaultID, IDFromContext(ctx))
+	assert.Equal(t, "", Scope(ctx))
+
+	ctx = WithID(ctx, "billing")
+	id, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "billing", id)
+	assert.Equal(t, "billing", Scope(ctx))
+}
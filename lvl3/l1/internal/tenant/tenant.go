@@ -0,0 +1,36 @@
+// Package tenant содержит идентификацию тенантов и их квоты
+package tenant
+
+import "context"
+
+// DefaultID идентификатор тенанта для запросов без аутентификации
+const DefaultID = "default"
+
+type idKey struct{}
+
+// WithID сохраняет идентификатор тенанта в контексте
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// FromContext возвращает идентификатор тенанта из контекста.
+// Контекст без тенанта означает системный вызов без ограничения области видимости
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(idKey{}).(string)
+	return id, ok && id != ""
+}
+
+// IDFromContext возвращает идентификатор тенанта из контекста или DefaultID
+func IDFromContext(ctx context.Context) string {
+	if id, ok := FromContext(ctx); ok {
+		return id
+	}
+	return DefaultID
+}
+
+// Scope возвращает тенанта, которым ограничиваются запросы к хранилищу,
+// или пустую строку, если ограничение не требуется
+func Scope(ctx context.Context) string {
+	id, _ := FromContext(ctx)
+	return id
+}
@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// AMQPHeadersCarrier адаптирует заголовки AMQP сообщения к propagation.TextMapCarrier
+type AMQPHeadersCarrier amqp091.Table
+
+var _ propagation.TextMapCarrier = AMQPHeadersCarrier{}
+
+// Get возвращает значение заголовка или пустую строку
+func (c AMQPHeadersCarrier) Get(key string) string {
+	value, ok := c[key].(string)
+	if !ok {
+		return ""
+	}
+	return value
+}
+
+// Set записывает значение заголовка
+func (c AMQPHeadersCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+// Keys возвращает имена всех заголовков
+func (c AMQPHeadersCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// InjectAMQP записывает контекст трассировки из ctx в заголовки сообщения
+func InjectAMQP(ctx context.Context, headers amqp091.Table) {
+	otel.GetTextMapPropagator().Inject(ctx, AMQPHeadersCarrier(headers))
+}
+
+// ExtractAMQP восстанавливает контекст трассировки из заголовков сообщения
+func ExtractAMQP(ctx context.Context, headers amqp091.Table) context.Context {
+	if headers == nil {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, AMQPHeadersCarrier(headers))
+}
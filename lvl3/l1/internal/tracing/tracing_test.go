@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"delayed-notifier/internal/config"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetup(t *testing.T) {
+	previousProvider := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previousProvider) })
+
+	for _, exporter := range []string{config.TracingExporterNone, config.TracingExporterStdout} {
+		shutdown, err := Setup(context.Background(), config.TracingConfig{
+			Exporter:    exporter,
+			ServiceName: "test",
+			SampleRatio: 1,
+		})
+		require.NoError(t, err, exporter)
+
+		_, span := Tracer().Start(context.Background(), "test")
+		assert.True(t, span.SpanContext().IsValid(), exporter)
+		span.End()
+
+		require.NoError(t, shutdown(context.Background()), exporter)
+	}
+}
+
+func TestAMQPPropagation(t *testing.T) {
+	useTestProvider(t)
+
+	ctx, span := Tracer().Start(context.Background(), "publish")
+	defer span.End()
+
+	headers := amqp091.Table{"x-delay": int64(1000)}
+	InjectAMQP(ctx, headers)
+
+	assert.Contains(t, headers, "traceparent")
+	assert.Equal(t, int64(1000), headers["x-delay"])
+
+	restored := trace.SpanContextFromContext(ExtractAMQP(context.Background(), headers))
+	assert.True(t, restored.IsRemote())
+	assert.Equal(t, span.SpanContext().TraceID(), restored.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), restored.SpanID())
+
+	empty := ExtractAMQP(context.Background(), nil)
+	assert.False(t, trace.SpanContextFromContext(empty).IsValid())
+}
+
+func TestLogHook(t *testing.T) {
+	useTestProvider(t)
+
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf).Hook(LogHook{})
+
+	ctx, span := Tracer().Start(context.Background(), "handler")
+	defer span.End()
+
+	logger.Info().Ctx(ctx).Msg("with span")
+
+	var entry map[string]string
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, span.SpanContext().TraceID().String(), entry["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), entry["span_id"])
+
+	buf.Reset()
+	logger.Info().Msg("without context")
+
+	entry = nil
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.NotContains(t, entry, "trace_id")
+}
+
+func useTestProvider(t *testing.T) {
+	t.Helper()
+
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+}
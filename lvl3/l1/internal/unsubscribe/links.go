@@ -0,0 +1,106 @@
+package unsubscribe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+
+	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/domain"
+)
+
+// ErrInvalidToken возвращается, когда токен отписки поврежден или подписан другим ключом
+var ErrInvalidToken = errors.New("invalid unsubscribe token")
+
+// Subject описывает получателя, которого отписывает токен
+type Subject struct {
+	TenantID    string         `json:"t"`
+	RecipientID string         `json:"r"`
+	Channel     domain.Channel `json:"c"`
+}
+
+// Links формирует и проверяет подписанные ссылки отписки.
+// Токен состоит из base64url JSON получателя и base64url HMAC-SHA256 подписи,
+// разделенных точкой, поэтому не требует хранения на сервере
+type Links struct {
+	baseURL string
+	secret  []byte
+}
+
+// NewLinks создает ссылки отписки из конфигурации.
+// Возвращает nil, если адрес страницы отписки не задан
+func NewLinks(cfg config.SuppressionConfig) *Links {
+	if cfg.UnsubscribeURL == "" {
+		return nil
+	}
+
+	return &Links{
+		baseURL: cfg.UnsubscribeURL,
+		secret:  []byte(cfg.UnsubscribeSecret),
+	}
+}
+
+// URL возвращает ссылку отписки получателя тенанта от канала.
+// Для отключенных ссылок возвращает пустую строку
+func (l *Links) URL(tenantID, recipientID string, channel domain.Channel) string {
+	if l == nil {
+		return ""
+	}
+
+	token := l.Token(Subject{TenantID: tenantID, RecipientID: recipientID, Channel: channel})
+
+	separator := "?"
+	if strings.Contains(l.baseURL, "?") {
+		separator = "&"
+	}
+	return l.baseURL + separator + "token=" + url.QueryEscape(token)
+}
+
+// Token подписывает получателя и возвращает токен отписки
+func (l *Links) Token(subject Subject) string {
+	payload, _ := json.Marshal(subject)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(l.sign(encoded))
+}
+
+// Verify проверяет подпись токена и возвращает получателя
+func (l *Links) Verify(token string) (Subject, error) {
+	if l == nil {
+		return Subject{}, ErrInvalidToken
+	}
+
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return Subject{}, ErrInvalidToken
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, l.sign(encoded)) {
+		return Subject{}, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Subject{}, ErrInvalidToken
+	}
+
+	var subject Subject
+	if err := json.Unmarshal(payload, &subject); err != nil {
+		return Subject{}, ErrInvalidToken
+	}
+	if subject.TenantID == "" || subject.RecipientID == "" || subject.Channel == "" {
+		return Subject{}, ErrInvalidToken
+	}
+
+	return subject, nil
+}
+
+func (l *Links) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
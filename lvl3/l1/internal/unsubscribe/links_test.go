@@ -0,0 +1,64 @@
+package unsubscribe
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"delayed-notifier/internal/config"
+	"delayed-notifier/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLinks(secret string) *Links {
+	return NewLinks(config.SuppressionConfig{
+		UnsubscribeURL:    "https://notifier.example.com/api/v1/unsubscribe",
+		UnsubscribeSecret: secret,
+	})
+}
+
+func TestLinks_URLRoundTrip(t *testing.T) {
+	links := testLinks("secret")
+
+	link := links.URL("billing", "user@example.com", domain.ChannelEmail)
+	require.True(t, strings.HasPrefix(link, "https://notifier.example.com/api/v1/unsubscribe?token="))
+
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+
+	subject, err := links.Verify(parsed.Query().Get("token"))
+	require.NoError(t, err)
+	assert.Equal(t, Subject{TenantID: "billing", RecipientID: "user@example.com", Channel: domain.ChannelEmail}, subject)
+}
+
+func TestLinks_VerifyRejectsTampering(t *testing.T) {
+	links := testLinks("secret")
+	token := links.Token(Subject{TenantID: "billing", RecipientID: "user@example.com", Channel: domain.ChannelEmail})
+
+	forged := testLinks("other").Token(Subject{TenantID: "billing", RecipientID: "user@example.com", Channel: domain.ChannelEmail})
+	payload, signature, _ := strings.Cut(token, ".")
+	otherPayload, _, _ := strings.Cut(links.Token(Subject{TenantID: "billing", RecipientID: "other@example.com", Channel: domain.ChannelEmail}), ".")
+
+	for name, candidate := range map[string]string{
+		"other secret":     forged,
+		"swapped payload":  otherPayload + "." + signature,
+		"missing dot":      payload + signature,
+		"empty":            "",
+		"broken signature": payload + ".!!!",
+	} {
+		_, err := links.Verify(candidate)
+		assert.ErrorIs(t, err, ErrInvalidToken, name)
+	}
+}
+
+func TestLinks_Disabled(t *testing.T) {
+	links := NewLinks(config.SuppressionConfig{})
+
+	assert.Nil(t, links)
+	assert.Empty(t, links.URL("billing", "user@example.com", domain.ChannelEmail))
+
+	_, err := links.Verify("token")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
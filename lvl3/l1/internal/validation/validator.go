@@ -24,6 +24,28 @@ var (
 	ErrEmptyNotificationID = errors.New("notification_id cannot be empty")
 	// ErrInvalidEmail возвращается, когда формат email неверный
 	ErrInvalidEmail = errors.New("invalid email format")
+	// ErrExpiryConflict возвращается, когда одновременно заданы expires_at и max_lateness
+	ErrExpiryConflict = errors.New("expires_at and max_lateness are mutually exclusive")
+	// ErrInvalidMaxLateness возвращается, когда max_lateness не является положительной длительностью
+	ErrInvalidMaxLateness = errors.New("max_lateness must be a positive duration, e.g. 15m")
+	// ErrExpiresBeforeDate возвращается, когда expires_at не позже notification_date
+	ErrExpiresBeforeDate = errors.New("expires_at must be after notification_date")
+	// ErrDateAfterExpiry возвращается, когда новая дата отправки не раньше срока актуальности уведомления
+	ErrDateAfterExpiry = errors.New("notification_date must be before expires_at")
+	// ErrInvalidStatus возвращается, когда статус уведомления не поддерживается
+	ErrInvalidStatus = errors.New("invalid status")
+	// ErrInvalidLimit возвращается, когда размер страницы вне допустимого диапазона
+	ErrInvalidLimit = errors.New("limit must be between 1 and 100")
+	// ErrInvalidOffset возвращается, когда смещение страницы отрицательное
+	ErrInvalidOffset = errors.New("offset must be a non-negative integer")
+	// ErrEmptySuppressionReason возвращается, когда причина подавления не задана
+	ErrEmptySuppressionReason = errors.New("reason cannot be empty")
+	// ErrInvalidSuppressionReason возвращается, когда причина подавления не поддерживается
+	ErrInvalidSuppressionReason = errors.New("invalid suppression reason")
+	// ErrPastExpiry возвращается, когда срок подавления уже истек
+	ErrPastExpiry = errors.New("expires_at cannot be in the past")
+	// ErrDigestEmailConfig возвращается, когда для уведомления дайджеста задана email_config
+	ErrDigestEmailConfig = errors.New("email_config is not supported for digest notifications")
 )
 
 // Validator обрабатывает валидацию запросов уведомлений
@@ -34,44 +56,200 @@ func NewValidator() *Validator {
 	return &Validator{}
 }
 
-// ValidateCreateNotificationRequest валидирует запрос на создание уведомления
+// MaxListLimit ограничивает размер страницы при выборке уведомлений
+const MaxListLimit = 100
+
+// Коды нарушений валидации, возвращаемые клиенту в поле details[].code
+const (
+	CodeRequired      = "required"
+	CodeInvalidValue  = "invalid_value"
+	CodeInvalidFormat = "invalid_format"
+	CodePastDate      = "past_date"
+	CodeConflict      = "conflict"
+)
+
+// ValidateCreateNotificationRequest валидирует запрос на создание уведомления.
+// Возвращает *domain.ValidationError со всеми найденными нарушениями
 func (v *Validator) ValidateCreateNotificationRequest(req *dto.CreateNotificationRequest) error {
+	validationErr := domain.NewValidationError()
+
 	if strings.TrimSpace(req.Payload) == "" {
-		return ErrEmptyPayload
+		validationErr.Add("payload", CodeRequired, ErrEmptyPayload)
 	}
 
-	if strings.TrimSpace(req.RecipientID) == "" {
-		return ErrEmptyRecipient
+	recipient := strings.TrimSpace(req.RecipientID)
+	if recipient == "" {
+		validationErr.Add("recipient_id", CodeRequired, ErrEmptyRecipient)
 	}
 
 	if !v.isValidChannel(req.Channel) {
-		return ErrInvalidChannel
+		validationErr.Add("channel", CodeInvalidValue, ErrInvalidChannel)
+	} else if req.Channel == domain.ChannelEmail && recipient != "" && !v.isValidEmail(recipient) {
+		validationErr.Add("recipient_id", CodeInvalidFormat, ErrInvalidEmail)
 	}
 
-	if req.Channel == domain.ChannelEmail && !v.isValidEmail(strings.TrimSpace(req.RecipientID)) {
-		return ErrInvalidEmail
+	if req.NotificationDate.Before(time.Now()) {
+		validationErr.Add("notification_date", CodePastDate, ErrPastDate)
 	}
 
-	if req.NotificationDate.Before(time.Now()) {
-		return ErrPastDate
+	v.validateExpiry(req, validationErr)
+
+	if req.Digest && req.EmailConfig != nil {
+		validationErr.Add("email_config", CodeConflict, ErrDigestEmailConfig)
 	}
 
+	if validationErr.HasViolations() {
+		return validationErr
+	}
 	return nil
 }
 
 // ValidateNotificationID валидирует формат ID уведомления
 func (v *Validator) ValidateNotificationID(id string) error {
+	validationErr := domain.NewValidationError()
+
 	if strings.TrimSpace(id) == "" {
-		return ErrEmptyNotificationID
+		validationErr.Add("id", CodeRequired, ErrEmptyNotificationID)
+	} else if !v.isValidUUID(id) {
+		validationErr.Add("id", CodeInvalidFormat, ErrInvalidUUID)
+	}
+
+	if validationErr.HasViolations() {
+		return validationErr
+	}
+	return nil
+}
+
+// ValidateRescheduleRequest валидирует запрос на перенос уведомления
+func (v *Validator) ValidateRescheduleRequest(req *dto.RescheduleNotificationRequest) error {
+	validationErr := domain.NewValidationError()
+
+	if req.NotificationDate.Before(time.Now()) {
+		validationErr.Add("notification_date", CodePastDate, ErrPastDate)
+	}
+
+	if validationErr.HasViolations() {
+		return validationErr
+	}
+	return nil
+}
+
+// ValidateListNotificationsQuery валидирует параметры выборки уведомлений
+func (v *Validator) ValidateListNotificationsQuery(query *dto.ListNotificationsQuery) error {
+	validationErr := domain.NewValidationError()
+
+	if query.Status != "" && !v.isValidStatus(query.Status) {
+		validationErr.Add("status", CodeInvalidValue, ErrInvalidStatus)
+	}
+
+	if query.Channel != "" && !v.isValidChannel(query.Channel) {
+		validationErr.Add("channel", CodeInvalidValue, ErrInvalidChannel)
+	}
+
+	if query.Limit < 1 || query.Limit > MaxListLimit {
+		validationErr.Add("limit", CodeInvalidValue, ErrInvalidLimit)
+	}
+
+	if query.Offset < 0 {
+		validationErr.Add("offset", CodeInvalidValue, ErrInvalidOffset)
+	}
+
+	if validationErr.HasViolations() {
+		return validationErr
+	}
+	return nil
+}
+
+// ValidateSuppressRequest валидирует запрос на добавление получателя в список подавления
+func (v *Validator) ValidateSuppressRequest(req *dto.SuppressRequest) error {
+	validationErr := domain.NewValidationError()
+
+	v.validateSuppressionKey(req.Channel, req.RecipientID, validationErr)
+
+	if req.Reason == "" {
+		validationErr.Add("reason", CodeRequired, ErrEmptySuppressionReason)
+	} else if !v.isValidSuppressionReason(req.Reason) {
+		validationErr.Add("reason", CodeInvalidValue, ErrInvalidSuppressionReason)
 	}
 
-	if !v.isValidUUID(id) {
-		return ErrInvalidUUID
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		validationErr.Add("expires_at", CodePastDate, ErrPastExpiry)
 	}
 
+	if validationErr.HasViolations() {
+		return validationErr
+	}
+	return nil
+}
+
+// ValidateSuppressionKey валидирует канал и получателя записи списка подавления
+func (v *Validator) ValidateSuppressionKey(channel domain.Channel, recipientID string) error {
+	validationErr := domain.NewValidationError()
+
+	v.validateSuppressionKey(channel, recipientID, validationErr)
+
+	if validationErr.HasViolations() {
+		return validationErr
+	}
+	return nil
+}
+
+// ValidateListSuppressionsQuery валидирует параметры выборки списка подавления
+func (v *Validator) ValidateListSuppressionsQuery(query *dto.ListSuppressionsQuery) error {
+	validationErr := domain.NewValidationError()
+
+	if query.Channel != "" && !v.isValidChannel(query.Channel) {
+		validationErr.Add("channel", CodeInvalidValue, ErrInvalidChannel)
+	}
+
+	if query.Reason != "" && !v.isValidSuppressionReason(query.Reason) {
+		validationErr.Add("reason", CodeInvalidValue, ErrInvalidSuppressionReason)
+	}
+
+	if query.Limit < 1 || query.Limit > MaxListLimit {
+		validationErr.Add("limit", CodeInvalidValue, ErrInvalidLimit)
+	}
+
+	if query.Offset < 0 {
+		validationErr.Add("offset", CodeInvalidValue, ErrInvalidOffset)
+	}
+
+	if validationErr.HasViolations() {
+		return validationErr
+	}
 	return nil
 }
 
+func (v *Validator) validateSuppressionKey(channel domain.Channel, recipientID string, validationErr *domain.ValidationError) {
+	if !v.isValidChannel(channel) {
+		validationErr.Add("channel", CodeInvalidValue, ErrInvalidChannel)
+	}
+
+	if strings.TrimSpace(recipientID) == "" {
+		validationErr.Add("recipient_id", CodeRequired, ErrEmptyRecipient)
+	}
+}
+
+// validateExpiry проверяет срок актуальности уведомления
+func (v *Validator) validateExpiry(req *dto.CreateNotificationRequest, validationErr *domain.ValidationError) {
+	if req.ExpiresAt != nil && req.MaxLateness != "" {
+		validationErr.Add("max_lateness", CodeConflict, ErrExpiryConflict)
+		return
+	}
+
+	if req.MaxLateness != "" {
+		maxLateness, err := time.ParseDuration(req.MaxLateness)
+		if err != nil || maxLateness <= 0 {
+			validationErr.Add("max_lateness", CodeInvalidFormat, ErrInvalidMaxLateness)
+		}
+		return
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(req.NotificationDate) {
+		validationErr.Add("expires_at", CodeInvalidValue, ErrExpiresBeforeDate)
+	}
+}
+
 func (v *Validator) isValidChannel(channel domain.Channel) bool {
 	validChannels := map[domain.Channel]bool{
 		domain.ChannelTelegram: true,
@@ -80,6 +258,24 @@ func (v *Validator) isValidChannel(channel domain.Channel) bool {
 	return validChannels[channel]
 }
 
+func (v *Validator) isValidStatus(status domain.Status) bool {
+	for _, known := range domain.Statuses {
+		if status == known {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Validator) isValidSuppressionReason(reason domain.SuppressionReason) bool {
+	for _, known := range domain.SuppressionReasons {
+		if reason == known {
+			return true
+		}
+	}
+	return false
+}
+
 func (v *Validator) isValidUUID(uuid string) bool {
 	uuidRegex := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
 	return uuidRegex.MatchString(strings.ToLower(uuid))
@@ -175,3 +175,163 @@ func TestValidateNotificationID(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateCreateNotificationRequest_CollectsAllViolations(t *testing.T) {
+	validator := NewValidator()
+
+	err := validator.ValidateCreateNotificationRequest(&dto.CreateNotificationRequest{
+		Channel:          "invalid",
+		NotificationDate: time.Now().Add(-time.Hour),
+	})
+
+	assert.ErrorIs(t, err, domain.ErrValidation)
+	assert.ErrorIs(t, err, ErrEmptyPayload)
+	assert.ErrorIs(t, err, ErrEmptyRecipient)
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+	assert.ErrorIs(t, err, ErrPastDate)
+
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	fields := make(map[string]string, len(validationErr.Violations))
+	for _, violation := range validationErr.Violations {
+		fields[violation.Field] = violation.Code
+	}
+	assert.Equal(t, map[string]string{
+		"payload":           CodeRequired,
+		"recipient_id":      CodeRequired,
+		"channel":           CodeInvalidValue,
+		"notification_date": CodePastDate,
+	}, fields)
+}
+
+func TestValidateCreateNotificationRequest_Expiry(t *testing.T) {
+	validator := NewValidator()
+	notificationDate := time.Now().Add(time.Hour)
+	afterDate := notificationDate.Add(time.Minute)
+	beforeDate := notificationDate.Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		expiresAt   *time.Time
+		maxLateness string
+		errType     error
+	}{
+		{name: "expires_at after notification_date", expiresAt: &afterDate},
+		{name: "valid max_lateness", maxLateness: "15m"},
+		{name: "expires_at before notification_date", expiresAt: &beforeDate, errType: ErrExpiresBeforeDate},
+		{name: "both fields set", expiresAt: &afterDate, maxLateness: "15m", errType: ErrExpiryConflict},
+		{name: "malformed max_lateness", maxLateness: "soon", errType: ErrInvalidMaxLateness},
+		{name: "negative max_lateness", maxLateness: "-5m", errType: ErrInvalidMaxLateness},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateCreateNotificationRequest(&dto.CreateNotificationRequest{
+				Payload:          "Test message",
+				RecipientID:      "user123",
+				Channel:          domain.ChannelTelegram,
+				NotificationDate: notificationDate,
+				ExpiresAt:        tt.expiresAt,
+				MaxLateness:      tt.maxLateness,
+			})
+			if tt.errType != nil {
+				assert.ErrorIs(t, err, tt.errType)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateCreateNotificationRequest_DigestWithEmailConfig(t *testing.T) {
+	validator := NewValidator()
+
+	req := dto.CreateNotificationRequest{
+		Payload:          "Test message",
+		RecipientID:      "user@example.com",
+		Channel:          domain.ChannelEmail,
+		NotificationDate: time.Now().Add(time.Hour),
+		Digest:           true,
+	}
+	assert.NoError(t, validator.ValidateCreateNotificationRequest(&req))
+
+	req.EmailConfig = &dto.EmailConfig{SMTPHost: "smtp.example.com"}
+	assert.ErrorIs(t, validator.ValidateCreateNotificationRequest(&req), ErrDigestEmailConfig)
+}
+
+func TestValidateListNotificationsQuery(t *testing.T) {
+	validator := NewValidator()
+
+	tests := []struct {
+		name    string
+		query   dto.ListNotificationsQuery
+		errType error
+	}{
+		{name: "no filters", query: dto.ListNotificationsQuery{Limit: 20}},
+		{name: "all filters", query: dto.ListNotificationsQuery{Status: domain.StatusSent, Channel: domain.ChannelEmail, RecipientID: "user@example.com", Limit: 100, Offset: 40}},
+		{name: "unknown status", query: dto.ListNotificationsQuery{Status: "delivered", Limit: 20}, errType: ErrInvalidStatus},
+		{name: "unknown channel", query: dto.ListNotificationsQuery{Channel: "sms", Limit: 20}, errType: ErrInvalidChannel},
+		{name: "zero limit", query: dto.ListNotificationsQuery{Limit: 0}, errType: ErrInvalidLimit},
+		{name: "limit above maximum", query: dto.ListNotificationsQuery{Limit: MaxListLimit + 1}, errType: ErrInvalidLimit},
+		{name: "negative offset", query: dto.ListNotificationsQuery{Limit: 20, Offset: -1}, errType: ErrInvalidOffset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateListNotificationsQuery(&tt.query)
+			if tt.errType != nil {
+				assert.ErrorIs(t, err, tt.errType)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateRescheduleRequest(t *testing.T) {
+	validator := NewValidator()
+
+	assert.NoError(t, validator.ValidateRescheduleRequest(&dto.RescheduleNotificationRequest{NotificationDate: time.Now().Add(time.Hour)}))
+	assert.ErrorIs(t, validator.ValidateRescheduleRequest(&dto.RescheduleNotificationRequest{NotificationDate: time.Now().Add(-time.Hour)}), ErrPastDate)
+	assert.ErrorIs(t, validator.ValidateRescheduleRequest(&dto.RescheduleNotificationRequest{}), ErrPastDate, "missing date is zero time")
+}
+
+func TestValidateSuppressRequest(t *testing.T) {
+	validator := NewValidator()
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		req     dto.SuppressRequest
+		errType error
+	}{
+		{name: "permanent", req: dto.SuppressRequest{Channel: domain.ChannelEmail, RecipientID: "user@example.com", Reason: domain.SuppressionBounced}},
+		{name: "with expiry", req: dto.SuppressRequest{Channel: domain.ChannelTelegram, RecipientID: "123", Reason: domain.SuppressionManual, ExpiresAt: &future}},
+		{name: "unknown channel", req: dto.SuppressRequest{Channel: "sms", RecipientID: "123", Reason: domain.SuppressionManual}, errType: ErrInvalidChannel},
+		{name: "empty recipient", req: dto.SuppressRequest{Channel: domain.ChannelEmail, RecipientID: " ", Reason: domain.SuppressionManual}, errType: ErrEmptyRecipient},
+		{name: "missing reason", req: dto.SuppressRequest{Channel: domain.ChannelEmail, RecipientID: "user@example.com"}, errType: ErrEmptySuppressionReason},
+		{name: "unknown reason", req: dto.SuppressRequest{Channel: domain.ChannelEmail, RecipientID: "user@example.com", Reason: "spam"}, errType: ErrInvalidSuppressionReason},
+		{name: "past expiry", req: dto.SuppressRequest{Channel: domain.ChannelEmail, RecipientID: "user@example.com", Reason: domain.SuppressionManual, ExpiresAt: &past}, errType: ErrPastExpiry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidateSuppressRequest(&tt.req)
+			if tt.errType != nil {
+				assert.ErrorIs(t, err, tt.errType)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateListSuppressionsQuery(t *testing.T) {
+	validator := NewValidator()
+
+	assert.NoError(t, validator.ValidateListSuppressionsQuery(&dto.ListSuppressionsQuery{Channel: domain.ChannelEmail, Reason: domain.SuppressionUnsubscribed, Limit: 20}))
+	assert.ErrorIs(t, validator.ValidateListSuppressionsQuery(&dto.ListSuppressionsQuery{Reason: "spam", Limit: 20}), ErrInvalidSuppressionReason)
+	assert.ErrorIs(t, validator.ValidateListSuppressionsQuery(&dto.ListSuppressionsQuery{Limit: 0}), ErrInvalidLimit)
+}
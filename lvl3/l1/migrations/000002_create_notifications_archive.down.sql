@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_notifications_status_updated_at;
+DROP INDEX IF EXISTS idx_notifications_archive_archived_at;
+DROP INDEX IF EXISTS idx_notifications_archive_status;
+DROP TABLE IF EXISTS notifications_archive;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS notifications_archive (
+    id VARCHAR(36) NOT NULL,
+    payload TEXT NOT NULL,
+    date_created TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    notification_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    sender_id VARCHAR(255) NOT NULL,
+    recipient_id VARCHAR(255) NOT NULL,
+    channel VARCHAR(50) NOT NULL,
+    retries INTEGER NOT NULL DEFAULT 0,
+    archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id, date_created)
+) PARTITION BY RANGE (date_created);
+
+CREATE TABLE IF NOT EXISTS notifications_archive_default PARTITION OF notifications_archive DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_archive_status ON notifications_archive(status);
+CREATE INDEX IF NOT EXISTS idx_notifications_archive_archived_at ON notifications_archive(archived_at);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_status_updated_at ON notifications(status, updated_at);
@@ -0,0 +1,3 @@
+ALTER TABLE notifications_archive DROP COLUMN IF EXISTS expires_at;
+
+ALTER TABLE notifications DROP COLUMN IF EXISTS expires_at;
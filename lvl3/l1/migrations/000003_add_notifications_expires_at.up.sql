@@ -0,0 +1,3 @@
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE notifications_archive ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,10 @@
+ALTER TABLE notifications_archive DROP COLUMN IF EXISTS digest_id;
+ALTER TABLE notifications_archive DROP COLUMN IF EXISTS digest;
+
+DROP INDEX IF EXISTS idx_notifications_digest_id;
+DROP INDEX IF EXISTS idx_notifications_digest_buffer;
+
+ALTER TABLE notifications DROP COLUMN IF EXISTS digest_id;
+ALTER TABLE notifications DROP COLUMN IF EXISTS digest;
+
+DROP TABLE IF EXISTS digests;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS digests (
+    id VARCHAR(36) PRIMARY KEY,
+    recipient_id VARCHAR(255) NOT NULL,
+    channel VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    notification_count INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_digests_recipient_channel ON digests(recipient_id, channel);
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS digest BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS digest_id VARCHAR(36) REFERENCES digests(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_digest_buffer
+    ON notifications(recipient_id, channel, notification_date)
+    WHERE digest AND status = 'pending' AND digest_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_digest_id ON notifications(digest_id);
+
+ALTER TABLE notifications_archive ADD COLUMN IF NOT EXISTS digest BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE notifications_archive ADD COLUMN IF NOT EXISTS digest_id VARCHAR(36);
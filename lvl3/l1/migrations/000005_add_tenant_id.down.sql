@@ -0,0 +1,11 @@
+ALTER TABLE notifications_archive DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE digests DROP COLUMN IF EXISTS tenant_id;
+
+DROP INDEX IF EXISTS idx_notifications_digest_buffer;
+CREATE INDEX IF NOT EXISTS idx_notifications_digest_buffer
+    ON notifications(recipient_id, channel, notification_date)
+    WHERE digest AND status = 'pending' AND digest_id IS NULL;
+
+DROP INDEX IF EXISTS idx_notifications_tenant_date_created;
+ALTER TABLE notifications DROP COLUMN IF EXISTS tenant_id;
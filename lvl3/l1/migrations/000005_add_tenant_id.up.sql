@@ -0,0 +1,11 @@
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(255) NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_date_created ON notifications(tenant_id, date_created);
+
+DROP INDEX IF EXISTS idx_notifications_digest_buffer;
+CREATE INDEX IF NOT EXISTS idx_notifications_digest_buffer
+    ON notifications(tenant_id, recipient_id, channel, notification_date)
+    WHERE digest AND status = 'pending' AND digest_id IS NULL;
+
+ALTER TABLE digests ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(255) NOT NULL DEFAULT 'default';
+
+ALTER TABLE notifications_archive ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(255) NOT NULL DEFAULT 'default';
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS record_notifications_status_history ON notifications;
+DROP FUNCTION IF EXISTS record_notification_status_history();
+
+DROP INDEX IF EXISTS idx_notification_status_history_notification_id;
+DROP TABLE IF EXISTS notification_status_history;
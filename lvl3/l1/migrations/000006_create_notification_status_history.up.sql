@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS notification_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    notification_id VARCHAR(36) NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    notification_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_status_history_notification_id
+    ON notification_status_history(notification_id, id);
+
+CREATE OR REPLACE FUNCTION record_notification_status_history()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND OLD.status = NEW.status
+        AND OLD.notification_date = NEW.notification_date THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO notification_status_history (notification_id, status, notification_date)
+    VALUES (NEW.id, NEW.status, NEW.notification_date);
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER record_notifications_status_history
+    AFTER INSERT OR UPDATE OF status, notification_date ON notifications
+    FOR EACH ROW
+    EXECUTE FUNCTION record_notification_status_history();
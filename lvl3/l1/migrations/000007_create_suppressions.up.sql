@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS suppressions (
+    tenant_id VARCHAR(255) NOT NULL DEFAULT 'default',
+    recipient_id VARCHAR(255) NOT NULL,
+    channel VARCHAR(50) NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, recipient_id, channel)
+);
+
+CREATE INDEX IF NOT EXISTS idx_suppressions_tenant_created_at ON suppressions(tenant_id, created_at);
@@ -0,0 +1 @@
+ALTER TABLE notifications DROP COLUMN IF EXISTS digest_retry_at;
@@ -0,0 +1 @@
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS digest_retry_at TIMESTAMP WITH TIME ZONE;
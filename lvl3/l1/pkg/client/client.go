@@ -0,0 +1,337 @@
+// Package client предоставляет типизированный Go клиент для HTTP API сервиса отложенных уведомлений
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Коды ошибок API, которые возвращает сервис в поле code
+const (
+	CodeValidationFailed = "validation_failed"
+	CodeNotFound         = "not_found"
+	CodeAlreadySent      = "already_sent"
+	CodeAlreadyFailed    = "already_failed"
+	CodeCancelled        = "cancelled"
+	CodeExpired          = "expired"
+	CodePending          = "pending"
+	CodeSuppressed       = "suppressed"
+	CodeQuotaExceeded    = "quota_exceeded"
+	CodeUnauthorized     = "unauthorized"
+	CodeInternal         = "internal_error"
+)
+
+// APIError возвращается, когда сервис ответил статусом ошибки
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Details    []FieldViolation
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("notifier API error (%d): %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("notifier API error (%d %s): %s", e.StatusCode, e.Code, e.Message)
+}
+
+// IsNotFound сообщает, что уведомление не найдено
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsValidationError сообщает, что запрос не прошел валидацию.
+// Нарушения по полям доступны в APIError.Details
+func IsValidationError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == CodeValidationFailed
+}
+
+// IsConflict сообщает, что уведомление уже отправлено, не отправлено или отменено
+func IsConflict(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict
+}
+
+// Client выполняет запросы к API сервиса уведомлений
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient создает новый клиент. baseURL указывает на корень API,
+// например http://localhost:8080/api/v1. Если httpClient равен nil,
+// используется клиент с таймаутом по умолчанию
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+type envelope struct {
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   string           `json:"error,omitempty"`
+	Code    string           `json:"code,omitempty"`
+	Details []FieldViolation `json:"details,omitempty"`
+}
+
+func (e envelope) apiError(resp *http.Response) *APIError {
+	message := e.Error
+	if message == "" {
+		message = resp.Status
+	}
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Code:       e.Code,
+		Message:    message,
+		Details:    e.Details,
+	}
+}
+
+// CreateNotification создает новое отложенное уведомление
+func (c *Client) CreateNotification(ctx context.Context, req CreateNotificationRequest) (*CreateNotificationResponse, error) {
+	var resp CreateNotificationResponse
+	if err := c.do(ctx, http.MethodPost, "/notify", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetNotification получает уведомление и его статус по ID
+func (c *Client) GetNotification(ctx context.Context, id string) (*Notification, error) {
+	var resp Notification
+	if err := c.do(ctx, http.MethodGet, "/notify/"+url.PathEscape(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CancelNotification отменяет уведомление по ID
+func (c *Client) CancelNotification(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/notify/"+url.PathEscape(id), nil, nil)
+}
+
+// RescheduleNotification переносит ожидающее уведомление на новую дату отправки
+func (c *Client) RescheduleNotification(ctx context.Context, id string, notificationDate time.Time) (*Notification, error) {
+	var resp Notification
+	req := RescheduleNotificationRequest{NotificationDate: notificationDate}
+	if err := c.do(ctx, http.MethodPost, "/notify/"+url.PathEscape(id)+"/reschedule", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ResendNotification повторно отправляет уведомление в конечном статусе
+func (c *Client) ResendNotification(ctx context.Context, id string) (*Notification, error) {
+	var resp Notification
+	if err := c.do(ctx, http.MethodPost, "/notify/"+url.PathEscape(id)+"/resend", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListNotifications получает страницу уведомлений, подходящих под фильтр, от новых к старым
+func (c *Client) ListNotifications(ctx context.Context, opts ListNotificationsOptions) (*NotificationList, error) {
+	var resp NotificationList
+	if err := c.do(ctx, http.MethodGet, "/notify"+opts.query(), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetStatusCounts получает количество уведомлений в разрезе статусов
+func (c *Client) GetStatusCounts(ctx context.Context) (*StatusCounts, error) {
+	var resp StatusCounts
+	if err := c.do(ctx, http.MethodGet, "/notify/stats", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetNotificationHistory получает историю статусов уведомления в порядке изменений
+func (c *Client) GetNotificationHistory(ctx context.Context, id string) ([]StatusChange, error) {
+	var resp struct {
+		History []StatusChange `json:"history"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/notify/"+url.PathEscape(id)+"/history", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.History, nil
+}
+
+// ListSuppressions получает страницу действующих записей списка подавления от новых к старым
+func (c *Client) ListSuppressions(ctx context.Context, opts ListSuppressionsOptions) (*SuppressionList, error) {
+	var resp SuppressionList
+	if err := c.do(ctx, http.MethodGet, "/suppressions"+opts.query(), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetSuppression получает действующую запись списка подавления получателя в канале
+func (c *Client) GetSuppression(ctx context.Context, channel Channel, recipientID string) (*Suppression, error) {
+	var resp Suppression
+	if err := c.do(ctx, http.MethodGet, suppressionPath(channel, recipientID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Suppress добавляет получателя в список подавления канала или заменяет существующую запись
+func (c *Client) Suppress(ctx context.Context, channel Channel, recipientID string, req SuppressRequest) (*Suppression, error) {
+	var resp Suppression
+	if err := c.do(ctx, http.MethodPut, suppressionPath(channel, recipientID), req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Unsuppress удаляет получателя из списка подавления канала
+func (c *Client) Unsuppress(ctx context.Context, channel Channel, recipientID string) error {
+	return c.do(ctx, http.MethodDelete, suppressionPath(channel, recipientID), nil, nil)
+}
+
+func suppressionPath(channel Channel, recipientID string) string {
+	return "/suppressions/" + url.PathEscape(string(channel)) + "/" + url.PathEscape(recipientID)
+}
+
+// GetRetentionStats получает статистику хранения и архивации уведомлений
+func (c *Client) GetRetentionStats(ctx context.Context) (*RetentionStats, error) {
+	var resp RetentionStats
+	if err := c.do(ctx, http.MethodGet, "/retention/stats", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// WatchNotification подписывается на изменения статуса уведомления и вызывает fn
+// для каждого события. Возвращает nil, когда сервер закрыл поток после конечного
+// статуса, или ошибку fn, контекста либо соединения
+func (c *Client) WatchNotification(ctx context.Context, id string, fn func(StatusEvent) error) error {
+	return c.stream(ctx, "/notify/"+url.PathEscape(id)+"/events", fn)
+}
+
+// WatchAll подписывается на изменения статусов всех уведомлений до отмены контекста
+// или ошибки fn
+func (c *Client) WatchAll(ctx context.Context, fn func(StatusEvent) error) error {
+	return c.stream(ctx, "/notify/events", fn)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil && !errors.Is(err, io.EOF) {
+		if resp.StatusCode >= http.StatusBadRequest {
+			return &APIError{StatusCode: resp.StatusCode, Message: resp.Status}
+		}
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return env.apiError(resp)
+	}
+
+	if result != nil && len(env.Result) > 0 {
+		if err := json.Unmarshal(env.Result, result); err != nil {
+			return fmt.Errorf("failed to decode result: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) stream(ctx context.Context, path string, fn func(StatusEvent) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	streamClient := *c.httpClient
+	streamClient.Timeout = 0
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to open event stream: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var env envelope
+		_ = json.NewDecoder(resp.Body).Decode(&env)
+		return env.apiError(resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+			var event StatusEvent
+			if err := json.Unmarshal([]byte(data.String()), &event); err != nil {
+				return fmt.Errorf("failed to decode status event: %w", err)
+			}
+			data.Reset()
+			if err := fn(event); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read event stream: %w", err)
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_WatchNotification(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/notify/test-id/events", r.URL.Path)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": heartbeat\n\n")
+		fmt.Fprint(w, "event: status\ndata: {\"id\":\"test-id\",\"status\":\"pending\",\"timestamp\":\"2024-12-31T23:59:59Z\"}\n\n")
+		fmt.Fprint(w, "event: status\ndata: {\"id\":\"test-id\",\"status\":\"sent\",\"timestamp\":\"2024-12-31T23:59:59Z\"}\n\n")
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL+"/api/v1/", nil)
+
+	var statuses []Status
+	err := c.WatchNotification(context.Background(), "test-id", func(event StatusEvent) error {
+		statuses = append(statuses, event.Status)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []Status{StatusPending, StatusSent}, statuses)
+}
+
+func TestClient_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"notification not found","code":"not_found"}`)
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, nil)
+
+	_, err := c.GetNotification(context.Background(), "missing")
+	require.Error(t, err)
+	assert.True(t, IsNotFound(err))
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "notification not found", apiErr.Message)
+	assert.Equal(t, CodeNotFound, apiErr.Code)
+	assert.False(t, IsConflict(err))
+}
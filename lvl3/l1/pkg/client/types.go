@@ -0,0 +1,265 @@
+package client
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Status представляет статус уведомления
+type Status string
+
+const (
+	// StatusPending указывает, что уведомление ожидает отправки
+	StatusPending Status = "pending"
+	// StatusSent указывает, что уведомление успешно отправлено
+	StatusSent Status = "sent"
+	// StatusFailed указывает, что уведомление не удалось отправить
+	StatusFailed Status = "failed"
+	// StatusCancelled указывает, что уведомление было отменено
+	StatusCancelled Status = "cancelled"
+	// StatusExpired указывает, что срок актуальности уведомления истек до отправки
+	StatusExpired Status = "expired"
+	// StatusSuppressed указывает, что уведомление не отправлено, так как получатель в списке подавления
+	StatusSuppressed Status = "suppressed"
+)
+
+// Channel представляет канал отправки уведомления
+type Channel string
+
+const (
+	// ChannelEmail указывает на email канал
+	ChannelEmail Channel = "email"
+	// ChannelTelegram указывает на telegram канал
+	ChannelTelegram Channel = "telegram"
+)
+
+// CreateNotificationRequest представляет запрос на создание уведомления
+type CreateNotificationRequest struct {
+	Payload          string       `json:"payload"`
+	NotificationDate time.Time    `json:"notification_date"`
+	SenderID         string       `json:"sender_id,omitempty"`
+	RecipientID      string       `json:"recipient_id"`
+	Channel          Channel      `json:"channel"`
+	EmailConfig      *EmailConfig `json:"email_config,omitempty"`
+	// ExpiresAt задает момент, после которого уведомление не отправляется
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// MaxLateness задает допустимое опоздание относительно NotificationDate, например "15m"
+	MaxLateness string `json:"max_lateness,omitempty"`
+	// Digest отправляет уведомление в составе дайджеста получателю
+	Digest bool `json:"digest,omitempty"`
+}
+
+// EmailConfig содержит пользовательскую конфигурацию email отправки
+type EmailConfig struct {
+	Subject   string `json:"subject,omitempty"`
+	FromName  string `json:"from_name,omitempty"`
+	FromEmail string `json:"from_email,omitempty"`
+	SMTPHost  string `json:"smtp_host,omitempty"`
+	SMTPPort  int    `json:"smtp_port,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Password  string `json:"password,omitempty"`
+}
+
+// CreateNotificationResponse представляет ответ на создание уведомления
+type CreateNotificationResponse struct {
+	ID     string `json:"id"`
+	Status Status `json:"status"`
+}
+
+// FieldViolation описывает нарушение валидации одного поля запроса
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Notification представляет уведомление, возвращаемое API
+type Notification struct {
+	ID               string     `json:"id"`
+	Status           Status     `json:"status"`
+	Payload          string     `json:"payload"`
+	Channel          Channel    `json:"channel"`
+	NotificationDate time.Time  `json:"notification_date"`
+	RecipientID      string     `json:"recipient_id"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	Digest           bool       `json:"digest,omitempty"`
+	DigestID         string     `json:"digest_id,omitempty"`
+}
+
+// RescheduleNotificationRequest представляет запрос на перенос уведомления
+type RescheduleNotificationRequest struct {
+	NotificationDate time.Time `json:"notification_date"`
+}
+
+// ListNotificationsOptions задает фильтр и страницу выборки уведомлений.
+// Пустые поля не ограничивают выборку, нулевой Limit означает размер страницы по умолчанию
+type ListNotificationsOptions struct {
+	Status      Status
+	Channel     Channel
+	RecipientID string
+	Limit       int
+	Offset      int
+}
+
+func (o ListNotificationsOptions) query() string {
+	values := url.Values{}
+	if o.Status != "" {
+		values.Set("status", string(o.Status))
+	}
+	if o.Channel != "" {
+		values.Set("channel", string(o.Channel))
+	}
+	if o.RecipientID != "" {
+		values.Set("recipient_id", o.RecipientID)
+	}
+	if o.Limit > 0 {
+		values.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		values.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return "?" + values.Encode()
+}
+
+// NotificationList представляет страницу уведомлений
+type NotificationList struct {
+	Notifications []Notification `json:"notifications"`
+	Total         int64          `json:"total"`
+	Limit         int            `json:"limit"`
+	Offset        int            `json:"offset"`
+}
+
+// StatusChange описывает запись истории статусов уведомления
+type StatusChange struct {
+	Status           Status    `json:"status"`
+	NotificationDate time.Time `json:"notification_date"`
+	ChangedAt        time.Time `json:"changed_at"`
+}
+
+// StatusCounts содержит количество уведомлений в разрезе статусов
+type StatusCounts struct {
+	Counts map[Status]int64 `json:"counts"`
+	Total  int64            `json:"total"`
+}
+
+// SuppressionReason представляет причину подавления получателя
+type SuppressionReason string
+
+const (
+	// SuppressionUnsubscribed указывает, что получатель отписался по ссылке из письма
+	SuppressionUnsubscribed SuppressionReason = "unsubscribed"
+	// SuppressionBounced указывает, что письма получателю не доставляются
+	SuppressionBounced SuppressionReason = "bounced"
+	// SuppressionComplaint указывает, что получатель пожаловался на рассылку
+	SuppressionComplaint SuppressionReason = "complaint"
+	// SuppressionManual указывает, что получатель добавлен в список вручную
+	SuppressionManual SuppressionReason = "manual"
+)
+
+// SuppressRequest представляет запрос на добавление получателя в список подавления
+type SuppressRequest struct {
+	Reason SuppressionReason `json:"reason"`
+	// ExpiresAt задает момент окончания подавления, nil — бессрочно
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Suppression представляет запись списка подавления
+type Suppression struct {
+	RecipientID string            `json:"recipient_id"`
+	Channel     Channel           `json:"channel"`
+	Reason      SuppressionReason `json:"reason"`
+	ExpiresAt   *time.Time        `json:"expires_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// ListSuppressionsOptions задает фильтр и страницу выборки списка подавления.
+// Пустые поля не ограничивают выборку, нулевой Limit означает размер страницы по умолчанию
+type ListSuppressionsOptions struct {
+	Channel     Channel
+	RecipientID string
+	Reason      SuppressionReason
+	Limit       int
+	Offset      int
+}
+
+func (o ListSuppressionsOptions) query() string {
+	values := url.Values{}
+	if o.Channel != "" {
+		values.Set("channel", string(o.Channel))
+	}
+	if o.RecipientID != "" {
+		values.Set("recipient_id", o.RecipientID)
+	}
+	if o.Reason != "" {
+		values.Set("reason", string(o.Reason))
+	}
+	if o.Limit > 0 {
+		values.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		values.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return "?" + values.Encode()
+}
+
+// SuppressionList представляет страницу списка подавления
+type SuppressionList struct {
+	Suppressions []Suppression `json:"suppressions"`
+	Total        int64         `json:"total"`
+	Limit        int           `json:"limit"`
+	Offset       int           `json:"offset"`
+}
+
+// StatusEvent описывает изменение статуса уведомления
+type StatusEvent struct {
+	ID        string    `json:"id"`
+	Status    Status    `json:"status"`
+	Channel   Channel   `json:"channel,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// IsFinal сообщает, является ли статус события конечным
+func (e StatusEvent) IsFinal() bool {
+	switch e.Status {
+	case StatusSent, StatusFailed, StatusCancelled, StatusExpired, StatusSuppressed:
+		return true
+	default:
+		return false
+	}
+}
+
+// RetentionStatusStats содержит статистику хранения по одному статусу
+type RetentionStatusStats struct {
+	Status   Status `json:"status"`
+	Period   string `json:"period"`
+	Stored   int64  `json:"stored"`
+	Eligible int64  `json:"eligible"`
+}
+
+// RetentionRun описывает результат одного прогона архивации
+type RetentionRun struct {
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt time.Time        `json:"finished_at"`
+	Archived   map[Status]int64 `json:"archived"`
+	Deleted    int64            `json:"deleted"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// RetentionStats содержит отчет о хранении и архивации уведомлений
+type RetentionStats struct {
+	Enabled       bool                   `json:"enabled"`
+	Archive       string                 `json:"archive"`
+	Interval      string                 `json:"interval"`
+	BatchSize     int                    `json:"batch_size"`
+	Statuses      []RetentionStatusStats `json:"statuses"`
+	TotalArchived int64                  `json:"total_archived"`
+	TotalDeleted  int64                  `json:"total_deleted"`
+	LastRun       *RetentionRun          `json:"last_run,omitempty"`
+}
@@ -0,0 +1,1046 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: notifier.proto
+
+package notifierpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Status представляет статус уведомления
+type Status int32
+
+const (
+	Status_STATUS_UNSPECIFIED Status = 0
+	Status_STATUS_PENDING     Status = 1
+	Status_STATUS_SENT        Status = 2
+	Status_STATUS_FAILED      Status = 3
+	Status_STATUS_CANCELLED   Status = 4
+	Status_STATUS_EXPIRED     Status = 5
+	Status_STATUS_SUPPRESSED  Status = 6
+)
+
+// Enum value maps for Status.
+var (
+	Status_name = map[int32]string{
+		0: "STATUS_UNSPECIFIED",
+		1: "STATUS_PENDING",
+		2: "STATUS_SENT",
+		3: "STATUS_FAILED",
+		4: "STATUS_CANCELLED",
+		5: "STATUS_EXPIRED",
+		6: "STATUS_SUPPRESSED",
+	}
+	Status_value = map[string]int32{
+		"STATUS_UNSPECIFIED": 0,
+		"STATUS_PENDING":     1,
+		"STATUS_SENT":        2,
+		"STATUS_FAILED":      3,
+		"STATUS_CANCELLED":   4,
+		"STATUS_EXPIRED":     5,
+		"STATUS_SUPPRESSED":  6,
+	}
+)
+
+func (x Status) Enum() *Status {
+	p := new(Status)
+	*p = x
+	return p
+}
+
+func (x Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_notifier_proto_enumTypes[0].Descriptor()
+}
+
+func (Status) Type() protoreflect.EnumType {
+	return &file_notifier_proto_enumTypes[0]
+}
+
+func (x Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Status.Descriptor instead.
+func (Status) EnumDescriptor() ([]byte, []int) {
+	return file_notifier_proto_rawDescGZIP(), []int{0}
+}
+
+// Channel представляет канал отправки уведомления
+type Channel int32
+
+const (
+	Channel_CHANNEL_UNSPECIFIED Channel = 0
+	Channel_CHANNEL_EMAIL       Channel = 1
+	Channel_CHANNEL_TELEGRAM    Channel = 2
+)
+
+// Enum value maps for Channel.
+var (
+	Channel_name = map[int32]string{
+		0: "CHANNEL_UNSPECIFIED",
+		1: "CHANNEL_EMAIL",
+		2: "CHANNEL_TELEGRAM",
+	}
+	Channel_value = map[string]int32{
+		"CHANNEL_UNSPECIFIED": 0,
+		"CHANNEL_EMAIL":       1,
+		"CHANNEL_TELEGRAM":    2,
+	}
+)
+
+func (x Channel) Enum() *Channel {
+	p := new(Channel)
+	*p = x
+	return p
+}
+
+func (x Channel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Channel) Descriptor() protoreflect.EnumDescriptor {
+	return file_notifier_proto_enumTypes[1].Descriptor()
+}
+
+func (Channel) Type() protoreflect.EnumType {
+	return &file_notifier_proto_enumTypes[1]
+}
+
+func (x Channel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Channel.Descriptor instead.
+func (Channel) EnumDescriptor() ([]byte, []int) {
+	return file_notifier_proto_rawDescGZIP(), []int{1}
+}
+
+// EmailConfig содержит пользовательскую конфигурацию email отправки
+type EmailConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subject       string                 `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	FromName      string                 `protobuf:"bytes,2,opt,name=from_name,json=fromName,proto3" json:"from_name,omitempty"`
+	FromEmail     string                 `protobuf:"bytes,3,opt,name=from_email,json=fromEmail,proto3" json:"from_email,omitempty"`
+	SmtpHost      string                 `protobuf:"bytes,4,opt,name=smtp_host,json=smtpHost,proto3" json:"smtp_host,omitempty"`
+	SmtpPort      int32                  `protobuf:"varint,5,opt,name=smtp_port,json=smtpPort,proto3" json:"smtp_port,omitempty"`
+	Username      string                 `protobuf:"bytes,6,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,7,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmailConfig) Reset() {
+	*x = EmailConfig{}
+	mi := &file_notifier_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmailConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmailConfig) ProtoMessage() {}
+
+func (x *EmailConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_notifier_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmailConfig.ProtoReflect.Descriptor instead.
+func (*EmailConfig) Descriptor() ([]byte, []int) {
+	return file_notifier_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *EmailConfig) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *EmailConfig) GetFromName() string {
+	if x != nil {
+		return x.FromName
+	}
+	return ""
+}
+
+func (x *EmailConfig) GetFromEmail() string {
+	if x != nil {
+		return x.FromEmail
+	}
+	return ""
+}
+
+func (x *EmailConfig) GetSmtpHost() string {
+	if x != nil {
+		return x.SmtpHost
+	}
+	return ""
+}
+
+func (x *EmailConfig) GetSmtpPort() int32 {
+	if x != nil {
+		return x.SmtpPort
+	}
+	return 0
+}
+
+func (x *EmailConfig) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *EmailConfig) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type CreateNotificationRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Payload          string                 `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	NotificationDate *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=notification_date,json=notificationDate,proto3" json:"notification_date,omitempty"`
+	SenderId         string                 `protobuf:"bytes,3,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	RecipientId      string                 `protobuf:"bytes,4,opt,name=recipient_id,json=recipientId,proto3" json:"recipient_id,omitempty"`
+	Channel          Channel                `protobuf:"varint,5,opt,name=channel,proto3,enum=notifier.v1.Channel" json:"channel,omitempty"`
+	EmailConfig      *EmailConfig           `protobuf:"bytes,6,opt,name=email_config,json=emailConfig,proto3" json:"email_config,omitempty"`
+	// expires_at и max_lateness взаимоисключаются: после срока уведомление не отправляется
+	ExpiresAt   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	MaxLateness *durationpb.Duration   `protobuf:"bytes,8,opt,name=max_lateness,json=maxLateness,proto3" json:"max_lateness,omitempty"`
+	// digest отправляет уведомление в составе дайджеста получателю
+	Digest        bool `protobuf:"varint,9,opt,name=digest,proto3" json:"digest,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateNotificationRequest) Reset() {
+	*x = CreateNotificationRequest{}
+	mi := &file_notifier_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateNotificationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateNotificationRequest) ProtoMessage() {}
+
+func (x *CreateNotificationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notifier_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateNotificationRequest.ProtoReflect.Descriptor instead.
+func (*CreateNotificationRequest) Descriptor() ([]byte, []int) {
+	return file_notifier_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateNotificationRequest) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+func (x *CreateNotificationRequest) GetNotificationDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotificationDate
+	}
+	return nil
+}
+
+func (x *CreateNotificationRequest) GetSenderId() string {
+	if x != nil {
+		return x.SenderId
+	}
+	return ""
+}
+
+func (x *CreateNotificationRequest) GetRecipientId() string {
+	if x != nil {
+		return x.RecipientId
+	}
+	return ""
+}
+
+func (x *CreateNotificationRequest) GetChannel() Channel {
+	if x != nil {
+		return x.Channel
+	}
+	return Channel_CHANNEL_UNSPECIFIED
+}
+
+func (x *CreateNotificationRequest) GetEmailConfig() *EmailConfig {
+	if x != nil {
+		return x.EmailConfig
+	}
+	return nil
+}
+
+func (x *CreateNotificationRequest) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *CreateNotificationRequest) GetMaxLateness() *durationpb.Duration {
+	if x != nil {
+		return x.MaxLateness
+	}
+	return nil
+}
+
+func (x *CreateNotificationRequest) GetDigest() bool {
+	if x != nil {
+		return x.Digest
+	}
+	return false
+}
+
+type CreateNotificationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status        Status                 `protobuf:"varint,2,opt,name=status,proto3,enum=notifier.v1.Status" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateNotificationResponse) Reset() {
+	*x = CreateNotificationResponse{}
+	mi := &file_notifier_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateNotificationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateNotificationResponse) ProtoMessage() {}
+
+func (x *CreateNotificationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_notifier_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateNotificationResponse.ProtoReflect.Descriptor instead.
+func (*CreateNotificationResponse) Descriptor() ([]byte, []int) {
+	return file_notifier_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateNotificationResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CreateNotificationResponse) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_STATUS_UNSPECIFIED
+}
+
+type GetNotificationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNotificationRequest) Reset() {
+	*x = GetNotificationRequest{}
+	mi := &file_notifier_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNotificationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNotificationRequest) ProtoMessage() {}
+
+func (x *GetNotificationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notifier_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNotificationRequest.ProtoReflect.Descriptor instead.
+func (*GetNotificationRequest) Descriptor() ([]byte, []int) {
+	return file_notifier_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetNotificationRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Notification представляет уведомление
+type Notification struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status           Status                 `protobuf:"varint,2,opt,name=status,proto3,enum=notifier.v1.Status" json:"status,omitempty"`
+	Payload          string                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	Channel          Channel                `protobuf:"varint,4,opt,name=channel,proto3,enum=notifier.v1.Channel" json:"channel,omitempty"`
+	NotificationDate *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=notification_date,json=notificationDate,proto3" json:"notification_date,omitempty"`
+	DateCreated      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=date_created,json=dateCreated,proto3" json:"date_created,omitempty"`
+	SenderId         string                 `protobuf:"bytes,7,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	RecipientId      string                 `protobuf:"bytes,8,opt,name=recipient_id,json=recipientId,proto3" json:"recipient_id,omitempty"`
+	Retries          int32                  `protobuf:"varint,9,opt,name=retries,proto3" json:"retries,omitempty"`
+	ExpiresAt        *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Digest           bool                   `protobuf:"varint,11,opt,name=digest,proto3" json:"digest,omitempty"`
+	// digest_id указывает дайджест, в составе которого отправлено уведомление
+	DigestId      string `protobuf:"bytes,12,opt,name=digest_id,json=digestId,proto3" json:"digest_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Notification) Reset() {
+	*x = Notification{}
+	mi := &file_notifier_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Notification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Notification) ProtoMessage() {}
+
+func (x *Notification) ProtoReflect() protoreflect.Message {
+	mi := &file_notifier_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Notification.ProtoReflect.Descriptor instead.
+func (*Notification) Descriptor() ([]byte, []int) {
+	return file_notifier_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Notification) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Notification) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_STATUS_UNSPECIFIED
+}
+
+func (x *Notification) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+func (x *Notification) GetChannel() Channel {
+	if x != nil {
+		return x.Channel
+	}
+	return Channel_CHANNEL_UNSPECIFIED
+}
+
+func (x *Notification) GetNotificationDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotificationDate
+	}
+	return nil
+}
+
+func (x *Notification) GetDateCreated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateCreated
+	}
+	return nil
+}
+
+func (x *Notification) GetSenderId() string {
+	if x != nil {
+		return x.SenderId
+	}
+	return ""
+}
+
+func (x *Notification) GetRecipientId() string {
+	if x != nil {
+		return x.RecipientId
+	}
+	return ""
+}
+
+func (x *Notification) GetRetries() int32 {
+	if x != nil {
+		return x.Retries
+	}
+	return 0
+}
+
+func (x *Notification) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Notification) GetDigest() bool {
+	if x != nil {
+		return x.Digest
+	}
+	return false
+}
+
+func (x *Notification) GetDigestId() string {
+	if x != nil {
+		return x.DigestId
+	}
+	return ""
+}
+
+type GetStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_notifier_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notifier_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_notifier_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetStatusRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status        Status                 `protobuf:"varint,2,opt,name=status,proto3,enum=notifier.v1.Status" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusResponse) Reset() {
+	*x = GetStatusResponse{}
+	mi := &file_notifier_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusResponse) ProtoMessage() {}
+
+func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_notifier_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetStatusResponse) Descriptor() ([]byte, []int) {
+	return file_notifier_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetStatusResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetStatusResponse) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_STATUS_UNSPECIFIED
+}
+
+type CancelNotificationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelNotificationRequest) Reset() {
+	*x = CancelNotificationRequest{}
+	mi := &file_notifier_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelNotificationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelNotificationRequest) ProtoMessage() {}
+
+func (x *CancelNotificationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notifier_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelNotificationRequest.ProtoReflect.Descriptor instead.
+func (*CancelNotificationRequest) Descriptor() ([]byte, []int) {
+	return file_notifier_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CancelNotificationRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CancelNotificationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status        Status                 `protobuf:"varint,2,opt,name=status,proto3,enum=notifier.v1.Status" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelNotificationResponse) Reset() {
+	*x = CancelNotificationResponse{}
+	mi := &file_notifier_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelNotificationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelNotificationResponse) ProtoMessage() {}
+
+func (x *CancelNotificationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_notifier_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelNotificationResponse.ProtoReflect.Descriptor instead.
+func (*CancelNotificationResponse) Descriptor() ([]byte, []int) {
+	return file_notifier_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CancelNotificationResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CancelNotificationResponse) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_STATUS_UNSPECIFIED
+}
+
+type WatchStatusRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id уведомления, пустое значение подписывает на все уведомления
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchStatusRequest) Reset() {
+	*x = WatchStatusRequest{}
+	mi := &file_notifier_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchStatusRequest) ProtoMessage() {}
+
+func (x *WatchStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notifier_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchStatusRequest.ProtoReflect.Descriptor instead.
+func (*WatchStatusRequest) Descriptor() ([]byte, []int) {
+	return file_notifier_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchStatusRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// StatusEvent описывает изменение статуса уведомления
+type StatusEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status        Status                 `protobuf:"varint,2,opt,name=status,proto3,enum=notifier.v1.Status" json:"status,omitempty"`
+	Channel       Channel                `protobuf:"varint,3,opt,name=channel,proto3,enum=notifier.v1.Channel" json:"channel,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusEvent) Reset() {
+	*x = StatusEvent{}
+	mi := &file_notifier_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusEvent) ProtoMessage() {}
+
+func (x *StatusEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_notifier_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusEvent.ProtoReflect.Descriptor instead.
+func (*StatusEvent) Descriptor() ([]byte, []int) {
+	return file_notifier_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *StatusEvent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *StatusEvent) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_STATUS_UNSPECIFIED
+}
+
+func (x *StatusEvent) GetChannel() Channel {
+	if x != nil {
+		return x.Channel
+	}
+	return Channel_CHANNEL_UNSPECIFIED
+}
+
+func (x *StatusEvent) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+var File_notifier_proto protoreflect.FileDescriptor
+
+const file_notifier_proto_rawDesc = "" +
+	"\n" +
+	"\x0enotifier.proto\x12\vnotifier.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd5\x01\n" +
+	"\vEmailConfig\x12\x18\n" +
+	"\asubject\x18\x01 \x01(\tR\asubject\x12\x1b\n" +
+	"\tfrom_name\x18\x02 \x01(\tR\bfromName\x12\x1d\n" +
+	"\n" +
+	"from_email\x18\x03 \x01(\tR\tfromEmail\x12\x1b\n" +
+	"\tsmtp_host\x18\x04 \x01(\tR\bsmtpHost\x12\x1b\n" +
+	"\tsmtp_port\x18\x05 \x01(\x05R\bsmtpPort\x12\x1a\n" +
+	"\busername\x18\x06 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\a \x01(\tR\bpassword\"\xbc\x03\n" +
+	"\x19CreateNotificationRequest\x12\x18\n" +
+	"\apayload\x18\x01 \x01(\tR\apayload\x12G\n" +
+	"\x11notification_date\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x10notificationDate\x12\x1b\n" +
+	"\tsender_id\x18\x03 \x01(\tR\bsenderId\x12!\n" +
+	"\frecipient_id\x18\x04 \x01(\tR\vrecipientId\x12.\n" +
+	"\achannel\x18\x05 \x01(\x0e2\x14.notifier.v1.ChannelR\achannel\x12;\n" +
+	"\femail_config\x18\x06 \x01(\v2\x18.notifier.v1.EmailConfigR\vemailConfig\x129\n" +
+	"\n" +
+	"expires_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12<\n" +
+	"\fmax_lateness\x18\b \x01(\v2\x19.google.protobuf.DurationR\vmaxLateness\x12\x16\n" +
+	"\x06digest\x18\t \x01(\bR\x06digest\"Y\n" +
+	"\x1aCreateNotificationResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12+\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x13.notifier.v1.StatusR\x06status\"(\n" +
+	"\x16GetNotificationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xe7\x03\n" +
+	"\fNotification\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12+\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x13.notifier.v1.StatusR\x06status\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\tR\apayload\x12.\n" +
+	"\achannel\x18\x04 \x01(\x0e2\x14.notifier.v1.ChannelR\achannel\x12G\n" +
+	"\x11notification_date\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x10notificationDate\x12=\n" +
+	"\fdate_created\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vdateCreated\x12\x1b\n" +
+	"\tsender_id\x18\a \x01(\tR\bsenderId\x12!\n" +
+	"\frecipient_id\x18\b \x01(\tR\vrecipientId\x12\x18\n" +
+	"\aretries\x18\t \x01(\x05R\aretries\x129\n" +
+	"\n" +
+	"expires_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x16\n" +
+	"\x06digest\x18\v \x01(\bR\x06digest\x12\x1b\n" +
+	"\tdigest_id\x18\f \x01(\tR\bdigestId\"\"\n" +
+	"\x10GetStatusRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"P\n" +
+	"\x11GetStatusResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12+\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x13.notifier.v1.StatusR\x06status\"+\n" +
+	"\x19CancelNotificationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"Y\n" +
+	"\x1aCancelNotificationResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12+\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x13.notifier.v1.StatusR\x06status\"$\n" +
+	"\x12WatchStatusRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xb4\x01\n" +
+	"\vStatusEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12+\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x13.notifier.v1.StatusR\x06status\x12.\n" +
+	"\achannel\x18\x03 \x01(\x0e2\x14.notifier.v1.ChannelR\achannel\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp*\x99\x01\n" +
+	"\x06Status\x12\x16\n" +
+	"\x12STATUS_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eSTATUS_PENDING\x10\x01\x12\x0f\n" +
+	"\vSTATUS_SENT\x10\x02\x12\x11\n" +
+	"\rSTATUS_FAILED\x10\x03\x12\x14\n" +
+	"\x10STATUS_CANCELLED\x10\x04\x12\x12\n" +
+	"\x0eSTATUS_EXPIRED\x10\x05\x12\x15\n" +
+	"\x11STATUS_SUPPRESSED\x10\x06*K\n" +
+	"\aChannel\x12\x17\n" +
+	"\x13CHANNEL_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rCHANNEL_EMAIL\x10\x01\x12\x14\n" +
+	"\x10CHANNEL_TELEGRAM\x10\x022\xca\x03\n" +
+	"\x0fNotifierService\x12e\n" +
+	"\x12CreateNotification\x12&.notifier.v1.CreateNotificationRequest\x1a'.notifier.v1.CreateNotificationResponse\x12Q\n" +
+	"\x0fGetNotification\x12#.notifier.v1.GetNotificationRequest\x1a\x19.notifier.v1.Notification\x12J\n" +
+	"\tGetStatus\x12\x1d.notifier.v1.GetStatusRequest\x1a\x1e.notifier.v1.GetStatusResponse\x12e\n" +
+	"\x12CancelNotification\x12&.notifier.v1.CancelNotificationRequest\x1a'.notifier.v1.CancelNotificationResponse\x12J\n" +
+	"\vWatchStatus\x12\x1f.notifier.v1.WatchStatusRequest\x1a\x18.notifier.v1.StatusEvent0\x01B,Z*delayed-notifier/pkg/notifierpb;notifierpbb\x06proto3"
+
+var (
+	file_notifier_proto_rawDescOnce sync.Once
+	file_notifier_proto_rawDescData []byte
+)
+
+func file_notifier_proto_rawDescGZIP() []byte {
+	file_notifier_proto_rawDescOnce.Do(func() {
+		file_notifier_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_notifier_proto_rawDesc), len(file_notifier_proto_rawDesc)))
+	})
+	return file_notifier_proto_rawDescData
+}
+
+var file_notifier_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_notifier_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_notifier_proto_goTypes = []any{
+	(Status)(0),                        // 0: notifier.v1.Status
+	(Channel)(0),                       // 1: notifier.v1.Channel
+	(*EmailConfig)(nil),                // 2: notifier.v1.EmailConfig
+	(*CreateNotificationRequest)(nil),  // 3: notifier.v1.CreateNotificationRequest
+	(*CreateNotificationResponse)(nil), // 4: notifier.v1.CreateNotificationResponse
+	(*GetNotificationRequest)(nil),     // 5: notifier.v1.GetNotificationRequest
+	(*Notification)(nil),               // 6: notifier.v1.Notification
+	(*GetStatusRequest)(nil),           // 7: notifier.v1.GetStatusRequest
+	(*GetStatusResponse)(nil),          // 8: notifier.v1.GetStatusResponse
+	(*CancelNotificationRequest)(nil),  // 9: notifier.v1.CancelNotificationRequest
+	(*CancelNotificationResponse)(nil), // 10: notifier.v1.CancelNotificationResponse
+	(*WatchStatusRequest)(nil),         // 11: notifier.v1.WatchStatusRequest
+	(*StatusEvent)(nil),                // 12: notifier.v1.StatusEvent
+	(*timestamppb.Timestamp)(nil),      // 13: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),        // 14: google.protobuf.Duration
+}
+var file_notifier_proto_depIdxs = []int32{
+	13, // 0: notifier.v1.CreateNotificationRequest.notification_date:type_name -> google.protobuf.Timestamp
+	1,  // 1: notifier.v1.CreateNotificationRequest.channel:type_name -> notifier.v1.Channel
+	2,  // 2: notifier.v1.CreateNotificationRequest.email_config:type_name -> notifier.v1.EmailConfig
+	13, // 3: notifier.v1.CreateNotificationRequest.expires_at:type_name -> google.protobuf.Timestamp
+	14, // 4: notifier.v1.CreateNotificationRequest.max_lateness:type_name -> google.protobuf.Duration
+	0,  // 5: notifier.v1.CreateNotificationResponse.status:type_name -> notifier.v1.Status
+	0,  // 6: notifier.v1.Notification.status:type_name -> notifier.v1.Status
+	1,  // 7: notifier.v1.Notification.channel:type_name -> notifier.v1.Channel
+	13, // 8: notifier.v1.Notification.notification_date:type_name -> google.protobuf.Timestamp
+	13, // 9: notifier.v1.Notification.date_created:type_name -> google.protobuf.Timestamp
+	13, // 10: notifier.v1.Notification.expires_at:type_name -> google.protobuf.Timestamp
+	0,  // 11: notifier.v1.GetStatusResponse.status:type_name -> notifier.v1.Status
+	0,  // 12: notifier.v1.CancelNotificationResponse.status:type_name -> notifier.v1.Status
+	0,  // 13: notifier.v1.StatusEvent.status:type_name -> notifier.v1.Status
+	1,  // 14: notifier.v1.StatusEvent.channel:type_name -> notifier.v1.Channel
+	13, // 15: notifier.v1.StatusEvent.timestamp:type_name -> google.protobuf.Timestamp
+	3,  // 16: notifier.v1.NotifierService.CreateNotification:input_type -> notifier.v1.CreateNotificationRequest
+	5,  // 17: notifier.v1.NotifierService.GetNotification:input_type -> notifier.v1.GetNotificationRequest
+	7,  // 18: notifier.v1.NotifierService.GetStatus:input_type -> notifier.v1.GetStatusRequest
+	9,  // 19: notifier.v1.NotifierService.CancelNotification:input_type -> notifier.v1.CancelNotificationRequest
+	11, // 20: notifier.v1.NotifierService.WatchStatus:input_type -> notifier.v1.WatchStatusRequest
+	4,  // 21: notifier.v1.NotifierService.CreateNotification:output_type -> notifier.v1.CreateNotificationResponse
+	6,  // 22: notifier.v1.NotifierService.GetNotification:output_type -> notifier.v1.Notification
+	8,  // 23: notifier.v1.NotifierService.GetStatus:output_type -> notifier.v1.GetStatusResponse
+	10, // 24: notifier.v1.NotifierService.CancelNotification:output_type -> notifier.v1.CancelNotificationResponse
+	12, // 25: notifier.v1.NotifierService.WatchStatus:output_type -> notifier.v1.StatusEvent
+	21, // [21:26] is the sub-list for method output_type
+	16, // [16:21] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_notifier_proto_init() }
+func file_notifier_proto_init() {
+	if File_notifier_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_notifier_proto_rawDesc), len(file_notifier_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_notifier_proto_goTypes,
+		DependencyIndexes: file_notifier_proto_depIdxs,
+		EnumInfos:         file_notifier_proto_enumTypes,
+		MessageInfos:      file_notifier_proto_msgTypes,
+	}.Build()
+	File_notifier_proto = out.File
+	file_notifier_proto_goTypes = nil
+	file_notifier_proto_depIdxs = nil
+}
@@ -0,0 +1,262 @@
+const API_BASE = '/api/v1';
+const STATUSES = ['pending', 'sent', 'failed', 'cancelled', 'expired', 'suppressed'];
+const RESENDABLE_STATUSES = ['sent', 'failed', 'cancelled', 'expired', 'suppressed'];
+const TOKEN_STORAGE_KEY = 'notifierAccessToken';
+const REFRESH_DEBOUNCE_MS = 500;
+
+let offset = 0;
+let total = 0;
+let selectedId = null;
+let eventStream = null;
+let refreshTimer = null;
+
+document.addEventListener('DOMContentLoaded', function () {
+    document.getElementById('accessToken').value = localStorage.getItem(TOKEN_STORAGE_KEY) || '';
+    document.getElementById('filterForm').addEventListener('submit', (e) => {
+        e.preventDefault();
+        offset = 0;
+        loadNotifications();
+    });
+
+    refreshAll();
+    subscribeToEvents();
+});
+
+function saveAccessToken() {
+    localStorage.setItem(TOKEN_STORAGE_KEY, document.getElementById('accessToken').value.trim());
+    refreshAll();
+    subscribeToEvents();
+}
+
+function accessToken() {
+    return localStorage.getItem(TOKEN_STORAGE_KEY) || '';
+}
+
+async function api(method, path, body) {
+    const headers = {'Accept': 'application/json'};
+    const token = accessToken();
+    if (token) {
+        headers['Authorization'] = `Bearer ${token}`;
+    }
+    if (body !== undefined) {
+        headers['Content-Type'] = 'application/json';
+    }
+
+    const response = await fetch(`${API_BASE}${path}`, {
+        method: method,
+        headers: headers,
+        body: body !== undefined ? JSON.stringify(body) : undefined
+    });
+
+    const data = await response.json().catch(() => ({error: response.statusText}));
+    if (!response.ok) {
+        throw new Error(formatApiError(data));
+    }
+    return data.result;
+}
+
+function refreshAll() {
+    loadCounters();
+    loadNotifications();
+    if (selectedId) {
+        selectNotification(selectedId);
+    }
+}
+
+// scheduleRefresh объединяет серию событий статусов в одно обновление страницы
+function scheduleRefresh() {
+    clearTimeout(refreshTimer);
+    refreshTimer = setTimeout(refreshAll, REFRESH_DEBOUNCE_MS);
+}
+
+function subscribeToEvents() {
+    if (eventStream) {
+        eventStream.close();
+    }
+
+    const liveStatus = document.getElementById('liveStatus');
+    const token = accessToken();
+    const query = token ? `?access_token=${encodeURIComponent(token)}` : '';
+    eventStream = new EventSource(`${API_BASE}/notify/events${query}`);
+
+    eventStream.onopen = () => {
+        liveStatus.textContent = 'Live updates connected';
+    };
+
+    eventStream.onerror = () => {
+        liveStatus.textContent = 'Live updates disconnected, reconnecting...';
+    };
+
+    eventStream.addEventListener('status', scheduleRefresh);
+}
+
+async function loadCounters() {
+    const counters = document.getElementById('statusCounters');
+    try {
+        const stats = await api('GET', '/notify/stats');
+        counters.innerHTML = STATUSES.map(status => `
+            <div class="counter" onclick="filterByStatus('${status}')">
+                <div class="counter-value">${stats.counts[status] || 0}</div>
+                <div class="status-${status}">${status.toUpperCase()}</div>
+            </div>
+        `).join('') + `
+            <div class="counter" onclick="filterByStatus('')">
+                <div class="counter-value">${stats.total}</div>
+                <div>TOTAL</div>
+            </div>
+        `;
+    } catch (error) {
+        counters.innerHTML = `<div class="error">${escapeHtml(error.message)}</div>`;
+    }
+}
+
+function filterByStatus(status) {
+    document.getElementById('filterStatus').value = status;
+    offset = 0;
+    loadNotifications();
+}
+
+async function loadNotifications() {
+    const body = document.getElementById('notificationsBody');
+    const limit = parseInt(document.getElementById('pageSize').value);
+    const params = new URLSearchParams({limit: limit, offset: offset});
+
+    const status = document.getElementById('filterStatus').value;
+    const channel = document.getElementById('filterChannel').value;
+    const recipient = document.getElementById('filterRecipient').value.trim();
+    if (status) params.set('status', status);
+    if (channel) params.set('channel', channel);
+    if (recipient) params.set('recipient_id', recipient);
+
+    try {
+        const page = await api('GET', `/notify?${params}`);
+        total = page.total;
+
+        if (page.notifications.length === 0) {
+            body.innerHTML = '<tr><td colspan="6" class="empty">No notifications</td></tr>';
+        } else {
+            body.innerHTML = page.notifications.map(n => `
+                <tr onclick="selectNotification('${n.id}')" class="${n.id === selectedId ? 'selected' : ''}">
+                    <td class="mono">${n.id}</td>
+                    <td><span class="status-${n.status}">${n.status.toUpperCase()}</span></td>
+                    <td>${n.channel}${n.digest ? ' (digest)' : ''}</td>
+                    <td>${escapeHtml(n.recipient_id)}</td>
+                    <td>${formatDate(n.notification_date)}</td>
+                    <td class="payload">${escapeHtml(n.payload)}</td>
+                </tr>
+            `).join('');
+        }
+
+        const from = total === 0 ? 0 : offset + 1;
+        const to = Math.min(offset + limit, total);
+        document.getElementById('pageInfo').textContent = `${from}-${to} of ${total}`;
+        document.getElementById('prevPage').disabled = offset === 0;
+        document.getElementById('nextPage').disabled = offset + limit >= total;
+    } catch (error) {
+        body.innerHTML = `<tr><td colspan="6"><div class="error">${escapeHtml(error.message)}</div></td></tr>`;
+    }
+}
+
+function changePage(direction) {
+    const limit = parseInt(document.getElementById('pageSize').value);
+    offset = Math.max(0, offset + direction * limit);
+    loadNotifications();
+}
+
+async function selectNotification(id) {
+    selectedId = id;
+    document.getElementById('detailSection').style.display = 'block';
+    const detail = document.getElementById('notificationDetail');
+
+    try {
+        const [notification, history] = await Promise.all([
+            api('GET', `/notify/${id}`),
+            api('GET', `/notify/${id}/history`)
+        ]);
+
+        detail.innerHTML = `
+            <div class="notification-result">
+                <div class="status-display">
+                    <strong>Status:</strong>
+                    <span class="status-${notification.status}">${notification.status.toUpperCase()}</span>
+                </div>
+                <div><strong>ID:</strong> <span class="mono">${notification.id}</span></div>
+                <div><strong>Channel:</strong> ${notification.channel}${notification.digest ? ' (digest)' : ''}</div>
+                <div><strong>Recipient:</strong> ${escapeHtml(notification.recipient_id)}</div>
+                <div><strong>Notify at:</strong> ${formatDate(notification.notification_date)}</div>
+                ${notification.expires_at ? `<div><strong>Expires at:</strong> ${formatDate(notification.expires_at)}</div>` : ''}
+                <div><strong>Payload:</strong> ${escapeHtml(notification.payload)}</div>
+            </div>
+        `;
+
+        const pending = notification.status === 'pending';
+        document.getElementById('cancelButton').disabled = !pending;
+        document.getElementById('rescheduleButton').disabled = !pending;
+        document.getElementById('rescheduleDate').disabled = !pending;
+        document.getElementById('resendButton').disabled = !RESENDABLE_STATUSES.includes(notification.status);
+
+        document.getElementById('statusHistory').innerHTML = history.history.length === 0
+            ? '<li>No history recorded</li>'
+            : history.history.map(change => `
+                <li>
+                    ${formatDate(change.changed_at)}
+                    <span class="status-${change.status}">${change.status.toUpperCase()}</span>
+                    notify at ${formatDate(change.notification_date)}
+                </li>
+            `).join('');
+    } catch (error) {
+        detail.innerHTML = `<div class="error">${escapeHtml(error.message)}</div>`;
+        document.getElementById('statusHistory').innerHTML = '';
+    }
+}
+
+async function cancelSelected() {
+    if (!confirm('Are you sure you want to cancel this notification?')) {
+        return;
+    }
+    await runAction(() => api('DELETE', `/notify/${selectedId}`));
+}
+
+async function rescheduleSelected() {
+    const value = document.getElementById('rescheduleDate').value;
+    if (!value) {
+        alert('Please choose a new notification date');
+        return;
+    }
+    const notificationDate = new Date(value).toISOString();
+    await runAction(() => api('POST', `/notify/${selectedId}/reschedule`, {notification_date: notificationDate}));
+}
+
+async function resendSelected() {
+    if (!confirm('Send this notification again?')) {
+        return;
+    }
+    await runAction(() => api('POST', `/notify/${selectedId}/resend`));
+}
+
+async function runAction(action) {
+    try {
+        await action();
+        refreshAll();
+    } catch (error) {
+        alert(error.message);
+    }
+}
+
+function formatDate(value) {
+    return value ? new Date(value).toLocaleString() : '';
+}
+
+function escapeHtml(value) {
+    const div = document.createElement('div');
+    div.textContent = value;
+    return div.innerHTML;
+}
+
+function formatApiError(error) {
+    if (!error.details || error.details.length === 0) {
+        return error.error;
+    }
+    const fields = error.details.map(d => `${d.field}: ${d.message}`).join('\n');
+    return `${error.error}\n${fields}`;
+}
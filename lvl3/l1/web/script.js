@@ -83,7 +83,16 @@ document.getElementById('createForm').addEventListener('submit', async (e) => {
         channel: document.getElementById('channel').value
     };
 
-    if (notification.channel === 'email') {
+    const maxLateness = document.getElementById('maxLateness').value.trim();
+    if (maxLateness) {
+        notification.max_lateness = maxLateness;
+    }
+
+    if (document.getElementById('digest').checked) {
+        notification.digest = true;
+    }
+
+    if (notification.channel === 'email' && !notification.digest) {
         notification.email_config = {
             subject: document.getElementById('emailSubject').value,
             from_name: document.getElementById('emailFromName').value,
@@ -117,7 +126,7 @@ document.getElementById('createForm').addEventListener('submit', async (e) => {
         } else {
             const error = await response.json();
             console.error('Error response:', error);
-            alert(`Error creating notification: ${error.error}`);
+            alert(`Error creating notification: ${formatApiError(error)}`);
         }
     } catch (error) {
         console.error('Network error:', error);
@@ -125,10 +134,84 @@ document.getElementById('createForm').addEventListener('submit', async (e) => {
     }
 });
 
+const MAX_LIVE_EVENTS = 50;
+
+let notificationStream = null;
+
 document.addEventListener('DOMContentLoaded', function () {
     toggleChannelFields();
+    subscribeToAllEvents();
 });
 
+function subscribeToAllEvents() {
+    const liveStatus = document.getElementById('liveStatus');
+    const liveEvents = document.getElementById('liveEvents');
+    const source = new EventSource(`${API_BASE}/notify/events`);
+
+    source.onopen = () => {
+        liveStatus.textContent = 'Connected';
+    };
+
+    source.onerror = () => {
+        liveStatus.textContent = 'Disconnected, reconnecting...';
+    };
+
+    source.addEventListener('status', (e) => {
+        const event = JSON.parse(e.data);
+        const item = document.createElement('li');
+        const time = new Date(event.timestamp).toLocaleTimeString();
+
+        item.innerHTML = `${time} <span class="status-${event.status}">${event.status.toUpperCase()}</span> ${event.id}`;
+        liveEvents.prepend(item);
+
+        while (liveEvents.children.length > MAX_LIVE_EVENTS) {
+            liveEvents.removeChild(liveEvents.lastChild);
+        }
+    });
+}
+
+function watchNotification(id) {
+    if (notificationStream) {
+        notificationStream.close();
+    }
+
+    notificationStream = new EventSource(`${API_BASE}/notify/${id}/events`);
+
+    notificationStream.addEventListener('status', (e) => {
+        const event = JSON.parse(e.data);
+        renderNotificationStatus(id, event.status);
+
+        if (['sent', 'failed', 'cancelled', 'expired', 'suppressed'].includes(event.status)) {
+            notificationStream.close();
+            notificationStream = null;
+        }
+    });
+
+    notificationStream.onerror = () => {
+        if (notificationStream && notificationStream.readyState === EventSource.CLOSED) {
+            notificationStream = null;
+        }
+    };
+}
+
+function renderNotificationStatus(id, status) {
+    document.getElementById('searchResult').innerHTML = `
+        <div class="notification-result">
+            <div class="status-display">
+                <strong>Status:</strong> 
+                <span class="status-${status}">${status.toUpperCase()}</span>
+            </div>
+            <div class="notification-actions">
+                <button onclick="cancelNotification('${id}')" 
+                        class="cancel-btn" 
+                        ${status !== 'pending' ? 'disabled' : ''}>
+                    Cancel Notification
+                </button>
+            </div>
+        </div>
+    `;
+}
+
 async function findNotification() {
     const id = document.getElementById('searchId').value.trim();
     if (!id) {
@@ -142,27 +225,11 @@ async function findNotification() {
 
         if (response.ok) {
             const data = await response.json();
-            const notification = data.result;
-            const status = notification.status;
-
-            result.innerHTML = `
-                <div class="notification-result">
-                    <div class="status-display">
-                        <strong>Status:</strong> 
-                        <span class="status-${status}">${status.toUpperCase()}</span>
-                    </div>
-                    <div class="notification-actions">
-                        <button onclick="cancelNotification('${id}')" 
-                                class="cancel-btn" 
-                                ${status === 'cancelled' || status === 'sent' ? 'disabled' : ''}>
-                            Cancel Notification
-                        </button>
-                    </div>
-                </div>
-            `;
+            renderNotificationStatus(id, data.result.status);
+            watchNotification(id);
         } else {
             const error = await response.json();
-            result.innerHTML = `<div class="error">${error.error}</div>`;
+            result.innerHTML = `<div class="error">${formatApiError(error)}</div>`;
         }
     } catch (error) {
         document.getElementById('searchResult').innerHTML = '<div class="error">Error fetching notification</div>';
@@ -181,12 +248,19 @@ async function cancelNotification(id) {
 
         if (response.ok) {
             alert('Notification cancelled successfully!');
-            findNotification();
         } else {
             const error = await response.json();
-            alert(`Error cancelling notification: ${error.error}`);
+            alert(`Error cancelling notification: ${formatApiError(error)}`);
         }
     } catch (error) {
         alert('Error cancelling notification');
     }
-}
\ No newline at end of file
+}
+
+function formatApiError(error) {
+    if (!error.details || error.details.length === 0) {
+        return error.error;
+    }
+    const fields = error.details.map(d => `${d.field}: ${d.message}`).join('\n');
+    return `${error.error}\n${fields}`;
+}